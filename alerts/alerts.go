@@ -0,0 +1,264 @@
+// Package alerts evaluates the conditions in metrics/alerts.yml in-process,
+// so deployments without Prometheus and Alertmanager still learn about them
+// from the log and /admin/alerts.
+package alerts
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/AnshulDekate/urlShortener/metrics"
+)
+
+const (
+	DefaultInterval = 30 * time.Second
+
+	// window is the span counter increases are measured over, like [5m] in
+	// the Prometheus rules.
+	window      = 5 * time.Minute
+	pingTimeout = 2 * time.Second
+)
+
+const (
+	StateInactive = "inactive"
+	StatePending  = "pending"
+	StateFiring   = "firing"
+)
+
+// sample is the total of each watched counter at one evaluation.
+type sample struct {
+	at     time.Time
+	totals map[string]float64
+}
+
+// readings is what rules are checked against: the last database ping, how
+// much each counter grew over the window and the current value of each
+// gauge.
+type readings struct {
+	dbUp     bool
+	increase func(name string) float64
+	gauge    func(name string) float64
+}
+
+// rule is one condition. check returns the measured value and whether the
+// condition holds, or ok false when there was too little traffic in the
+// window to judge. hold is how long the condition must last to fire.
+type rule struct {
+	name      string
+	summary   string
+	threshold float64
+	hold      time.Duration
+	check     func(r readings) (value float64, active bool, ok bool)
+}
+
+var rules = []rule{
+	{
+		name:      "DatabaseDown",
+		summary:   "The database is not answering pings.",
+		threshold: 0,
+		hold:      time.Minute,
+		check: func(r readings) (float64, bool, bool) {
+			if r.dbUp {
+				return 1, false, true
+			}
+			return 0, true, true
+		},
+	},
+	{
+		name:      "CollisionRateHigh",
+		summary:   "More than 10% of generated short codes collide; the code space is filling up.",
+		threshold: 0.1,
+		hold:      5 * time.Minute,
+		check: func(r readings) (float64, bool, bool) {
+			collisions := r.increase("urlshortener_shortcode_collisions_total")
+			attempts := r.increase("urlshortener_shortcode_generated_total") + collisions
+			if attempts < 20 {
+				return 0, false, false
+			}
+			rate := collisions / attempts
+			return rate, rate > 0.1, true
+		},
+	},
+	{
+		name:      "CacheHitRatioLow",
+		summary:   "Fewer than half of redirects are served from the destination cache.",
+		threshold: 0.5,
+		hold:      15 * time.Minute,
+		check: func(r readings) (float64, bool, bool) {
+			hits := r.increase("urlshortener_destination_cache_hits_total")
+			lookups := hits + r.increase("urlshortener_destination_cache_misses_total")
+			if lookups < 100 {
+				return 0, false, false
+			}
+			ratio := hits / lookups
+			return ratio, ratio < 0.5, true
+		},
+	},
+	{
+		name:      "RateLimitSaturated",
+		summary:   "More than 5% of requests are rejected by the rate limiter.",
+		threshold: 0.05,
+		hold:      5 * time.Minute,
+		check: func(r readings) (float64, bool, bool) {
+			requests := r.increase("urlshortener_http_requests_total")
+			if requests < 100 {
+				return 0, false, false
+			}
+			share := r.increase("urlshortener_rate_limited_total") / requests
+			return share, share > 0.05, true
+		},
+	},
+	{
+		name:      "KeyspaceFilling",
+		summary:   "More than half of the random short code space is issued; most candidates now collide.",
+		threshold: 0.5,
+		hold:      15 * time.Minute,
+		check: func(r readings) (float64, bool, bool) {
+			used := r.gauge("urlshortener_shortcode_keyspace_utilization")
+			return used, used > 0.5, true
+		},
+	},
+}
+
+// Alert is the current state of one rule. Value is nil while there is too
+// little traffic to judge the rule.
+type Alert struct {
+	Name        string     `json:"name"`
+	State       string     `json:"state"`
+	Summary     string     `json:"summary"`
+	Value       *float64   `json:"value"`
+	Threshold   float64    `json:"threshold"`
+	ActiveSince *time.Time `json:"active_since,omitempty"`
+}
+
+// Evaluator checks the rules on every Evaluate call, typically from a job.
+type Evaluator struct {
+	ping     func(ctx context.Context) error
+	gatherer prometheus.Gatherer
+
+	mu          sync.Mutex
+	samples     []sample
+	alerts      []Alert
+	evaluatedAt time.Time
+}
+
+// NewEvaluator returns an evaluator that checks the database with ping and
+// reads counters from the default Prometheus registry.
+func NewEvaluator(ping func(ctx context.Context) error) *Evaluator {
+	e := &Evaluator{ping: ping, gatherer: prometheus.DefaultGatherer}
+	for _, r := range rules {
+		e.alerts = append(e.alerts, Alert{Name: r.name, State: StateInactive, Summary: r.summary, Threshold: r.threshold})
+	}
+	return e
+}
+
+// Evaluate pings the database, samples the counters and updates every
+// alert, logging alerts that start firing or resolve.
+func (e *Evaluator) Evaluate(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	pingErr := e.ping(pingCtx)
+	cancel()
+	if pingErr != nil {
+		metrics.DatabaseUp.Set(0)
+	} else {
+		metrics.DatabaseUp.Set(1)
+	}
+
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	totals := make(map[string]float64)
+	gauges := make(map[string]float64)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			if c := m.GetCounter(); c != nil {
+				totals[mf.GetName()] += c.GetValue()
+			}
+			if g := m.GetGauge(); g != nil {
+				gauges[mf.GetName()] += g.GetValue()
+			}
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.samples = append(e.samples, sample{at: now, totals: totals})
+	// Keep the newest sample at least a window old as the baseline.
+	for len(e.samples) > 2 && now.Sub(e.samples[1].at) >= window {
+		e.samples = e.samples[1:]
+	}
+	oldest := e.samples[0]
+	current := readings{
+		dbUp: pingErr == nil,
+		increase: func(name string) float64 {
+			return totals[name] - oldest.totals[name]
+		},
+		gauge: func(name string) float64 {
+			return gauges[name]
+		},
+	}
+
+	for i, r := range rules {
+		a := &e.alerts[i]
+		value, active, ok := r.check(current)
+		if !ok {
+			a.Value = nil
+			active = false
+		} else {
+			a.Value = &value
+		}
+
+		if !active {
+			if a.State == StateFiring {
+				log.Printf("INFO: Alert %s resolved.", a.Name)
+			}
+			a.State = StateInactive
+			a.ActiveSince = nil
+			continue
+		}
+		if a.ActiveSince == nil {
+			a.ActiveSince = &now
+		}
+		if now.Sub(*a.ActiveSince) >= r.hold {
+			if a.State != StateFiring {
+				log.Printf("WARN: Alert %s firing: %s (value %.3g, threshold %.3g)", a.Name, a.Summary, value, a.Threshold)
+			}
+			a.State = StateFiring
+		} else {
+			a.State = StatePending
+		}
+	}
+	e.evaluatedAt = now
+	return nil
+}
+
+// Handler reports the state of every alert. It answers 503 while any alert
+// is firing so it can double as a monitor target.
+func (e *Evaluator) Handler(c *gin.Context) {
+	e.mu.Lock()
+	alerts := make([]Alert, len(e.alerts))
+	copy(alerts, e.alerts)
+	evaluatedAt := e.evaluatedAt
+	e.mu.Unlock()
+
+	status := http.StatusOK
+	for _, a := range alerts {
+		if a.State == StateFiring {
+			status = http.StatusServiceUnavailable
+		}
+	}
+	var at *time.Time
+	if !evaluatedAt.IsZero() {
+		at = &evaluatedAt
+	}
+	c.JSON(status, gin.H{"alerts": alerts, "evaluated_at": at})
+}
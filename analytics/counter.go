@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const DefaultCountFlushInterval = time.Second
+
+type CountStore interface {
+	IncrementClickCounts(ctx context.Context, counts map[string]int) error
+}
+
+// ClickCounter aggregates per-link click counts in memory and adds them to
+// the links in one UPDATE per interval, so redirects only read the database.
+type ClickCounter struct {
+	Store         CountStore
+	FlushInterval time.Duration
+
+	mu      sync.Mutex
+	pending map[string]int
+}
+
+func NewClickCounter(store CountStore) *ClickCounter {
+	return &ClickCounter{
+		Store:         store,
+		FlushInterval: DefaultCountFlushInterval,
+		pending:       make(map[string]int),
+	}
+}
+
+func (c *ClickCounter) Add(shortCode string) {
+	c.mu.Lock()
+	c.pending[shortCode]++
+	c.mu.Unlock()
+}
+
+func (c *ClickCounter) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.flush()
+		case <-ctx.Done():
+			c.flush()
+			return
+		}
+	}
+}
+
+func (c *ClickCounter) flush() {
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	counts := c.pending
+	c.pending = make(map[string]int, len(counts))
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := c.Store.IncrementClickCounts(ctx, counts); err != nil {
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		log.Printf("ERROR: Failed to persist %d click counts for %d links: %v", total, len(counts), err)
+	}
+}
@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+// LiveWindow is how far back live counters look.
+const LiveWindow = 60 * time.Second
+
+type liveBucket struct {
+	second int64
+	total  int
+	byCode map[string]int
+}
+
+type TrendingLink struct {
+	ShortCode string `json:"short_url"`
+	Clicks    int    `json:"clicks"`
+}
+
+// LiveCounter keeps per-second click counts for the last LiveWindow in a ring
+// buffer. Buckets are reused in place once their second falls out of the
+// window, so memory stays bounded by the number of links clicked per minute.
+type LiveCounter struct {
+	mu      sync.Mutex
+	buckets [int(LiveWindow / time.Second)]liveBucket
+}
+
+func NewLiveCounter() *LiveCounter {
+	return &LiveCounter{}
+}
+
+func (l *LiveCounter) Record(e events.Event) {
+	sec := e.OccurredAt.Unix()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := &l.buckets[sec%int64(len(l.buckets))]
+	if b.second != sec {
+		if b.second > sec {
+			return // older than the window
+		}
+		*b = liveBucket{second: sec, byCode: make(map[string]int)}
+	}
+	b.total++
+	b.byCode[e.ShortCode]++
+}
+
+// Snapshot returns the clicks in the window ending at now and up to limit
+// links with the most clicks in it.
+func (l *LiveCounter) Snapshot(now time.Time, limit int) (int, []TrendingLink) {
+	end := now.Unix()
+	start := end - int64(len(l.buckets)) + 1
+
+	total := 0
+	byCode := make(map[string]int)
+	l.mu.Lock()
+	for i := range l.buckets {
+		b := &l.buckets[i]
+		if b.second < start || b.second > end {
+			continue
+		}
+		total += b.total
+		for code, n := range b.byCode {
+			byCode[code] += n
+		}
+	}
+	l.mu.Unlock()
+
+	trending := make([]TrendingLink, 0, len(byCode))
+	for code, n := range byCode {
+		trending = append(trending, TrendingLink{ShortCode: code, Clicks: n})
+	}
+	sort.Slice(trending, func(i, j int) bool {
+		if trending[i].Clicks != trending[j].Clicks {
+			return trending[i].Clicks > trending[j].Clicks
+		}
+		return trending[i].ShortCode < trending[j].ShortCode
+	})
+	if len(trending) > limit {
+		trending = trending[:limit]
+	}
+	return total, trending
+}
@@ -0,0 +1,72 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/queue"
+)
+
+const (
+	DefaultQueueSize     = 50000
+	DefaultBatchSize     = 500
+	DefaultFlushInterval = 1 * time.Second
+	flushTimeout         = 10 * time.Second
+)
+
+type Store interface {
+	InsertEvents(ctx context.Context, batch []events.Event) (int, error)
+}
+
+// Enricher adds derived fields to an event before it is stored. Enrichers run
+// on the recorder goroutine, off the redirect path.
+type Enricher interface {
+	Enrich(e *events.Event)
+}
+
+// Recorder buffers click and view events and persists them in batches so the
+// redirect path never waits on an analytics write.
+type Recorder struct {
+	Store         Store
+	Enrichers     []Enricher
+	BatchSize     int
+	FlushInterval time.Duration
+	// Queue holds events until they are written in batches.
+	Queue queue.Queue
+}
+
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{
+		Store:         store,
+		BatchSize:     DefaultBatchSize,
+		FlushInterval: DefaultFlushInterval,
+		Queue:         queue.NewMemory(DefaultQueueSize),
+	}
+}
+
+func (r *Recorder) Record(e events.Event) {
+	if err := r.Queue.Publish(e); err != nil {
+		log.Printf("WARN: Analytics queue full. Dropping %s event for %s.", e.Type, e.ShortCode)
+	}
+}
+
+func (r *Recorder) Run(ctx context.Context) {
+	queue.Consume(ctx, r.Queue, r.BatchSize, r.FlushInterval, r.flush)
+}
+
+func (r *Recorder) flush(batch []events.Event) {
+	for _, enricher := range r.Enrichers {
+		for i := range batch {
+			enricher.Enrich(&batch[i])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if _, err := r.Store.InsertEvents(ctx, batch); err != nil {
+		log.Printf("ERROR: Failed to persist %d analytics events: %v", len(batch), err)
+	}
+}
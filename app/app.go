@@ -0,0 +1,251 @@
+// Package app holds what the urlShortener binaries share: configuration
+// from the environment, the database connection, migrations, and the
+// service with its background workers and jobs. cmd/server, cmd/worker and
+// cmd/migrate are thin mains over it.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/AnshulDekate/urlShortener/analytics"
+	"github.com/AnshulDekate/urlShortener/devices"
+	"github.com/AnshulDekate/urlShortener/geoip"
+	"github.com/AnshulDekate/urlShortener/linkcheck"
+	"github.com/AnshulDekate/urlShortener/migrate"
+	"github.com/AnshulDekate/urlShortener/migrations"
+	"github.com/AnshulDekate/urlShortener/queryplan"
+	"github.com/AnshulDekate/urlShortener/queue"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/screenshot"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/AnshulDekate/urlShortener/sharedcache"
+	"github.com/AnshulDekate/urlShortener/stream"
+	"github.com/AnshulDekate/urlShortener/wayback"
+	"github.com/AnshulDekate/urlShortener/webhook"
+)
+
+// App is a configured service and the queues behind it.
+type App struct {
+	DB      *sql.DB
+	Replica *sql.DB
+	Service *service.Service
+
+	Dispatcher   *webhook.Dispatcher
+	Recorder     *analytics.Recorder
+	ClickCounter *analytics.ClickCounter
+	ClickHub     *stream.Hub
+
+	closers []io.Closer
+}
+
+// New builds the service on db and the optional read replica from the
+// environment, starting the query sampler OpenDatabase returned, if any.
+func New(db *sql.DB, replica *sql.DB, sampler *queryplan.Sampler) (*App, error) {
+	a := &App{DB: db, Replica: replica}
+	repo := &repository.Repository{DB: db, Replica: replica, Region: os.Getenv("REGION")}
+
+	a.Dispatcher = webhook.NewDispatcher(repo)
+	a.Dispatcher.Log = repo
+	a.Recorder = analytics.NewRecorder(repo)
+	a.ClickHub = stream.NewHub()
+	a.ClickCounter = analytics.NewClickCounter(repo)
+	a.Recorder.Enrichers = append(a.Recorder.Enrichers, devices.Parser{})
+	if err := a.configureQueues(); err != nil {
+		a.Close()
+		return nil, err
+	}
+	if path := os.Getenv("ASN_DB_PATH"); path != "" {
+		asnDB, err := geoip.OpenASN(path)
+		if err != nil {
+			return nil, err
+		}
+		a.closers = append(a.closers, asnDB)
+		a.Recorder.Enrichers = append(a.Recorder.Enrichers, asnDB)
+		log.Printf("INFO: Enriching clicks with network data from %s (%s).", path, asnDB.DatabaseType())
+	}
+	if path := os.Getenv("CITY_DB_PATH"); path != "" {
+		cityDB, err := geoip.OpenCity(path)
+		if err != nil {
+			a.Close()
+			return nil, err
+		}
+		a.closers = append(a.closers, cityDB)
+		a.Recorder.Enrichers = append(a.Recorder.Enrichers, cityDB)
+		log.Printf("INFO: Enriching clicks with locations from %s (%s).", path, cityDB.DatabaseType())
+	}
+
+	objectStore, err := newObjectStore()
+	if err != nil {
+		a.Close()
+		return nil, fmt.Errorf("failed to configure object storage: %w", err)
+	}
+
+	snowflake, err := newSnowflakeGenerator()
+	if err != nil {
+		a.Close()
+		return nil, fmt.Errorf("failed to configure code generator: %w", err)
+	}
+	if snowflake != nil {
+		log.Printf("INFO: Generating Snowflake codes as node %d.", snowflake.NodeID())
+	}
+	sharedCache, err := a.newSharedCache()
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+	regionCode := os.Getenv("REGION_CODE")
+	if err := service.ValidateRegionCode(regionCode); err != nil {
+		a.Close()
+		return nil, fmt.Errorf("REGION_CODE %q: %w", regionCode, err)
+	}
+	if repo.Region != "" && snowflake == nil && regionCode == "" {
+		log.Printf("WARN: REGION is set without REGION_CODE or Snowflake codes; regions sharing a database may issue the same code.")
+	}
+
+	svc := &service.Service{
+		Repo:                 repo,
+		RotationGracePeriod:  EnvDuration("ROTATION_GRACE_PERIOD", service.DefaultRotationGracePeriod),
+		Webhooks:             a.Dispatcher,
+		Analytics:            a.Recorder,
+		Storage:              objectStore,
+		BackupInterval:       EnvDuration("BACKUP_INTERVAL", 0),
+		DeletedLinkRetention: EnvDuration("DELETED_LINK_RETENTION", service.DefaultDeletedLinkRetention),
+		ClickEventRetention:  EnvDuration("CLICK_EVENT_RETENTION", 0),
+		Snowflake:            snowflake,
+		Clicks:               a.ClickHub,
+		Live:                 analytics.NewLiveCounter(),
+		Counter:              a.ClickCounter,
+		DestinationCacheTTL:  EnvDuration("DESTINATION_CACHE_TTL", 0),
+		DestinationStaleTTL:  EnvDuration("DESTINATION_CACHE_STALE_TTL", 0),
+		SharedCache:          sharedCache,
+		NegativeCacheTTL:     EnvDuration("NEGATIVE_CACHE_TTL", service.DefaultNegativeCacheTTL),
+		Spam:                 newSpamPolicy(),
+		ReviewThreshold:      EnvInt("REVIEW_SCORE_THRESHOLD", service.DefaultReviewThreshold),
+		DomainSignalHalfLife: EnvDuration("DOMAIN_SIGNAL_HALF_LIFE", service.DefaultDomainSignalHalfLife),
+		LinkChecker:          linkcheck.NewChecker(),
+		HealthRecheck:        EnvDuration("LINK_HEALTH_RECHECK", 0),
+		InspectDestinations:  EnvBool("INSPECT_DESTINATIONS", false),
+		ExpiryNotice:         EnvDuration("EXPIRY_NOTICE_PERIOD", service.DefaultExpiryNotice),
+		ShortURLHosts:        EnvList("SHORT_URL_HOSTS"),
+
+		WebhookDeliveryRetention: EnvDuration("WEBHOOK_DELIVERY_RETENTION", service.DefaultWebhookDeliveryRetention),
+		QueryPlanRetention:       EnvDuration("QUERY_PLAN_RETENTION", service.DefaultQueryPlanRetention),
+		RegionCode:               regionCode,
+		ConversionSecret:         []byte(os.Getenv("CONVERSION_SECRET")),
+		ConversionWindow:         EnvDuration("CONVERSION_WINDOW", service.DefaultConversionWindow),
+		ClickIDParam:             Env("CLICK_ID_PARAM", service.DefaultClickIDParam),
+	}
+	if svc.SchemaVersion, err = migrate.LatestVersion(migrations.Files); err != nil {
+		return nil, err
+	}
+	if EnvBool("WAYBACK_FALLBACK", false) {
+		svc.Archive = wayback.NewClient()
+	}
+	if endpoint := os.Getenv("SCREENSHOT_SERVICE_URL"); endpoint != "" {
+		if svc.Screenshots, err = screenshot.NewClient(endpoint); err != nil {
+			a.Close()
+			return nil, err
+		}
+	}
+	if sampler != nil {
+		sampler.Start(repo)
+		a.closers = append(a.closers, sampler)
+	}
+	a.Service = svc
+	return a, nil
+}
+
+// newSharedCache connects to the CACHE_REDIS_URLS nodes, if any. The shared
+// cache holds entries for DESTINATION_CACHE_TTL, so it needs one.
+func (a *App) newSharedCache() (*sharedcache.Ring, error) {
+	urls := EnvList("CACHE_REDIS_URLS")
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	if EnvDuration("DESTINATION_CACHE_TTL", 0) <= 0 {
+		return nil, errors.New("CACHE_REDIS_URLS needs DESTINATION_CACHE_TTL")
+	}
+	ring, err := sharedcache.New(urls)
+	if err != nil {
+		return nil, err
+	}
+	a.closers = append(a.closers, ring)
+	log.Printf("INFO: Sharing cached destinations over %d Redis nodes.", len(urls))
+	return ring, nil
+}
+
+// configureQueues moves the click and webhook queues to Redis streams when
+// QUEUE_BACKEND=redis. Every instance then publishes to the same streams and
+// consumes them as one group, so events published by a server can be
+// delivered by whichever instance reads them first.
+func (a *App) configureQueues() error {
+	switch backend := Env("QUEUE_BACKEND", "memory"); backend {
+	case "memory":
+		return nil
+	case "redis":
+	default:
+		return fmt.Errorf("unknown QUEUE_BACKEND %q (expected memory or redis)", backend)
+	}
+
+	opts, err := redis.ParseURL(MustEnv("REDIS_URL"))
+	if err != nil {
+		return fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	prefix := Env("QUEUE_STREAM_PREFIX", "urlshortener")
+	clicks, err := queue.NewRedisStream(ctx, client, prefix+":clicks", "recorder")
+	if err != nil {
+		client.Close()
+		return err
+	}
+	webhooks, err := queue.NewRedisStream(ctx, client, prefix+":webhooks", "dispatcher")
+	if err != nil {
+		clicks.Close()
+		client.Close()
+		return err
+	}
+	a.Recorder.Queue = clicks
+	a.Dispatcher.Queue = webhooks
+	// The streams flush their publish buffers on Close, so they go before
+	// the client they write through.
+	a.closers = append(a.closers, clicks, webhooks, client)
+	log.Printf("INFO: Queueing clicks and webhooks on Redis streams %s:*.", prefix)
+	return nil
+}
+
+// Workers are the loops draining the queues the service feeds while
+// handling requests.
+func (a *App) Workers() []Worker {
+	return []Worker{
+		{Name: "webhook-dispatcher", Run: a.Dispatcher.Run},
+		{Name: "click-recorder", Run: a.Recorder.Run},
+		{Name: "click-counter", Run: a.ClickCounter.Run},
+	}
+}
+
+// Close releases the databases and any files opened by New.
+func (a *App) Close() {
+	for _, c := range a.closers {
+		c.Close()
+	}
+	if a.Replica != nil {
+		a.Replica.Close()
+	}
+	a.DB.Close()
+}
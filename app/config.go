@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/accesslog"
+	"github.com/AnshulDekate/urlShortener/captcha"
+	"github.com/AnshulDekate/urlShortener/chaos"
+	"github.com/AnshulDekate/urlShortener/errtrack"
+	"github.com/AnshulDekate/urlShortener/rdap"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/AnshulDekate/urlShortener/slo"
+	"github.com/AnshulDekate/urlShortener/storage"
+	"github.com/gin-gonic/gin"
+)
+
+func newObjectStore() (storage.Store, error) {
+	switch backend := Env("STORAGE_BACKEND", "local"); backend {
+	case "local":
+		return &storage.LocalStore{Dir: Env("STORAGE_DIR", "./data")}, nil
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  MustEnv("S3_ENDPOINT"),
+			Bucket:    MustEnv("S3_BUCKET"),
+			AccessKey: MustEnv("S3_ACCESS_KEY"),
+			SecretKey: MustEnv("S3_SECRET_KEY"),
+			Region:    os.Getenv("S3_REGION"),
+			Prefix:    os.Getenv("S3_PREFIX"),
+			UseSSL:    EnvBool("S3_USE_SSL", true),
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q (expected local or s3)", backend)
+	}
+}
+
+// NewCaptchaVerifier returns nil when CAPTCHA_PROVIDER is unset, leaving link
+// creation open to anonymous callers.
+func NewCaptchaVerifier() (*captcha.Client, error) {
+	provider := os.Getenv("CAPTCHA_PROVIDER")
+	if provider == "" {
+		return nil, nil
+	}
+	return captcha.New(provider, MustEnv("CAPTCHA_SECRET"))
+}
+
+// NewErrorTracker returns nil when SENTRY_DSN is unset; recovered panics are
+// then only logged.
+func NewErrorTracker() (errtrack.Tracker, error) {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return nil, nil
+	}
+	tracker, err := errtrack.NewSentry(dsn, os.Getenv("SENTRY_ENVIRONMENT"), os.Getenv("SENTRY_RELEASE"))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INFO: Reporting panics to %s.", tracker.StoreURL)
+	return tracker, nil
+}
+
+func newSpamPolicy() service.SpamPolicy {
+	policy := service.SpamPolicy{
+		IPLimit:      EnvInt("CREATION_IP_LIMIT", 30),
+		DomainLimit:  EnvInt("CREATION_DOMAIN_LIMIT", 0),
+		Window:       EnvDuration("CREATION_WINDOW", service.DefaultSpamWindow),
+		MinDomainAge: EnvDuration("NEW_DOMAIN_MIN_AGE", 0),
+	}
+	if policy.MinDomainAge > 0 {
+		policy.Registry = rdap.NewClient(os.Getenv("RDAP_URL"))
+	}
+	return policy
+}
+
+func newSnowflakeGenerator() (*service.SnowflakeGenerator, error) {
+	switch mode := Env("CODE_GENERATOR", "random"); mode {
+	case "random":
+		return nil, nil
+	case "snowflake":
+		nodeID, err := strconv.Atoi(MustEnv("NODE_ID"))
+		if err != nil {
+			return nil, fmt.Errorf("NODE_ID must be an integer: %w", err)
+		}
+		return service.NewSnowflakeGenerator(nodeID)
+	default:
+		return nil, fmt.Errorf("unknown CODE_GENERATOR %q (expected random or snowflake)", mode)
+	}
+}
+
+func NewSLOTracker() *slo.Tracker {
+	defaults := slo.Target{
+		Latency:      EnvDuration("SLO_LATENCY_TARGET", slo.DefaultLatency),
+		Availability: slo.DefaultAvailability,
+	}
+	if value := os.Getenv("SLO_AVAILABILITY_TARGET"); value != "" {
+		a, err := strconv.ParseFloat(value, 64)
+		if err != nil || a <= 0 || a >= 100 {
+			log.Fatalf("Fatal: SLO_AVAILABILITY_TARGET must be a percentage between 0 and 100, e.g. 99.9")
+		}
+		defaults.Availability = a
+	}
+	overrides, err := slo.ParseTargets(os.Getenv("SLO_TARGETS"), defaults)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	return slo.NewTracker(defaults, overrides)
+}
+
+// NewFileAccessLogger returns a logger writing every request, redirects
+// included, to ACCESS_LOG_FILE, and the file to close on exit. Both are nil
+// when no file is configured.
+func NewFileAccessLogger() (gin.HandlerFunc, io.Closer, error) {
+	path := os.Getenv("ACCESS_LOG_FILE")
+	if path == "" {
+		return nil, nil, nil
+	}
+	format := Env("ACCESS_LOG_FORMAT", "combined")
+	formatter, ok := accesslog.Formatters[format]
+	if !ok {
+		return nil, nil, fmt.Errorf("ACCESS_LOG_FORMAT must be combined or json, got %q", format)
+	}
+	file, err := accesslog.Open(path, int64(EnvInt("ACCESS_LOG_MAX_SIZE_MB", 100))<<20, EnvInt("ACCESS_LOG_MAX_BACKUPS", 5))
+	if err != nil {
+		return nil, nil, err
+	}
+	return gin.LoggerWithConfig(gin.LoggerConfig{Formatter: formatter, Output: file}), file, nil
+}
+
+// configureChaos applies CHAOS_* fault injection settings, which only take
+// effect in builds with -tags chaos.
+func configureChaos() error {
+	cfg := chaos.Config{
+		Latency: EnvDuration("CHAOS_LATENCY", 0),
+		Jitter:  EnvDuration("CHAOS_JITTER", 0),
+	}
+	if value := os.Getenv("CHAOS_ERROR_RATE"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("CHAOS_ERROR_RATE must be a number between 0 and 1: %w", err)
+		}
+		cfg.ErrorRate = rate
+	}
+	for _, target := range strings.Split(Env("CHAOS_TARGETS", "db,cache"), ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			cfg.Targets = append(cfg.Targets, target)
+		}
+	}
+	return chaos.Configure(cfg)
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/AnshulDekate/urlShortener/chaos"
+	"github.com/AnshulDekate/urlShortener/migrate"
+	"github.com/AnshulDekate/urlShortener/migrations"
+	"github.com/AnshulDekate/urlShortener/queryplan"
+)
+
+// OpenDatabase connects to DB_HOST and waits for it to answer. When
+// QUERY_PLAN_SAMPLE_PERCENT is set it also returns the sampler tracing the
+// connection, for New to start once the repository exists; otherwise the
+// sampler is nil.
+func OpenDatabase() (*sql.DB, *queryplan.Sampler, error) {
+	if err := configureChaos(); err != nil {
+		return nil, nil, err
+	}
+	var sampler *queryplan.Sampler
+	var tracer pgx.QueryTracer
+	if value := os.Getenv("QUERY_PLAN_SAMPLE_PERCENT"); value != "" {
+		percent, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("QUERY_PLAN_SAMPLE_PERCENT must be a number: %w", err)
+		}
+		if sampler, err = queryplan.NewSampler(percent); err != nil {
+			return nil, nil, fmt.Errorf("QUERY_PLAN_SAMPLE_PERCENT: %w", err)
+		}
+		tracer = sampler
+		log.Printf("INFO: Recording the plans of %g%% of database queries.", percent)
+	}
+	db, err := open(MustEnv("DB_HOST"), MustEnv("DB_PORT"), tracer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database not available: %w", err)
+	}
+	return db, sampler, nil
+}
+
+// OpenReplica connects to DB_READ_HOST, or returns nil when it is unset.
+func OpenReplica() (*sql.DB, error) {
+	readHost := os.Getenv("DB_READ_HOST")
+	if readHost == "" {
+		return nil, nil
+	}
+	replica, err := open(readHost, Env("DB_READ_PORT", MustEnv("DB_PORT")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("read replica not available: %w", err)
+	}
+	log.Printf("INFO: Serving redirect lookups from read replica %s.", readHost)
+	return replica, nil
+}
+
+func open(host string, port string, tracer pgx.QueryTracer) (*sql.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		host, port, MustEnv("DB_USER"), MustEnv("DB_PASS"), MustEnv("DB_NAME"))
+	if tracer != nil {
+		// A registered config is opened by name through the pgx driver,
+		// including the one chaos wraps.
+		cfg, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Tracer = tracer
+		dsn = stdlib.RegisterConnConfig(cfg)
+	}
+	db, err := sql.Open(chaos.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(50)
+	db.SetMaxIdleConns(25)
+	db.SetConnMaxLifetime(30 * time.Minute)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	if err := waitForDB(db, 10, 1*time.Second); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func waitForDB(db *sql.DB, maxAttempts int, delay time.Duration) error {
+	for i := 0; i < maxAttempts; i++ {
+		if err := db.Ping(); err == nil {
+			return nil
+		}
+		log.Printf("Database not ready, waiting %s... (Attempt %d/%d)", delay, i+1, maxAttempts)
+		time.Sleep(delay)
+	}
+	return fmt.Errorf("database connection timed out")
+}
+
+// RunMigrations applies the migrations in MIGRATIONS_PATH. With
+// MIGRATIONS_DRY_RUN it only logs them and reports dryRun, after which the
+// caller should exit.
+func RunMigrations(db *sql.DB) (dryRun bool, err error) {
+	log.Println("Running database migrations...")
+
+	migrationsPath := MustEnv("MIGRATIONS_PATH")
+	if EnvBool("MIGRATIONS_DRY_RUN", false) {
+		pending, err := migrate.Plan(context.Background(), db, migrationsPath)
+		if err != nil {
+			return true, err
+		}
+		log.Printf("Dry run: %d pending migrations.", len(pending))
+		for _, p := range pending {
+			if len(p.Destructive) > 0 {
+				log.Printf("  %s  DESTRUCTIVE: %s", filepath.Base(p.Source), strings.Join(p.Destructive, "; "))
+			} else {
+				log.Printf("  %s", filepath.Base(p.Source))
+			}
+		}
+		return true, nil
+	}
+
+	err = migrate.Up(context.Background(), db, migrate.Options{
+		Dir:               migrationsPath,
+		LockTimeout:       EnvDuration("MIGRATIONS_LOCK_TIMEOUT", 5*time.Minute),
+		MaxTransactionAge: EnvDuration("MIGRATIONS_MAX_TX_AGE", time.Minute),
+		AllowDestructive:  EnvBool("MIGRATIONS_ALLOW_DESTRUCTIVE", false),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	log.Println("Migrations completed successfully.")
+	return false, nil
+}
+
+// CheckSchema refuses a database whose schema version differs from the
+// newest migration compiled into the binary; see migrate.CheckCompatibility.
+func CheckSchema(db *sql.DB) error {
+	expected, err := migrate.LatestVersion(migrations.Files)
+	if err != nil {
+		return err
+	}
+	return migrate.CheckCompatibility(context.Background(), db, expected, EnvBool("SCHEMA_ALLOW_NEWER", false))
+}
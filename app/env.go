@@ -0,0 +1,73 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MustEnv returns the value of a required variable and exits when it is
+// unset.
+func MustEnv(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Fatalf("Fatal: Required environment variable %s is not set. Application cannot start.", key)
+	}
+	return value
+}
+
+func Env(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func EnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Fatal: Environment variable %s must be a boolean: %v", key, err)
+	}
+	return b
+}
+
+func EnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Fatal: Environment variable %s must be an integer: %v", key, err)
+	}
+	return n
+}
+
+func EnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Fatal: Environment variable %s must be a duration (e.g. 72h): %v", key, err)
+	}
+	return d
+}
+
+// EnvList splits a comma-separated variable, dropping blank entries.
+func EnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
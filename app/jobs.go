@@ -0,0 +1,38 @@
+package app
+
+import (
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/jobs"
+)
+
+// RegisterJobs adds the periodic background work: reports, backups,
+// exports, purges, domain signal decay, nonce pruning, expiry warnings, fraud detection, partition upkeep, click rollups and the optional
+// destination checks. It runs in cmd/worker, or in cmd/server when
+// RUN_JOBS is left on.
+func (a *App) RegisterJobs(runner *jobs.Runner) {
+	svc := a.Service
+	runner.Register(jobs.Job{Name: "scheduled-reports", Interval: time.Minute, Run: svc.RunDueReports})
+	runner.Register(jobs.Job{Name: "scheduled-backup", Interval: time.Minute, Run: svc.RunScheduledBackup})
+	if interval := EnvDuration("CHANGE_EXPORT_INTERVAL", 0); interval > 0 && svc.Storage != nil {
+		runner.Register(jobs.Job{Name: "export-changes", Interval: interval, Run: svc.RunChangeExport})
+	}
+	runner.Register(jobs.Job{Name: "purge-deleted-links", Interval: time.Hour, Run: svc.PurgeDeletedURLs})
+	runner.Register(jobs.Job{Name: "purge-webhook-deliveries", Interval: time.Hour, Run: svc.PurgeWebhookDeliveries})
+	runner.Register(jobs.Job{Name: "purge-query-plans", Interval: time.Hour, Run: svc.PurgeQueryPlans})
+	runner.Register(jobs.Job{Name: "decay-domain-signals", Interval: time.Hour, Run: svc.DecayDomainSignals})
+	runner.Register(jobs.Job{Name: "prune-request-nonces", Interval: 10 * time.Minute, Run: svc.PruneRequestNonces})
+	runner.Register(jobs.Job{Name: "notify-expiring-links", Interval: time.Hour, Run: svc.NotifyExpiringLinks})
+	runner.Register(jobs.Job{Name: "detect-click-fraud", Interval: 5 * time.Minute, Run: svc.RunFraudDetection})
+	runner.Register(jobs.Job{Name: "maintain-click-partitions", Interval: 6 * time.Hour, Run: svc.MaintainClickPartitions})
+	runner.Register(jobs.Job{Name: "roll-up-clicks", Interval: 5 * time.Minute, Run: svc.RollUpClicks})
+	if svc.InspectDestinations {
+		runner.Register(jobs.Job{Name: "inspect-destinations", Interval: time.Minute, Run: svc.InspectNewDestinations})
+	}
+	if svc.Screenshots != nil {
+		runner.Register(jobs.Job{Name: "capture-screenshots", Interval: time.Minute, Run: svc.CaptureScreenshots})
+	}
+	if svc.HealthRecheck > 0 {
+		runner.Register(jobs.Job{Name: "check-link-health", Interval: 10 * time.Minute, Run: svc.CheckLinkHealth})
+	}
+}
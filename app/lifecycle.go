@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/sync/errgroup"
+)
+
+// Worker is a long-running background loop that returns once ctx is done.
+type Worker struct {
+	Name string
+	Run  func(ctx context.Context)
+}
+
+// Server is an HTTP server and, when HTTP3 is set, the HTTP/3 server that
+// answers on the same port over UDP. HTTP serves TLS when its TLSConfig is
+// set.
+type Server struct {
+	HTTP  *http.Server
+	HTTP3 *http3.Server
+}
+
+// Run serves HTTP on every server and runs background workers until ctx is
+// cancelled or a server fails. Shutdown is ordered: the HTTP servers drain
+// first so no request can enqueue new events, then workers are cancelled and
+// flush. Server addresses take any form Listen accepts; all listeners are
+// opened before anything starts.
+func Run(ctx context.Context, servers []Server, workers []Worker, shutdownTimeout time.Duration) error {
+	var listeners []net.Listener
+	var packetConns []net.PacketConn
+	fail := func(err error) error {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+		for _, pc := range packetConns {
+			if pc != nil {
+				pc.Close()
+			}
+		}
+		return err
+	}
+	for _, s := range servers {
+		ln, err := Listen(s.HTTP.Addr)
+		if err != nil {
+			return fail(fmt.Errorf("failed to listen on %s: %w", s.HTTP.Addr, err))
+		}
+		listeners = append(listeners, ln)
+
+		var pc net.PacketConn
+		if s.HTTP3 != nil {
+			if pc, err = net.ListenPacket("udp", s.HTTP3.Addr); err != nil {
+				return fail(fmt.Errorf("failed to listen for HTTP/3 on %s: %w", s.HTTP3.Addr, err))
+			}
+		}
+		packetConns = append(packetConns, pc)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	for _, w := range workers {
+		g.Go(func() error {
+			w.Run(workerCtx)
+			log.Printf("Worker %s stopped.", w.Name)
+			return nil
+		})
+	}
+
+	for i, s := range servers {
+		g.Go(func() error {
+			log.Printf("Gin server starting on %s...", s.HTTP.Addr)
+			var err error
+			if s.HTTP.TLSConfig != nil {
+				err = s.HTTP.ServeTLS(listeners[i], "", "")
+			} else {
+				err = s.HTTP.Serve(listeners[i])
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("http server on %s failed: %w", s.HTTP.Addr, err)
+			}
+			return nil
+		})
+		if s.HTTP3 != nil {
+			g.Go(func() error {
+				log.Printf("HTTP/3 server starting on %s/udp...", s.HTTP3.Addr)
+				if err := s.HTTP3.Serve(packetConns[i]); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return fmt.Errorf("http/3 server on %s failed: %w", s.HTTP3.Addr, err)
+				}
+				return nil
+			})
+		}
+	}
+
+	g.Go(func() error {
+		<-gctx.Done()
+		log.Println("Shutting down: draining HTTP connections...")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		var drain errgroup.Group
+		for _, s := range servers {
+			drain.Go(func() error { return s.HTTP.Shutdown(shutdownCtx) })
+			if s.HTTP3 != nil {
+				drain.Go(func() error { return s.HTTP3.Shutdown(shutdownCtx) })
+			}
+		}
+		err := drain.Wait()
+
+		log.Println("HTTP servers stopped. Flushing background workers...")
+		stopWorkers()
+		return err
+	})
+
+	return g.Wait()
+}
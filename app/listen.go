@@ -0,0 +1,94 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor systemd passes, after stdin,
+// stdout and stderr.
+const listenFDsStart = 3
+
+// Listen opens the listener for a server address. Besides a TCP address
+// such as ":8080", it accepts "unix:<path>" for a Unix domain socket and
+// "systemd" or "systemd:<name>" for a socket passed by systemd socket
+// activation, the first one or the one with that FileDescriptorName.
+func Listen(addr string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, "unix:"):
+		return listenUnix(strings.TrimPrefix(addr, "unix:"))
+	case addr == "systemd":
+		return systemdListener("")
+	case strings.HasPrefix(addr, "systemd:"):
+		return systemdListener(strings.TrimPrefix(addr, "systemd:"))
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// listenUnix binds a Unix socket with UNIX_SOCKET_MODE permissions. A socket
+// left behind by an earlier run is replaced; any other file at the path is
+// an error rather than something to delete.
+func listenUnix(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("unix listen address needs a socket path")
+	}
+	mode, err := strconv.ParseUint(Env("UNIX_SOCKET_MODE", "0660"), 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("UNIX_SOCKET_MODE must be an octal file mode: %w", err)
+	}
+
+	info, err := os.Lstat(path)
+	switch {
+	case err == nil && info.Mode()&fs.ModeSocket == 0:
+		return nil, fmt.Errorf("%s exists and is not a socket", path)
+	case err == nil:
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("failed to check socket path %s: %w", path, err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, fs.FileMode(mode)); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// systemdListener takes over a socket passed through LISTEN_FDS, selected by
+// its name in LISTEN_FDNAMES, or the first one when name is empty.
+func systemdListener(name string) (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, errors.New("systemd passed no sockets to this process (LISTEN_PID is unset or another process's)")
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, errors.New("systemd passed no sockets (LISTEN_FDS is unset or 0)")
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for i := 0; i < n; i++ {
+		if name != "" && (i >= len(names) || names[i] != name) {
+			continue
+		}
+		f := os.NewFile(uintptr(listenFDsStart+i), "systemd:"+name)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("systemd socket %d is not a listening socket: %w", i, err)
+		}
+		return ln, nil
+	}
+	return nil, fmt.Errorf("systemd passed no socket named %q (LISTEN_FDNAMES=%s)", name, os.Getenv("LISTEN_FDNAMES"))
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// ConfigureProtocols sets up the public server's protocols from the
+// environment and returns the HTTP/3 server to run beside it, or nil.
+//
+// With TLS_CERT_FILE and TLS_KEY_FILE the server terminates TLS itself and
+// negotiates HTTP/2 over it. HTTP3=true then also answers over QUIC on the
+// same port, which needs a TCP address since UDP is bound alongside it, and
+// advertises that with an Alt-Svc header. Without TLS, H2C=true accepts
+// cleartext HTTP/2 from a proxy that speaks it to its backends.
+func ConfigureProtocols(srv *http.Server, engine *gin.Engine) (*http3.Server, error) {
+	certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	useTLS := certFile != ""
+	h2c := EnvBool("H2C", false)
+	useHTTP3 := EnvBool("HTTP3", false)
+
+	switch {
+	case h2c && useTLS:
+		return nil, errors.New("H2C only applies without TLS; HTTP/2 is negotiated over TLS already")
+	case useHTTP3 && !useTLS:
+		return nil, errors.New("HTTP3 needs TLS_CERT_FILE and TLS_KEY_FILE")
+	case useHTTP3 && (strings.HasPrefix(srv.Addr, "unix:") || strings.HasPrefix(srv.Addr, "systemd")):
+		return nil, fmt.Errorf("HTTP3 needs a TCP listen address, got %s", srv.Addr)
+	}
+
+	if h2c {
+		engine.UseH2C = true
+		srv.Handler = engine.Handler()
+		log.Println("INFO: Accepting cleartext HTTP/2 (h2c).")
+	}
+	if !useTLS {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	srv.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if !useHTTP3 {
+		log.Println("INFO: Serving HTTPS with HTTP/2.")
+		return nil, nil
+	}
+
+	h3 := &http3.Server{
+		Addr:           srv.Addr,
+		Handler:        srv.Handler,
+		TLSConfig:      http3.ConfigureTLSConfig(srv.TLSConfig),
+		MaxHeaderBytes: srv.MaxHeaderBytes,
+		IdleTimeout:    srv.IdleTimeout,
+	}
+	tcpHandler := srv.Handler
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Clients learn about the QUIC endpoint from this header and switch
+		// on their next connection.
+		h3.SetQUICHeaders(w.Header())
+		tcpHandler.ServeHTTP(w, r)
+	})
+	log.Println("INFO: Serving HTTPS with HTTP/2 and HTTP/3.")
+	return h3, nil
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewHTTPServer returns a server for addr with connection limits from the
+// environment. The defaults bound how long a client may take to send its
+// headers and body, which is what a slowloris attack relies on, and keep
+// idle keep-alive connections around long enough for a browser's follow-up
+// requests. Writes are not limited by default: click streams and instance
+// exports run for minutes, and the handlers serving them lift the limit.
+func NewHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: EnvDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       EnvDuration("HTTP_READ_TIMEOUT", time.Minute),
+		WriteTimeout:      EnvDuration("HTTP_WRITE_TIMEOUT", 0),
+		IdleTimeout:       EnvDuration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		MaxHeaderBytes:    EnvInt("HTTP_MAX_HEADER_BYTES", 64<<10),
+	}
+}
@@ -0,0 +1,139 @@
+// Package applinks turns redirects to destinations owned by a native app
+// into a page that opens the app on mobile and falls back to the web.
+package applinks
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/AnshulDekate/urlShortener/devices"
+)
+
+// App describes one native app. Hosts match exactly or any subdomain, so
+// "youtube.com" covers "www.youtube.com" and "m.youtube.com".
+type App struct {
+	Name           string   `yaml:"name"`
+	Hosts          []string `yaml:"hosts"`
+	AndroidPackage string   `yaml:"android_package"`
+	IOSScheme      string   `yaml:"ios_scheme"`
+}
+
+// Config is the APP_LINKS_FILE layout, YAML or JSON.
+type Config struct {
+	Apps []App `yaml:"apps"`
+}
+
+// Launch is what the app page needs: the URL that opens the app and the
+// destination to fall back to when it is not installed.
+type Launch struct {
+	App      string
+	AppURL   string
+	Fallback string
+	Android  bool
+}
+
+type Matcher struct {
+	apps []App
+}
+
+var (
+	packagePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)+$`)
+	schemePattern  = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+)
+
+// Load reads and validates an app links file.
+func Load(path string) (*Matcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read app links file: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse app links file: %w", err)
+	}
+	return New(cfg.Apps)
+}
+
+func New(apps []App) (*Matcher, error) {
+	m := &Matcher{}
+	for _, app := range apps {
+		if app.Name == "" {
+			return nil, fmt.Errorf("app with hosts %v has no name", app.Hosts)
+		}
+		if len(app.Hosts) == 0 {
+			return nil, fmt.Errorf("app %q has no hosts", app.Name)
+		}
+		if app.AndroidPackage == "" && app.IOSScheme == "" {
+			return nil, fmt.Errorf("app %q needs android_package or ios_scheme", app.Name)
+		}
+		if app.AndroidPackage != "" && !packagePattern.MatchString(app.AndroidPackage) {
+			return nil, fmt.Errorf("app %q has invalid android_package %q", app.Name, app.AndroidPackage)
+		}
+		app.IOSScheme = strings.ToLower(app.IOSScheme)
+		if app.IOSScheme != "" && (!schemePattern.MatchString(app.IOSScheme) || app.IOSScheme == "http" || app.IOSScheme == "https" || app.IOSScheme == "javascript") {
+			return nil, fmt.Errorf("app %q has invalid ios_scheme %q", app.Name, app.IOSScheme)
+		}
+		for i, host := range app.Hosts {
+			app.Hosts[i] = strings.ToLower(strings.TrimSuffix(host, "."))
+		}
+		m.apps = append(m.apps, app)
+	}
+	return m, nil
+}
+
+func (m *Matcher) Len() int {
+	return len(m.apps)
+}
+
+func (m *Matcher) match(host string) *App {
+	host = strings.ToLower(host)
+	for i := range m.apps {
+		for _, h := range m.apps[i].Hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return &m.apps[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Launch reports how a visitor with userAgent should open dest. ok is false
+// when dest belongs to no configured app, the visitor is not on Android or
+// iOS, or the app has no way in on that platform; the caller then redirects
+// as usual.
+func (m *Matcher) Launch(dest string, userAgent string) (Launch, bool) {
+	u, err := url.Parse(dest)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Launch{}, false
+	}
+	app := m.match(u.Hostname())
+	if app == nil {
+		return Launch{}, false
+	}
+
+	info := devices.Parse(userAgent)
+	if info.Device == devices.ClassBot {
+		return Launch{}, false
+	}
+	target := u.Host + u.EscapedPath()
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+
+	switch {
+	case info.OS == "Android" && app.AndroidPackage != "":
+		// Chrome opens the package when installed and otherwise navigates to
+		// the fallback itself, so no timer is needed on Android.
+		intent := "intent://" + target + "#Intent;scheme=" + u.Scheme + ";package=" + app.AndroidPackage +
+			";S.browser_fallback_url=" + url.QueryEscape(dest) + ";end"
+		return Launch{App: app.Name, AppURL: intent, Fallback: dest, Android: true}, true
+	case info.OS == "iOS" && app.IOSScheme != "":
+		return Launch{App: app.Name, AppURL: app.IOSScheme + "://" + target, Fallback: dest}, true
+	}
+	return Launch{}, false
+}
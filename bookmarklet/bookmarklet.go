@@ -0,0 +1,22 @@
+// Package bookmarklet serves a page that builds a bookmarklet for shortening
+// the page a user is on with their own API key.
+package bookmarklet
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/pages"
+)
+
+// Handler serves the bookmarklet page for a service reachable at base, the
+// short URL domain with its trailing slash, or at the base URL a trusted
+// proxy forwarded. The API key is only ever handled by script in the user's
+// browser.
+func Handler(renderer *pages.Renderer, base string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderer.Write(c, http.StatusOK, pages.Bookmarklet, struct{ Base string }{middleware.BaseURL(c, base)})
+	}
+}
@@ -0,0 +1,80 @@
+// Package captcha verifies CAPTCHA response tokens with the provider that
+// issued them. Turnstile, hCaptcha and reCAPTCHA share the same siteverify
+// protocol and differ only in the endpoint.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultTimeout = 5 * time.Second
+
+var verifyURLs = map[string]string{
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// Client checks tokens against one provider with the deployment's secret.
+type Client struct {
+	Provider  string
+	Secret    string
+	VerifyURL string
+	HTTP      *http.Client
+}
+
+func New(provider string, secret string) (*Client, error) {
+	verifyURL, ok := verifyURLs[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown CAPTCHA provider %q (expected turnstile, hcaptcha or recaptcha)", provider)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("CAPTCHA provider %s needs a secret", provider)
+	}
+	return &Client{
+		Provider:  provider,
+		Secret:    secret,
+		VerifyURL: verifyURL,
+		HTTP:      &http.Client{Timeout: DefaultTimeout},
+	}, nil
+}
+
+type verifyResponse struct {
+	Success bool `json:"success"`
+}
+
+// Verify reports whether token is a valid, unused response. An error means
+// the provider could not be asked, not that the token was rejected.
+func (c *Client) Verify(ctx context.Context, token string, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {c.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("failed to build %s verification request: %w", c.Provider, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach %s: %w", c.Provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s verification returned status %d", c.Provider, resp.StatusCode)
+	}
+
+	var result verifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode %s verification response: %w", c.Provider, err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,47 @@
+// Package chaos injects latency and errors into database and cache calls for
+// resilience testing. Injection is compiled in only with -tags chaos; in
+// normal builds Inject is a no-op and the database driver is pgx itself.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Targets that faults can be injected into.
+const (
+	DB    = "db"
+	Cache = "cache"
+)
+
+// ErrInjected is returned by calls chosen to fail.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Config describes the faults to inject. Each call to a listed target waits
+// Latency plus up to Jitter, then fails with probability ErrorRate.
+type Config struct {
+	Latency   time.Duration `json:"latency"`
+	Jitter    time.Duration `json:"jitter"`
+	ErrorRate float64       `json:"error_rate"`
+	Targets   []string      `json:"targets"`
+}
+
+func (c Config) active() bool {
+	return len(c.Targets) > 0 && (c.Latency > 0 || c.Jitter > 0 || c.ErrorRate > 0)
+}
+
+func (c Config) validate() error {
+	if c.Latency < 0 || c.Jitter < 0 {
+		return errors.New("latency and jitter must not be negative")
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return errors.New("error_rate must be between 0 and 1")
+	}
+	for _, t := range c.Targets {
+		if t != DB && t != Cache {
+			return fmt.Errorf("unknown target %q (want %s or %s)", t, DB, Cache)
+		}
+	}
+	return nil
+}
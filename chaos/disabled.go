@@ -0,0 +1,30 @@
+//go:build !chaos
+
+package chaos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const Enabled = false
+
+// DriverName is the database/sql driver to open.
+const DriverName = "pgx"
+
+func Inject(ctx context.Context, target string) error { return nil }
+
+// Configure refuses any active configuration, so a production build never
+// silently ignores fault settings someone expected to take effect.
+func Configure(c Config) error {
+	if c.active() {
+		return errors.New("fault injection requires a build with -tags chaos")
+	}
+	return nil
+}
+
+// Routes registers nothing: the chaos admin endpoint exists only in chaos
+// builds.
+func Routes(g *gin.RouterGroup) {}
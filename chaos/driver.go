@@ -0,0 +1,84 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+type wrappedDriver struct {
+	base driver.Driver
+}
+
+func (d wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{base: c}, nil
+}
+
+// conn forwards to the pgx connection, injecting faults before queries,
+// execs and transaction starts. It implements every optional interface the
+// pgx connection does, so database/sql behaves the same with and without it,
+// including pgx's handling of array and JSON arguments.
+type conn struct {
+	base driver.Conn
+}
+
+// Unwrap returns the pgx connection, for code that needs pgx itself, such
+// as COPY. Those calls bypass injection.
+func (c *conn) Unwrap() driver.Conn { return c.base }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return c.base.Prepare(query) }
+func (c *conn) Close() error                              { return c.base.Close() }
+
+func (c *conn) Begin() (driver.Tx, error) { return c.BeginTx(context.Background(), driver.TxOptions{}) }
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := Inject(ctx, DB); err != nil {
+		return nil, err
+	}
+	return c.base.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := Inject(ctx, DB); err != nil {
+		return nil, err
+	}
+	return c.base.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := Inject(ctx, DB); err != nil {
+		return nil, err
+	}
+	return c.base.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := Inject(ctx, DB); err != nil {
+		return nil, err
+	}
+	return c.base.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if err := Inject(ctx, DB); err != nil {
+		return err
+	}
+	return c.base.(driver.Pinger).Ping(ctx)
+}
+
+func (c *conn) CheckNamedValue(v *driver.NamedValue) error {
+	return c.base.(driver.NamedValueChecker).CheckNamedValue(v)
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	return c.base.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *conn) IsValid() bool {
+	return c.base.(driver.Validator).IsValid()
+}
@@ -0,0 +1,112 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+const Enabled = true
+
+// DriverName is pgx wrapped so every query, exec and transaction start goes
+// through Inject.
+const DriverName = "pgx-chaos"
+
+var current atomic.Pointer[Config]
+
+func init() {
+	current.Store(&Config{})
+	sql.Register(DriverName, wrappedDriver{base: stdlib.GetDefaultDriver()})
+}
+
+// Inject applies the configured faults to one call to target.
+func Inject(ctx context.Context, target string) error {
+	c := current.Load()
+	if !c.active() || !slices.Contains(c.Targets, target) {
+		return nil
+	}
+	delay := c.Latency
+	if c.Jitter > 0 {
+		delay += rand.N(c.Jitter)
+	}
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+	if c.ErrorRate > 0 && rand.Float64() < c.ErrorRate {
+		return ErrInjected
+	}
+	return nil
+}
+
+func Configure(c Config) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	current.Store(&c)
+	if c.active() {
+		log.Printf("WARN: Injecting faults into %v: latency %s (+%s jitter), error rate %.2f.", c.Targets, c.Latency, c.Jitter, c.ErrorRate)
+	} else {
+		log.Printf("INFO: Fault injection off.")
+	}
+	return nil
+}
+
+// Routes registers GET and PUT /chaos. Durations are Go duration strings.
+func Routes(g *gin.RouterGroup) {
+	g.GET("/chaos", func(c *gin.Context) {
+		cfg := current.Load()
+		c.JSON(http.StatusOK, gin.H{
+			"latency":    cfg.Latency.String(),
+			"jitter":     cfg.Jitter.String(),
+			"error_rate": cfg.ErrorRate,
+			"targets":    cfg.Targets,
+		})
+	})
+	g.PUT("/chaos", func(c *gin.Context) {
+		var req struct {
+			Latency   string   `json:"latency"`
+			Jitter    string   `json:"jitter"`
+			ErrorRate float64  `json:"error_rate"`
+			Targets   []string `json:"targets"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"latency\": \"50ms\", \"jitter\": \"20ms\", \"error_rate\": 0.1, \"targets\": [\"db\", \"cache\"]})"})
+			return
+		}
+		cfg := Config{ErrorRate: req.ErrorRate, Targets: req.Targets}
+		var err error
+		if req.Latency != "" {
+			if cfg.Latency, err = time.ParseDuration(req.Latency); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "latency must be a Go duration such as 50ms"})
+				return
+			}
+		}
+		if req.Jitter != "" {
+			if cfg.Jitter, err = time.ParseDuration(req.Jitter); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "jitter must be a Go duration such as 20ms"})
+				return
+			}
+		}
+		if err := Configure(cfg); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,105 @@
+// Command clickbench compares the two click ingestion paths, COPY and
+// multi-row INSERT, against a real database.
+//
+//	DB_HOST=localhost DB_PORT=5432 DB_USER=... DB_PASS=... DB_NAME=... go run ./cmd/clickbench -batches 100,500,2000
+//
+// It creates a throwaway link, writes synthetic clicks for it and deletes the
+// link (and with it every inserted click) when done.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+func main() {
+	batches := flag.String("batches", "100,500,2000", "comma-separated batch sizes")
+	flag.Parse()
+
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASS"), os.Getenv("DB_NAME"))
+	db, err := sql.Open("pgx", connStr)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer db.Close()
+	repo := &repository.Repository{DB: db}
+
+	code := "bench" + strconv.FormatInt(time.Now().UnixNano()%1e5, 10)
+	id, err := repo.InsertURL("https://example.com/clickbench", 0)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer db.Exec("DELETE FROM urls WHERE id = $1", id)
+	if err := repo.UpdateShortCode(id, code); err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+
+	paths := []struct {
+		name   string
+		insert func(context.Context, []events.Event) (int, error)
+	}{
+		{"insert", repo.InsertEventsMultiRow},
+		{"copy", repo.InsertEvents},
+	}
+
+	fmt.Printf("%-8s %6s %14s %14s %12s\n", "path", "batch", "ns/batch", "events/sec", "allocs/batch")
+	for _, field := range strings.Split(*batches, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 1 {
+			log.Fatalf("Fatal: invalid batch size %q", field)
+		}
+		batch := syntheticBatch(code, size)
+
+		for _, p := range paths {
+			var failure error
+			result := testing.Benchmark(func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := p.insert(context.Background(), batch); err != nil {
+						failure = err
+						b.FailNow()
+					}
+				}
+			})
+			if failure != nil {
+				log.Fatalf("Fatal: %s path failed: %v", p.name, failure)
+			}
+			perBatch := result.NsPerOp()
+			fmt.Printf("%-8s %6d %14d %14.0f %12d\n", p.name, size, perBatch,
+				float64(size)*float64(time.Second)/float64(perBatch), result.AllocsPerOp())
+		}
+	}
+}
+
+func syntheticBatch(code string, size int) []events.Event {
+	now := time.Now().UTC()
+	batch := make([]events.Event, size)
+	for i := range batch {
+		batch[i] = events.Event{
+			Type:       events.TypeClick,
+			ShortCode:  code,
+			IP:         fmt.Sprintf("10.0.%d.%d", i/256%256, i%256),
+			UserAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+			Referer:    "https://news.example.com/",
+			Browser:    "Chrome",
+			OS:         "Windows",
+			Device:     "desktop",
+			OccurredAt: now,
+		}
+	}
+	return batch
+}
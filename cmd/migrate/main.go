@@ -0,0 +1,32 @@
+// Command migrate applies the database migrations and exits, for running
+// as a one-off step of a deploy before the servers and workers start. It
+// honours the same MIGRATIONS_* settings as a server migrating on start,
+// including MIGRATIONS_DRY_RUN.
+//
+//	go run ./cmd/migrate
+package main
+
+import (
+	"log"
+
+	"github.com/AnshulDekate/urlShortener/app"
+)
+
+func main() {
+	db, _, err := app.OpenDatabase()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer db.Close()
+
+	dryRun, err := app.RunMigrations(db)
+	if err != nil {
+		log.Fatalf("Fatal: Failed to run migrations: %v", err)
+	}
+	if dryRun {
+		return
+	}
+	if err := app.CheckSchema(db); err != nil {
+		log.Fatalf("Fatal: Incompatible database schema: %v", err)
+	}
+}
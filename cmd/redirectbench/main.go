@@ -0,0 +1,205 @@
+// Command redirectbench measures allocations and latency on the redirect path.
+//
+// Without a database it compares the response techniques the redirect
+// handler used before and after its hot-path rework (gin.H JSON vs static
+// 404 bodies, c.Redirect vs a bare Location header, concatenated vs streamed
+// split hashing). With DB_HOST and friends set it also drives the real
+// handler through gin with the metrics and access-log middleware, creating a
+// throwaway link, and reports allocations per request and p50/p99 latency:
+//
+//	go run ./cmd/redirectbench
+//	DB_HOST=localhost DB_PORT=5432 DB_USER=... DB_PASS=... DB_NAME=... go run ./cmd/redirectbench -requests 20000
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/AnshulDekate/urlShortener/handler"
+	"github.com/AnshulDekate/urlShortener/metrics"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// discardWriter is a reusable ResponseWriter so the benchmarks measure the
+// handler rather than a recorder's buffers.
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(status int)      { w.status = status }
+
+func (w *discardWriter) reset() {
+	clear(w.header)
+	w.status = 0
+}
+
+func serve(engine *gin.Engine, req *http.Request) func(b *testing.B) {
+	return func(b *testing.B) {
+		w := &discardWriter{header: http.Header{}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			w.reset()
+			engine.ServeHTTP(w, req)
+		}
+	}
+}
+
+var notFoundBody = []byte(`{"error":"Short code not found"}`)
+
+func techniqueEngine() *gin.Engine {
+	engine := gin.New()
+	engine.GET("/json-404/:code", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+	})
+	engine.GET("/static-404/:code", func(c *gin.Context) {
+		c.Data(http.StatusNotFound, "application/json; charset=utf-8", notFoundBody)
+	})
+	engine.GET("/redirect/:code", func(c *gin.Context) {
+		c.Redirect(http.StatusFound, "https://example.com/landing?utm_source=bench")
+	})
+	engine.GET("/location/:code", func(c *gin.Context) {
+		c.Header("Location", "https://example.com/landing?utm_source=bench")
+		c.Status(http.StatusFound)
+		c.Writer.WriteHeaderNow()
+	})
+	return engine
+}
+
+func report(name string, r testing.BenchmarkResult) {
+	fmt.Printf("%-28s %10d ns/op %8d B/op %6d allocs/op\n", name, r.NsPerOp(), r.AllocedBytesPerOp(), r.AllocsPerOp())
+}
+
+func mustRequest(path string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	return req
+}
+
+func runTechniques() {
+	engine := techniqueEngine()
+	report("404 gin.H JSON", testing.Benchmark(serve(engine, mustRequest("/json-404/abc123XYZ0"))))
+	report("404 static body", testing.Benchmark(serve(engine, mustRequest("/static-404/abc123XYZ0"))))
+	report("302 c.Redirect", testing.Benchmark(serve(engine, mustRequest("/redirect/abc123XYZ0"))))
+	report("302 Location header", testing.Benchmark(serve(engine, mustRequest("/location/abc123XYZ0"))))
+
+	for _, lg := range []struct {
+		name   string
+		logger gin.HandlerFunc
+	}{
+		{"302 with access log", gin.Logger()},
+		{"302 redirect log skipped", middleware.AccessLogger(false)},
+	} {
+		logged := gin.New()
+		logged.Use(lg.logger)
+		logged.GET("/:code", func(c *gin.Context) {
+			c.Header("Location", "https://example.com/landing?utm_source=bench")
+			c.Status(http.StatusFound)
+			c.Writer.WriteHeaderNow()
+		})
+		report(lg.name, testing.Benchmark(serve(logged, mustRequest("/abc123XYZ0"))))
+	}
+
+	code, ip, ua := "abc123XYZ0", "203.0.113.7", "Mozilla/5.0 (X11; Linux x86_64)"
+	report("split hash hash/fnv", testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h := fnv.New32a()
+			h.Write([]byte(code))
+			h.Write([]byte{0})
+			h.Write([]byte(ip + "|" + ua))
+			_ = h.Sum32() % 100
+		}
+	}))
+	report("split hash streamed", testing.Benchmark(func(b *testing.B) {
+		dest := repository.Destination{LongURL: "https://example.com/a", SplitDestination: "https://example.com/b", SplitPercent: 50}
+		req := service.RedirectRequest{ShortCode: code, ClientIP: ip, UserAgent: ua, Now: time.Now()}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			service.ChooseDestination(dest, req)
+		}
+	}))
+}
+
+func runFullPath(requests int) {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASS"), os.Getenv("DB_NAME"))
+	db, err := sql.Open("pgx", connStr)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer db.Close()
+	repo := &repository.Repository{DB: db}
+
+	code := "rbench" + strconv.FormatInt(time.Now().UnixNano()%1e4, 10)
+	id, err := repo.InsertURL("https://example.com/redirectbench", 0)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer db.Exec("DELETE FROM urls WHERE id = $1", id)
+	if err := repo.UpdateShortCode(id, code); err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+
+	// No analytics recorder or webhooks: the benchmark measures the request,
+	// not the background pipeline.
+	h := handler.NewGinHandler(&service.Service{Repo: repo}, "http://localhost/")
+	engine := gin.New()
+	engine.Use(metrics.NewRequestMetrics().Middleware())
+	engine.Use(middleware.AccessLogger(false))
+	engine.GET("/:code", h.Redirect)
+
+	hit, miss := mustRequest("/"+code), mustRequest("/zzzzzzzzzz")
+	report("redirect (db)", testing.Benchmark(serve(engine, hit)))
+	report("unknown code (db)", testing.Benchmark(serve(engine, miss)))
+
+	w := &discardWriter{header: http.Header{}}
+	latencies := make([]time.Duration, requests)
+	for i := range latencies {
+		w.reset()
+		start := time.Now()
+		engine.ServeHTTP(w, hit)
+		latencies[i] = time.Since(start)
+		if w.status != http.StatusFound {
+			log.Fatalf("Fatal: redirect returned %d", w.status)
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Printf("redirect latency over %d requests: p50 %s, p99 %s\n",
+		requests, latencies[requests/2], latencies[requests*99/100])
+}
+
+func main() {
+	requests := flag.Int("requests", 20000, "sequential redirects timed for latency percentiles")
+	flag.Parse()
+
+	gin.SetMode(gin.ReleaseMode)
+	gin.DefaultWriter = io.Discard
+
+	runTechniques()
+	if os.Getenv("DB_HOST") != "" {
+		runFullPath(max(*requests, 100))
+	}
+}
@@ -0,0 +1,443 @@
+// Command sdkgen generates the TypeScript and Python client stubs in sdk/
+// from the OpenAPI spec, so non-Go consumers get typed access to the link
+// API.
+//
+//	go run ./cmd/sdkgen -spec api/openapi.yaml -out sdk
+//
+// It understands the subset of OpenAPI 3.0 the spec uses: JSON bodies,
+// path and query parameters of scalar types, and object schemas whose
+// properties are scalars, maps of scalars or references to other object
+// schemas.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/goccy/go-yaml"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// outputs maps each template to the file it renders, relative to -out.
+var outputs = map[string]string{
+	"templates/typescript.tmpl": "typescript/src/index.ts",
+	"templates/python.tmpl":     "python/urlshortener/__init__.py",
+}
+
+type spec struct {
+	Info struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths      map[string]map[string]operation `yaml:"paths"`
+	Components struct {
+		Schemas map[string]schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type operation struct {
+	OperationID string      `yaml:"operationId"`
+	Summary     string      `yaml:"summary"`
+	Parameters  []parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema schema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema schema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+type parameter struct {
+	Name        string `yaml:"name"`
+	In          string `yaml:"in"`
+	Required    bool   `yaml:"required"`
+	Description string `yaml:"description"`
+	Schema      schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref         string            `yaml:"$ref"`
+	Type        string            `yaml:"type"`
+	Format      string            `yaml:"format"`
+	Nullable    bool              `yaml:"nullable"`
+	Description string            `yaml:"description"`
+	Required    []string          `yaml:"required"`
+	Properties  map[string]schema `yaml:"properties"`
+	// AdditionalProperties makes an object a map of these values.
+	AdditionalProperties *schema `yaml:"additionalProperties"`
+}
+
+// Model is what the templates render.
+type Model struct {
+	Title      string
+	Version    string
+	Server     string
+	Types      []Type
+	Operations []Operation
+}
+
+type Type struct {
+	Name        string
+	Description string
+	Fields      []Field
+}
+
+// Field is a property of a type, or a parameter of an operation.
+type Field struct {
+	Name        string
+	Description string
+	Required    bool
+	// Nullable fields are always present but may be null.
+	Nullable bool
+	// Scalar is string, integer, number or boolean; Ref names a type
+	// instead. Map fields are objects of Scalar values.
+	Scalar string
+	Ref    string
+	Map    bool
+}
+
+type Operation struct {
+	Name        string
+	Summary     string
+	Method      string
+	Path        string
+	PathParams  []Field
+	QueryParams []Field
+	// Body and Result name types; Body is empty for operations without a
+	// request body.
+	Body   string
+	Result string
+}
+
+func main() {
+	specPath := flag.String("spec", "api/openapi.yaml", "OpenAPI spec to read")
+	out := flag.String("out", "sdk", "directory to write the SDKs to")
+	flag.Parse()
+
+	raw, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	var s spec
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		log.Fatalf("Fatal: Failed to parse %s: %v", *specPath, err)
+	}
+	model, err := buildModel(s)
+	if err != nil {
+		log.Fatalf("Fatal: %s: %v", *specPath, err)
+	}
+
+	tmpl, err := template.New("sdk").Funcs(funcs).ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	for name, file := range outputs {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, filepath.Base(name), model); err != nil {
+			log.Fatalf("Fatal: Failed to render %s: %v", file, err)
+		}
+		path := filepath.Join(*out, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatalf("Fatal: %v", err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			log.Fatalf("Fatal: %v", err)
+		}
+		log.Printf("INFO: Wrote %s.", path)
+	}
+}
+
+func buildModel(s spec) (Model, error) {
+	m := Model{Title: s.Info.Title, Version: s.Info.Version}
+	if len(s.Servers) > 0 {
+		m.Server = s.Servers[0].URL
+	}
+
+	for _, name := range sortedKeys(s.Components.Schemas) {
+		sc := s.Components.Schemas[name]
+		if sc.Type != "object" {
+			return m, fmt.Errorf("schema %s: only object schemas are supported", name)
+		}
+		t := Type{Name: name, Description: sc.Description}
+		// Required fields come first, so Python dataclasses can give the
+		// others a default.
+		required := make(map[string]bool, len(sc.Required))
+		for _, r := range sc.Required {
+			required[r] = true
+		}
+		var optional []Field
+		for _, prop := range sortedKeys(sc.Properties) {
+			f, err := field(prop, sc.Properties[prop], required[prop])
+			if err != nil {
+				return m, fmt.Errorf("schema %s: %w", name, err)
+			}
+			if f.Required {
+				t.Fields = append(t.Fields, f)
+			} else {
+				optional = append(optional, f)
+			}
+		}
+		t.Fields = append(t.Fields, optional...)
+		m.Types = append(m.Types, t)
+	}
+
+	for _, path := range sortedKeys(s.Paths) {
+		for _, method := range sortedKeys(s.Paths[path]) {
+			op := s.Paths[path][method]
+			if op.OperationID == "" {
+				return m, fmt.Errorf("%s %s has no operationId", strings.ToUpper(method), path)
+			}
+			o := Operation{Name: op.OperationID, Summary: op.Summary, Method: strings.ToUpper(method), Path: path}
+			for _, p := range op.Parameters {
+				f, err := field(p.Name, p.Schema, p.Required || p.In == "path")
+				if err != nil {
+					return m, fmt.Errorf("%s: %w", op.OperationID, err)
+				}
+				if f.Ref != "" {
+					return m, fmt.Errorf("%s: parameter %s must be a scalar", op.OperationID, p.Name)
+				}
+				f.Description = p.Description
+				switch p.In {
+				case "path":
+					o.PathParams = append(o.PathParams, f)
+				case "query":
+					o.QueryParams = append(o.QueryParams, f)
+				default:
+					return m, fmt.Errorf("%s: %s parameters are not supported", op.OperationID, p.In)
+				}
+			}
+			if op.RequestBody != nil {
+				o.Body = refName(op.RequestBody.Content["application/json"].Schema.Ref)
+				if o.Body == "" {
+					return m, fmt.Errorf("%s: request body must reference a JSON schema", op.OperationID)
+				}
+			}
+			for _, status := range []string{"200", "201"} {
+				if r, ok := op.Responses[status]; ok {
+					o.Result = refName(r.Content["application/json"].Schema.Ref)
+					break
+				}
+			}
+			if o.Result == "" {
+				return m, fmt.Errorf("%s: success response must reference a JSON schema", op.OperationID)
+			}
+			m.Operations = append(m.Operations, o)
+		}
+	}
+	sort.Slice(m.Operations, func(i, j int) bool { return m.Operations[i].Name < m.Operations[j].Name })
+	return m, nil
+}
+
+func field(name string, sc schema, required bool) (Field, error) {
+	f := Field{Name: name, Description: sc.Description, Required: required, Nullable: sc.Nullable}
+	if sc.Ref != "" {
+		f.Ref = refName(sc.Ref)
+		return f, nil
+	}
+	if sc.Type == "object" && sc.AdditionalProperties != nil {
+		f.Map = true
+		sc = *sc.AdditionalProperties
+	}
+	switch sc.Type {
+	case "string", "integer", "number", "boolean":
+		f.Scalar = sc.Type
+	default:
+		return f, fmt.Errorf("property %s: type %q is not supported", name, sc.Type)
+	}
+	return f, nil
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var funcs = template.FuncMap{
+	"tsType": tsType,
+	"pyType": pyType,
+	// tsPath and pyPath turn /urls/{code}/stats into an expression with
+	// each path parameter escaped.
+	"tsPath": func(path string) string {
+		if !strings.Contains(path, "{") {
+			return `"` + path + `"`
+		}
+		return "`" + replaceParams(path, func(p string) string { return "${encodeURIComponent(" + p + ")}" }) + "`"
+	},
+	"pyPath": func(path string) string {
+		if !strings.Contains(path, "{") {
+			return `"` + path + `"`
+		}
+		return `f"` + replaceParams(path, func(p string) string { return "{quote(str(" + snake(p) + "), safe='')}" }) + `"`
+	},
+	"tsParams": tsParams,
+	"tsQuery":  tsQuery,
+	"pyParams": pyParams,
+	"pyQuery":  pyQuery,
+	"snake":    snake,
+}
+
+// tsParams is the parameter list of an operation's method: path parameters,
+// required query parameters and the body in order, then an options object
+// with the optional query parameters.
+func tsParams(o Operation) string {
+	var params, optional []string
+	for _, f := range o.PathParams {
+		params = append(params, f.Name+": "+tsType(f))
+	}
+	for _, f := range o.QueryParams {
+		typ := f.Name + ": " + tsType(f)
+		if f.Required {
+			params = append(params, typ)
+		} else {
+			optional = append(optional, strings.Replace(typ, ":", "?:", 1))
+		}
+	}
+	if o.Body != "" {
+		params = append(params, "body: "+o.Body)
+	}
+	if len(optional) > 0 {
+		params = append(params, "options: { "+strings.Join(optional, "; ")+" } = {}")
+	}
+	return strings.Join(params, ", ")
+}
+
+func tsQuery(o Operation) string {
+	var entries []string
+	for _, f := range o.QueryParams {
+		if f.Required {
+			entries = append(entries, f.Name)
+		} else {
+			entries = append(entries, f.Name+": options."+f.Name)
+		}
+	}
+	if len(entries) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(entries, ", ") + " }"
+}
+
+// pyParams is like tsParams, with the optional query parameters as keyword
+// arguments.
+func pyParams(o Operation) string {
+	params := []string{"self"}
+	var optional []string
+	for _, f := range o.PathParams {
+		params = append(params, snake(f.Name)+": "+pyType(f))
+	}
+	for _, f := range o.QueryParams {
+		typ := snake(f.Name) + ": " + pyType(f)
+		if f.Required {
+			params = append(params, typ)
+		} else {
+			optional = append(optional, typ+" = None")
+		}
+	}
+	if o.Body != "" {
+		params = append(params, "body: "+o.Body)
+	}
+	return strings.Join(append(params, optional...), ", ")
+}
+
+func pyQuery(o Operation) string {
+	var entries []string
+	for _, f := range o.QueryParams {
+		entries = append(entries, `"`+f.Name+`": `+snake(f.Name))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func replaceParams(path string, expr func(string) string) string {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(path, '{')
+		end := strings.IndexByte(path, '}')
+		if open < 0 || end < open {
+			b.WriteString(path)
+			return b.String()
+		}
+		b.WriteString(path[:open])
+		b.WriteString(expr(path[open+1 : end]))
+		path = path[end+1:]
+	}
+}
+
+// snake turns linkStats into link_stats.
+func snake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func tsType(f Field) string {
+	t := f.Ref
+	switch f.Scalar {
+	case "string":
+		t = "string"
+	case "integer", "number":
+		t = "number"
+	case "boolean":
+		t = "boolean"
+	}
+	if f.Map {
+		t = "Record<string, " + t + ">"
+	}
+	if f.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func pyType(f Field) string {
+	t := f.Ref
+	switch f.Scalar {
+	case "string":
+		t = "str"
+	case "integer":
+		t = "int"
+	case "number":
+		t = "float"
+	case "boolean":
+		t = "bool"
+	}
+	if f.Map {
+		t = "Dict[str, " + t + "]"
+	}
+	if f.Nullable || !f.Required {
+		t = "Optional[" + t + "]"
+	}
+	return t
+}
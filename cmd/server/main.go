@@ -0,0 +1,492 @@
+// Command server serves the redirects, the API and the admin routes. By
+// default it also migrates the database on start and runs the background
+// jobs; turn those off with MIGRATE_ON_START and RUN_JOBS when cmd/migrate
+// and cmd/worker are deployed.
+//
+//	go run ./cmd/server
+//	go run ./cmd/server restore <storage-key>
+//	go run ./cmd/server import <archive-file>
+//	go run ./cmd/server recount [-repair] [-limit n] [code ...]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/AnshulDekate/urlShortener/alerts"
+	"github.com/AnshulDekate/urlShortener/app"
+	"github.com/AnshulDekate/urlShortener/applinks"
+	"github.com/AnshulDekate/urlShortener/bookmarklet"
+	"github.com/AnshulDekate/urlShortener/chaos"
+	"github.com/AnshulDekate/urlShortener/debugcapture"
+	"github.com/AnshulDekate/urlShortener/graph"
+	"github.com/AnshulDekate/urlShortener/handler"
+	"github.com/AnshulDekate/urlShortener/i18n"
+	"github.com/AnshulDekate/urlShortener/jobs"
+	"github.com/AnshulDekate/urlShortener/metrics"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/AnshulDekate/urlShortener/scaling"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/AnshulDekate/urlShortener/wellknown"
+)
+
+func runRestore(svc *service.Service, args []string) {
+	if len(args) != 1 {
+		log.Fatalf("Usage: server restore <storage-key>")
+	}
+
+	rows, err := svc.RestoreBackup(context.Background(), args[0])
+	if err != nil {
+		log.Fatalf("Fatal: Restore from %s failed: %v", args[0], err)
+	}
+	log.Printf("Restore from %s completed: %d rows loaded.", args[0], rows)
+}
+
+// runImport loads an archive downloaded from /admin/export, or any backup
+// file, into this deployment's empty database.
+func runImport(svc *service.Service, args []string) {
+	if len(args) != 1 {
+		log.Fatalf("Usage: server import <archive-file>")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := svc.ImportArchive(context.Background(), f, args[0])
+	if err != nil {
+		log.Fatalf("Fatal: Import of %s failed: %v", args[0], err)
+	}
+	log.Printf("Import of %s completed: %d rows loaded.", args[0], rows)
+}
+
+// runRecount compares click counts with the recorded click events of the
+// given codes, or of all links, and with -repair raises the counts that
+// fell short.
+func runRecount(svc *service.Service, args []string) {
+	fs := flag.NewFlagSet("recount", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "raise click counts below the recount to it")
+	limit := fs.Int("limit", service.DefaultRecountLimit, "most discrepancies to list")
+	fs.Parse(args)
+
+	result, err := svc.RecountClicks(context.Background(), fs.Args(), *limit, *repair, "recount command")
+	if err != nil {
+		log.Fatalf("Fatal: Recount failed: %v", err)
+	}
+	for _, l := range result.Links {
+		log.Printf("%s: click_count %d, recounted %d (%+d)", l.ShortCode, l.ClickCount, l.Recounted, l.Difference)
+	}
+	log.Printf("Recount completed: %d discrepancies listed, %d links repaired.", len(result.Links), result.Repaired)
+}
+
+func main() {
+	appPort := app.MustEnv("APP_PORT")
+
+	db, sampler, err := app.OpenDatabase()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	if app.EnvBool("MIGRATE_ON_START", true) {
+		dryRun, err := app.RunMigrations(db)
+		if err != nil {
+			log.Fatalf("Fatal: Failed to run migrations: %v", err)
+		}
+		if dryRun {
+			return
+		}
+	}
+	if err := app.CheckSchema(db); err != nil {
+		log.Fatalf("Fatal: Incompatible database schema: %v", err)
+	}
+
+	replica, err := app.OpenReplica()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	a, err := app.New(db, replica, sampler)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer a.Close()
+	svc := a.Service
+
+	// APP_PORT names the short domain even when LISTEN_ADDR puts the server
+	// on a Unix or systemd socket behind a local proxy.
+	shortURLDomain := fmt.Sprintf("http://localhost:%s/", appPort)
+	svc.ShortURLHosts = append(svc.ShortURLHosts, "localhost:"+appPort)
+	listenAddr := app.Env("LISTEN_ADDR", ":"+appPort)
+
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		runRestore(svc, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(svc, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "recount" {
+		runRecount(svc, os.Args[2:])
+		return
+	}
+
+	if path := os.Getenv("SEED_FILE"); path != "" {
+		seed, err := service.ReadSeedFile(path)
+		if err != nil {
+			log.Fatalf("Fatal: %v", err)
+		}
+		if err := svc.ApplySeed(context.Background(), seed); err != nil {
+			log.Fatalf("Fatal: Failed to apply seed file: %v", err)
+		}
+	}
+
+	bootstrapToken, err := svc.EnableBootstrap(context.Background(), os.Getenv("BOOTSTRAP_TOKEN"))
+	if err != nil {
+		log.Fatalf("Fatal: Failed to check API keys: %v", err)
+	}
+	if bootstrapToken != "" && os.Getenv("BOOTSTRAP_TOKEN") == "" {
+		log.Printf("INFO: No API keys exist yet. One-time bootstrap token: %s", bootstrapToken)
+	}
+
+	if err := svc.MaintainClickPartitions(context.Background()); err != nil {
+		log.Printf("ERROR: Click partition maintenance failed: %v", err)
+	}
+
+	h := handler.NewGinHandler(svc, shortURLDomain)
+	catalog, err := i18n.Load(os.Getenv("MESSAGES_DIR"), app.Env("DEFAULT_LANG", i18n.Fallback))
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	if h.Pages, err = pages.New(os.Getenv("PAGES_DIR"), catalog); err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	if h.InterstitialSeconds = app.EnvInt("INTERSTITIAL_SECONDS", 5); h.InterstitialSeconds < 0 {
+		log.Fatalf("Fatal: INTERSTITIAL_SECONDS must not be negative, got %d", h.InterstitialSeconds)
+	}
+	h.BrandingCacheTTL = app.EnvDuration("WORKSPACE_PAGES_CACHE_TTL", handler.DefaultBrandingCacheTTL)
+	if path := os.Getenv("APP_LINKS_FILE"); path != "" {
+		appLinks, err := applinks.Load(path)
+		if err != nil {
+			log.Fatalf("Fatal: %v", err)
+		}
+		h.AppLinks = appLinks
+		log.Printf("INFO: App links enabled for %d apps.", appLinks.Len())
+	}
+
+	runner := jobs.NewRunner()
+	if svc.Snowflake == nil {
+		runner.Register(jobs.Job{Name: "keyspace-utilization", Interval: 5 * time.Minute, Run: svc.UpdateKeyspaceUtilization})
+	}
+	alertEvaluator := alerts.NewEvaluator(svc.HealthCheck)
+	runner.Register(jobs.Job{Name: "evaluate-alerts", Interval: alerts.DefaultInterval, Run: alertEvaluator.Evaluate})
+	scalingMonitor := scaling.NewMonitor(a.DB, map[string]scaling.QueueDepth{
+		"click":   a.Recorder.Queue.Depth,
+		"webhook": a.Dispatcher.Queue.Depth,
+	})
+	runner.Register(jobs.Job{Name: "sample-scaling-signals", Interval: scaling.DefaultInterval, Run: scalingMonitor.Sample})
+	if app.EnvBool("RUN_JOBS", true) {
+		a.RegisterJobs(runner)
+	} else {
+		log.Println("INFO: RUN_JOBS is off; background jobs are left to the worker.")
+	}
+
+	log.Println("Setting up HTTP handlers with Gin...")
+
+	tracker, err := app.NewErrorTracker()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	// Behind TRUSTED_PROXIES, short URLs are built on the scheme and host the
+	// proxy forwarded instead of the local address.
+	forwardedBaseURL, err := middleware.ForwardedBaseURL(app.EnvList("TRUSTED_PROXIES"), app.EnvList("SHORT_URL_HOSTS"))
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	// Rate limits, bans and IP lists key on the client behind
+	// TRUSTED_PROXIES; X-Forwarded-For from anyone else is ignored.
+	clientIP, err := middleware.ResolveClientIP(app.EnvList("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	r := gin.New()
+	r.Use(middleware.Recovery(tracker))
+	r.Use(middleware.Correlate())
+	r.Use(middleware.Localize(catalog))
+	r.Use(forwardedBaseURL)
+	r.Use(clientIP)
+
+	// Probes and scrapers are registered outside the limited group below:
+	// they must not spend a client's rate limit budget or trip its bans, and
+	// are guarded by OPS_TOKEN or OPS_ALLOWED_CIDRS instead.
+	opsAccess, err := middleware.OpsAccess(os.Getenv("OPS_TOKEN"), app.EnvList("OPS_ALLOWED_CIDRS"))
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	registerOps := func(g *gin.RouterGroup) {
+		ops := g.Group("", opsAccess)
+		ops.GET("/livez", h.Livez)
+		ops.GET("/readyz", h.Readyz)
+		ops.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
+	registerOps(&r.RouterGroup)
+
+	chain := []gin.HandlerFunc{middleware.AccessLogger(app.EnvBool("LOG_REDIRECTS", false))}
+	fileAccessLogger, accessLogFile, err := app.NewFileAccessLogger()
+	if err != nil {
+		log.Fatalf("Fatal: Failed to open access log: %v", err)
+	}
+	if fileAccessLogger != nil {
+		defer accessLogFile.Close()
+		chain = append(chain, fileAccessLogger)
+		log.Printf("INFO: Writing access log to %s.", os.Getenv("ACCESS_LOG_FILE"))
+	}
+	sloTracker := app.NewSLOTracker()
+	requestMetrics := metrics.NewRequestMetrics()
+	chain = append(chain, requestMetrics.Middleware(), sloTracker.Middleware())
+	ipLists, err := middleware.NewIPLists(middleware.IPListConfig{
+		Allow: app.EnvList("RATE_LIMIT_ALLOWLIST"),
+		Deny:  app.EnvList("RATE_LIMIT_DENYLIST"),
+	})
+	if err != nil {
+		log.Fatalf("Fatal: Invalid rate limit IP list: %v", err)
+	}
+	chain = append(chain,
+		ipLists.Middleware(),
+		middleware.NewProbeLimiter(middleware.ProbeLimiterConfig{
+			NotFoundLimit: app.EnvInt("PROBE_NOT_FOUND_LIMIT", 30),
+			BanDuration:   app.EnvDuration("PROBE_BAN_DURATION", 10*time.Minute),
+		}).Middleware(),
+		middleware.NewRateLimiter(middleware.MaxRequestsPerIP, middleware.WindowDuration).Middleware(),
+	)
+	limited := r.Group("", chain...)
+	// Unmatched paths are logged and limited like any other request.
+	r.NoRoute(chain...)
+
+	adminAuth := middleware.AdminAuth(os.Getenv("ADMIN_TOKEN"), svc)
+	optionalAuth := middleware.OptionalAdminAuth(os.Getenv("ADMIN_TOKEN"), svc)
+
+	// Anonymous link creation is the spam vector; other routes stay open.
+	// Deployments can turn it off entirely, or make it cost a CAPTCHA.
+	// Callers with credentials are identified first, so their links skip
+	// the spam screening.
+	creation := []gin.HandlerFunc{optionalAuth}
+	captchaVerifier, err := app.NewCaptchaVerifier()
+	if err != nil {
+		log.Fatalf("Fatal: Failed to configure CAPTCHA: %v", err)
+	}
+	switch {
+	case !app.EnvBool("ANONYMOUS_CREATION", true):
+		docsURL := app.Env("ANONYMOUS_CREATION_DOCS_URL", "https://github.com/AnshulDekate/urlShortener#admin-api")
+		creation = append(creation, middleware.RequireCredentials(os.Getenv("ADMIN_TOKEN"), svc, docsURL))
+		log.Println("INFO: Anonymous link creation is disabled; creating links requires an API key.")
+	case captchaVerifier != nil:
+		creation = append(creation, middleware.RequireCaptcha(captchaVerifier, os.Getenv("ADMIN_TOKEN"), svc))
+		log.Printf("INFO: Requiring %s CAPTCHA for anonymous link creation.", captchaVerifier.Provider)
+	}
+
+	graphQL := graph.Handler(&graph.Resolver{Service: svc, Domain: shortURLDomain})
+	limited.GET("/graphql", optionalAuth, graphQL)
+	limited.POST("/graphql", optionalAuth, graphQL)
+
+	limited.POST("/shorten", append(creation, h.Shorten)...)
+	limited.POST("/api/utm-shorten", append(creation, h.UTMShorten)...)
+	limited.POST("/api/campaigns", adminAuth, h.CreateCampaign)
+	limited.GET("/api/campaigns", h.ListCampaigns)
+	limited.POST("/api/campaigns/:id/links", adminAuth, h.AttachCampaignLinks)
+	limited.GET("/api/campaigns/:id/stats", h.CampaignStats)
+	limited.GET("/api/stats/live", h.LiveStats)
+	limited.GET("/healthcheck", h.HealthCheck)
+	limited.GET("/metrics/dashboard.json", metrics.DashboardHandler)
+	limited.GET("/metrics/alerts.yml", metrics.AlertRulesHandler)
+	if dir := os.Getenv("WELL_KNOWN_DIR"); dir != "" {
+		limited.GET("/.well-known/*file", wellknown.Handler(dir))
+		log.Printf("INFO: Serving /.well-known/ from %s.", dir)
+	}
+	limited.GET("/:code", h.Redirect)
+	limited.GET("/:code/pixel", h.Pixel)
+	limited.GET("/:code/stats", h.PublicStats)
+	limited.GET("/:code/qr", h.LinkQRCode)
+	limited.GET("/urls", h.ListURLs)
+	limited.POST("/urls/:code/rotate", adminAuth, h.Rotate)
+	limited.GET("/urls/:code/stats", adminAuth, h.LinkStats)
+	limited.GET("/urls/:code/networks", adminAuth, h.LinkNetworks)
+	limited.GET("/urls/:code/devices", adminAuth, h.LinkDevices)
+	limited.GET("/urls/:code/geo", adminAuth, h.LinkGeo)
+	limited.GET("/urls/:code/timeseries", adminAuth, h.LinkTimeseries)
+	limited.GET("/urls/:code/preview", h.LinkPreview)
+	limited.GET("/urls/:code/screenshot", h.LinkScreenshot)
+
+	quickShortenCORS := middleware.CORS(http.MethodGet)
+	limited.GET("/api/shorten", quickShortenCORS, adminAuth, h.ShortenGET)
+	limited.OPTIONS("/api/shorten", quickShortenCORS)
+	limited.GET("/tools/bookmarklet", bookmarklet.Handler(h.Pages, shortURLDomain))
+	expandCORS := middleware.CORS(http.MethodGet)
+	limited.GET("/api/expand", expandCORS, h.Expand)
+	limited.OPTIONS("/api/expand", expandCORS)
+	limited.GET("/b/:code", h.ViewBundle)
+	limited.GET("/w/:slug/logo", h.WorkspaceLogo)
+	limited.POST("/api/conversions", adminAuth, h.RecordConversion)
+
+	// With ADMIN_ADDR set, /admin and the other management APIs move to a
+	// second listener, normally bound to a private interface, and the public
+	// port no longer serves them. The
+	// admin listener skips the public chain's IP lists and rate limits;
+	// requests there are only logged and measured.
+	management := limited
+	var adminSrv *http.Server
+	if adminAddr := os.Getenv("ADMIN_ADDR"); adminAddr != "" {
+		if adminAddr == listenAddr {
+			log.Fatalf("Fatal: ADMIN_ADDR must differ from the public listener %s", listenAddr)
+		}
+		adminRouter := gin.New()
+		adminRouter.Use(middleware.Recovery(tracker), middleware.Correlate(), middleware.Localize(catalog), forwardedBaseURL, clientIP, middleware.AccessLogger(false))
+		if fileAccessLogger != nil {
+			adminRouter.Use(fileAccessLogger)
+		}
+		adminRouter.Use(requestMetrics.Middleware())
+		registerOps(&adminRouter.RouterGroup)
+		management = &adminRouter.RouterGroup
+		adminSrv = app.NewHTTPServer(adminAddr, adminRouter)
+		log.Printf("INFO: Serving the admin API on %s only.", adminAddr)
+	}
+
+	management.POST("/admin/bootstrap", h.Bootstrap)
+	management.GET("/urls/:code/stream", adminAuth, h.StreamClicks)
+	management.POST("/api/link-sets", adminAuth, h.CreateLinkSet)
+	management.GET("/api/link-sets/:id", adminAuth, h.GetLinkSet)
+	management.GET("/api/link-sets/:id/stats", adminAuth, h.LinkSetRecipientStats)
+	management.GET("/api/poll/links", adminAuth, h.PollLinks)
+	management.GET("/api/poll/clicks", adminAuth, h.PollClicks)
+	requestCapture := debugcapture.New()
+	admin := management.Group("/admin", adminAuth, requestCapture.Middleware())
+	chaos.Routes(admin)
+	admin.GET("/debug/capture", requestCapture.GetSettings)
+	admin.PUT("/debug/capture", requestCapture.SetSettings)
+	admin.DELETE("/debug/capture", requestCapture.DeleteSettings)
+	admin.GET("/debug/requests", requestCapture.ListRequests)
+	admin.DELETE("/debug/requests", requestCapture.ClearRequests)
+	admin.GET("/alerts", alertEvaluator.Handler)
+	admin.GET("/slo", sloTracker.Handler)
+	admin.GET("/scaling", scalingMonitor.Handler)
+	admin.GET("/diagnostics/query-plans", h.ListQueryPlans)
+	admin.GET("/rate-limit/ip-lists", ipLists.GetLists)
+	admin.PUT("/rate-limit/ip-lists", ipLists.SetLists)
+	admin.GET("/regions/click-drift", h.ClickDrift)
+	admin.POST("/regions/click-drift/reconcile", h.ReconcileClickCounts)
+	admin.POST("/clicks/recount", h.RecountClicks)
+	admin.GET("/api-keys", h.ListAPIKeys)
+	admin.PUT("/api-keys/:name", h.EnsureAPIKey)
+	admin.DELETE("/api-keys/:name", h.RevokeAPIKey)
+	admin.POST("/api-keys/:name/signing-secret", h.RotateAPIKeySigningSecret)
+	admin.DELETE("/api-keys/:name/signing-secret", h.DisableAPIKeySigning)
+	admin.POST("/workspaces", h.CreateWorkspace)
+	admin.PUT("/workspaces/:slug/code-prefix", h.SetWorkspaceCodePrefix)
+	admin.PUT("/workspaces/:slug/code-format", h.SetWorkspaceCodeFormat)
+	admin.PUT("/workspaces/:slug/interstitial", h.SetWorkspaceInterstitial)
+	admin.GET("/workspaces/:slug/pages", h.GetWorkspacePages)
+	admin.PUT("/workspaces/:slug/pages/:page", h.SetWorkspacePage)
+	admin.DELETE("/workspaces/:slug/pages/:page", h.DeleteWorkspacePage)
+	admin.PUT("/workspaces/:slug/logo", h.SetWorkspaceLogo)
+	admin.DELETE("/workspaces/:slug/logo", h.DeleteWorkspaceLogo)
+	admin.GET("/workspaces/:slug/qr", h.GetWorkspaceQRStyle)
+	admin.PUT("/workspaces/:slug/qr", h.SetWorkspaceQRStyle)
+	admin.DELETE("/workspaces/:slug/qr", h.DeleteWorkspaceQRStyle)
+	admin.POST("/links/transfer", h.TransferLinks)
+	admin.POST("/mirrors", h.CreateMirror)
+	admin.POST("/mirrors/import", h.ImportBitlyMirrors)
+	admin.POST("/import", h.ImportLinks)
+	admin.DELETE("/urls/:code", h.DeleteURL)
+	admin.PATCH("/urls/:code", h.PatchLink)
+	admin.GET("/urls/deleted", h.ListDeletedURLs)
+	admin.GET("/urls/duplicates", h.DuplicateLinks)
+	admin.POST("/urls/duplicates/merge", h.MergeDuplicateLinks)
+	admin.POST("/urls/restore", h.RestoreDeletedURLs)
+	admin.GET("/urls/:code/webhook", h.GetLinkWebhook)
+	admin.PUT("/urls/:code/webhook", h.SetLinkWebhook)
+	admin.DELETE("/urls/:code/webhook", h.DeleteLinkWebhook)
+	admin.POST("/urls/:code/webhook/secret", h.RotateWebhookSecret)
+	admin.GET("/webhooks/:id/deliveries", h.ListWebhookDeliveries)
+	admin.POST("/webhooks/:id/deliveries/:delivery/redeliver", h.RedeliverWebhook)
+	admin.GET("/urls/:code/languages", h.GetLinkLanguages)
+	admin.PUT("/urls/:code/languages", h.SetLinkLanguages)
+	admin.GET("/urls/:code/schedule", h.GetScheduleRules)
+	admin.PUT("/urls/:code/schedule", h.SetScheduleRules)
+	admin.GET("/urls/:code/split", h.GetSplit)
+	admin.PUT("/urls/:code/split", h.SetSplit)
+	admin.PATCH("/urls/:code/split", h.UpdateSplit)
+	admin.DELETE("/urls/:code/split", h.DeleteSplit)
+	admin.GET("/urls/:code/sampling", h.GetSampleRate)
+	admin.PUT("/urls/:code/sampling", h.SetSampleRate)
+	admin.POST("/bundles", h.CreateBundle)
+	admin.GET("/bundles", h.ListBundles)
+	admin.GET("/bundles/:code/stats", h.BundleStats)
+	admin.DELETE("/bundles/:code", h.DeleteBundle)
+	admin.PUT("/urls/:code/interstitial", h.SetLinkInterstitial)
+	admin.GET("/urls/:code/expiry", h.GetLinkExpiry)
+	admin.PUT("/urls/:code/expiry", h.SetLinkExpiry)
+	admin.POST("/urls/:code/expiry/extend", h.ExtendLinkExpiry)
+	admin.POST("/urls/:code/expiry/snooze", h.SnoozeExpiryNotice)
+	admin.GET("/urls/:code/og", h.GetOpenGraph)
+	admin.PUT("/urls/:code/og", h.SetOpenGraph)
+	admin.DELETE("/urls/:code/og", h.DeleteOpenGraph)
+	admin.GET("/urls/:code/public-stats", h.GetPublicStatsSetting)
+	admin.PUT("/urls/:code/public-stats", h.SetPublicStats)
+	admin.GET("/urls/:code/conversions", h.GetConversionTracking)
+	admin.PUT("/urls/:code/conversions", h.SetConversionTracking)
+	admin.GET("/reports/schedules", h.ListReportSchedules)
+	admin.POST("/reports/schedules", h.CreateReportSchedule)
+	admin.DELETE("/reports/schedules/:id", h.DeleteReportSchedule)
+	admin.GET("/backups", h.ListBackups)
+	admin.POST("/backups", h.CreateBackup)
+	admin.GET("/export", h.ExportInstance)
+	admin.GET("/changes/exports", h.ListChangeExports)
+	admin.POST("/changes/exports", h.ExportChanges)
+	admin.GET("/fraud/flags", h.ListClickFlags)
+	admin.DELETE("/fraud/flags/:id", h.DismissClickFlag)
+	admin.GET("/codes/entropy", h.AuditCodeEntropy)
+	admin.GET("/abuse/events", h.ListAbuseEvents)
+	admin.GET("/domains", h.ListDomainReputations)
+	admin.GET("/domains/:domain", h.GetDomainReputation)
+	admin.PUT("/domains/:domain/score", h.OverrideDomainScore)
+	admin.DELETE("/domains/:domain/score", h.ClearDomainScore)
+	admin.POST("/domains/:domain/signals", h.RecordDomainSignal)
+	admin.GET("/moderation", h.ListPendingLinks)
+	admin.GET("/links/dead", h.ListDeadLinks)
+	admin.POST("/moderation/:code/approve", h.ApproveLink)
+
+	srv := app.NewHTTPServer(listenAddr, r)
+	// Shutdown waits for in-flight requests and a click stream never ends on
+	// its own, so close the streams as soon as shutdown begins.
+	srv.RegisterOnShutdown(a.ClickHub.Close)
+	h3, err := app.ConfigureProtocols(srv, r)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	servers := []app.Server{{HTTP: srv, HTTP3: h3}}
+	if adminSrv != nil {
+		servers = append(servers, app.Server{HTTP: adminSrv})
+	}
+	workers := append([]app.Worker{{Name: "job-runner", Run: runner.Run}}, a.Workers()...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Run(ctx, servers, workers, app.EnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second)); err != nil {
+		log.Printf("Server exited with error: %v", err)
+		return
+	}
+	log.Println("Shutdown complete.")
+}
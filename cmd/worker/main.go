@@ -0,0 +1,82 @@
+// Command worker runs the background jobs (scheduled reports, backups,
+// change exports, purges, fraud detection, partition upkeep, destination
+// checks) apart from the redirect servers, which then run with
+// RUN_JOBS=false. It also delivers the webhooks and clicks its own jobs
+// produce, and with QUEUE_BACKEND=redis those the servers queue too.
+// /healthcheck, /livez, /readyz and /metrics are served on WORKER_PORT.
+//
+//	go run ./cmd/worker
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/AnshulDekate/urlShortener/app"
+	"github.com/AnshulDekate/urlShortener/handler"
+	"github.com/AnshulDekate/urlShortener/jobs"
+	"github.com/AnshulDekate/urlShortener/middleware"
+)
+
+func main() {
+	db, sampler, err := app.OpenDatabase()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	if err := app.CheckSchema(db); err != nil {
+		log.Fatalf("Fatal: Incompatible database schema: %v", err)
+	}
+
+	replica, err := app.OpenReplica()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	a, err := app.New(db, replica, sampler)
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	defer a.Close()
+
+	if err := a.Service.MaintainClickPartitions(context.Background()); err != nil {
+		log.Printf("ERROR: Click partition maintenance failed: %v", err)
+	}
+
+	runner := jobs.NewRunner()
+	a.RegisterJobs(runner)
+
+	h := handler.NewGinHandler(a.Service, "")
+	tracker, err := app.NewErrorTracker()
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	r := gin.New()
+	r.Use(middleware.Recovery(tracker))
+	r.GET("/healthcheck", h.HealthCheck)
+	opsAccess, err := middleware.OpsAccess(os.Getenv("OPS_TOKEN"), app.EnvList("OPS_ALLOWED_CIDRS"))
+	if err != nil {
+		log.Fatalf("Fatal: %v", err)
+	}
+	ops := r.Group("", opsAccess)
+	ops.GET("/livez", h.Livez)
+	ops.GET("/readyz", h.Readyz)
+	ops.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	srv := app.NewHTTPServer(":"+app.Env("WORKER_PORT", "9090"), r)
+	workers := append([]app.Worker{{Name: "job-runner", Run: runner.Run}}, a.Workers()...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Run(ctx, []app.Server{{HTTP: srv}}, workers, app.EnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second)); err != nil {
+		log.Printf("Worker exited with error: %v", err)
+		return
+	}
+	log.Println("Shutdown complete.")
+}
@@ -0,0 +1,343 @@
+// Package debugcapture records management API requests and responses into an
+// in-memory ring buffer for debugging integrations. Nothing is captured until
+// an admin turns capture on for some client IPs, or sends a request with the
+// capture header; secrets are redacted before anything is stored.
+package debugcapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+)
+
+const (
+	// Header asks for one authenticated request to be captured.
+	Header = "X-Debug-Capture"
+
+	bufferSize     = 200
+	maxBodyBytes   = 16 << 10
+	DefaultTimeout = 15 * time.Minute
+	maxTimeout     = 24 * time.Hour
+	redacted       = "[redacted]"
+)
+
+// sensitiveHeaders are never stored.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":          true,
+	"Cookie":                 true,
+	"Set-Cookie":             true,
+	middleware.CaptchaHeader: true,
+	"Proxy-Authorization":    true,
+	"X-Webhook-Signature":    true,
+}
+
+// sensitiveFields redacts string values in JSON bodies whose key contains
+// one of these words, such as "secret", "api_key" or "bootstrap_token".
+var sensitiveFields = []string{"token", "secret", "password", "key"}
+
+type Body struct {
+	ContentType string `json:"content_type,omitempty"`
+	Size        int    `json:"size"`
+	Truncated   bool   `json:"truncated,omitempty"`
+	// Content is the sanitized body: JSON is embedded as-is, other text as a
+	// string, and binary bodies are omitted.
+	Content any `json:"content,omitempty"`
+}
+
+type Exchange struct {
+	ID              int64               `json:"id"`
+	Time            time.Time           `json:"time"`
+	ClientIP        string              `json:"client_ip"`
+	Actor           string              `json:"actor"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	Status          int                 `json:"status"`
+	LatencyMS       float64             `json:"latency_ms"`
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	Request         Body                `json:"request"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	Response        Body                `json:"response"`
+}
+
+// Capture holds the ring buffer and the IPs capture is enabled for.
+type Capture struct {
+	mu      sync.Mutex
+	ips     map[string]time.Time
+	entries []Exchange
+	next    int64
+}
+
+func New() *Capture {
+	return &Capture{ips: make(map[string]time.Time)}
+}
+
+func (c *Capture) enabledFor(ip string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.ips[ip]
+	if ok && !now.Before(until) {
+		delete(c.ips, ip)
+		return false
+	}
+	return ok
+}
+
+func (c *Capture) add(e Exchange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next++
+	e.ID = c.next
+	if len(c.entries) >= bufferSize {
+		copy(c.entries, c.entries[1:])
+		c.entries = c.entries[:bufferSize-1]
+	}
+	c.entries = append(c.entries, e)
+}
+
+// recorder tees the response body, up to the limit, while it is written.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	size int
+}
+
+func (w *recorder) Write(p []byte) (int, error) {
+	w.size += len(p)
+	if room := maxBodyBytes - w.body.Len(); room > 0 {
+		w.body.Write(p[:min(room, len(p))])
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Middleware captures requests from enabled IPs, or carrying Header, on
+// routes behind admin authentication. It must run after AdminAuth. Requests
+// to the capture endpoints themselves are never captured.
+func (c *Capture) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ip := middleware.GetClientIP(ctx)
+		if strings.HasPrefix(ctx.FullPath(), "/admin/debug/") || (ctx.GetHeader(Header) == "" && !c.enabledFor(ip, start)) {
+			ctx.Next()
+			return
+		}
+
+		var reqBody []byte
+		reqSize := 0
+		if ctx.Request.Body != nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(ctx.Request.Body, maxBodyBytes+1))
+			reqSize = len(reqBody)
+			ctx.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(reqBody), ctx.Request.Body), ctx.Request.Body}
+		}
+		w := &recorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+
+		ctx.Next()
+
+		c.add(Exchange{
+			Time:            start,
+			ClientIP:        ip,
+			Actor:           ctx.GetString(middleware.ActorContextKey),
+			Method:          ctx.Request.Method,
+			Path:            ctx.Request.URL.RequestURI(),
+			Status:          w.Status(),
+			LatencyMS:       float64(time.Since(start).Microseconds()) / 1000,
+			RequestHeaders:  sanitizeHeaders(ctx.Request.Header),
+			Request:         sanitizeBody(ctx.Request.Header.Get("Content-Type"), reqBody, reqSize),
+			ResponseHeaders: sanitizeHeaders(w.Header()),
+			Response:        sanitizeBody(w.Header().Get("Content-Type"), w.body.Bytes(), w.size),
+		})
+	}
+}
+
+func sanitizeHeaders(h http.Header) map[string][]string {
+	out := make(map[string][]string, len(h))
+	for name, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{redacted}
+			continue
+		}
+		out[name] = values
+	}
+	return out
+}
+
+// sanitizeBody keeps at most maxBodyBytes of body. size is the number of
+// bytes seen, which may be one more than kept for requests.
+func sanitizeBody(contentType string, body []byte, size int) Body {
+	b := Body{ContentType: contentType, Size: size}
+	if len(body) > maxBodyBytes {
+		body = body[:maxBodyBytes]
+	}
+	b.Truncated = size > len(body)
+	if len(body) == 0 {
+		return b
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || mediaType == "application/x-ndjson" || strings.HasSuffix(mediaType, "+json"):
+		var v any
+		if !b.Truncated && json.Unmarshal(body, &v) == nil {
+			b.Content = redactJSON(v)
+		} else {
+			// JSON that cannot be parsed cannot be redacted field by field.
+			b.Content = redacted
+		}
+	case strings.HasPrefix(mediaType, "text/"):
+		b.Content = string(body)
+	}
+	return b
+}
+
+func redactJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if _, isString := val.(string); isString && sensitiveField(k) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactJSON(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = redactJSON(t[i])
+		}
+	}
+	return v
+}
+
+func sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range sensitiveFields {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entries returns the captured exchanges, newest first.
+func (c *Capture) Entries() []Exchange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Exchange, len(c.entries))
+	for i, e := range c.entries {
+		out[len(out)-1-i] = e
+	}
+	return out
+}
+
+// Enable captures requests from ips for d.
+func (c *Capture) Enable(ips []string, d time.Duration) error {
+	if d <= 0 || d > maxTimeout {
+		return fmt.Errorf("capture duration must be between 0 and %s", maxTimeout)
+	}
+	until := time.Now().Add(d)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ip := range ips {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("%q is not an IP address", ip)
+		}
+	}
+	for _, ip := range ips {
+		c.ips[ip] = until
+	}
+	return nil
+}
+
+// Disable stops capturing for every IP. Captured entries are kept.
+func (c *Capture) Disable() {
+	c.mu.Lock()
+	c.ips = make(map[string]time.Time)
+	c.mu.Unlock()
+}
+
+// Clear drops every captured entry.
+func (c *Capture) Clear() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+func (c *Capture) enabledIPs(now time.Time) map[string]time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]time.Time, len(c.ips))
+	for ip, until := range c.ips {
+		if now.Before(until) {
+			out[ip] = until
+		}
+	}
+	return out
+}
+
+func (c *Capture) GetSettings(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"ips": c.enabledIPs(time.Now())})
+}
+
+// SetSettings enables capture for the listed IPs, or the caller's own IP
+// when none are given, for duration (default 15m).
+func (c *Capture) SetSettings(ctx *gin.Context) {
+	var req struct {
+		IPs      []string `json:"ips"`
+		Duration string   `json:"duration"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"ips\": [\"...\"], \"duration\": \"15m\"})"})
+		return
+	}
+	d := DefaultTimeout
+	if req.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(req.Duration); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "duration must be a Go duration such as 15m"})
+			return
+		}
+	}
+	if len(req.IPs) == 0 {
+		req.IPs = []string{middleware.GetClientIP(ctx)}
+	}
+	if err := c.Enable(req.IPs, d); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("INFO: %s enabled request capture for %s for %s.", ctx.GetString(middleware.ActorContextKey), strings.Join(req.IPs, ", "), d)
+	ctx.JSON(http.StatusOK, gin.H{"ips": c.enabledIPs(time.Now())})
+}
+
+func (c *Capture) DeleteSettings(ctx *gin.Context) {
+	c.Disable()
+	log.Printf("INFO: %s disabled request capture.", ctx.GetString(middleware.ActorContextKey))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *Capture) ListRequests(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"requests": c.Entries()})
+}
+
+func (c *Capture) ClearRequests(ctx *gin.Context) {
+	c.Clear()
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package devices
+
+import (
+	"strings"
+
+	"github.com/mssola/useragent"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+const (
+	ClassDesktop = "desktop"
+	ClassMobile  = "mobile"
+	ClassTablet  = "tablet"
+	ClassBot     = "bot"
+	ClassUnknown = "unknown"
+)
+
+// Info is what a user agent says about the visitor's software and hardware.
+type Info struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+// Parse classifies a User-Agent header. Empty or unrecognised values yield
+// "Other" families and the unknown device class.
+func Parse(ua string) Info {
+	if strings.TrimSpace(ua) == "" {
+		return Info{Browser: "Other", OS: "Other", Device: ClassUnknown}
+	}
+
+	p := useragent.New(ua)
+	info := Info{OS: p.OSInfo().Name}
+	info.Browser, _ = p.Browser()
+	if info.Browser == "" {
+		info.Browser = "Other"
+	}
+	switch {
+	case info.OS == "iPhone OS" || strings.Contains(ua, "iPad"):
+		info.OS = "iOS"
+	case info.OS == "":
+		info.OS = "Other"
+	}
+
+	info.Browser = truncate(info.Browser)
+	info.OS = truncate(info.OS)
+
+	switch {
+	case p.Bot():
+		info.Device = ClassBot
+	case isTablet(ua):
+		info.Device = ClassTablet
+	case p.Mobile():
+		info.Device = ClassMobile
+	case p.Mozilla() != "":
+		info.Device = ClassDesktop
+	default:
+		info.Device = ClassUnknown
+	}
+	return info
+}
+
+// maxNameLength matches the browser and os columns of click_events.
+const maxNameLength = 64
+
+func truncate(name string) string {
+	if len(name) > maxNameLength {
+		return name[:maxNameLength]
+	}
+	return name
+}
+
+// isTablet catches iPads and Android tablets, which omit the "Mobile" token
+// that Android phones send.
+func isTablet(ua string) bool {
+	if strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") {
+		return true
+	}
+	return strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile")
+}
+
+// Parser fills in browser, OS and device class on recorded events.
+type Parser struct{}
+
+func (Parser) Enrich(e *events.Event) {
+	info := Parse(e.UserAgent)
+	e.Browser = info.Browser
+	e.OS = info.OS
+	e.Device = info.Device
+}
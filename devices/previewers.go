@@ -0,0 +1,32 @@
+package devices
+
+import "strings"
+
+// previewerTokens identify the fetchers messaging and social apps send to
+// unfurl a link when it is posted. iMessage presents itself as Safari with
+// the facebookexternalhit and Twitterbot tokens appended.
+var previewerTokens = []string{
+	"WhatsApp/",
+	"facebookexternalhit",
+	"Facebot",
+	"Twitterbot",
+	"TelegramBot",
+	"Slackbot-LinkExpanding",
+	"Discordbot",
+	"LinkedInBot",
+	"SkypeUriPreview",
+	"Viber",
+	"Iframely",
+	"redditbot",
+}
+
+// IsLinkPreviewer reports whether ua belongs to an app building a link
+// preview rather than a person following the link.
+func IsLinkPreviewer(ua string) bool {
+	for _, token := range previewerTokens {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Package errtrack reports panics recovered from requests to an error
+// tracker. Sentry, and the self-hosted trackers that accept its protocol
+// such as GlitchTip, are supported with nothing but the project's DSN.
+package errtrack
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds one report, which is sent after the request failed
+// and must not pile up if the tracker is slow.
+const DefaultTimeout = 5 * time.Second
+
+// Report describes a panic and the request it interrupted. It carries no
+// query string, headers or body, which may hold credentials.
+type Report struct {
+	Value     any
+	Stack     []runtime.Frame
+	Method    string
+	URL       string
+	Route     string
+	ClientIP  string
+	UserAgent string
+	Actor     string
+	Time      time.Time
+}
+
+// Tracker receives panic reports. Implementations must be safe for
+// concurrent use.
+type Tracker interface {
+	CapturePanic(ctx context.Context, report Report) error
+}
+
+// Callers returns the stack of a goroutine that is panicking, from the
+// function that panicked outwards. Call it from the deferred function that
+// recovered.
+func Callers() []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if frame.Function == "runtime.gopanic" {
+			stack = stack[:0]
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+// FormatStack renders frames like a goroutine dump, for logs.
+func FormatStack(stack []runtime.Frame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
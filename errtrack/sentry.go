@@ -0,0 +1,173 @@
+package errtrack
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// Sentry sends reports to the store endpoint named by a Sentry DSN.
+type Sentry struct {
+	StoreURL    string
+	Environment string
+	Release     string
+	HTTP        *http.Client
+
+	auth       string
+	serverName string
+}
+
+// NewSentry parses a DSN of the form https://<key>@<host>/<project>, where
+// the host may carry a path prefix before the project ID.
+func NewSentry(dsn string, environment string, release string) (*Sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User == nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: expected https://<key>@<host>/<project>")
+	}
+	prefix, project := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if project == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing project ID")
+	}
+
+	auth := "Sentry sentry_version=7, sentry_client=urlshortener/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	hostname, _ := os.Hostname()
+	store := url.URL{Scheme: u.Scheme, Host: u.Host, Path: prefix + "api/" + project + "/store/"}
+	return &Sentry{
+		StoreURL:    store.String(),
+		Environment: environment,
+		Release:     release,
+		HTTP:        &http.Client{Timeout: DefaultTimeout},
+		auth:        auth,
+		serverName:  hostname,
+	}, nil
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryException struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Stacktrace struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+type sentryEvent struct {
+	EventID     string `json:"event_id"`
+	Timestamp   string `json:"timestamp"`
+	Platform    string `json:"platform"`
+	Level       string `json:"level"`
+	ServerName  string `json:"server_name,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Release     string `json:"release,omitempty"`
+	Transaction string `json:"transaction,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+	Request struct {
+		Method  string            `json:"method"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers,omitempty"`
+	} `json:"request"`
+	User *sentryUser       `json:"user,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+type sentryUser struct {
+	ID        string `json:"id,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+// CapturePanic sends one event. Sentry expects frames oldest first, the
+// reverse of a goroutine dump.
+func (s *Sentry) CapturePanic(ctx context.Context, report Report) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	var event sentryEvent
+	event.EventID = hex.EncodeToString(id)
+	event.Timestamp = report.Time.UTC().Format("2006-01-02T15:04:05.000Z")
+	event.Platform = "go"
+	event.Level = "error"
+	event.ServerName = s.serverName
+	event.Environment = s.Environment
+	event.Release = s.Release
+	event.Transaction = report.Method + " " + report.Route
+
+	exception := sentryException{Type: "panic", Value: fmt.Sprint(report.Value)}
+	for i := len(report.Stack) - 1; i >= 0; i-- {
+		f := report.Stack[i]
+		module, function := splitFunction(f.Function)
+		exception.Stacktrace.Frames = append(exception.Stacktrace.Frames, sentryFrame{
+			Function: function,
+			Module:   module,
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    strings.HasPrefix(module, "github.com/AnshulDekate/urlShortener"),
+		})
+	}
+	event.Exception.Values = []sentryException{exception}
+
+	event.Request.Method = report.Method
+	event.Request.URL = report.URL
+	if report.UserAgent != "" {
+		event.Request.Headers = map[string]string{"User-Agent": report.UserAgent}
+	}
+	if report.Actor != "" || report.ClientIP != "" {
+		event.User = &sentryUser{ID: report.Actor, IPAddress: report.ClientIP}
+	}
+	if report.Route != "" {
+		event.Tags = map[string]string{"route": report.Route}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode Sentry event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.StoreURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build Sentry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Sentry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sentry rejected event with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// splitFunction splits a runtime function name such as
+// "github.com/x/y/pkg.(*T).Method" into its package path and the rest.
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+2+dot:]
+}
@@ -0,0 +1,46 @@
+package events
+
+import "time"
+
+const (
+	TypeClick = "click"
+	TypeView  = "view"
+	// TypeExpiring warns a link's webhook that the link expires at
+	// ExpiresAt. It is sent once, ahead of the expiry.
+	TypeExpiring = "expiring"
+)
+
+const (
+	VariantControl = "control"
+	VariantCanary  = "canary"
+)
+
+type Event struct {
+	Type       string     `json:"type"`
+	ShortCode  string     `json:"short_url"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	Referer    string     `json:"referer"`
+	Variant    string     `json:"variant,omitempty"`
+	ASN        uint32     `json:"asn,omitempty"`
+	ASOrg      string     `json:"as_org,omitempty"`
+	Browser    string     `json:"browser,omitempty"`
+	OS         string     `json:"os,omitempty"`
+	Device     string     `json:"device,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+
+	// CorrelationID is the X-Correlation-ID of the request that recorded
+	// the event.
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// Latitude and Longitude are the rounded location of IP, both zero when
+	// unknown. They are filled in just before the event is stored, and so
+	// never reach webhooks or the click stream.
+	Latitude  float64 `json:"-"`
+	Longitude float64 `json:"-"`
+
+	// SampleRate is the link's detail sampling rate at click time; zero
+	// means the click is always recorded.
+	SampleRate float64 `json:"-"`
+}
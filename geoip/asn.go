@@ -0,0 +1,98 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+// record covers the fields shared by GeoLite2-ASN and GeoIP2-ISP databases.
+// Only the ISP edition carries isp.
+type record struct {
+	ASN          uint32 `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+	ISP          string `maxminddb:"isp"`
+}
+
+// ASNReader resolves client IPs to their autonomous system using a MaxMind
+// format database file.
+type ASNReader struct {
+	db *maxminddb.Reader
+}
+
+func OpenASN(path string) (*ASNReader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ASN database %s: %w", path, err)
+	}
+	return &ASNReader{db: db}, nil
+}
+
+func (r *ASNReader) DatabaseType() string {
+	return r.db.Metadata.DatabaseType
+}
+
+func (r *ASNReader) Close() error {
+	return r.db.Close()
+}
+
+// Enrich fills in the event's network fields. Unparseable and unknown
+// addresses are left blank.
+func (r *ASNReader) Enrich(e *events.Event) {
+	ip := net.ParseIP(e.IP)
+	if ip == nil {
+		return
+	}
+	var rec record
+	if err := r.db.Lookup(ip, &rec); err != nil || rec.ASN == 0 {
+		return
+	}
+	e.ASN = rec.ASN
+	e.ASOrg = rec.Organization
+	if rec.ISP != "" {
+		e.ASOrg = rec.ISP
+	}
+}
+
+// hostingASNs are large cloud and hosting networks whose traffic is almost
+// never a person behind a browser.
+var hostingASNs = map[uint32]bool{
+	14061:  true, // DigitalOcean
+	14618:  true, // Amazon
+	16509:  true, // Amazon
+	15169:  true, // Google
+	396982: true, // Google Cloud
+	8075:   true, // Microsoft
+	16276:  true, // OVH
+	24940:  true, // Hetzner
+	63949:  true, // Linode
+	20473:  true, // Vultr
+	45102:  true, // Alibaba
+	31898:  true, // Oracle
+	13335:  true, // Cloudflare
+	54113:  true, // Fastly
+}
+
+var hostingKeywords = []string{
+	"hosting", "cloud", "datacenter", "data center", "server", "vps", "colo",
+	"amazon", "google", "microsoft", "digitalocean", "ovh", "hetzner", "linode", "vultr",
+}
+
+// IsHosting reports whether a network looks like a datacenter rather than a
+// residential or mobile ISP.
+func IsHosting(asn uint32, org string) bool {
+	if hostingASNs[asn] {
+		return true
+	}
+	org = strings.ToLower(org)
+	for _, kw := range hostingKeywords {
+		if strings.Contains(org, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package geoip
+
+import (
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+const (
+	// CoordinatePrecision is the number of decimal places click locations
+	// are rounded to before they are stored: 0.1° is about 11km, wider than
+	// most cities' centres, so no stored click points at a street.
+	CoordinatePrecision = 1
+
+	// maxAccuracyRadius drops locations the database only knows to the
+	// country or region, in kilometres. They sit at the area's centroid and
+	// would show as a hotspot where nobody clicked.
+	maxAccuracyRadius = 200
+)
+
+type cityRecord struct {
+	Location struct {
+		Latitude       *float64 `maxminddb:"latitude"`
+		Longitude      *float64 `maxminddb:"longitude"`
+		AccuracyRadius uint16   `maxminddb:"accuracy_radius"`
+	} `maxminddb:"location"`
+}
+
+// CityReader resolves client IPs to an approximate location using a
+// GeoLite2-City or GeoIP2-City database file.
+type CityReader struct {
+	db *maxminddb.Reader
+}
+
+func OpenCity(path string) (*CityReader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open city database %s: %w", path, err)
+	}
+	return &CityReader{db: db}, nil
+}
+
+func (r *CityReader) DatabaseType() string {
+	return r.db.Metadata.DatabaseType
+}
+
+func (r *CityReader) Close() error {
+	return r.db.Close()
+}
+
+// Enrich fills in the event's rounded location. Unparseable and unknown
+// addresses, and ones located only roughly, are left blank.
+func (r *CityReader) Enrich(e *events.Event) {
+	ip := net.ParseIP(e.IP)
+	if ip == nil {
+		return
+	}
+	var rec cityRecord
+	if err := r.db.Lookup(ip, &rec); err != nil || rec.Location.Latitude == nil || rec.Location.Longitude == nil {
+		return
+	}
+	if rec.Location.AccuracyRadius == 0 || rec.Location.AccuracyRadius > maxAccuracyRadius {
+		return
+	}
+	e.Latitude = RoundCoordinate(*rec.Location.Latitude, CoordinatePrecision)
+	e.Longitude = RoundCoordinate(*rec.Location.Longitude, CoordinatePrecision)
+}
+
+// RoundCoordinate rounds a latitude or longitude to places decimal places.
+func RoundCoordinate(degrees float64, places int) float64 {
+	scale := math.Pow10(places)
+	return math.Round(degrees*scale) / scale
+}
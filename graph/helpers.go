@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/AnshulDekate/urlShortener/graph/model"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (r *Resolver) linkConnection(ctx context.Context, filter repository.URLFilter, first *int, after *string) (*model.LinkConnection, error) {
+	page, err := r.Service.ListLinkPage(ctx, filter, intValue(first), stringValue(after))
+	if errors.Is(err, service.ErrInvalidCursor) {
+		return nil, gqlerror.Errorf("first must be between 1 and %d and after a cursor from a previous page", service.MaxPageSize)
+	}
+	if err != nil {
+		return nil, internal("link listing", err)
+	}
+
+	conn := &model.LinkConnection{Edges: make([]*model.LinkEdge, len(page.Links)), PageInfo: &model.PageInfo{HasNextPage: page.HasNextPage}}
+	for i := range page.Links {
+		conn.Edges[i] = &model.LinkEdge{Cursor: service.LinkCursor(page.Links[i].ID), Node: &page.Links[i]}
+	}
+	if page.EndCursor != "" {
+		conn.PageInfo.EndCursor = &page.EndCursor
+	}
+	return conn, nil
+}
+
+func intValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
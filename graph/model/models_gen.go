@@ -0,0 +1,31 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+type LinkConnection struct {
+	Edges    []*LinkEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type LinkEdge struct {
+	Cursor string               `json:"cursor"`
+	Node   *repository.PagedURL `json:"node"`
+}
+
+type LinkFilter struct {
+	UtmSource   *string `json:"utmSource,omitempty"`
+	UtmMedium   *string `json:"utmMedium,omitempty"`
+	UtmCampaign *string `json:"utmCampaign,omitempty"`
+}
+
+type PageInfo struct {
+	EndCursor   *string `json:"endCursor,omitempty"`
+	HasNextPage bool    `json:"hasNextPage"`
+}
+
+type Query struct {
+}
@@ -0,0 +1,28 @@
+// Package graph serves a GraphQL API over the service layer, so dashboards
+// can fetch links, their stats and campaigns in one request.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"context"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// Service is what the resolvers need of the service layer.
+type Service interface {
+	GetLink(ctx context.Context, shortCode string) (*repository.PagedURL, error)
+	ListLinkPage(ctx context.Context, filter repository.URLFilter, first int, after string) (*service.LinkPage, error)
+	GetLinkStats(ctx context.Context, shortCode string, excludeFlagged bool) (*repository.LinkStats, error)
+	GetCampaign(ctx context.Context, campaignID int64) (*repository.Campaign, error)
+	ListCampaigns(ctx context.Context) ([]repository.Campaign, error)
+	GetCampaignStats(ctx context.Context, campaignID int64, days int) (*service.CampaignStats, error)
+}
+
+type Resolver struct {
+	Service Service
+	// Domain is prepended to short codes to build shortURL.
+	Domain string
+}
@@ -0,0 +1,133 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/graph/model"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// Links is the resolver for the links field.
+func (r *campaignResolver) Links(ctx context.Context, obj *repository.Campaign, first *int, after *string) (*model.LinkConnection, error) {
+	return r.linkConnection(ctx, repository.URLFilter{CampaignID: obj.ID}, first, after)
+}
+
+// Stats is the resolver for the stats field.
+func (r *campaignResolver) Stats(ctx context.Context, obj *repository.Campaign, days *int) (*service.CampaignStats, error) {
+	stats, err := r.Service.GetCampaignStats(ctx, obj.ID, intValue(days))
+	if err != nil {
+		return nil, internal("campaign stats", err)
+	}
+	return stats, nil
+}
+
+// ShortURL is the resolver for the shortURL field.
+func (r *linkResolver) ShortURL(ctx context.Context, obj *repository.PagedURL) (string, error) {
+	return r.domain(ctx) + obj.ShortCode, nil
+}
+
+// LastAccessedAt is the resolver for the lastAccessedAt field.
+func (r *linkResolver) LastAccessedAt(ctx context.Context, obj *repository.PagedURL) (*time.Time, error) {
+	if obj.LastAccessedAt.IsZero() {
+		return nil, nil
+	}
+	return &obj.LastAccessedAt, nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *linkResolver) Owner(ctx context.Context, obj *repository.PagedURL) (*string, error) {
+	return optional(obj.Owner), nil
+}
+
+// Workspace is the resolver for the workspace field.
+func (r *linkResolver) Workspace(ctx context.Context, obj *repository.PagedURL) (*string, error) {
+	return optional(obj.Workspace), nil
+}
+
+// Campaign is the resolver for the campaign field.
+func (r *linkResolver) Campaign(ctx context.Context, obj *repository.PagedURL) (*repository.Campaign, error) {
+	if obj.CampaignID == nil {
+		return nil, nil
+	}
+	return r.Query().Campaign(ctx, *obj.CampaignID)
+}
+
+// Stats is the resolver for the stats field.
+func (r *linkResolver) Stats(ctx context.Context, obj *repository.PagedURL, excludeFlagged *bool) (*repository.LinkStats, error) {
+	stats, err := r.Service.GetLinkStats(ctx, obj.ShortCode, excludeFlagged != nil && *excludeFlagged)
+	if errors.Is(err, service.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, internal("link stats", err)
+	}
+	return stats, nil
+}
+
+// Link is the resolver for the link field.
+func (r *queryResolver) Link(ctx context.Context, shortCode string) (*repository.PagedURL, error) {
+	link, err := r.Service.GetLink(ctx, shortCode)
+	if errors.Is(err, service.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, internal("link lookup", err)
+	}
+	return link, nil
+}
+
+// Links is the resolver for the links field.
+func (r *queryResolver) Links(ctx context.Context, first *int, after *string, filter *model.LinkFilter) (*model.LinkConnection, error) {
+	var f repository.URLFilter
+	if filter != nil {
+		f.UTMSource = stringValue(filter.UtmSource)
+		f.UTMMedium = stringValue(filter.UtmMedium)
+		f.UTMCampaign = stringValue(filter.UtmCampaign)
+	}
+	return r.linkConnection(ctx, f, first, after)
+}
+
+// Campaign is the resolver for the campaign field.
+func (r *queryResolver) Campaign(ctx context.Context, id int64) (*repository.Campaign, error) {
+	c, err := r.Service.GetCampaign(ctx, id)
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, internal("campaign lookup", err)
+	}
+	return c, nil
+}
+
+// Campaigns is the resolver for the campaigns field.
+func (r *queryResolver) Campaigns(ctx context.Context) ([]*repository.Campaign, error) {
+	campaigns, err := r.Service.ListCampaigns(ctx)
+	if err != nil {
+		return nil, internal("campaign listing", err)
+	}
+	out := make([]*repository.Campaign, len(campaigns))
+	for i := range campaigns {
+		out[i] = &campaigns[i]
+	}
+	return out, nil
+}
+
+// Campaign returns CampaignResolver implementation.
+func (r *Resolver) Campaign() CampaignResolver { return &campaignResolver{r} }
+
+// Link returns LinkResolver implementation.
+func (r *Resolver) Link() LinkResolver { return &linkResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type campaignResolver struct{ *Resolver }
+type linkResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+)
+
+// maxComplexity bounds the fields one query may select, so a single request
+// cannot fan out into thousands of stats lookups.
+const maxComplexity = 500
+
+var (
+	errUnauthenticated = errors.New("authentication required")
+	errInternal        = errors.New("internal error")
+)
+
+type (
+	actorKey   struct{}
+	baseURLKey struct{}
+)
+
+func actor(ctx context.Context) string {
+	a, _ := ctx.Value(actorKey{}).(string)
+	return a
+}
+
+// domain is the short URL domain a trusted proxy forwarded for the request,
+// or the resolver's Domain.
+func (r *Resolver) domain(ctx context.Context) string {
+	if base, _ := ctx.Value(baseURLKey{}).(string); base != "" {
+		return base
+	}
+	return r.Domain
+}
+
+// auth implements @auth: the field resolves only for requests that passed
+// authentication.
+func auth(ctx context.Context, obj any, next graphql.Resolver) (any, error) {
+	if actor(ctx) == "" {
+		return nil, errUnauthenticated
+	}
+	return next(ctx)
+}
+
+// internal logs a service failure and hides it from the client.
+func internal(op string, err error) error {
+	log.Printf("Service error during GraphQL %s: %v", op, err)
+	return errInternal
+}
+
+// Handler serves POST and GET GraphQL requests. It must run after
+// middleware.OptionalAdminAuth, whose actor unlocks @auth fields.
+func Handler(r *Resolver) gin.HandlerFunc {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: r, Directives: DirectiveRoot{Auth: auth}}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(maxComplexity))
+
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), actorKey{}, c.GetString(middleware.ActorContextKey))
+		ctx = context.WithValue(ctx, baseURLKey{}, c.GetString(middleware.BaseURLContextKey))
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
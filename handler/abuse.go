@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) ListAbuseEvents(c *gin.Context) {
+	var q struct {
+		Days int `form:"days,default=7" binding:"min=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	abuse, err := h.Service.ListAbuseEvents(ctx, q.Days)
+	if err != nil {
+		log.Printf("Service error during abuse event listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve abuse events."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": abuse})
+}
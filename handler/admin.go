@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) CreateWorkspace(c *gin.Context) {
+	var req struct {
+		Slug       string `json:"slug" binding:"required"`
+		Name       string `json:"name"`
+		CodePrefix string `json:"code_prefix"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"slug\": \"...\", \"name\": \"...\", \"code_prefix\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	workspace, err := h.Service.CreateWorkspace(ctx, req.Slug, req.Name, req.CodePrefix)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWorkspace):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Workspace slug must be 2-64 lowercase letters, digits or dashes."})
+		case errors.Is(err, service.ErrInvalidCodePrefix):
+			c.JSON(http.StatusBadRequest, gin.H{"error": codePrefixRules})
+		case errors.Is(err, service.ErrWorkspaceExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Workspace already exists"})
+		case errors.Is(err, service.ErrCodePrefixTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Code prefix is already reserved by another workspace"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace."})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, workspace)
+}
+
+const codePrefixRules = "Code prefix must be 2-12 lowercase letters or digits followed by a dash, e.g. \"acme-\"."
+
+func (h *GinHandler) SetWorkspaceCodePrefix(c *gin.Context) {
+	var req struct {
+		CodePrefix string `json:"code_prefix"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"code_prefix\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	workspace, err := h.Service.SetWorkspaceCodePrefix(ctx, c.Param("slug"), req.CodePrefix)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCodePrefix):
+			c.JSON(http.StatusBadRequest, gin.H{"error": codePrefixRules})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		case errors.Is(err, service.ErrCodePrefixTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Code prefix is already reserved by another workspace"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update code prefix."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
+
+// SetWorkspaceCodeFormat answers the workspace with the size of its new
+// keyspace and any warnings about it.
+func (h *GinHandler) SetWorkspaceCodeFormat(c *gin.Context) {
+	var req struct {
+		Alphabet string `json:"alphabet"`
+		Length   int    `json:"length"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"alphabet\": \"0123456789\", \"length\": 8})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.Service.SetWorkspaceCodeFormat(ctx, c.Param("slug"), req.Alphabet, req.Length)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCodeFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		default:
+			log.Printf("Service error while setting code format: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update code format."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *GinHandler) TransferLinks(c *gin.Context) {
+	var req struct {
+		Codes     []string `json:"codes" binding:"required"`
+		Owner     string   `json:"owner"`
+		Workspace string   `json:"workspace"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"codes\": [\"...\"], \"owner\": \"...\", \"workspace\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.Service.TransferLinks(ctx, req.Codes, req.Owner, req.Workspace, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTransfer):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provide 1-500 codes and at least one of owner or workspace."})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		case errors.Is(err, service.ErrTransferConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "A destination of these links is already shortened in the target workspace; nothing was transferred."})
+		default:
+			log.Printf("Service error during link transfer: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer links."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// 1x1 transparent GIF89a.
+var transparentPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+func (h *GinHandler) Pixel(c *gin.Context) {
+	h.Service.TrackEvent(events.Event{
+		Type:          events.TypeView,
+		ShortCode:     c.Param("code"),
+		IP:            middleware.GetClientIP(c),
+		UserAgent:     c.Request.UserAgent(),
+		Referer:       c.Request.Referer(),
+		OccurredAt:    time.Now().UTC(),
+		CorrelationID: c.GetString(middleware.CorrelationIDContextKey),
+	})
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	c.Header("Pragma", "no-cache")
+	c.Data(http.StatusOK, "image/gif", transparentPixel)
+}
+
+func (h *GinHandler) LinkStats(c *gin.Context) {
+	var q struct {
+		ExcludeFlagged bool `form:"exclude_flagged"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetLinkStats(ctx, c.Param("code"), q.ExcludeFlagged)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve link stats."})
+		return
+	}
+
+	stats.ShortCode = h.domain(c) + stats.ShortCode
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *GinHandler) LinkNetworks(c *gin.Context) {
+	var q struct {
+		Days int `form:"days,default=30" binding:"min=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetNetworkStats(ctx, c.Param("code"), q.Days)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve network breakdown."})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *GinHandler) LinkDevices(c *gin.Context) {
+	var q struct {
+		Days int `form:"days,default=30" binding:"min=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetDeviceStats(ctx, c.Param("code"), q.Days)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device breakdown."})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// LinkGeo answers the located clicks of a link bucketed for a heat map.
+func (h *GinHandler) LinkGeo(c *gin.Context) {
+	var q struct {
+		Days      int `form:"days,default=30" binding:"min=1"`
+		Precision int `form:"precision,default=1" binding:"min=0,max=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetGeoStats(ctx, c.Param("code"), q.Days, q.Precision)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve click locations."})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// LinkTimeseries answers a link's clicks per hour, day or week, for charts.
+func (h *GinHandler) LinkTimeseries(c *gin.Context) {
+	var q struct {
+		Granularity string    `form:"granularity,default=day" binding:"oneof=hour day week"`
+		From        time.Time `form:"from"`
+		To          time.Time `form:"to"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	series, err := h.Service.GetClickSeries(ctx, c.Param("code"), q.Granularity, q.From, q.To)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSeries):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			log.Printf("Service error during click time series: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve click time series."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *GinHandler) LiveStats(c *gin.Context) {
+	stats := h.Service.GetLiveStats()
+	for i := range stats.Trending {
+		stats.Trending[i].ShortCode = h.domain(c) + stats.Trending[i].ShortCode
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, stats)
+}
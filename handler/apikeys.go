@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyNameRules = "Key name must be 2-64 lowercase letters, digits, dots, underscores or dashes."
+
+// Bootstrap is served outside the admin group: the caller authenticates with
+// the one-time bootstrap token instead of an API key.
+func (h *GinHandler) Bootstrap(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"name\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	key, err := h.Service.Bootstrap(ctx, middleware.BearerToken(c.Request), req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBootstrapToken):
+			log.Printf("ADMIN AUTH: rejected bootstrap from %s.", middleware.GetClientIP(c))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		case errors.Is(err, service.ErrAlreadyBootstrapped):
+			c.JSON(http.StatusConflict, gin.H{"error": "Admin API is already bootstrapped"})
+		case errors.Is(err, service.ErrInvalidAPIKeyName):
+			c.JSON(http.StatusBadRequest, gin.H{"error": apiKeyNameRules})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Bootstrap failed."})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// EnsureAPIKey creates the named key if it does not exist. The plaintext key
+// is only returned with 201; repeating the call returns 200 and metadata.
+func (h *GinHandler) EnsureAPIKey(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	key, created, err := h.Service.EnsureAPIKey(ctx, c.Param("name"), c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": apiKeyNameRules})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key."})
+		return
+	}
+	if created {
+		c.JSON(http.StatusCreated, key)
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *GinHandler) ListAPIKeys(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	keys, err := h.Service.ListAPIKeys(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+func (h *GinHandler) RevokeAPIKey(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.RevokeAPIKey(ctx, c.Param("name"), c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RotateAPIKeySigningSecret enables signed requests for a key, or replaces its
+// secret. The secret is only shown in this response.
+func (h *GinHandler) RotateAPIKeySigningSecret(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	name := c.Param("name")
+	secret, err := h.Service.RotateAPIKeySigningSecret(ctx, name, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue signing secret."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": name, "signing_secret": secret})
+}
+
+func (h *GinHandler) DisableAPIKeySigning(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DisableAPIKeySigning(ctx, c.Param("name"), c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable signed requests."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
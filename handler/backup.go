@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) CreateBackup(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Minute)
+	defer cancel()
+
+	key, err := h.Service.CreateBackup(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBackupInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": "A backup is already running"})
+		case errors.Is(err, service.ErrStorageNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Object storage is not configured"})
+		default:
+			log.Printf("Service error during backup: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup failed."})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"storage_key": key})
+}
+
+func (h *GinHandler) ListBackups(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	backups, err := h.Service.ListBackups(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve backups."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"backups": backups})
+}
+
+// ExportInstance streams the whole instance as a backup archive, for
+// `server import` on another deployment. A failure after the download
+// started leaves a truncated gzip stream, which the import rejects.
+func (h *GinHandler) ExportInstance(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Minute)
+	defer cancel()
+
+	liftWriteDeadline(c)
+	filename := fmt.Sprintf("urlshortener-export-%s.ndjson.gz", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	rows, err := h.Service.ExportArchive(ctx, c.Writer)
+	if err != nil {
+		if c.Writer.Written() {
+			log.Printf("Service error during instance export, download truncated: %v", err)
+			return
+		}
+		c.Header("Content-Disposition", "")
+		if errors.Is(err, service.ErrBackupInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A backup or export is already running"})
+			return
+		}
+		log.Printf("Service error during instance export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Export failed."})
+		return
+	}
+	log.Printf("INFO: Exported %d rows.", rows)
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// DefaultBrandingCacheTTL is how long another instance's change to a
+// workspace's pages can take to show here.
+const DefaultBrandingCacheTTL = time.Minute
+
+const maxPageTemplateBytes = 256 << 10
+
+// brandingCache holds the parsed pages of every workspace that has been
+// rendered, and the code prefixes whose unknown codes get a workspace's 404
+// page. Workspaces that replaced nothing are cached as nil.
+type brandingCache struct {
+	mu               sync.Mutex
+	renderers        map[int64]brandedRenderer
+	prefixes         map[string]int64
+	prefixesExpireAt time.Time
+}
+
+type brandedRenderer struct {
+	pages    *pages.Renderer
+	expireAt time.Time
+}
+
+func (b *brandingCache) purge() {
+	b.mu.Lock()
+	b.renderers = nil
+	b.prefixes = nil
+	b.mu.Unlock()
+}
+
+func (h *GinHandler) brandingTTL() time.Duration {
+	if h.BrandingCacheTTL > 0 {
+		return h.BrandingCacheTTL
+	}
+	return DefaultBrandingCacheTTL
+}
+
+// pagesFor returns the pages of a workspace's links: h.Pages with the
+// workspace's templates swapped in, or h.Pages itself when it replaced none
+// or they cannot be loaded.
+func (h *GinHandler) pagesFor(ctx context.Context, workspaceID int64) *pages.Renderer {
+	if workspaceID == 0 {
+		return h.Pages
+	}
+	now := time.Now()
+	h.branding.mu.Lock()
+	cached, ok := h.branding.renderers[workspaceID]
+	h.branding.mu.Unlock()
+	if ok && now.Before(cached.expireAt) {
+		if cached.pages == nil {
+			return h.Pages
+		}
+		return cached.pages
+	}
+
+	renderer, err := h.loadBranding(ctx, workspaceID)
+	if err != nil {
+		log.Printf("ERROR: Failed to load pages of workspace %d: %v", workspaceID, err)
+		return h.Pages
+	}
+	h.branding.mu.Lock()
+	if h.branding.renderers == nil {
+		h.branding.renderers = map[int64]brandedRenderer{}
+	}
+	h.branding.renderers[workspaceID] = brandedRenderer{pages: renderer, expireAt: now.Add(h.brandingTTL())}
+	h.branding.mu.Unlock()
+	if renderer == nil {
+		return h.Pages
+	}
+	return renderer
+}
+
+func (h *GinHandler) loadBranding(ctx context.Context, workspaceID int64) (*pages.Renderer, error) {
+	wp, err := h.Service.GetWorkspacePages(ctx, workspaceID)
+	if errors.Is(err, service.ErrWorkspaceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(wp.Templates) == 0 && !wp.HasLogo {
+		return nil, nil
+	}
+	return h.Pages.Branded(wp.Templates, h.brandingFuncs(wp.Slug, wp.Name, wp.HasLogo))
+}
+
+func (h *GinHandler) brandingFuncs(slug string, name string, hasLogo bool) template.FuncMap {
+	logo := ""
+	if hasLogo {
+		logo = "/w/" + slug + "/logo"
+	}
+	return template.FuncMap{
+		"logo":      func() string { return logo },
+		"workspace": func() string { return name },
+	}
+}
+
+// notFoundPages picks the 404 page for an unknown code by the longest
+// workspace code prefix it starts with.
+func (h *GinHandler) notFoundPages(ctx context.Context, code string) *pages.Renderer {
+	now := time.Now()
+	h.branding.mu.Lock()
+	prefixes, expired := h.branding.prefixes, !now.Before(h.branding.prefixesExpireAt)
+	h.branding.mu.Unlock()
+	if prefixes == nil || expired {
+		loaded, err := h.Service.BrandedCodePrefixes(ctx)
+		if err != nil {
+			log.Printf("ERROR: Failed to load branded code prefixes: %v", err)
+			return h.Pages
+		}
+		prefixes = loaded
+		h.branding.mu.Lock()
+		h.branding.prefixes, h.branding.prefixesExpireAt = prefixes, now.Add(h.brandingTTL())
+		h.branding.mu.Unlock()
+	}
+
+	best, workspaceID := "", int64(0)
+	for prefix, id := range prefixes {
+		if strings.HasPrefix(code, prefix) && len(prefix) > len(best) {
+			best, workspaceID = prefix, id
+		}
+	}
+	return h.pagesFor(ctx, workspaceID)
+}
+
+// writePage renders page with renderer, falling back to the default pages
+// when a workspace's template fails at execution time.
+func (h *GinHandler) writePage(c *gin.Context, renderer *pages.Renderer, status int, page string, data any) {
+	if renderer != h.Pages {
+		var buf bytes.Buffer
+		err := renderer.Render(&buf, middleware.Locale(c), page, data)
+		if err == nil {
+			c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+			return
+		}
+		log.Printf("WARN: Workspace %s page failed to render, using the default: %v", page, err)
+	}
+	h.Pages.Write(c, status, page, data)
+}
+
+func (h *GinHandler) GetWorkspacePages(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	wp, err := h.Service.GetWorkspacePagesBySlug(ctx, c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			return
+		}
+		log.Printf("Service error while reading workspace pages: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read workspace pages."})
+		return
+	}
+	c.JSON(http.StatusOK, wp)
+}
+
+// SetWorkspacePage takes the template as the raw request body. It must
+// parse together with the default templates before it is stored.
+func (h *GinHandler) SetWorkspacePage(c *gin.Context) {
+	slug, page := c.Param("slug"), c.Param("page")
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPageTemplateBytes))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Template exceeds 256 KiB"})
+		return
+	}
+	if _, err := h.Pages.Branded(map[string]string{page: string(body)}, h.brandingFuncs(slug, "", false)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.SetWorkspacePage(ctx, slug, page, string(body)); err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			return
+		}
+		log.Printf("Service error while storing workspace page: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store page."})
+		return
+	}
+	h.branding.purge()
+	c.JSON(http.StatusOK, gin.H{"workspace": slug, "page": page})
+}
+
+func (h *GinHandler) DeleteWorkspacePage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteWorkspacePage(ctx, c.Param("slug"), c.Param("page")); err != nil {
+		if errors.Is(err, service.ErrPageNotReplaced) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace has not replaced this page"})
+			return
+		}
+		log.Printf("Service error while deleting workspace page: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete page."})
+		return
+	}
+	h.branding.purge()
+	c.Status(http.StatusNoContent)
+}
+
+// SetWorkspaceLogo takes the image as the raw request body, typed by its
+// Content-Type header.
+func (h *GinHandler) SetWorkspaceLogo(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxLogoSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrInvalidLogo.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.SetWorkspaceLogo(ctx, c.Param("slug"), c.ContentType(), body); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLogo):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		default:
+			log.Printf("Service error while storing workspace logo: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store logo."})
+		}
+		return
+	}
+	h.branding.purge()
+	c.JSON(http.StatusOK, gin.H{"logo": h.domain(c) + "w/" + c.Param("slug") + "/logo"})
+}
+
+func (h *GinHandler) DeleteWorkspaceLogo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteWorkspaceLogo(ctx, c.Param("slug")); err != nil {
+		if errors.Is(err, service.ErrNoLogo) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace has no logo"})
+			return
+		}
+		log.Printf("Service error while deleting workspace logo: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete logo."})
+		return
+	}
+	h.branding.purge()
+	c.Status(http.StatusNoContent)
+}
+
+// WorkspaceLogo serves a workspace's logo to its pages. The CSP keeps an SVG
+// logo opened on its own from running scripts on the short domain.
+func (h *GinHandler) WorkspaceLogo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	logo, err := h.Service.GetWorkspaceLogo(ctx, c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, service.ErrNoLogo) {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		log.Printf("Service error while reading workspace logo: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Last-Modified", logo.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, logo.ContentType, logo.Data)
+}
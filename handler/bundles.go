@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/devices"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) bundleURL(c *gin.Context, code string) string {
+	return h.domain(c) + "b/" + code
+}
+
+func (h *GinHandler) CreateBundle(c *gin.Context) {
+	var req struct {
+		Title       string   `json:"title" binding:"required"`
+		Description string   `json:"description"`
+		Links       []string `json:"links" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"title\": \"...\", \"links\": [\"abc123\", ...]})"})
+		return
+	}
+	for i, code := range req.Links {
+		req.Links[i] = h.trimDomain(c, code)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	bundle, err := h.Service.CreateBundle(ctx, req.Title, req.Description, req.Links, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBundle):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrBundleLinksNotFound):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Service error during bundle creation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bundle."})
+		}
+		return
+	}
+	bundle.Code = h.bundleURL(c, bundle.Code)
+	c.JSON(http.StatusCreated, bundle)
+}
+
+func (h *GinHandler) ListBundles(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	bundles, err := h.Service.ListBundles(ctx)
+	if err != nil {
+		log.Printf("Service error during bundle listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bundles."})
+		return
+	}
+	for i := range bundles {
+		bundles[i].Code = h.bundleURL(c, bundles[i].Code)
+	}
+	c.JSON(http.StatusOK, bundles)
+}
+
+// BundleStats reports a bundle's views and the clicks of each of its links
+// without counting a view.
+func (h *GinHandler) BundleStats(c *gin.Context) {
+	h.writeBundle(c, false, mimeJSON)
+}
+
+func (h *GinHandler) DeleteBundle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteBundle(ctx, c.Param("code"), c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrBundleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+			return
+		}
+		log.Printf("Service error during bundle deletion: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bundle."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ViewBundle is the public page of a bundle, as HTML or JSON. Views from
+// link-preview fetchers are not counted.
+func (h *GinHandler) ViewBundle(c *gin.Context) {
+	format, ok := negotiate(c, gin.MIMEHTML, mimeJSON)
+	if !ok {
+		return
+	}
+	h.writeBundle(c, !devices.IsLinkPreviewer(c.Request.UserAgent()), format)
+}
+
+func (h *GinHandler) writeBundle(c *gin.Context, countView bool, format string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	bundle, err := h.Service.ViewBundle(ctx, c.Param("code"), countView)
+	if err != nil {
+		if errors.Is(err, service.ErrBundleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+			return
+		}
+		log.Printf("Service error while loading bundle: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bundle."})
+		return
+	}
+
+	bundle.Code = h.bundleURL(c, bundle.Code)
+	for i := range bundle.Links {
+		bundle.Links[i].ShortCode = h.domain(c) + bundle.Links[i].ShortCode
+	}
+	if format == mimeJSON {
+		c.JSON(http.StatusOK, bundle)
+		return
+	}
+	h.Pages.Write(c, http.StatusOK, pages.Bundle, bundle)
+}
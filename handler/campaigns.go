@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func campaignID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Campaign ID must be a positive integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *GinHandler) CreateCampaign(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"name\": \"...\", \"description\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	campaign, err := h.Service.CreateCampaign(ctx, req.Name, req.Description)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCampaign):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Campaign name must not be empty"})
+		case errors.Is(err, service.ErrCampaignExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Campaign already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create campaign."})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, campaign)
+}
+
+func (h *GinHandler) ListCampaigns(c *gin.Context) {
+	format, ok := negotiate(c, mimeJSON, mimeCSV)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	campaigns, err := h.Service.ListCampaigns(ctx)
+	if err != nil {
+		log.Printf("Service error during campaign listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve campaigns."})
+		return
+	}
+	if format == mimeCSV {
+		rows := make([][]string, len(campaigns))
+		for i, cp := range campaigns {
+			rows[i] = []string{csvInt(cp.ID), cp.Name, cp.Description, csvInt(int64(cp.LinkCount)), csvTime(cp.CreatedAt)}
+		}
+		writeCSV(c, []string{"id", "name", "description", "link_count", "created_at"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"campaigns": campaigns})
+}
+
+func (h *GinHandler) AttachCampaignLinks(c *gin.Context) {
+	id, ok := campaignID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Codes []string `json:"codes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"codes\": [\"...\"]})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.Service.AttachLinksToCampaign(ctx, id, req.Codes)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCampaign):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provide between 1 and 500 codes"})
+		case errors.Is(err, service.ErrCampaignNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to attach links to campaign."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *GinHandler) CampaignStats(c *gin.Context) {
+	id, ok := campaignID(c)
+	if !ok {
+		return
+	}
+	var q struct {
+		Days int `form:"days,default=30" binding:"min=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetCampaignStats(ctx, id, q.Days)
+	if err != nil {
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+			return
+		}
+		log.Printf("Service error during campaign stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve campaign stats."})
+		return
+	}
+
+	for i := range stats.Links {
+		stats.Links[i].ShortCode = h.domain(c) + stats.Links[i].ShortCode
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// ExportChanges runs a change export now instead of waiting for the job.
+func (h *GinHandler) ExportChanges(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Minute)
+	defer cancel()
+
+	exports, err := h.Service.ExportChanges(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrChangeExportInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": "A change export is already running"})
+		case errors.Is(err, service.ErrStorageNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Object storage is not configured"})
+		default:
+			log.Printf("Service error during change export: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Change export failed."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
+
+func (h *GinHandler) ListChangeExports(c *gin.Context) {
+	var q struct {
+		Stream string `form:"stream"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	exports, err := h.Service.ListChangeExports(ctx, q.Stream)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownChangeStream) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "stream must be links or clicks"})
+			return
+		}
+		log.Printf("Service error during change export listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve change exports."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
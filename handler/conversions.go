@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RecordConversion is the advertiser postback: it ties a conversion to the
+// click ID the redirect appended to the destination.
+func (h *GinHandler) RecordConversion(c *gin.Context) {
+	var req struct {
+		ClickID string   `json:"click_id" binding:"required"`
+		Value   *float64 `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"click_id\": \"...\", \"value\": 19.99})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	conv, created, err := h.Service.RecordConversion(ctx, req.ClickID, req.Value, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrConversionsDisabled):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": "Conversion tracking is not configured"})
+		case errors.Is(err, service.ErrInvalidClickID), errors.Is(err, service.ErrInvalidConversionValue):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrClickIDExpired):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrConversionLinkNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Service error during conversion postback: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record conversion."})
+		}
+		return
+	}
+
+	conv.ShortCode = h.domain(c) + conv.ShortCode
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, conv)
+}
+
+func (h *GinHandler) SetConversionTracking(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"enabled\": true})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.SetConversionTracking(ctx, c.Param("code"), *req.Enabled); err != nil {
+		switch {
+		case errors.Is(err, service.ErrConversionsDisabled):
+			c.JSON(http.StatusConflict, gin.H{"error": "Set CONVERSION_SECRET to enable conversion tracking"})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			log.Printf("Service error while updating conversion tracking: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update conversion tracking."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": *req.Enabled})
+}
+
+func (h *GinHandler) GetConversionTracking(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	enabled, err := h.Service.GetConversionTracking(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversion tracking."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": enabled})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) DeleteURL(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteURL(ctx, c.Param("code"), c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete link."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *GinHandler) ListDeletedURLs(c *gin.Context) {
+	format, ok := negotiate(c, mimeJSON, mimeCSV)
+	if !ok {
+		return
+	}
+
+	var q struct {
+		Days int `form:"days,default=7" binding:"min=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	deleted, err := h.Service.ListDeletedURLs(ctx, q.Days)
+	if err != nil {
+		log.Printf("Service error during deleted link listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deleted links."})
+		return
+	}
+	if format == mimeCSV {
+		rows := make([][]string, len(deleted))
+		for i, d := range deleted {
+			rows[i] = []string{csvInt(d.ID), d.ShortCode, d.LongURL, csvInt(int64(d.ClickCount)), d.DeletedBy, csvTime(d.DeletedAt)}
+		}
+		writeCSV(c, []string{"id", "short_url", "long_url", "click_count", "deleted_by", "deleted_at"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+func (h *GinHandler) RestoreDeletedURLs(c *gin.Context) {
+	var req struct {
+		Days           int      `json:"days" binding:"required"`
+		Codes          []string `json:"codes"`
+		OnCodeConflict string   `json:"on_code_conflict"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"days\": 7, \"codes\": [\"...\"], \"on_code_conflict\": \"skip|new_code\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.Service.RestoreDeletedURLs(ctx, req.Days, req.Codes, req.OnCodeConflict, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRestore) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be positive, at most 500 codes, on_code_conflict must be skip or new_code"})
+			return
+		}
+		log.Printf("Service error during link restore: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore links."})
+		return
+	}
+
+	for i := range result.Restored {
+		result.Restored[i].ShortCode = h.domain(c) + result.Restored[i].ShortCode
+	}
+	c.JSON(http.StatusOK, result)
+}
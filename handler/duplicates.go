@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// DuplicateLinks reports links that point to the same normalized
+// destination. It reads every link, hence the long timeout.
+func (h *GinHandler) DuplicateLinks(c *gin.Context) {
+	q := struct {
+		Limit int `form:"limit" binding:"min=1"`
+	}{Limit: service.DefaultDuplicateReportLimit}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	report, err := h.Service.FindDuplicateLinks(ctx, q.Limit)
+	if err != nil {
+		log.Printf("Service error during duplicate link report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build duplicate report."})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *GinHandler) MergeDuplicateLinks(c *gin.Context) {
+	var req struct {
+		Canonical  string   `json:"canonical" binding:"required"`
+		Duplicates []string `json:"duplicates" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"canonical\": \"...\", \"duplicates\": [\"...\"]})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.Service.MergeDuplicateLinks(ctx, req.Canonical, req.Duplicates)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMerge), errors.Is(err, service.ErrNotDuplicate):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			log.Printf("Service error during duplicate link merge: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge links."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) AuditCodeEntropy(c *gin.Context) {
+	// Reads every short code, so allow for large tables.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	report, err := h.Service.AuditCodeEntropy(ctx)
+	if err != nil {
+		log.Printf("Service error during code entropy audit: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to audit short codes."})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
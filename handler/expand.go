@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// jsonpCallbackPattern admits plain identifiers and dotted paths such as
+// "app.links.expanded", and nothing that could inject script.
+var jsonpCallbackPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+const maxJSONPCallbackLength = 64
+
+// Expand resolves a short code without following the redirect or counting
+// a click, for pages that expand links client-side. code may also be a full
+// short URL. Modern pages call it cross-origin; legacy pages pass callback
+// and load it as a script, in which case the answer is always 200 and
+// failures are reported in the payload's error field.
+func (h *GinHandler) Expand(c *gin.Context) {
+	callback := c.Query("callback")
+	if callback != "" && (len(callback) > maxJSONPCallbackLength || !jsonpCallbackPattern.MatchString(callback)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "callback must be a JavaScript identifier or dotted path"})
+		return
+	}
+	respond := func(status int, payload gin.H) {
+		if callback == "" {
+			c.JSON(status, payload)
+			return
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("ERROR: Failed to encode JSONP payload: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Header("X-Content-Type-Options", "nosniff")
+		// The leading comment keeps the body from starting with
+		// attacker-chosen bytes, which some plugins sniff as content.
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte("/**/"+callback+"("+string(body)+");"))
+	}
+
+	code := h.trimDomain(c, c.Query("code"))
+	if code == "" {
+		respond(http.StatusBadRequest, gin.H{"error": "code query parameter is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	preview, err := h.Service.GetLinkPreview(ctx, code)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respond(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		log.Printf("Service error during expand of %s: %v", code, err)
+		respond(http.StatusInternalServerError, gin.H{"error": "Failed to expand short code."})
+		return
+	}
+	respond(http.StatusOK, gin.H{"short_url": h.domain(c) + preview.ShortCode, "long_url": preview.LongURL})
+}
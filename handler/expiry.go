@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) GetLinkExpiry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	e, err := h.Service.GetLinkExpiry(ctx, c.Param("code"))
+	if err != nil {
+		h.writeExpiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+// SetLinkExpiry takes {"expires_at": "<RFC 3339>"}, or null to make the link
+// permanent.
+func (h *GinHandler) SetLinkExpiry(c *gin.Context) {
+	var req struct {
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"expires_at\": \"2026-12-31T00:00:00Z\"})"})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	e, err := h.Service.SetLinkExpiry(ctx, c.Param("code"), req.ExpiresAt)
+	if err != nil {
+		h.writeExpiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+// ExtendLinkExpiry takes {"by": "720h"}.
+func (h *GinHandler) ExtendLinkExpiry(c *gin.Context) {
+	d, ok := bindExpiryDuration(c, "by")
+	if !ok {
+		return
+	}
+	h.updateExpiry(c, func(ctx context.Context) (*repository.LinkExpiry, error) {
+		return h.Service.ExtendLinkExpiry(ctx, c.Param("code"), d)
+	})
+}
+
+// SnoozeExpiryNotice takes {"for": "24h"}.
+func (h *GinHandler) SnoozeExpiryNotice(c *gin.Context) {
+	d, ok := bindExpiryDuration(c, "for")
+	if !ok {
+		return
+	}
+	h.updateExpiry(c, func(ctx context.Context) (*repository.LinkExpiry, error) {
+		return h.Service.SnoozeExpiryNotice(ctx, c.Param("code"), d)
+	})
+}
+
+func (h *GinHandler) updateExpiry(c *gin.Context, update func(ctx context.Context) (*repository.LinkExpiry, error)) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	e, err := update(ctx)
+	if err != nil {
+		h.writeExpiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func bindExpiryDuration(c *gin.Context, field string) (time.Duration, bool) {
+	var req map[string]string
+	if err := c.ShouldBindJSON(&req); err != nil || req[field] == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"" + field + "\": \"24h\"})"})
+		return 0, false
+	}
+	d, err := time.ParseDuration(req[field])
+	if err != nil || d <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": field + " must be a positive duration such as 24h"})
+		return 0, false
+	}
+	return d, true
+}
+
+func (h *GinHandler) writeExpiryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+	case errors.Is(err, service.ErrNoExpiry):
+		c.JSON(http.StatusConflict, gin.H{"error": "Link does not expire"})
+	default:
+		log.Printf("Service error while handling link expiry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle link expiry."})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) ListClickFlags(c *gin.Context) {
+	var q struct {
+		Days int `form:"days,default=7" binding:"min=1"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	flags, err := h.Service.ListClickFlags(ctx, q.Days)
+	if err != nil {
+		log.Printf("Service error during click flag listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve click flags."})
+		return
+	}
+	for i := range flags {
+		flags[i].ShortCode = h.domain(c) + flags[i].ShortCode
+	}
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+func (h *GinHandler) DismissClickFlag(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DismissClickFlag(ctx, id, c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrFlagNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Click flag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss click flag."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -2,30 +2,66 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
+	"html/template"
 	"net/http"
 	"strings"
 	"time" 
 	"strconv"
 	"log"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/applinks"
+	"github.com/AnshulDekate/urlShortener/devices"
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/AnshulDekate/urlShortener/repository"
 	"github.com/AnshulDekate/urlShortener/service" 
 )
 
 type GinHandler struct {
-	Service *service.Service
+	Service Service
 	Domain  string 
+	// AppLinks, when set, sends mobile visitors of app-owned destinations
+	// to a page that opens the native app instead of a plain redirect.
+	AppLinks *applinks.Matcher
+	// Pages renders every HTML response.
+	Pages *pages.Renderer
+	// InterstitialSeconds is how long the interstitial page waits before
+	// redirecting links that enable it.
+	InterstitialSeconds int
+	// BrandingCacheTTL is how long workspace pages are cached; zero means
+	// DefaultBrandingCacheTTL.
+	BrandingCacheTTL time.Duration
+
+	branding brandingCache
+	// errorBodies caches encoded {"error": ...} bodies by locale and
+	// message key.
+	errorBodies sync.Map
 }
 
-func NewGinHandler(svc *service.Service, domain string) *GinHandler {
+func NewGinHandler(svc Service, domain string) *GinHandler {
 	return &GinHandler{
 		Service: svc,
 		Domain:  domain,
 	}
 }
 
+// domain is the short URL domain for the request: the public base URL a
+// trusted proxy forwarded, or Domain.
+func (h *GinHandler) domain(c *gin.Context) string {
+	return middleware.BaseURL(c, h.Domain)
+}
+
+// trimDomain strips either short URL domain from a submitted short URL,
+// leaving its code.
+func (h *GinHandler) trimDomain(c *gin.Context, shortURL string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(shortURL, h.domain(c)), h.Domain)
+}
+
 func (h *GinHandler) HealthCheck(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 1*time.Second) 
 	defer cancel()
@@ -47,79 +83,314 @@ func (h *GinHandler) HealthCheck(c *gin.Context) {
 	})
 }
 
+// Livez answers as long as the process can serve requests at all. It checks
+// no dependencies, so a database outage does not get every instance
+// restarted.
+func (h *GinHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// Readyz answers 503 while the database is unreachable, so load balancers
+// stop routing to the instance until it recovers.
+func (h *GinHandler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 1*time.Second)
+	defer cancel()
+
+	if err := h.Service.HealthCheck(ctx); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
 func (h *GinHandler) Shorten(c *gin.Context) {
-    
+	format, ok := negotiate(c, mimeJSON, mimeText)
+	if !ok {
+		return
+	}
+
 	var req struct {
-		LongURL string `json:"long_url" binding:"required"`
+		LongURL   string            `json:"long_url" binding:"required"`
+		Workspace string            `json:"workspace"`
+		Alias     string            `json:"alias"`
+		Metadata  map[string]string `json:"metadata"`
+		Website   string            `json:"website"` // honeypot, hidden from humans
 	}
     
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request payload (Expected JSON: {\"long_url\": \"...\"})",
+			"error": middleware.T(c, "error.shorten_payload"),
 		})
 		return
 	}
 
-	shortCode, err := h.Service.CreateShortURL(req.LongURL)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	opts := service.ShortenOptions{
+		Workspace: req.Workspace,
+		Alias:     req.Alias,
+		Honeypot:  req.Website,
+		Metadata:  req.Metadata,
+	}
+	// Only anonymous requests are screened for spam.
+	if c.GetString(middleware.ActorContextKey) == "" {
+		opts.ClientIP = middleware.GetClientIP(c)
+	}
+	res, err := h.Service.Shorten(ctx, req.LongURL, opts)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid URL format") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		if strings.Contains(err.Error(), "service capacity exhausted") {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Short code generation failed. Try again later."})
-			return
+		writeShortenError(c, err)
+		return
+	}
+	h.writeShortened(c, format, res)
+}
+
+// writeShortened answers a successful shorten request. When no link was
+// created because the URL already was one of ours, the status is 200 and
+// the JSON body carries the warning.
+func (h *GinHandler) writeShortened(c *gin.Context, format string, res *service.ShortenResult) {
+	status := http.StatusCreated
+	if !res.Created {
+		status = http.StatusOK
+	}
+	if format == mimeText {
+		c.String(status, h.domain(c)+res.ShortCode+"\n")
+		return
+	}
+	body := gin.H{"short_url": h.domain(c) + res.ShortCode}
+	if res.Warning != nil {
+		body["warning"] = res.Warning
+	}
+	c.JSON(status, body)
+}
+
+// writeShortenError answers a failed link creation, shared by every route
+// that creates links from a URL.
+func writeShortenError(c *gin.Context, err error) {
+	var spamErr *service.SpamError
+	switch {
+	case errors.As(err, &spamErr) && errors.Is(err, service.ErrCreationThrottled):
+		c.Header("Retry-After", strconv.Itoa(int(spamErr.RetryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": middleware.T(c, "error.creation_throttled"), "reason": spamErr.Reason})
+		return
+	case errors.As(err, &spamErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": middleware.T(c, "error.creation_rejected"), "reason": spamErr.Reason})
+		return
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": middleware.T(c, "error.workspace_not_found")})
+		return
+	case errors.Is(err, service.ErrInvalidAlias):
+		c.JSON(http.StatusBadRequest, gin.H{"error": middleware.T(c, "error.invalid_alias")})
+		return
+	case errors.Is(err, service.ErrAliasTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": middleware.T(c, "error.alias_taken")})
+		return
+	case errors.Is(err, service.ErrUnknownShortURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": middleware.T(c, "error.unknown_short_url")})
+		return
+	case errors.Is(err, service.ErrURLAlreadyShortened):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrInvalidMetadata):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.Contains(err.Error(), "invalid URL format") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": middleware.T(c, "error.invalid_url")})
+		return
+	}
+	if strings.Contains(err.Error(), "service capacity exhausted") {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": middleware.T(c, "error.capacity_exhausted")})
+		return
+	}
+	
+	c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.T(c, "error.create_failed")})
+}
+
+// Message keys of the redirect route's errors.
+const (
+	msgCodeNotFound  = "error.code_not_found"
+	msgPendingReview = "error.pending_review"
+	msgLookupFailed  = "error.lookup_failed"
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// writeErrorBody answers with the cached JSON error for key in the request's
+// locale. The redirect route answers far more requests than any other and
+// should not build a gin.H and encode it each time; catalogs never change
+// after startup.
+func (h *GinHandler) writeErrorBody(c *gin.Context, status int, key string) {
+	cacheKey := middleware.Locale(c) + "\x00" + key
+	body, ok := h.errorBodies.Load(cacheKey)
+	if !ok {
+		encoded, _ := json.Marshal(gin.H{"error": middleware.T(c, key)})
+		body, _ = h.errorBodies.LoadOrStore(cacheKey, encoded)
+	}
+	c.Data(status, jsonContentType, body.([]byte))
+}
+
+// writeLinkError answers a failed redirect with page for browsers, which
+// ask for text/html, and with the JSON error for everyone else. The 404 page
+// is the one of the workspace whose code prefix the code carries.
+func (h *GinHandler) writeLinkError(c *gin.Context, status int, page string, key string) {
+	if strings.Contains(c.GetHeader("Accept"), gin.MIMEHTML) {
+		renderer := h.Pages
+		if page == pages.NotFound {
+			renderer = h.notFoundPages(c.Request.Context(), c.Param("code"))
 		}
-		
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: Failed to process URL creation."})
+		h.writePage(c, renderer, status, page, nil)
 		return
 	}
+	h.writeErrorBody(c, status, key)
+}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"short_url": h.Domain + shortCode,
-	})
+// redirectTo answers with a bare Location header. c.Redirect goes through
+// http.Redirect, which re-parses the URL and writes an HTML body.
+func redirectTo(c *gin.Context, code int, location string) {
+	c.Header("Location", location)
+	c.Status(code)
+	c.Writer.WriteHeaderNow()
 }
 
 func (h *GinHandler) Redirect(c *gin.Context) {
 	shortCode := c.Param("code")
 	if shortCode == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Not Found"})
+		h.writeLinkError(c, http.StatusNotFound, pages.NotFound, msgCodeNotFound)
+		return
+	}
+	// bit.ly-style "+" suffix: /abc123+ is the public stats page of abc123.
+	if code, ok := strings.CutSuffix(shortCode, "+"); ok {
+		h.writePublicStats(c, code)
 		return
 	}
 
-	longURL, err := h.Service.GetLongURL(shortCode)
-	
+	dest, err := h.Service.GetDestination(shortCode)
 	if err != nil {
-		if strings.Contains(err.Error(), "short code not found") || errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, service.ErrNotFound) {
+			if rotatedCode, rerr := h.Service.GetRotatedShortCode(c.Request.Context(), shortCode); rerr == nil {
+				redirectTo(c, http.StatusMovedPermanently, h.domain(c)+rotatedCode)
+				return
+			}
+			h.writeLinkError(c, http.StatusNotFound, pages.NotFound, msgCodeNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPendingReview) {
+			h.writeLinkError(c, http.StatusForbidden, pages.PendingReview, msgPendingReview)
+			return
+		}
+		h.writeErrorBody(c, http.StatusInternalServerError, msgLookupFailed)
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	if devices.IsLinkPreviewer(userAgent) {
+		h.serveUnfurl(c, shortCode, dest.WorkspaceID)
+		return
+	}
+
+	now := time.Now()
+	clientIP := middleware.GetClientIP(c)
+	target, variant := service.ChooseDestination(dest, service.RedirectRequest{
+		ShortCode:      shortCode,
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+		ClientIP:       clientIP,
+		UserAgent:      userAgent,
+		Now:            now,
+	})
+	if len(dest.Languages) > 0 {
+		c.Header("Vary", "Accept-Language")
+	}
+	target = h.Service.TagClick(dest, target, now)
+
+	h.Service.TrackEvent(events.Event{
+		Type:          events.TypeClick,
+		ShortCode:     shortCode,
+		IP:            clientIP,
+		UserAgent:     userAgent,
+		Referer:       c.Request.Referer(),
+		Variant:       variant,
+		OccurredAt:    now.UTC(),
+		SampleRate:    dest.SampleRate,
+		CorrelationID: c.GetString(middleware.CorrelationIDContextKey),
+	})
+
+	if dest.Interstitial {
+		h.serveInterstitial(c, shortCode, target, dest.WorkspaceID)
+		return
+	}
+	if h.AppLinks != nil {
+		if launch, ok := h.AppLinks.Launch(target, userAgent); ok {
+			c.Header("Cache-Control", "no-store")
+			// AppHref marks the app URL safe for href; html/template would
+			// otherwise replace its custom scheme with #ZgotmplZ.
+			h.Pages.Write(c, http.StatusOK, pages.AppLink, struct {
+				applinks.Launch
+				AppHref template.URL
+			}{launch, template.URL(launch.AppURL)})
+			return
+		}
+	}
+
+	// Only a mirror's fixed target may be cached by browsers as permanent.
+	if dest.Mirror && target == dest.LongURL && variant == "" {
+		redirectTo(c, http.StatusMovedPermanently, target)
+		return
+	}
+	redirectTo(c, http.StatusFound, target)
+}
+
+func (h *GinHandler) Rotate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.Service.RotateShortURL(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
 			return
 		}
-		
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error during lookup"})
+		if strings.Contains(err.Error(), "service capacity exhausted") {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Short code generation failed. Try again later."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: Failed to rotate short code."})
 		return
 	}
 
-	c.Redirect(http.StatusFound, longURL) // 302 Found
+	result.ShortCode = h.domain(c) + result.ShortCode
+	result.RetiredCode = h.domain(c) + result.RetiredCode
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *GinHandler) ListURLs(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	format, ok := negotiate(c, mimeJSON, mimeCSV)
+	if !ok {
+		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 10
+	var q struct {
+		Page        int    `form:"page,default=1" binding:"min=1"`
+		Limit       int    `form:"limit,default=10" binding:"min=1"`
+		UTMSource   string `form:"utm_source"`
+		UTMMedium   string `form:"utm_medium"`
+		UTMCampaign string `form:"utm_campaign"`
+	}
+	if !bindQuery(c, &q) {
+		return
 	}
     
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second) 
 	defer cancel()
 
-	listResponse, err := h.Service.ListURLs(ctx, page, limit)
+	filter := repository.URLFilter{
+		UTMSource:   q.UTMSource,
+		UTMMedium:   q.UTMMedium,
+		UTMCampaign: q.UTMCampaign,
+		Metadata:    metadataFilter(c),
+	}
+
+	listResponse, err := h.Service.ListURLs(ctx, filter, q.Page, q.Limit)
 	if err != nil {
 		log.Printf("Service error during URL listing: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve URL list."})
@@ -127,7 +398,12 @@ func (h *GinHandler) ListURLs(c *gin.Context) {
 	}
 	
 	for i:=0; i<len(listResponse.URLs); i++ {
-		listResponse.URLs[i].ShortCode = h.Domain + listResponse.URLs[i].ShortCode 
+		listResponse.URLs[i].ShortCode = h.domain(c) + listResponse.URLs[i].ShortCode 
+	}
+	if format == mimeCSV {
+		c.Header("X-Total-Count", strconv.Itoa(listResponse.TotalCount))
+		writeCSV(c, urlCSVHeader, urlCSVRows(listResponse.URLs))
+		return
 	}
 	c.JSON(http.StatusOK, listResponse)
 }
@@ -0,0 +1,169 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/handler"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/AnshulDekate/urlShortener/servicetest"
+)
+
+const testDomain = "http://localhost/"
+
+func newRouter(fake *servicetest.Fake) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewGinHandler(fake, testDomain)
+	r := gin.New()
+	r.POST("/shorten", h.Shorten)
+	r.GET("/urls", h.ListURLs)
+	r.GET("/:code", h.Redirect)
+	return r
+}
+
+func serve(r *gin.Engine, method string, target string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestShorten(t *testing.T) {
+	fake := servicetest.NewFake()
+	fake.AddLink("taken", "https://example.com/taken")
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantURL    string
+	}{
+		{name: "creates link", body: `{"long_url": "https://example.com/a"}`, wantStatus: http.StatusCreated, wantURL: testDomain + "2"},
+		{name: "creates alias", body: `{"long_url": "https://example.com/b", "alias": "mine"}`, wantStatus: http.StatusCreated, wantURL: testDomain + "mine"},
+		{name: "alias taken", body: `{"long_url": "https://example.com/c", "alias": "taken"}`, wantStatus: http.StatusConflict},
+		{name: "missing long_url", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "service failure", body: `{"long_url": "https://example.com/d"}`, err: errors.New("database is down"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.Err = tt.err
+			w := serve(newRouter(fake), http.MethodPost, "/shorten", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantURL == "" {
+				return
+			}
+			var resp struct {
+				ShortURL string `json:"short_url"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.ShortURL != tt.wantURL {
+				t.Errorf("short_url = %q, want %q", resp.ShortURL, tt.wantURL)
+			}
+			code := strings.TrimPrefix(tt.wantURL, testDomain)
+			if _, ok := fake.Link(code); !ok {
+				t.Errorf("link %s was not stored", code)
+			}
+		})
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	fake := servicetest.NewFake()
+	fake.AddLink("abc123", "https://example.com/landing")
+	r := newRouter(fake)
+
+	w := serve(r, http.MethodGet, "/abc123", "")
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusFound)
+	}
+	if got := w.Header().Get("Location"); got != "https://example.com/landing" {
+		t.Errorf("Location = %q, want the destination", got)
+	}
+	if link, _ := fake.Link("abc123"); link.ClickCount != 1 {
+		t.Errorf("click count = %d, want 1", link.ClickCount)
+	}
+	tracked := fake.Events()
+	if len(tracked) != 1 || tracked[0].Type != events.TypeClick || tracked[0].ShortCode != "abc123" {
+		t.Errorf("tracked events = %+v, want one click on abc123", tracked)
+	}
+}
+
+func TestRedirectErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "unknown code", wantStatus: http.StatusNotFound},
+		{name: "pending review", err: service.ErrPendingReview, wantStatus: http.StatusForbidden},
+		{name: "service failure", err: errors.New("database is down"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := servicetest.NewFake()
+			fake.Err = tt.err
+			w := serve(newRouter(fake), http.MethodGet, "/missing", "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Header().Get("Location") != "" {
+				t.Errorf("unexpected redirect to %q", w.Header().Get("Location"))
+			}
+			if len(fake.Events()) != 0 {
+				t.Errorf("tracked %d events for a failed redirect", len(fake.Events()))
+			}
+		})
+	}
+}
+
+func TestListURLs(t *testing.T) {
+	fake := servicetest.NewFake()
+	fake.AddLink("first", "https://example.com/1")
+	fake.AddLink("second", "https://example.com/2")
+	fake.AddLink("third", "https://example.com/3")
+	r := newRouter(fake)
+
+	w := serve(r, http.MethodGet, "/urls?page=1&limit=2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp service.URLListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.TotalCount != 3 || resp.TotalPages != 2 || resp.Page != 1 {
+		t.Errorf("total_count, total_pages, page = %d, %d, %d, want 3, 2, 1", resp.TotalCount, resp.TotalPages, resp.Page)
+	}
+	var got []string
+	for _, u := range resp.URLs {
+		got = append(got, u.ShortCode)
+	}
+	want := []string{testDomain + "third", testDomain + "second"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("short URLs = %v, want newest first %v", got, want)
+	}
+
+	if w := serve(r, http.MethodGet, "/urls?limit=0", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("limit=0: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	fake.Err = errors.New("database is down")
+	if w := serve(r, http.MethodGet, "/urls", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("service failure: status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) ListDeadLinks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	links, err := h.Service.ListDeadLinks(ctx)
+	if err != nil {
+		log.Printf("Service error during dead link listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead links."})
+		return
+	}
+	for i := range links {
+		links[i].ShortCode = h.domain(c) + links[i].ShortCode
+	}
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/importer"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportLinks takes a bit.ly or TinyURL CSV export as the raw request body.
+func (h *GinHandler) ImportLinks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	result, err := h.Service.ImportLinks(ctx, c.Query("format"), body, c.Query("workspace"), c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Export file exceeds 10 MiB"})
+		case errors.Is(err, importer.ErrUnknownFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter format must be bitly or tinyurl"})
+		case errors.Is(err, importer.ErrMissingColumn), errors.Is(err, importer.ErrTooManyRows):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		default:
+			log.Printf("Service error during link import: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import links."})
+		}
+		return
+	}
+	for i := range result.Imported {
+		result.Imported[i].ShortCode = h.domain(c) + result.Imported[i].ShortCode
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) serveInterstitial(c *gin.Context, shortCode string, target string, workspaceID int64) {
+	c.Header("Cache-Control", "no-store")
+	h.writePage(c, h.pagesFor(c.Request.Context(), workspaceID), http.StatusOK, pages.Interstitial, struct {
+		ShortURL    string
+		Destination string
+		Seconds     int
+	}{h.domain(c) + shortCode, target, h.InterstitialSeconds})
+}
+
+func (h *GinHandler) SetLinkInterstitial(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"enabled\": true})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.SetLinkInterstitial(ctx, c.Param("code"), *req.Enabled); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		log.Printf("Service error while updating link interstitial: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update interstitial."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": *req.Enabled})
+}
+
+func (h *GinHandler) SetWorkspaceInterstitial(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"enabled\": true})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	workspace, err := h.Service.SetWorkspaceInterstitial(ctx, c.Param("slug"), *req.Enabled)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			return
+		}
+		log.Printf("Service error while updating workspace interstitial: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update interstitial."})
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// CreateLinkSet answers with the new links as a CSV download, or as JSON
+// for clients that ask for it.
+func (h *GinHandler) CreateLinkSet(c *gin.Context) {
+	format, ok := negotiate(c, mimeCSV, mimeJSON)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name       string     `json:"name" binding:"required"`
+		URL        string     `json:"url" binding:"required"`
+		Count      int        `json:"count"`
+		Recipients []string   `json:"recipients"`
+		Tags       []string   `json:"tags"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+		CampaignID *int64     `json:"campaign_id"`
+		Workspace  string     `json:"workspace"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"name\": \"...\", \"url\": \"https://example.com/claim/{code}\", \"count\": 100}, or \"recipients\": [\"...\"] in place of count)"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	set, err := h.Service.CreateLinkSet(ctx, service.LinkSetRequest{
+		Name:       req.Name,
+		URL:        req.URL,
+		Count:      req.Count,
+		Recipients: req.Recipients,
+		Tags:       req.Tags,
+		ExpiresAt:  req.ExpiresAt,
+		CampaignID: req.CampaignID,
+		Workspace:  req.Workspace,
+	}, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLinkSet):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrCampaignNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		default:
+			log.Printf("Service error during link set creation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create link set."})
+		}
+		return
+	}
+	h.writeLinkSet(c, http.StatusCreated, format, set)
+}
+
+func linkSetID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Link set ID must be a positive integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *GinHandler) GetLinkSet(c *gin.Context) {
+	format, ok := negotiate(c, mimeJSON, mimeCSV)
+	if !ok {
+		return
+	}
+	id, ok := linkSetID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	set, err := h.Service.GetLinkSet(ctx, id)
+	if err != nil {
+		if errors.Is(err, service.ErrLinkSetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link set not found"})
+			return
+		}
+		log.Printf("Service error during link set lookup: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve link set."})
+		return
+	}
+	h.writeLinkSet(c, http.StatusOK, format, set)
+}
+
+// LinkSetRecipientStats serves the stats of the link a set issued to the
+// recipient query parameter.
+func (h *GinHandler) LinkSetRecipientStats(c *gin.Context) {
+	id, ok := linkSetID(c)
+	if !ok {
+		return
+	}
+	var q struct {
+		Recipient      string `form:"recipient" binding:"required"`
+		ExcludeFlagged bool   `form:"exclude_flagged"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.LinkSetRecipientStats(ctx, id, q.Recipient, q.ExcludeFlagged)
+	if err != nil {
+		if errors.Is(err, service.ErrRecipientNotFound) || errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No link for this recipient in the link set"})
+			return
+		}
+		log.Printf("Service error during recipient stats lookup: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve link stats."})
+		return
+	}
+	stats.ShortCode = h.domain(c) + stats.ShortCode
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *GinHandler) writeLinkSet(c *gin.Context, status int, format string, set *service.LinkSetDetail) {
+	for i := range set.Links {
+		set.Links[i].ShortCode = h.domain(c) + set.Links[i].ShortCode
+	}
+	if format == mimeJSON {
+		c.JSON(status, set)
+		return
+	}
+	rows := make([][]string, len(set.Links))
+	for i, l := range set.Links {
+		rows[i] = []string{l.Recipient, l.ShortCode, l.LongURL, csvInt(int64(l.ClickCount))}
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="link-set-%d.csv"`, set.ID))
+	writeCSVStatus(c, status, []string{"recipient", "short_url", "long_url", "click_count"}, rows)
+}
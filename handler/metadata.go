@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+// metadataQueryPrefix marks listing filters on link metadata, as in
+// ?metadata.order_id=1234.
+const metadataQueryPrefix = "metadata."
+
+// metadataFilter collects the metadata.<key>=<value> pairs of the query.
+func metadataFilter(c *gin.Context) map[string]string {
+	var filter map[string]string
+	for name, values := range c.Request.URL.Query() {
+		key, ok := strings.CutPrefix(name, metadataQueryPrefix)
+		if !ok || key == "" || len(values) == 0 {
+			continue
+		}
+		if filter == nil {
+			filter = make(map[string]string)
+		}
+		filter[key] = values[0]
+	}
+	return filter
+}
+
+// PatchLink updates a link's metadata as a JSON merge patch: keys given a
+// string are set, keys given null are removed and the rest are kept.
+func (h *GinHandler) PatchLink(c *gin.Context) {
+	var req struct {
+		Metadata map[string]*string `json:"metadata" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"metadata\": {\"order_id\": \"1234\", \"old_key\": null}})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	metadata, err := h.Service.PatchLinkMetadata(ctx, c.Param("code"), req.Metadata, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		case errors.Is(err, service.ErrInvalidMetadata):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Service error while patching link metadata: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update metadata."})
+		}
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"short_url": h.domain(c) + c.Param("code"), "metadata": metadata})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/importer"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportBodyBytes = 10 << 20
+
+func (h *GinHandler) CreateMirror(c *gin.Context) {
+	var req struct {
+		Source    string `json:"source" binding:"required"`
+		Workspace string `json:"workspace"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"source\": \"https://bit.ly/...\", \"workspace\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	link, err := h.Service.CreateMirror(ctx, req.Source, req.Workspace)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMirror):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Source must be an absolute http(s) short URL"})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		case errors.Is(err, service.ErrURLAlreadyShortened):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Service error during mirror creation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mirror."})
+		}
+		return
+	}
+	link.ShortCode = h.domain(c) + link.ShortCode
+	c.JSON(http.StatusCreated, link)
+}
+
+// ImportBitlyMirrors takes a bit.ly CSV export as the raw request body.
+func (h *GinHandler) ImportBitlyMirrors(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	result, err := h.Service.ImportBitlyMirrors(ctx, body, c.Query("workspace"))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Export file exceeds 10 MiB"})
+		case errors.Is(err, importer.ErrMissingColumn), errors.Is(err, importer.ErrTooManyRows):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		default:
+			log.Printf("Service error during bit.ly mirror import: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import mirrors."})
+		}
+		return
+	}
+	for i := range result.Mirrored {
+		result.Mirrored[i].ShortCode = h.domain(c) + result.Mirrored[i].ShortCode
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+const (
+	mimeJSON = gin.MIMEJSON
+	mimeText = gin.MIMEPlain
+	mimeCSV  = "text/csv"
+)
+
+// negotiate picks the response type for the Accept header from offered,
+// whose first entry is the default for clients that send no Accept header
+// or accept anything. It answers 406 and returns false when none of
+// offered is acceptable.
+func negotiate(c *gin.Context, offered ...string) (string, bool) {
+	if c.GetHeader("Accept") == "" {
+		return offered[0], true
+	}
+	format := c.NegotiateFormat(offered...)
+	if format == "" {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "Not acceptable", "available": offered})
+		return "", false
+	}
+	return format, true
+}
+
+// writeCSV answers with a header row followed by rows.
+func writeCSV(c *gin.Context, header []string, rows [][]string) {
+	writeCSVStatus(c, http.StatusOK, header, rows)
+}
+
+func writeCSVStatus(c *gin.Context, status int, header []string, rows [][]string) {
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Status(status)
+	w := csv.NewWriter(c.Writer)
+	w.Write(header)
+	w.WriteAll(rows)
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// csvMetadata writes link metadata as a JSON object, or nothing.
+func csvMetadata(metadata map[string]string) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+	raw, _ := json.Marshal(metadata)
+	return string(raw)
+}
+
+var urlCSVHeader = []string{
+	"short_url", "long_url", "click_count", "created_at", "last_accessed_at", "owner", "workspace",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content", "metadata",
+}
+
+func urlCSVRows(urls []repository.URL) [][]string {
+	rows := make([][]string, len(urls))
+	for i, u := range urls {
+		row := []string{u.ShortCode, u.LongURL, csvInt(int64(u.ClickCount)), csvTime(u.CreatedAt), csvTime(u.LastAccessedAt), u.Owner, u.Workspace}
+		if u.UTM != nil {
+			row = append(row, u.UTM.Source, u.UTM.Medium, u.UTM.Campaign, u.UTM.Term, u.UTM.Content)
+		} else {
+			row = append(row, "", "", "", "", "")
+		}
+		row = append(row, csvMetadata(u.Metadata))
+		rows[i] = row
+	}
+	return rows
+}
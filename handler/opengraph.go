@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+const openGraphRules = "set at least one of title (up to 200 characters), description (up to 500) and image_url (an absolute http(s) URL)"
+
+func (h *GinHandler) SetOpenGraph(c *gin.Context) {
+	var req struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		ImageURL    string `json:"image_url"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"title\": \"...\", \"description\": \"...\", \"image_url\": \"https://...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	og, err := h.Service.SetOpenGraph(ctx, c.Param("code"), repository.OpenGraph{Title: req.Title, Description: req.Description, ImageURL: req.ImageURL})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidOpenGraph):
+			c.JSON(http.StatusBadRequest, gin.H{"error": openGraphRules})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set Open Graph tags."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, og)
+}
+
+func (h *GinHandler) GetOpenGraph(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	og, err := h.Service.GetOpenGraph(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrOpenGraphNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No Open Graph tags set for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Open Graph tags."})
+		return
+	}
+	c.JSON(http.StatusOK, og)
+}
+
+func (h *GinHandler) DeleteOpenGraph(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteOpenGraph(ctx, c.Param("code")); err != nil {
+		if errors.Is(err, service.ErrOpenGraphNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No Open Graph tags set for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete Open Graph tags."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// pollParams reads the since cursor and limit shared by the polling
+// endpoints. A limit of 0 takes the service's default.
+func pollParams(c *gin.Context) (int64, int, bool) {
+	var q struct {
+		Since int64 `form:"since" binding:"min=0"`
+		Limit int   `form:"limit" binding:"min=0"`
+	}
+	if !bindQuery(c, &q) {
+		return 0, 0, false
+	}
+	return q.Since, q.Limit, true
+}
+
+func pollError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidPoll) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must not be negative and limit must be between 1 and 100"})
+		return
+	}
+	log.Printf("Service error during polling: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to poll."})
+}
+
+// PollLinks answers with a bare JSON array, newest first, as polling
+// triggers in Zapier and similar tools expect.
+func (h *GinHandler) PollLinks(c *gin.Context) {
+	since, limit, ok := pollParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	links, err := h.Service.PollLinks(ctx, since, limit)
+	if err != nil {
+		pollError(c, err)
+		return
+	}
+	for i := range links {
+		links[i].ShortCode = h.domain(c) + links[i].ShortCode
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+func (h *GinHandler) PollClicks(c *gin.Context) {
+	since, limit, ok := pollParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	clicks, err := h.Service.PollClicks(ctx, since, limit)
+	if err != nil {
+		pollError(c, err)
+		return
+	}
+	for i := range clicks {
+		clicks[i].ShortCode = h.domain(c) + clicks[i].ShortCode
+	}
+	c.JSON(http.StatusOK, clicks)
+}
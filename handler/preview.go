@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) LinkPreview(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	preview, err := h.Service.GetLinkPreview(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve link preview."})
+		return
+	}
+
+	if preview.ScreenshotKey != "" {
+		preview.ScreenshotURL = h.domain(c) + "urls/" + preview.ShortCode + "/screenshot"
+	}
+	preview.ShortCode = h.domain(c) + preview.ShortCode
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *GinHandler) LinkScreenshot(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	body, contentType, err := h.Service.OpenScreenshot(ctx, c.Param("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		case errors.Is(err, service.ErrNoScreenshot):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No screenshot captured for this link"})
+		default:
+			log.Printf("Service error while opening screenshot: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve screenshot."})
+		}
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, -1, contentType, body, map[string]string{"Cache-Control": "public, max-age=86400"})
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+type publicStatsDay struct {
+	Day     string
+	Clicks  int
+	Percent int
+}
+
+type publicStatsView struct {
+	*repository.PublicStats
+	Created string
+	Days    []publicStatsDay
+}
+
+// PublicStats serves GET /:code/stats for links whose stats were made
+// public, as a page or as JSON.
+func (h *GinHandler) PublicStats(c *gin.Context) {
+	h.writePublicStats(c, c.Param("code"))
+}
+
+func (h *GinHandler) writePublicStats(c *gin.Context, shortCode string) {
+	format, ok := negotiate(c, gin.MIMEHTML, mimeJSON)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetPublicStats(ctx, shortCode)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No public stats for this short code"})
+			return
+		}
+		log.Printf("Service error during public stats lookup: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats."})
+		return
+	}
+	stats.ShortCode = h.domain(c) + stats.ShortCode
+	c.Header("Cache-Control", "public, max-age=60")
+	if format == mimeJSON {
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
+	view := publicStatsView{PublicStats: stats, Created: stats.CreatedAt.Format("2 January 2006")}
+	peak := 0
+	for _, d := range stats.Daily {
+		peak = max(peak, d.Clicks)
+	}
+	for _, d := range stats.Daily {
+		day := publicStatsDay{Day: d.Bucket.Format("Jan 2"), Clicks: d.Clicks}
+		if peak > 0 {
+			day.Percent = d.Clicks * 100 / peak
+		}
+		view.Days = append(view.Days, day)
+	}
+	h.Pages.Write(c, http.StatusOK, pages.PublicStats, view)
+}
+
+func (h *GinHandler) SetPublicStats(c *gin.Context) {
+	var req struct {
+		Public *bool `json:"public" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"public\": true})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.SetPublicStats(ctx, c.Param("code"), *req.Public, c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		log.Printf("Service error while updating public stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update public stats."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"public": *req.Public})
+}
+
+func (h *GinHandler) GetPublicStatsSetting(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	public, err := h.Service.IsPublicStats(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch public stats setting."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"public": public})
+}
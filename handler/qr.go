@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// LinkQRCode serves GET /:code/qr, the short URL as a PNG QR code in the
+// style of the link's workspace. The fg, bg, size and logo query parameters
+// override it for one image.
+func (h *GinHandler) LinkQRCode(c *gin.Context) {
+	var q struct {
+		Foreground string `form:"fg"`
+		Background string `form:"bg"`
+		Size       *int   `form:"size"`
+		Logo       *bool  `form:"logo"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	shortCode := c.Param("code")
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	workspaceID, err := h.Service.LinkWorkspaceID(shortCode)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		log.Printf("Service error during QR code lookup: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code."})
+		return
+	}
+	style, err := h.Service.QRStyleFor(ctx, workspaceID)
+	if err != nil {
+		log.Printf("Service error while reading QR style: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code."})
+		return
+	}
+
+	if q.Foreground != "" {
+		style.Foreground = q.Foreground
+	}
+	if q.Background != "" {
+		style.Background = q.Background
+	}
+	if q.Size != nil {
+		style.Size = *q.Size
+	}
+	if q.Logo != nil {
+		style.Logo = *q.Logo
+	}
+	if err := service.ValidateQRStyle(&style); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	image, err := h.Service.RenderQRCode(ctx, h.domain(c)+shortCode, workspaceID, style)
+	if err != nil {
+		log.Printf("Service error while rendering QR code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code."})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+func (h *GinHandler) GetWorkspaceQRStyle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	style, err := h.Service.GetWorkspaceQRStyle(ctx, c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			return
+		}
+		log.Printf("Service error while reading workspace QR style: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read QR style."})
+		return
+	}
+	c.JSON(http.StatusOK, style)
+}
+
+// SetWorkspaceQRStyle replaces a workspace's QR defaults. Omitted fields
+// take the values of service.DefaultQRStyle.
+func (h *GinHandler) SetWorkspaceQRStyle(c *gin.Context) {
+	style := service.DefaultQRStyle
+	if err := c.ShouldBindJSON(&style); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"foreground\": \"#1a237e\", \"background\": \"#ffffff\", \"size\": 1024, \"logo\": true})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stored, err := h.Service.SetWorkspaceQRStyle(ctx, c.Param("slug"), style)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidQRStyle):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		default:
+			log.Printf("Service error while storing workspace QR style: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store QR style."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, stored)
+}
+
+func (h *GinHandler) DeleteWorkspaceQRStyle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteWorkspaceQRStyle(ctx, c.Param("slug")); err != nil {
+		if errors.Is(err, service.ErrNoQRStyle) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace has no QR defaults"})
+			return
+		}
+		log.Printf("Service error while deleting workspace QR style: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// paramError is one invalid query parameter of a 400 answer.
+type paramError struct {
+	Param string `json:"param"`
+	Error string `json:"error"`
+}
+
+// bindQuery fills the fields of the struct dst points to from the query
+// string, as gin's form binding does: a field named by its form tag takes
+// the parameter's value, or the tag's default= option when the parameter is
+// absent. Fields without either keep the value they had, and pointer fields
+// stay nil. The binding tags are checked afterwards. Unlike gin, every
+// invalid parameter is reported, in one 400 answer, and bindQuery returns
+// false.
+func bindQuery(c *gin.Context, dst any) bool {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	invalid := map[int]string{}
+	params := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		name, def, ok := formTag(t.Field(i))
+		if !ok {
+			continue
+		}
+		params[t.Field(i).Name] = i
+		value, present := c.GetQuery(name)
+		if !present {
+			if def == "" {
+				continue
+			}
+			value = def
+		}
+		if msg := setParam(v.Field(i), value); msg != "" {
+			invalid[i] = msg
+		}
+	}
+
+	var verrs validator.ValidationErrors
+	if err := binding.Validator.ValidateStruct(dst); errors.As(err, &verrs) {
+		for _, fe := range verrs {
+			i, ok := params[fe.StructField()]
+			if _, parsed := invalid[i]; ok && !parsed {
+				invalid[i] = validationMessage(fe)
+			}
+		}
+	} else if err != nil {
+		panic(fmt.Sprintf("bindQuery: %v", err))
+	}
+	if len(invalid) == 0 {
+		return true
+	}
+
+	fields := make([]int, 0, len(invalid))
+	for i := range invalid {
+		fields = append(fields, i)
+	}
+	sort.Ints(fields)
+	errs := make([]paramError, 0, len(fields))
+	for _, i := range fields {
+		name, _, _ := formTag(t.Field(i))
+		errs = append(errs, paramError{Param: name, Error: invalid[i]})
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "params": errs})
+	return false
+}
+
+// formTag splits a form tag into the parameter name and its default.
+func formTag(f reflect.StructField) (name string, def string, ok bool) {
+	tag := f.Tag.Get("form")
+	if tag == "" || tag == "-" {
+		return "", "", false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if value, found := strings.CutPrefix(opt, "default="); found {
+			def = value
+		}
+	}
+	return name, def, true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// setParam parses value into field, returning what is wrong with it if it
+// does not parse. Times are RFC 3339 timestamps or dates, taken as UTC.
+func setParam(field reflect.Value, value string) string {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	if field.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, value); err != nil {
+				return "must be an RFC 3339 time or a date"
+			}
+		}
+		field.Set(reflect.ValueOf(t))
+		return ""
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "must be true or false"
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return "must be an integer"
+		}
+		field.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return "must be a number"
+		}
+		field.SetFloat(f)
+	default:
+		panic("bindQuery: unsupported field type " + field.Type().String())
+	}
+	return ""
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	}
+	return "is invalid"
+}
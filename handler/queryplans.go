@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListQueryPlans answers the newest sampled query plans. query narrows them
+// to statements containing it, such as a table name.
+func (h *GinHandler) ListQueryPlans(c *gin.Context) {
+	var q struct {
+		Limit int    `form:"limit,default=50" binding:"min=1"`
+		Query string `form:"query"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	plans, err := h.Service.ListQueryPlans(ctx, q.Query, q.Limit)
+	if err != nil {
+		log.Printf("Service error during query plan listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list query plans."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"plans": plans})
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// ShortenGET creates a link from query parameters for scripts and tools that
+// cannot easily POST JSON. Callers are authenticated, so spam screening is
+// skipped as for other admin-created links. format=text, or an Accept
+// header preferring text/plain, answers with the bare short URL; a URL
+// shortened before returns its existing code.
+func (h *GinHandler) ShortenGET(c *gin.Context) {
+	longURL := c.Query("url")
+	if longURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url query parameter is required"})
+		return
+	}
+	var format string
+	switch c.Query("format") {
+	case "json":
+		format = mimeJSON
+	case "text":
+		format = mimeText
+	case "":
+		var ok bool
+		if format, ok = negotiate(c, mimeJSON, mimeText); !ok {
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or text"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.Service.Shorten(ctx, longURL, service.ShortenOptions{
+		Workspace: c.Query("workspace"),
+		Alias:     c.Query("alias"),
+	})
+	if err != nil {
+		writeShortenError(c, err)
+		return
+	}
+
+	// GET responses may be cached by proxies and browsers; a new link must
+	// not be.
+	c.Header("Cache-Control", "no-store")
+	h.writeShortened(c, format, res)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// RecountClicks reports links whose click_count disagrees with their click
+// events, and with "repair": true raises the counts that fell short. An
+// empty "codes" recounts every link.
+func (h *GinHandler) RecountClicks(c *gin.Context) {
+	var req struct {
+		Codes  []string `json:"codes"`
+		Limit  int      `json:"limit"`
+		Repair bool     `json:"repair"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"codes\": [\"...\"], \"limit\": 100, \"repair\": false})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.Service.RecountClicks(ctx, req.Codes, req.Limit, req.Repair, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRecount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Service error during click recount: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recount clicks."})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// ClickDrift reports links whose click count disagrees with the counters
+// each region keeps, as happens when replicated updates overwrite each other.
+func (h *GinHandler) ClickDrift(c *gin.Context) {
+	var q struct {
+		Limit int `form:"limit"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	drift, err := h.Service.ClickDrift(ctx, q.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDriftLimit) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		log.Printf("Service error during click drift report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build click drift report."})
+		return
+	}
+	for i := range drift {
+		drift[i].ShortCode = h.domain(c) + drift[i].ShortCode
+	}
+	c.JSON(http.StatusOK, gin.H{"links": drift})
+}
+
+func (h *GinHandler) ReconcileClickCounts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	n, err := h.Service.ReconcileClickCounts(ctx, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		log.Printf("Service error during click count reconciliation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile click counts."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reconciled": n})
+}
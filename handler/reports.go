@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) CreateReportSchedule(c *gin.Context) {
+	var req struct {
+		Name      string `json:"name" binding:"required"`
+		Frequency string `json:"frequency" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"name\": \"...\", \"frequency\": \"daily|weekly\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	schedule, err := h.Service.CreateReportSchedule(ctx, req.Name, req.Frequency)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReportSchedule):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be 1-64 letters, digits, '-' or '_' and frequency must be daily or weekly"})
+		case errors.Is(err, service.ErrReportScheduleExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Report schedule already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report schedule."})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *GinHandler) ListReportSchedules(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	schedules, err := h.Service.ListReportSchedules(ctx)
+	if err != nil {
+		log.Printf("Service error during report schedule listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report schedules."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+func (h *GinHandler) DeleteReportSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Schedule ID must be an integer"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteReportSchedule(ctx, id); err != nil {
+		if errors.Is(err, service.ErrReportScheduleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report schedule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete report schedule."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
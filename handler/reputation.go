@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) ListDomainReputations(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	domains, err := h.Service.ListDomainReputations(ctx)
+	if err != nil {
+		log.Printf("Service error during domain reputation listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domain reputations."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"domains": domains})
+}
+
+func (h *GinHandler) GetDomainReputation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	reputation, err := h.Service.GetDomainReputation(ctx, c.Param("domain"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domain reputation."})
+		return
+	}
+	c.JSON(http.StatusOK, reputation)
+}
+
+func (h *GinHandler) OverrideDomainScore(c *gin.Context) {
+	var req struct {
+		Score *int   `json:"score" binding:"required"`
+		Note  string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"score\": 0-100, \"note\": \"...\"})"})
+		return
+	}
+	h.overrideDomainScore(c, req.Score, req.Note)
+}
+
+func (h *GinHandler) ClearDomainScore(c *gin.Context) {
+	h.overrideDomainScore(c, nil, "")
+}
+
+func (h *GinHandler) overrideDomainScore(c *gin.Context, score *int, note string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.Service.OverrideDomainScore(ctx, c.Param("domain"), score, note, c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReputation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Score must be between 0 and 100"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to override domain score."})
+		return
+	}
+	h.GetDomainReputation(c)
+}
+
+func (h *GinHandler) RecordDomainSignal(c *gin.Context) {
+	var req struct {
+		Signal string `json:"signal" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"signal\": \"report|blocklist_hit|health_failure\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.RecordDomainSignal(ctx, c.Param("domain"), req.Signal); err != nil {
+		if errors.Is(err, service.ErrInvalidDomainSignal) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "signal must be report, blocklist_hit or health_failure"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record domain signal."})
+		return
+	}
+	h.GetDomainReputation(c)
+}
+
+func (h *GinHandler) ListPendingLinks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	links, err := h.Service.ListPendingLinks(ctx)
+	if err != nil {
+		log.Printf("Service error during pending link listing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve links pending review."})
+		return
+	}
+	for i := range links {
+		links[i].ShortCode = h.domain(c) + links[i].ShortCode
+	}
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+func (h *GinHandler) ApproveLink(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.ApproveLink(ctx, c.Param("code"), c.GetString(middleware.ActorContextKey)); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve link."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) SetLinkLanguages(c *gin.Context) {
+	var req struct {
+		Destinations map[string]string `json:"destinations"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"destinations\": {\"de\": \"https://...\"}})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	destinations, err := h.Service.SetLinkLanguages(ctx, c.Param("code"), req.Destinations)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLanguages):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Keys must be language tags like \"de\" or \"pt-br\" and values absolute http(s) URLs (max 50)."})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update language destinations."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"destinations": destinations})
+}
+
+func (h *GinHandler) GetLinkLanguages(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	destinations, err := h.Service.GetLinkLanguages(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch language destinations."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"destinations": destinations})
+}
+
+func (h *GinHandler) SetScheduleRules(c *gin.Context) {
+	var req struct {
+		Rules []service.ScheduleRuleSpec `json:"rules"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"rules\": [{\"days\": [\"mon\"], \"start\": \"09:00\", \"end\": \"17:00\", \"timezone\": \"Europe/Berlin\", \"destination\": \"https://...\"}]})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	rules, err := h.Service.SetScheduleRules(ctx, c.Param("code"), req.Rules)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSchedule):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Rules need HH:MM start and end, an IANA timezone, weekday names like \"mon\" and an absolute http(s) destination (max 20)."})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule rules."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+func (h *GinHandler) GetScheduleRules(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	rules, err := h.Service.GetScheduleRules(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedule rules."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GinHandler) SetSampleRate(c *gin.Context) {
+	var req struct {
+		Rate float64 `json:"rate" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"rate\": 0.1})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.SetSampleRate(ctx, c.Param("code"), req.Rate); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSampleRate):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sample rate."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rate": req.Rate})
+}
+
+func (h *GinHandler) GetSampleRate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	rate, err := h.Service.GetSampleRate(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sample rate."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rate": rate})
+}
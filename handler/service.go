@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// Service is what the handlers need of the service layer. *service.Service
+// implements it; handlers can be tested against a double such as
+// servicetest.Fake.
+type Service interface {
+	// Clicks and their stats.
+	GetLinkStats(ctx context.Context, shortCode string, excludeFlagged bool) (*repository.LinkStats, error)
+	SubscribeClicks(ctx context.Context, shortCode string) (<-chan events.Event, func(), error)
+	TrackEvent(e events.Event)
+
+	// API keys.
+	Bootstrap(ctx context.Context, token string, name string) (*service.IssuedAPIKey, error)
+	DisableAPIKeySigning(ctx context.Context, name string, actor string) error
+	EnsureAPIKey(ctx context.Context, name string, actor string) (*service.IssuedAPIKey, bool, error)
+	ListAPIKeys(ctx context.Context) ([]repository.APIKey, error)
+	RevokeAPIKey(ctx context.Context, name string, actor string) error
+	RotateAPIKeySigningSecret(ctx context.Context, name string, actor string) (string, error)
+
+	// Backups.
+	CreateBackup(ctx context.Context) (string, error)
+	ExportArchive(ctx context.Context, w io.Writer) (int64, error)
+	ListBackups(ctx context.Context) ([]repository.Backup, error)
+
+	// Workspace branding.
+	BrandedCodePrefixes(ctx context.Context) (map[string]int64, error)
+	DeleteWorkspaceLogo(ctx context.Context, slug string) error
+	DeleteWorkspacePage(ctx context.Context, slug string, page string) error
+	GetWorkspaceLogo(ctx context.Context, slug string) (*repository.WorkspaceLogo, error)
+	GetWorkspacePages(ctx context.Context, workspaceID int64) (*repository.WorkspacePages, error)
+	GetWorkspacePagesBySlug(ctx context.Context, slug string) (*repository.WorkspacePages, error)
+	SetWorkspaceLogo(ctx context.Context, slug string, contentType string, data []byte) error
+	SetWorkspacePage(ctx context.Context, slug string, page string, template string) error
+
+	// Bundles.
+	CreateBundle(ctx context.Context, title string, description string, links []string, actor string) (*repository.Bundle, error)
+	DeleteBundle(ctx context.Context, code string, actor string) error
+	ListBundles(ctx context.Context) ([]repository.Bundle, error)
+	ViewBundle(ctx context.Context, code string, countView bool) (*service.BundleDetail, error)
+
+	// Campaigns.
+	AttachLinksToCampaign(ctx context.Context, campaignID int64, codes []string) (*service.AttachResult, error)
+	CreateCampaign(ctx context.Context, name string, description string) (*repository.Campaign, error)
+	GetCampaignStats(ctx context.Context, campaignID int64, days int) (*service.CampaignStats, error)
+	ListCampaigns(ctx context.Context) ([]repository.Campaign, error)
+
+	// Change exports.
+	ExportChanges(ctx context.Context) ([]repository.ChangeExport, error)
+	ListChangeExports(ctx context.Context, stream string) ([]repository.ChangeExport, error)
+
+	// Code formats.
+	SetWorkspaceCodeFormat(ctx context.Context, slug string, alphabet string, length int) (*service.CodeFormatResult, error)
+
+	// Conversions.
+	GetConversionTracking(ctx context.Context, shortCode string) (bool, error)
+	RecordConversion(ctx context.Context, clickID string, value *float64, actor string) (*repository.Conversion, bool, error)
+	SetConversionTracking(ctx context.Context, shortCode string, enabled bool) error
+	TagClick(dest repository.Destination, target string, now time.Time) string
+
+	// Deleted links.
+	DeleteURL(ctx context.Context, shortCode string, actor string) error
+	ListDeletedURLs(ctx context.Context, days int) ([]repository.DeletedURL, error)
+	RestoreDeletedURLs(ctx context.Context, days int, codes []string, onCodeConflict string, actor string) (*service.RestoreResult, error)
+
+	// Device stats.
+	GetDeviceStats(ctx context.Context, shortCode string, days int) (*service.DeviceStats, error)
+
+	// Duplicate links.
+	FindDuplicateLinks(ctx context.Context, limit int) (*service.DuplicateReport, error)
+	MergeDuplicateLinks(ctx context.Context, canonical string, duplicates []string) (*service.MergeResult, error)
+
+	// Code entropy.
+	AuditCodeEntropy(ctx context.Context) (*service.EntropyReport, error)
+
+	// Expiry.
+	ExtendLinkExpiry(ctx context.Context, shortCode string, d time.Duration) (*repository.LinkExpiry, error)
+	GetLinkExpiry(ctx context.Context, shortCode string) (*repository.LinkExpiry, error)
+	SetLinkExpiry(ctx context.Context, shortCode string, expiresAt *time.Time) (*repository.LinkExpiry, error)
+	SnoozeExpiryNotice(ctx context.Context, shortCode string, d time.Duration) (*repository.LinkExpiry, error)
+
+	// Click fraud.
+	DismissClickFlag(ctx context.Context, id int64, actor string) error
+	ListClickFlags(ctx context.Context, days int) ([]repository.ClickFlag, error)
+
+	// Click locations.
+	GetGeoStats(ctx context.Context, shortCode string, days int, precision int) (*service.GeoStats, error)
+
+	// Link health.
+	ListDeadLinks(ctx context.Context) ([]repository.LinkHealth, error)
+
+	// Imports.
+	ImportLinks(ctx context.Context, format string, export io.Reader, workspaceSlug string, actor string) (*service.ImportResult, error)
+
+	// Interstitials.
+	SetLinkInterstitial(ctx context.Context, shortCode string, enabled bool) error
+	SetWorkspaceInterstitial(ctx context.Context, slug string, enabled bool) (*repository.Workspace, error)
+
+	// Link sets.
+	CreateLinkSet(ctx context.Context, req service.LinkSetRequest, actor string) (*service.LinkSetDetail, error)
+	GetLinkSet(ctx context.Context, id int64) (*service.LinkSetDetail, error)
+	LinkSetRecipientStats(ctx context.Context, id int64, recipient string, excludeFlagged bool) (*service.RecipientStats, error)
+
+	// Live stats.
+	GetLiveStats() *service.LiveStats
+
+	// Link metadata.
+	PatchLinkMetadata(ctx context.Context, shortCode string, patch map[string]*string, actor string) (map[string]string, error)
+
+	// Mirrors.
+	CreateMirror(ctx context.Context, source string, workspace string) (*service.MirroredLink, error)
+	ImportBitlyMirrors(ctx context.Context, export io.Reader, workspace string) (*service.MirrorImportResult, error)
+
+	// Code prefixes.
+	SetWorkspaceCodePrefix(ctx context.Context, slug string, prefix string) (*repository.Workspace, error)
+
+	// Network stats.
+	GetNetworkStats(ctx context.Context, shortCode string, days int) (*service.NetworkStats, error)
+
+	// Open Graph tags.
+	DeleteOpenGraph(ctx context.Context, shortCode string) error
+	GetOpenGraph(ctx context.Context, shortCode string) (*repository.OpenGraph, error)
+	SetOpenGraph(ctx context.Context, shortCode string, og repository.OpenGraph) (*repository.OpenGraph, error)
+
+	// Workspaces and ownership.
+	CreateWorkspace(ctx context.Context, slug string, name string, codePrefix string) (*repository.Workspace, error)
+	TransferLinks(ctx context.Context, codes []string, owner string, workspaceSlug string, actor string) (*service.TransferResult, error)
+
+	// Polling.
+	PollClicks(ctx context.Context, since int64, limit int) ([]repository.PolledClick, error)
+	PollLinks(ctx context.Context, since int64, limit int) ([]repository.PolledLink, error)
+
+	// Previews.
+	GetLinkPreview(ctx context.Context, shortCode string) (*repository.LinkPreview, error)
+	OpenScreenshot(ctx context.Context, shortCode string) (io.ReadCloser, string, error)
+
+	// Public stats.
+	GetPublicStats(ctx context.Context, shortCode string) (*repository.PublicStats, error)
+	IsPublicStats(ctx context.Context, shortCode string) (bool, error)
+	SetPublicStats(ctx context.Context, shortCode string, public bool, actor string) error
+
+	// QR codes.
+	DeleteWorkspaceQRStyle(ctx context.Context, slug string) error
+	GetWorkspaceQRStyle(ctx context.Context, slug string) (repository.QRStyle, error)
+	LinkWorkspaceID(shortCode string) (int64, error)
+	QRStyleFor(ctx context.Context, workspaceID int64) (repository.QRStyle, error)
+	RenderQRCode(ctx context.Context, content string, workspaceID int64, style repository.QRStyle) ([]byte, error)
+	SetWorkspaceQRStyle(ctx context.Context, slug string, style repository.QRStyle) (repository.QRStyle, error)
+
+	// Query plans.
+	ListQueryPlans(ctx context.Context, contains string, limit int) ([]repository.QueryPlan, error)
+
+	// Click recounts.
+	RecountClicks(ctx context.Context, codes []string, limit int, repair bool, actor string) (*service.RecountResult, error)
+
+	// Regions.
+	ClickDrift(ctx context.Context, limit int) ([]repository.ClickDrift, error)
+	ReconcileClickCounts(ctx context.Context, actor string) (int64, error)
+
+	// Report schedules.
+	CreateReportSchedule(ctx context.Context, name string, frequency string) (*repository.ReportSchedule, error)
+	DeleteReportSchedule(ctx context.Context, id int64) error
+	ListReportSchedules(ctx context.Context) ([]repository.ReportSchedule, error)
+
+	// Domain reputation and review.
+	ApproveLink(ctx context.Context, shortCode string, actor string) error
+	GetDomainReputation(ctx context.Context, domain string) (*repository.DomainReputation, error)
+	ListDomainReputations(ctx context.Context) ([]repository.DomainReputation, error)
+	ListPendingLinks(ctx context.Context) ([]repository.PendingLink, error)
+	OverrideDomainScore(ctx context.Context, domain string, score *int, note string, actor string) error
+	RecordDomainSignal(ctx context.Context, domain string, signal string) error
+
+	// Language routing.
+	GetLinkLanguages(ctx context.Context, shortCode string) (map[string]string, error)
+	SetLinkLanguages(ctx context.Context, shortCode string, destinations map[string]string) (map[string]string, error)
+
+	// Click sampling.
+	GetSampleRate(ctx context.Context, shortCode string) (float64, error)
+	SetSampleRate(ctx context.Context, shortCode string, rate float64) error
+
+	// Schedules.
+	GetScheduleRules(ctx context.Context, shortCode string) ([]service.ScheduleRuleSpec, error)
+	SetScheduleRules(ctx context.Context, shortCode string, specs []service.ScheduleRuleSpec) ([]service.ScheduleRuleSpec, error)
+
+	// Shortening.
+	Shorten(ctx context.Context, longURL string, opts service.ShortenOptions) (*service.ShortenResult, error)
+
+	// Links and redirects.
+	GetDestination(shortCode string) (repository.Destination, error)
+	GetRotatedShortCode(ctx context.Context, retiredCode string) (string, error)
+	HealthCheck(ctx context.Context) error
+	ListURLs(ctx context.Context, filter repository.URLFilter, page int, limit int) (*service.URLListResponse, error)
+	RotateShortURL(ctx context.Context, shortCode string) (*service.RotationResult, error)
+
+	// Abuse.
+	ListAbuseEvents(ctx context.Context, days int) ([]repository.AbuseEvent, error)
+
+	// Splits.
+	DeleteSplit(ctx context.Context, shortCode string) error
+	GetSplitStats(ctx context.Context, shortCode string) (*service.SplitStats, error)
+	SetSplit(ctx context.Context, shortCode string, destination string, percent int) (*repository.Split, error)
+	UpdateSplitPercent(ctx context.Context, shortCode string, percent int) (*repository.Split, error)
+
+	// Time series.
+	GetClickSeries(ctx context.Context, shortCode string, granularity string, from time.Time, to time.Time) (*service.ClickSeries, error)
+
+	// UTM links.
+	CreateUTMShortURL(ctx context.Context, baseURL string, p repository.UTMParams) (string, string, error)
+
+	// Webhooks.
+	DeleteLinkWebhook(ctx context.Context, shortCode string) error
+	GetLinkWebhook(ctx context.Context, shortCode string) (*repository.Webhook, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID int64, status string) ([]repository.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, webhookID int64, deliveryID string, actor string) (*repository.WebhookDelivery, error)
+	RotateWebhookSecret(ctx context.Context, shortCode string, actor string) (*repository.Webhook, error)
+	SetLinkWebhook(ctx context.Context, shortCode string, targetURL string) (*repository.Webhook, error)
+}
+
+var _ Service = (*service.Service)(nil)
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/service"
+	"github.com/gin-gonic/gin"
+)
+
+const splitRules = "destination must be an absolute http(s) URL and percent between 0 and 100"
+
+func (h *GinHandler) SetSplit(c *gin.Context) {
+	var req struct {
+		Destination string `json:"destination" binding:"required"`
+		Percent     *int   `json:"percent" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"destination\": \"...\", \"percent\": 10})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	split, err := h.Service.SetSplit(ctx, c.Param("code"), req.Destination, *req.Percent)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSplit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": splitRules})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set split."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, split)
+}
+
+func (h *GinHandler) UpdateSplit(c *gin.Context) {
+	var req struct {
+		Percent *int `json:"percent" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"percent\": 50})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	split, err := h.Service.UpdateSplitPercent(ctx, c.Param("code"), *req.Percent)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSplit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": splitRules})
+		case errors.Is(err, service.ErrSplitNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No split configured for this short code"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update split."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, split)
+}
+
+func (h *GinHandler) GetSplit(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.Service.GetSplitStats(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrSplitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No split configured for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch split."})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *GinHandler) DeleteSplit(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteSplit(ctx, c.Param("code")); err != nil {
+		if errors.Is(err, service.ErrSplitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No split configured for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete split."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
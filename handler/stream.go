@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// streamKeepAlive keeps idle streams from being cut by proxies.
+const streamKeepAlive = 15 * time.Second
+
+// liftWriteDeadline exempts a long-running response from HTTP_WRITE_TIMEOUT.
+// Writers that cannot change their deadline, such as HTTP/3 streams, have
+// none to lift.
+func liftWriteDeadline(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
+// StreamClicks pushes a link's clicks to the client as Server-Sent Events
+// until the client disconnects or the server shuts down.
+func (h *GinHandler) StreamClicks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	clicks, unsubscribe, err := h.Service.SubscribeClicks(ctx, c.Param("code"))
+	cancel()
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		case errors.Is(err, service.ErrStreamingDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Click streaming is not enabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open click stream."})
+		}
+		return
+	}
+	defer unsubscribe()
+
+	liftWriteDeadline(c)
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-clicks:
+			if !ok {
+				return false
+			}
+			c.SSEvent("click", e)
+			return true
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/pages"
+	"github.com/gin-gonic/gin"
+)
+
+type unfurlCard struct {
+	ShortURL    string
+	Title       string
+	Description string
+	Image       string
+}
+
+// serveUnfurl answers a link-preview fetcher with Open Graph tags for the
+// link instead of redirecting, so a message preview is neither counted as
+// a click nor followed through to the destination. Tags set on the link
+// take precedence over the defaults derived from its destination. The page
+// is the link's workspace's, if it replaced it.
+func (h *GinHandler) serveUnfurl(c *gin.Context, shortCode string, workspaceID int64) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	preview, err := h.Service.GetLinkPreview(ctx, shortCode)
+	if err != nil {
+		log.Printf("Service error during link unfurl: %v", err)
+		h.writeErrorBody(c, http.StatusInternalServerError, msgLookupFailed)
+		return
+	}
+
+	card := unfurlCard{ShortURL: h.domain(c) + preview.ShortCode, Title: preview.LongURL, Description: preview.LongURL}
+	if u, err := url.Parse(preview.LongURL); err == nil && u.Hostname() != "" {
+		card.Title = u.Hostname()
+	}
+	if preview.ScreenshotKey != "" {
+		card.Image = h.domain(c) + "urls/" + preview.ShortCode + "/screenshot"
+	}
+	if og := preview.OpenGraph; og != nil {
+		if og.Title != "" {
+			card.Title = og.Title
+		}
+		if og.Description != "" {
+			card.Description = og.Description
+		}
+		if og.ImageURL != "" {
+			card.Image = og.ImageURL
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	h.writePage(c, h.pagesFor(ctx, workspaceID), http.StatusOK, pages.Unfurl, card)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) UTMShorten(c *gin.Context) {
+	var req struct {
+		BaseURL     string `json:"base_url" binding:"required"`
+		UTMSource   string `json:"utm_source" binding:"required"`
+		UTMMedium   string `json:"utm_medium" binding:"required"`
+		UTMCampaign string `json:"utm_campaign" binding:"required"`
+		UTMTerm     string `json:"utm_term"`
+		UTMContent  string `json:"utm_content"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload (Expected JSON: {\"base_url\": \"...\", \"utm_source\": \"...\", \"utm_medium\": \"...\", \"utm_campaign\": \"...\"})",
+		})
+		return
+	}
+
+	params := repository.UTMParams{
+		Source:   req.UTMSource,
+		Medium:   req.UTMMedium,
+		Campaign: req.UTMCampaign,
+		Term:     req.UTMTerm,
+		Content:  req.UTMContent,
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	shortCode, longURL, err := h.Service.CreateUTMShortURL(ctx, req.BaseURL, params)
+	if err != nil {
+		if errors.Is(err, service.ErrMissingUTMFields) || strings.Contains(err.Error(), "invalid URL format") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "service capacity exhausted") {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Short code generation failed. Try again later."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: Failed to process URL creation."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"short_url": h.domain(c) + shortCode,
+		"long_url":  longURL,
+		"utm":       params,
+	})
+}
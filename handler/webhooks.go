@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/middleware"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+func (h *GinHandler) SetLinkWebhook(c *gin.Context) {
+	var req struct {
+		TargetURL string `json:"target_url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload (Expected JSON: {\"target_url\": \"...\"})"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	webhook, err := h.Service.SetLinkWebhook(ctx, c.Param("code"), req.TargetURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWebhookURL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "target_url must be an absolute http(s) URL"})
+		case errors.Is(err, service.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short code not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register webhook."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *GinHandler) GetLinkWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	webhook, err := h.Service.GetLinkWebhook(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No webhook registered for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook."})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *GinHandler) DeleteLinkWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.DeleteLinkWebhook(ctx, c.Param("code")); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No webhook registered for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *GinHandler) RotateWebhookSecret(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	webhook, err := h.Service.RotateWebhookSecret(ctx, c.Param("code"), c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No webhook registered for this short code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate webhook secret."})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+func webhookID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID must be a positive integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *GinHandler) ListWebhookDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	var q struct {
+		Status string `form:"status"`
+	}
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	deliveries, err := h.Service.ListWebhookDeliveries(ctx, id, q.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDeliveryStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be retrying, succeeded or failed"})
+		case errors.Is(err, service.ErrWebhookNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		default:
+			log.Printf("Service error during webhook delivery listing: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhook deliveries."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+func (h *GinHandler) RedeliverWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	// The attempt itself may take up to the dispatcher's 5s timeout.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	delivery, err := h.Service.RedeliverWebhook(ctx, id, c.Param("delivery"), c.GetString(middleware.ActorContextKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDeliveryNotFound), errors.Is(err, service.ErrWebhookNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		case errors.Is(err, service.ErrDeliveryNotFailed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Only failed deliveries can be redelivered"})
+		default:
+			log.Printf("Service error during webhook redelivery: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver webhook."})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,193 @@
+// Package i18n holds the message catalogs for user-facing text. English is
+// built in and is the fallback for every other locale; deployments add or
+// override locales with JSON files of key to message.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fallback is the locale every lookup ends in.
+const Fallback = "en"
+
+//go:embed locales/*.json
+var builtin embed.FS
+
+// Catalog maps locales to their messages. Messages are fmt formats.
+type Catalog struct {
+	locales map[string]map[string]string
+	def     string
+}
+
+// Load reads the built-in locales and then every <locale>.json in dir, when
+// dir is set. Keys from dir replace built-in ones, so a deployment can
+// reword single messages without copying the whole catalog. def is the
+// locale used for requests that accept none of the available ones.
+func Load(dir string, def string) (*Catalog, error) {
+	c := &Catalog{locales: map[string]map[string]string{}, def: strings.ToLower(def)}
+	entries, err := builtin.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		data, err := builtin.ReadFile("locales/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := c.add(e.Name(), data); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read message catalog: %w", err)
+			}
+			if err := c.add(filepath.Base(f), data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !c.Has(c.def) {
+		return nil, fmt.Errorf("no message catalog for default language %q (have %s)", def, strings.Join(c.Locales(), ", "))
+	}
+	return c, nil
+}
+
+func (c *Catalog) add(file string, data []byte) error {
+	locale := strings.ToLower(strings.TrimSuffix(file, ".json"))
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse message catalog %s: %w", file, err)
+	}
+	if c.locales[locale] == nil {
+		c.locales[locale] = map[string]string{}
+	}
+	for k, v := range messages {
+		c.locales[locale][k] = v
+	}
+	return nil
+}
+
+// Locales lists the available locales in sorted order.
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.locales))
+	for l := range c.locales {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Default is the locale for requests that accept none of the available ones.
+func (c *Catalog) Default() string {
+	return c.def
+}
+
+// Negotiate picks the locale for an Accept-Language header: the first
+// preferred tag with a catalog, tried exactly and then by its primary
+// subtag, or the default.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		if c.Has(tag) {
+			return tag
+		}
+		if primary, _, found := strings.Cut(tag, "-"); found && c.Has(primary) {
+			return primary
+		}
+	}
+	return c.def
+}
+
+type weightedLanguage struct {
+	tag string
+	q   float64
+}
+
+// ParseAcceptLanguage returns the tags of an Accept-Language header ordered by
+// preference. Wildcards and tags with q=0 are dropped.
+func ParseAcceptLanguage(header string) []string {
+	var langs []weightedLanguage
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		langs = append(langs, weightedLanguage{tag: tag, q: q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	tags := make([]string, len(langs))
+	for i, l := range langs {
+		tags[i] = l.tag
+	}
+	return tags
+}
+
+// Has reports whether locale has a catalog of its own.
+func (c *Catalog) Has(locale string) bool {
+	_, ok := c.locales[strings.ToLower(locale)]
+	return ok
+}
+
+// T formats the message for key in locale. A missing message falls back to
+// the locale's primary language ("pt" for "pt-br"), then to English, then to
+// the key itself so a gap shows up on the page rather than as an error.
+func (c *Catalog) T(locale string, key string, args ...any) string {
+	msg, ok := c.lookup(strings.ToLower(locale), key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+func (c *Catalog) lookup(locale string, key string) (string, bool) {
+	if msg, ok := c.locales[locale][key]; ok {
+		return msg, true
+	}
+	if primary, _, found := strings.Cut(locale, "-"); found {
+		if msg, ok := c.locales[primary][key]; ok {
+			return msg, true
+		}
+	}
+	msg, ok := c.locales[Fallback][key]
+	return msg, ok
+}
+
+// Dir is the text direction of locale, for the HTML dir attribute.
+func Dir(locale string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	switch primary {
+	case "ar", "fa", "he", "ur", "yi", "ps", "sd", "ug", "dv":
+		return "rtl"
+	}
+	return "ltr"
+}
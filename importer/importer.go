@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MaxRecords = 10000
+
+var (
+	ErrMissingColumn = errors.New("required column missing")
+	ErrTooManyRows   = errors.New("too many rows in export")
+	ErrUnknownFormat = errors.New("unknown export format")
+)
+
+// Record is one link from another shortener's export.
+type Record struct {
+	Line        int
+	ShortURL    string
+	Code        string
+	Destination string
+	CreatedAt   time.Time
+	Clicks      int
+}
+
+type field int
+
+const (
+	fieldShortURL field = iota
+	fieldCode
+	fieldDestination
+	fieldCreatedAt
+	fieldClicks
+)
+
+// format maps each field to the header names a provider uses for it, matched
+// case-insensitively with underscores treated as spaces.
+type format struct {
+	name     string
+	columns  map[field][]string
+	required []field
+}
+
+var bitlyFormat = format{
+	name: "bitly",
+	columns: map[field][]string{
+		fieldShortURL:    {"bitlink", "link", "short link", "short url"},
+		fieldDestination: {"long url", "destination", "url"},
+		fieldCreatedAt:   {"created", "created at", "date created"},
+		fieldClicks:      {"clicks", "total clicks", "click count"},
+	},
+	required: []field{fieldShortURL},
+}
+
+// TinyURL exports carry the alias on its own; the short URL column is
+// optional there.
+var tinyURLFormat = format{
+	name: "tinyurl",
+	columns: map[field][]string{
+		fieldShortURL:    {"tinyurl", "tiny url", "short url"},
+		fieldCode:        {"alias"},
+		fieldDestination: {"url", "long url", "destination"},
+		fieldCreatedAt:   {"created", "created at", "date created"},
+		fieldClicks:      {"clicks", "total clicks", "hits"},
+	},
+	required: []field{fieldCode, fieldDestination},
+}
+
+var formats = map[string]format{
+	bitlyFormat.name:   bitlyFormat,
+	tinyURLFormat.name: tinyURLFormat,
+}
+
+// ReadBitly parses a bit.ly link export.
+func ReadBitly(r io.Reader) ([]Record, error) {
+	return read(r, bitlyFormat)
+}
+
+// Read parses an export in the named format: "bitly" or "tinyurl".
+func Read(r io.Reader, formatName string) ([]Record, error) {
+	f, ok := formats[formatName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, formatName)
+	}
+	return read(r, f)
+}
+
+var createdLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"01/02/2006 15:04",
+	"01/02/2006",
+}
+
+func read(r io.Reader, f format) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s header: %w", f.name, err)
+	}
+	index := make(map[field]int)
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(strings.TrimPrefix(h, "\ufeff"), "_", " ")))
+		for fld, names := range f.columns {
+			if _, seen := index[fld]; seen {
+				continue
+			}
+			for _, n := range names {
+				if name == n {
+					index[fld] = i
+				}
+			}
+		}
+	}
+	for _, fld := range f.required {
+		if _, ok := index[fld]; !ok {
+			return nil, fmt.Errorf("%w: %s export needs one of %q", ErrMissingColumn, f.name, f.columns[fld])
+		}
+	}
+
+	records := []Record{}
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s export: %w", f.name, err)
+		}
+		if len(records) == MaxRecords {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRows, MaxRecords)
+		}
+
+		get := func(fld field) string {
+			i, ok := index[fld]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		rec := Record{Line: line, Destination: get(fieldDestination)}
+		rec.ShortURL, rec.Code = normalizeShortURL(get(fieldShortURL))
+		if code := get(fieldCode); code != "" {
+			rec.Code = code
+		}
+		if v := get(fieldCreatedAt); v != "" {
+			rec.CreatedAt = parseCreated(v)
+		}
+		if v := get(fieldClicks); v != "" {
+			rec.Clicks, _ = strconv.Atoi(strings.ReplaceAll(v, ",", ""))
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// normalizeShortURL adds the scheme exports often leave off ("bit.ly/abc")
+// and extracts the code from the path.
+func normalizeShortURL(raw string) (string, string) {
+	if raw == "" {
+		return "", ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+	return raw, strings.Trim(u.Path, "/")
+}
+
+func parseCreated(v string) time.Time {
+	for _, layout := range createdLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,143 @@
+// Package linkcheck probes whether a destination URL still serves content.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const DefaultTimeout = 10 * time.Second
+
+type Checker struct {
+	HTTP      *http.Client
+	UserAgent string
+}
+
+// ErrPrivateAddress is returned for destinations on internal addresses, which
+// are not probed and never count as dead.
+var ErrPrivateAddress = errors.New("destination resolves to a private address")
+
+// NewChecker returns a checker that refuses to connect to loopback, private
+// and link-local addresses, so that user-supplied destinations cannot be used
+// to probe the internal network.
+func NewChecker() *Checker {
+	dialer := &net.Dialer{
+		Timeout: DefaultTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || internalIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return &Checker{
+		HTTP:      &http.Client{Timeout: DefaultTimeout, Transport: transport},
+		UserAgent: "urlShortener-linkcheck/1.0",
+	}
+}
+
+// CheckPublic resolves the host of target and returns ErrPrivateAddress when
+// any of its addresses is loopback, private or link-local, for destinations
+// fetched by services that cannot be given NewChecker's dialer.
+func CheckPublic(ctx context.Context, target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if internalIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// Result is the outcome of one probe. Status is 0 when no response arrived.
+// ContentType and ContentLength describe the final response after redirects;
+// ContentLength is -1 when the server did not say.
+type Result struct {
+	Status        int
+	Err           error
+	ContentType   string
+	ContentLength int64
+	Attachment    bool
+}
+
+// Dead reports whether the probe shows the destination is gone: no response,
+// 404/410, or a server error. Other 4xx answers such as 403 or 429 usually
+// mean the site blocks automated clients, not that the page disappeared.
+func (r Result) Dead() bool {
+	if errors.Is(r.Err, ErrPrivateAddress) {
+		return false
+	}
+	return r.Err != nil || r.Status == http.StatusNotFound || r.Status == http.StatusGone || r.Status >= 500
+}
+
+// Download reports whether the destination is a file rather than a web page:
+// the server asked for it to be saved, or it is anything but HTML.
+func (r Result) Download() bool {
+	if r.Err != nil || r.Status < 200 || r.Status >= 300 {
+		return false
+	}
+	if r.Attachment {
+		return true
+	}
+	return r.ContentType != "" && r.ContentType != "text/html" && r.ContentType != "application/xhtml+xml"
+}
+
+// Check sends a HEAD request, falling back to GET for servers that do not
+// implement HEAD. Redirects are followed.
+func (c *Checker) Check(ctx context.Context, target string) Result {
+	result := c.do(ctx, http.MethodHead, target)
+	if result.Err == nil && (result.Status == http.StatusMethodNotAllowed || result.Status == http.StatusNotImplemented) {
+		result = c.do(ctx, http.MethodGet, target)
+	}
+	return result
+}
+
+func (c *Checker) do(ctx context.Context, method string, target string) Result {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return Result{Err: fmt.Errorf("failed to build %s request for %s: %w", method, target, err)}
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer resp.Body.Close()
+	// Read a little so small bodies let the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	disposition, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+	return Result{
+		Status:        resp.StatusCode,
+		ContentType:   contentType,
+		ContentLength: resp.ContentLength,
+		Attachment:    disposition == "attachment",
+	}
+}
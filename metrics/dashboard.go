@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed dashboard.json
+var dashboardJSON []byte
+
+//go:embed alerts.yml
+var alertRulesYAML []byte
+
+// DashboardHandler serves a Grafana dashboard for the metrics above, ready for
+// import or file-based provisioning.
+func DashboardHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", dashboardJSON)
+}
+
+// AlertRulesHandler serves Prometheus alerting rules for the same metrics.
+func AlertRulesHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml", alertRulesYAML)
+}
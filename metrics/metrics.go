@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "urlshortener"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"route", "method"})
+
+	CodesGenerated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shortcode_generated_total",
+		Help:      "Unique short codes successfully generated.",
+	})
+
+	CodeCollisions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shortcode_collisions_total",
+		Help:      "Generated short codes rejected because they were already taken.",
+	})
+
+	CodeGenerationFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shortcode_generation_exhausted_total",
+		Help:      "Short code generations that gave up after exhausting all retries.",
+	})
+
+	CodeGenerationAttempts = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "shortcode_generation_attempts",
+		Help:      "Candidates drawn per random short code generation, including the ones that exhausted all retries.",
+		Buckets:   []float64{1, 2, 3, 4, 5, 6, 8, 10, 15, 20},
+	})
+
+	KeyspaceUtilization = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "shortcode_keyspace_utilization",
+		Help:      "Estimated share of the random short code space already issued; also the chance a single candidate collides.",
+	})
+
+	DestinationCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_cache_hits_total",
+		Help:      "Redirect lookups answered from the in-memory destination cache.",
+	})
+
+	DestinationCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_cache_misses_total",
+		Help:      "Redirect lookups that had to go to the database.",
+	})
+
+	DestinationCacheStaleHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_cache_stale_hits_total",
+		Help:      "Redirect lookups answered from an expired cache entry while it was refreshed.",
+	})
+
+	DestinationRefreshesSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_refreshes_skipped_total",
+		Help:      "Stale cache entries served without a refresh because too many were already running.",
+	})
+
+	DestinationNegativeHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_negative_cache_hits_total",
+		Help:      "Lookups of recently missing codes answered 404 without a database query.",
+	})
+
+	ProbeBans = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "probe_bans_total",
+		Help:      "IPs temporarily banned for redirect lookups that were mostly not found.",
+	})
+
+	DestinationLookupsShared = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_lookups_shared_total",
+		Help:      "Redirect lookups that waited on an identical in-flight query instead of issuing their own.",
+	})
+
+	RateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by the per-IP rate limiter.",
+	})
+
+	Panics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_panics_total",
+		Help:      "Handler panics recovered and answered with a 500, by route template.",
+	}, []string{"route"})
+
+	IPListMatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ip_list_matches_total",
+		Help:      "Requests from allowlisted IPs let past the rate limits, and from denylisted IPs rejected, by list.",
+	}, []string{"list"})
+
+	DatabaseUp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "database_up",
+		Help:      "1 if the last database ping by the alert evaluator succeeded, 0 if it failed.",
+	})
+
+	CacheNodeUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_node_up",
+		Help:      "1 while a shared cache node is in the ring, 0 while it is taken out after failing.",
+	}, []string{"node"})
+
+	SharedCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "destination_shared_cache_hits_total",
+		Help:      "Local destination cache misses answered from the shared cache instead of the database.",
+	})
+)
+
+type seriesKey struct {
+	route  string
+	method string
+	status int
+}
+
+type requestSeries struct {
+	requests prometheus.Counter
+	duration prometheus.Observer
+}
+
+// RequestMetrics records HTTP requests in HTTPRequests and HTTPDuration.
+// It holds the resolved children per label set: WithLabelValues hashes its
+// labels and allocates on every call, which adds up on the redirect path.
+type RequestMetrics struct {
+	series sync.Map
+}
+
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{}
+}
+
+func (m *RequestMetrics) seriesFor(key seriesKey) requestSeries {
+	if s, ok := m.series.Load(key); ok {
+		return s.(requestSeries)
+	}
+	s := requestSeries{
+		requests: HTTPRequests.WithLabelValues(key.route, key.method, strconv.Itoa(key.status)),
+		duration: HTTPDuration.WithLabelValues(key.route, key.method),
+	}
+	m.series.Store(key, s)
+	return s
+}
+
+// Middleware records request counts and latency labelled by the matched route
+// template (not the raw path) so codes don't explode label cardinality.
+func (m *RequestMetrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		s := m.seriesFor(seriesKey{route: route, method: c.Request.Method, status: c.Writer.Status()})
+		s.requests.Inc()
+		s.duration.Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogger is gin's request logger. Unless logRedirects is set, successful
+// redirects are not logged: they are the bulk of traffic, already counted in
+// metrics and click analytics, and formatting a line for each is the largest
+// per-request cost after the database.
+func AccessLogger(logRedirects bool) gin.HandlerFunc {
+	if logRedirects {
+		return gin.Logger()
+	}
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Skip: func(c *gin.Context) bool {
+			status := c.Writer.Status()
+			return c.FullPath() == "/:code" && status >= 300 && status < 400
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ActorContextKey = "actor"
+
+func BearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// APIKeyVerifier resolves an API key to its name; ok is false for unknown keys.
+// The signing methods back signed requests: APIKeySigningSecret reports ok
+// false for keys without a signing secret, and AcceptSignedRequest records a
+// nonce, returning fresh false when it was used before.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (name string, ok bool, err error)
+	APIKeySigningSecret(ctx context.Context, name string) (secret string, ok bool, err error)
+	AcceptSignedRequest(ctx context.Context, name string, nonce string) (fresh bool, err error)
+}
+
+// AdminAuth accepts the static ADMIN_TOKEN, when set, any API key known to
+// keys, or a request signed with a key's signing secret. Requests made with
+// a key are attributed to "key:<name>".
+func AdminAuth(adminToken string, keys APIKeyVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := BearerToken(c.Request)
+		if token == "" && isSignedRequest(c.Request) {
+			name, err := verifySignedRequest(c, keys)
+			if err != nil {
+				rejectSignedRequest(c, err)
+				return
+			}
+			c.Set(ActorContextKey, "key:"+name)
+			c.Next()
+			return
+		}
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			c.Set(ActorContextKey, "admin")
+			c.Next()
+			return
+		}
+
+		name, ok, err := keys.VerifyAPIKey(c.Request.Context(), token)
+		if err != nil {
+			log.Printf("ERROR: API key verification failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify credentials"})
+			c.Abort()
+			return
+		}
+		if !ok {
+			log.Printf("ADMIN AUTH: rejected request to %s from %s.", c.Request.URL.Path, GetClientIP(c))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		c.Set(ActorContextKey, "key:"+name)
+		c.Next()
+	}
+}
+
+// OptionalAdminAuth is AdminAuth for routes that also serve anonymous
+// callers: requests without credentials pass through with no actor set,
+// while a token or signature that is sent must be valid.
+func OptionalAdminAuth(adminToken string, keys APIKeyVerifier) gin.HandlerFunc {
+	auth := AdminAuth(adminToken, keys)
+	return func(c *gin.Context) {
+		if BearerToken(c.Request) == "" && !isSignedRequest(c.Request) {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireCredentials closes a route to anonymous callers. Requests with a
+// bearer token or signature are checked like AdminAuth checks them; the
+// rest get 401 pointing at docsURL, which explains how to get an API key.
+func RequireCredentials(adminToken string, keys APIKeyVerifier, docsURL string) gin.HandlerFunc {
+	auth := AdminAuth(adminToken, keys)
+	return func(c *gin.Context) {
+		if BearerToken(c.Request) != "" || isSignedRequest(c.Request) {
+			auth(c)
+			return
+		}
+		c.Header("WWW-Authenticate", "Bearer")
+		body := gin.H{"error": T(c, "error.anonymous_creation_disabled")}
+		if docsURL != "" {
+			c.Header("Link", "<"+docsURL+`>; rel="help"`)
+			body["documentation"] = docsURL
+		}
+		c.JSON(http.StatusUnauthorized, body)
+		c.Abort()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaptchaHeader carries the widget's response token on creation requests.
+const CaptchaHeader = "X-Captcha-Token"
+
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string, remoteIP string) (bool, error)
+}
+
+// RequireCaptcha asks callers without valid credentials to solve a CAPTCHA.
+// Requests bearing ADMIN_TOKEN or a known API key skip the check, so
+// integrations keep working while anonymous spam needs a human per link.
+func RequireCaptcha(captcha CaptchaVerifier, adminToken string, keys APIKeyVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticated(c, adminToken, keys) {
+			c.Next()
+			return
+		}
+
+		token := c.GetHeader(CaptchaHeader)
+		if token == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "CAPTCHA token required in " + CaptchaHeader + " header"})
+			c.Abort()
+			return
+		}
+
+		clientIP := GetClientIP(c)
+		ok, err := captcha.Verify(c.Request.Context(), token, clientIP)
+		if err != nil {
+			log.Printf("ERROR: CAPTCHA verification failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "CAPTCHA verification is unavailable. Try again later."})
+			c.Abort()
+			return
+		}
+		if !ok {
+			log.Printf("CAPTCHA: rejected token for %s from %s.", c.Request.URL.Path, clientIP)
+			c.JSON(http.StatusForbidden, gin.H{"error": "CAPTCHA verification failed"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticated reports whether the request carries the admin token or a
+// valid API key. Lookup errors count as unauthenticated, which only means
+// the caller is asked for a CAPTCHA.
+func authenticated(c *gin.Context, adminToken string, keys APIKeyVerifier) bool {
+	token := BearerToken(c.Request)
+	if token == "" {
+		return false
+	}
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+		return true
+	}
+	_, ok, err := keys.VerifyAPIKey(c.Request.Context(), token)
+	if err != nil {
+		log.Printf("ERROR: API key verification failed: %v", err)
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPContextKey holds the address of the client a request came from,
+// as resolved by ResolveClientIP.
+const ClientIPContextKey = "client_ip"
+
+// ResolveClientIP records the address of the client behind the trusted
+// proxies, for GetClientIP. On connections from the trusted ranges
+// X-Forwarded-For is read from the right, skipping the trusted proxies each
+// hop appended, and the first other address is the client. Anything left of
+// it was written by the client and is ignored. Other connections are keyed
+// on the peer address, whatever headers they send.
+func ResolveClientIP(trusted []string) (gin.HandlerFunc, error) {
+	prefixes, err := parsePrefixes(trusted)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	return func(c *gin.Context) {
+		c.Set(ClientIPContextKey, resolveClientIP(c.Request, prefixes))
+		c.Next()
+	}, nil
+}
+
+// GetClientIP returns the client address ResolveClientIP found, or the peer
+// address on routers without it.
+func GetClientIP(c *gin.Context) string {
+	if ip := c.GetString(ClientIPContextKey); ip != "" {
+		return ip
+	}
+	return peerAddr(c.Request)
+}
+
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	ip := peerAddr(r)
+	if !peerIn(r, trusted) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = addr.Unmap().WithZone("")
+		ip = addr.String()
+		if !prefixesContain(trusted, addr) {
+			break
+		}
+	}
+	return ip
+}
+
+func peerAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().WithZone("").String()
+	}
+	return host
+}
+
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
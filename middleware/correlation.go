@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// CorrelationHeader carries a request's correlation ID, in both
+	// directions.
+	CorrelationHeader = "X-Correlation-ID"
+	// CorrelationIDContextKey holds the correlation ID of the request.
+	CorrelationIDContextKey = "correlation_id"
+
+	maxCorrelationIDLength = 128
+)
+
+// Correlate gives every request a correlation ID, echoed in the response,
+// so the events it records can be joined with it downstream. An ID sent by
+// the client or an upstream proxy is kept when it is well formed;
+// otherwise a random one is made.
+func Correlate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(CorrelationHeader)
+		if !validCorrelationID(id) {
+			id = newCorrelationID()
+		}
+		c.Set(CorrelationIDContextKey, id)
+		c.Header(CorrelationHeader, id)
+		c.Next()
+	}
+}
+
+// validCorrelationID accepts the characters of UUIDs, trace IDs and most
+// request IDs load balancers generate, nothing that could break a log line
+// or a CSV cell.
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BaseURLContextKey holds the public base URL of the request, scheme://host/,
+// when a trusted proxy forwarded it.
+const BaseURLContextKey = "base_url"
+
+// ForwardedBaseURL derives the base URL clients reached the service at from
+// the Forwarded header (RFC 7239) or, failing that, X-Forwarded-Proto and
+// X-Forwarded-Host, so generated short URLs point at the public host rather
+// than the listen address. The headers are only read on connections from
+// the trusted ranges; anyone else could use them to have links minted on a
+// host of their choosing. When hosts is not empty, a forwarded host must be
+// one of them. A trusted proxy that forwards no host keeps the request's
+// Host header.
+func ForwardedBaseURL(trusted []string, hosts []string) (gin.HandlerFunc, error) {
+	prefixes, err := parsePrefixes(trusted)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	allowed := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		allowed[strings.ToLower(h)] = true
+	}
+	return func(c *gin.Context) {
+		if len(prefixes) == 0 || !peerIn(c.Request, prefixes) {
+			c.Next()
+			return
+		}
+		proto, host := forwardedOrigin(c.Request)
+		if proto == "" {
+			proto = "http"
+			if c.Request.TLS != nil {
+				proto = "https"
+			}
+		}
+		if host == "" {
+			host = c.Request.Host
+		}
+		host = strings.ToLower(host)
+		if (proto == "http" || proto == "https") && validHost(host) && (len(allowed) == 0 || allowed[host]) {
+			c.Set(BaseURLContextKey, proto+"://"+host+"/")
+		}
+		c.Next()
+	}, nil
+}
+
+// BaseURL returns the forwarded base URL of the request, or fallback.
+func BaseURL(c *gin.Context, fallback string) string {
+	if base := c.GetString(BaseURLContextKey); base != "" {
+		return base
+	}
+	return fallback
+}
+
+// forwardedOrigin reads proto and host from the first element of Forwarded,
+// which the proxy nearest the client added, or from the X-Forwarded-*
+// headers when Forwarded is absent.
+func forwardedOrigin(r *http.Request) (proto string, host string) {
+	if fwd := r.Header.Get("Forwarded"); fwd != "" {
+		first, _, _ := strings.Cut(fwd, ",")
+		for _, pair := range strings.Split(first, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			value = strings.Trim(value, `"`)
+			switch strings.ToLower(name) {
+			case "proto":
+				proto = strings.ToLower(value)
+			case "host":
+				host = value
+			}
+		}
+		return proto, host
+	}
+	first := func(header string) string {
+		v, _, _ := strings.Cut(r.Header.Get(header), ",")
+		return strings.TrimSpace(v)
+	}
+	return strings.ToLower(first("X-Forwarded-Proto")), first("X-Forwarded-Host")
+}
+
+// validHost accepts a bare host name or address with an optional port.
+func validHost(host string) bool {
+	if host == "" || strings.ContainsAny(host, "/\\@?# \t") {
+		return false
+	}
+	u, err := url.Parse("http://" + host)
+	if err != nil || u.Host != host || u.Hostname() == "" {
+		return false
+	}
+	if strings.HasPrefix(host, "[") {
+		_, err := netip.ParseAddr(u.Hostname())
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/metrics"
+)
+
+// IPAllowedContextKey is set to true for requests from allowlisted IPs,
+// which RateLimiter and ProbeLimiter let through uncounted.
+const IPAllowedContextKey = "ip_allowed"
+
+// Outcomes of IPLists.Match.
+const (
+	IPListNone  = "none"
+	IPListAllow = "allow"
+	IPListDeny  = "deny"
+)
+
+// IPListConfig is the JSON form of the lists, as read and replaced through
+// the admin API.
+type IPListConfig struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// IPLists exempts monitoring probes and internal services from the rate
+// limits and rejects denied addresses outright. The deny list wins when an
+// address is on both. Lists can be replaced while the server runs.
+type IPLists struct {
+	mu    sync.RWMutex
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// parsePrefixes accepts addresses and CIDR ranges. A bare address is a
+// single-address range.
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func NewIPLists(cfg IPListConfig) (*IPLists, error) {
+	l := &IPLists{}
+	if err := l.Set(cfg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Set replaces both lists. Nothing changes if either fails to parse.
+func (l *IPLists) Set(cfg IPListConfig) error {
+	allow, err := parsePrefixes(cfg.Allow)
+	if err != nil {
+		return fmt.Errorf("allow: %w", err)
+	}
+	deny, err := parsePrefixes(cfg.Deny)
+	if err != nil {
+		return fmt.Errorf("deny: %w", err)
+	}
+	l.mu.Lock()
+	l.allow, l.deny = allow, deny
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *IPLists) Config() IPListConfig {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	cfg := IPListConfig{Allow: make([]string, len(l.allow)), Deny: make([]string, len(l.deny))}
+	for i, p := range l.allow {
+		cfg.Allow[i] = p.String()
+	}
+	for i, p := range l.deny {
+		cfg.Deny[i] = p.String()
+	}
+	return cfg
+}
+
+// Match reports which list ip is on and the range that matched it. An
+// unparseable ip is on neither.
+func (l *IPLists) Match(ip string) (string, netip.Prefix) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return IPListNone, netip.Prefix{}
+	}
+	addr = addr.Unmap().WithZone("")
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, p := range l.deny {
+		if p.Contains(addr) {
+			return IPListDeny, p
+		}
+	}
+	for _, p := range l.allow {
+		if p.Contains(addr) {
+			return IPListAllow, p
+		}
+	}
+	return IPListNone, netip.Prefix{}
+}
+
+// Middleware answers denied IPs with 403 and marks allowed ones for the
+// limiters after it.
+func (l *IPLists) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientIP := GetClientIP(c)
+		switch list, prefix := l.Match(clientIP); list {
+		case IPListDeny:
+			metrics.IPListMatches.WithLabelValues(IPListDeny).Inc()
+			log.Printf("GIN IP DENY: IP %s matched %s on the denylist.", clientIP, prefix)
+			c.String(http.StatusForbidden, T(c, "error.ip_denied"))
+			c.Abort()
+			return
+		case IPListAllow:
+			metrics.IPListMatches.WithLabelValues(IPListAllow).Inc()
+			c.Set(IPAllowedContextKey, true)
+		}
+		c.Next()
+	}
+}
+
+// GetLists answers the current lists.
+func (l *IPLists) GetLists(c *gin.Context) {
+	c.JSON(http.StatusOK, l.Config())
+}
+
+// SetLists replaces the lists on this instance. Other instances keep theirs
+// until they are updated too or restarted with new settings.
+func (l *IPLists) SetLists(c *gin.Context) {
+	var cfg IPListConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload."})
+		return
+	}
+	if err := l.Set(cfg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cfg = l.Config()
+	log.Printf("INFO: IP lists replaced: %d allowed and %d denied ranges.", len(cfg.Allow), len(cfg.Deny))
+	c.JSON(http.StatusOK, cfg)
+}
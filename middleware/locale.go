@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/i18n"
+)
+
+const (
+	catalogContextKey = "i18n_catalog"
+	// LocaleContextKey holds the locale negotiated for the request, once
+	// something has asked for it.
+	LocaleContextKey = "locale"
+)
+
+// Localize makes catalog available to later handlers. The Accept-Language
+// header is only parsed when a response actually needs translated text, so
+// redirects that succeed pay nothing for it.
+func Localize(catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(catalogContextKey, catalog)
+		c.Next()
+	}
+}
+
+// Locale returns the locale for the request's Accept-Language header and
+// marks the response as varying by it.
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(LocaleContextKey); locale != "" {
+		return locale
+	}
+	catalog, ok := c.Value(catalogContextKey).(*i18n.Catalog)
+	if !ok {
+		return i18n.Fallback
+	}
+	locale := catalog.Negotiate(c.GetHeader("Accept-Language"))
+	c.Set(LocaleContextKey, locale)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return locale
+}
+
+// T formats the message for key in the request's locale. Without Localize
+// in the chain it answers with the key, which makes the omission obvious.
+func T(c *gin.Context, key string, args ...any) string {
+	catalog, ok := c.Value(catalogContextKey).(*i18n.Catalog)
+	if !ok {
+		return key
+	}
+	return catalog.T(Locale(c), key, args...)
+}
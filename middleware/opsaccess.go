@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpsAccess guards the probe and scrape endpoints (/livez, /readyz,
+// /metrics) in place of the user-facing auth and rate limits, which they are
+// registered outside of. With neither token nor cidrs set the endpoints are
+// open. Otherwise a request needs the bearer token or a connection from one
+// of the ranges; either is enough. The range is checked against the peer
+// address, never X-Forwarded-For, which any client can set.
+func OpsAccess(token string, cidrs []string) (gin.HandlerFunc, error) {
+	prefixes, err := parsePrefixes(cidrs)
+	if err != nil {
+		return nil, fmt.Errorf("ops endpoint ranges: %w", err)
+	}
+	return func(c *gin.Context) {
+		if token == "" && len(prefixes) == 0 {
+			c.Next()
+			return
+		}
+		if token != "" && subtle.ConstantTimeCompare([]byte(BearerToken(c.Request)), []byte(token)) == 1 {
+			c.Next()
+			return
+		}
+		if peerIn(c.Request, prefixes) {
+			c.Next()
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Abort()
+	}, nil
+}
+
+func peerIn(r *http.Request, prefixes []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	return prefixesContain(prefixes, addr.Unmap().WithZone(""))
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/metrics"
+)
+
+const (
+	// probeWindow is how long an IP's short code lookups are counted together.
+	probeWindow = time.Minute
+	// probeNotFoundShare is the share of an IP's lookups that must be 404s
+	// before it is treated as enumerating codes rather than following links.
+	probeNotFoundShare = 0.8
+	// maxProbeBan caps the doubling ban for repeat offenders.
+	maxProbeBan = 24 * time.Hour
+)
+
+// ProbeLimiterConfig sets when an IP probing for short codes gets banned.
+type ProbeLimiterConfig struct {
+	// NotFoundLimit is the number of short code lookups per minute answered
+	// 404 that trips a ban, provided most of the IP's lookups missed. Zero disables the limiter.
+	NotFoundLimit int
+	// BanDuration is the first ban; each further ban of the same IP doubles it.
+	BanDuration time.Duration
+}
+
+type probeRecord struct {
+	windowEnd   time.Time
+	lookups     int
+	notFound    int
+	bannedUntil time.Time
+	strikes     int
+}
+
+// ProbeLimiter bans IPs whose short code lookups are mostly 404s. It is kept
+// apart from RateLimiter so that scanners are cut off long before
+// the general request limit, while visitors following real links are not
+// affected by it.
+type ProbeLimiter struct {
+	cfg       ProbeLimiterConfig
+	mu        sync.Mutex
+	ips       map[string]*probeRecord
+	nextSweep time.Time
+}
+
+func NewProbeLimiter(cfg ProbeLimiterConfig) *ProbeLimiter {
+	return &ProbeLimiter{cfg: cfg, ips: make(map[string]*probeRecord)}
+}
+
+// Middleware counts the outcome of short code lookups and answers every
+// lookup of a banned IP with 429 before it reaches the handler, so hits and
+// misses look the same to it and cost no database lookup. Its other
+// requests are served as usual; addresses shared by many visitors, such as
+// a NAT, belong on the IP allow list.
+func (p *ProbeLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p.cfg.NotFoundLimit <= 0 || c.GetBool(IPAllowedContextKey) || !probeRoute(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		clientIP := GetClientIP(c)
+		if until, banned := p.bannedUntil(clientIP, time.Now()); banned {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(until).Seconds())+1))
+			c.String(http.StatusTooManyRequests, T(c, "error.probe_limited"))
+			c.Abort()
+			return
+		}
+		c.Next()
+		p.record(clientIP, c.Writer.Status() == http.StatusNotFound, time.Now())
+	}
+}
+
+// probeRoute reports whether the route looks up a short code, so that a 404
+// from it means the code does not exist. Admin routes are left out; their
+// callers are authenticated.
+func probeRoute(path string) bool {
+	return strings.Contains(path, ":code") && !strings.HasPrefix(path, "/admin/")
+}
+
+func (p *ProbeLimiter) bannedUntil(ip string, now time.Time) (time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rec, ok := p.ips[ip]
+	if !ok || !now.Before(rec.bannedUntil) {
+		return time.Time{}, false
+	}
+	return rec.bannedUntil, true
+}
+
+func (p *ProbeLimiter) record(ip string, notFound bool, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sweep(now)
+
+	rec, ok := p.ips[ip]
+	if !ok {
+		rec = &probeRecord{}
+		p.ips[ip] = rec
+	}
+	if now.After(rec.windowEnd) {
+		rec.windowEnd = now.Add(probeWindow)
+		rec.lookups, rec.notFound = 0, 0
+	}
+	rec.lookups++
+	if notFound {
+		rec.notFound++
+	}
+
+	if rec.notFound < p.cfg.NotFoundLimit || float64(rec.notFound) < probeNotFoundShare*float64(rec.lookups) {
+		return
+	}
+	ban := p.cfg.BanDuration
+	for i := 0; i < rec.strikes && ban < maxProbeBan; i++ {
+		ban *= 2
+	}
+	ban = min(ban, maxProbeBan)
+	rec.strikes++
+	rec.bannedUntil = now.Add(ban)
+	metrics.ProbeBans.Inc()
+	log.Printf("WARN: Banned IP %s for %s after %d of %d lookups were not found (ban #%d).", ip, ban, rec.notFound, rec.lookups, rec.strikes)
+	rec.lookups, rec.notFound = 0, 0
+}
+
+// sweep forgets idle IPs, at most once per window. Banned IPs are kept for
+// maxProbeBan after the ban ends so that a scanner coming back the same day
+// gets a longer ban.
+func (p *ProbeLimiter) sweep(now time.Time) {
+	if now.Before(p.nextSweep) {
+		return
+	}
+	p.nextSweep = now.Add(probeWindow)
+	for ip, rec := range p.ips {
+		if now.After(rec.windowEnd) && (rec.strikes == 0 || now.After(rec.bannedUntil.Add(maxProbeBan))) {
+			delete(p.ips, ip)
+		}
+	}
+}
@@ -1,74 +1,98 @@
 package middleware
 
-
 import (
-	"log" 
+	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/AnshulDekate/urlShortener/metrics"
 )
 
 const (
-	MaxRequestsPerIP  = 20
-	WindowDuration    = 60 * time.Second
+	MaxRequestsPerIP = 20
+	WindowDuration   = 60 * time.Second
 )
 
 type ipAccess struct {
-	Count int
+	Count     int
 	WindowEnd time.Time
 }
 
-var rateLimitStore = make(map[string]ipAccess)
-var limitMutex sync.Mutex
+// RateLimiter allows each IP a number of requests per window.
+type RateLimiter struct {
+	limit     int
+	window    time.Duration
+	mu        sync.Mutex
+	ips       map[string]ipAccess
+	nextSweep time.Time
+}
 
-func CheckAndIncrementAccess(ip string) bool {
-	limitMutex.Lock()
-	defer limitMutex.Unlock()
+// NewRateLimiter allows each IP limit requests per window. The server uses
+// MaxRequestsPerIP and WindowDuration.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, ips: make(map[string]ipAccess)}
+}
+
+// Allow counts a request from ip, reporting false when it is over the limit.
+func (l *RateLimiter) Allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
 	now := time.Now()
-	access, exists := rateLimitStore[ip]
+	l.sweep(now)
+	access, exists := l.ips[ip]
 
 	if !exists || now.After(access.WindowEnd) {
-		rateLimitStore[ip] = ipAccess{
-			Count: 1,
-			WindowEnd: now.Add(WindowDuration),
+		l.ips[ip] = ipAccess{
+			Count:     1,
+			WindowEnd: now.Add(l.window),
 		}
-		return true 
+		return true
 	}
 
-	if access.Count < MaxRequestsPerIP {
+	if access.Count < l.limit {
 		access.Count++
-		rateLimitStore[ip] = access
+		l.ips[ip] = access
 		return true
 	}
 
-	return false 
+	return false
 }
 
-func GetClientIP(r *http.Request) string {
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		parts := strings.Split(forwarded, ",")
-		return strings.TrimSpace(parts[0])
+// sweep forgets IPs whose window has ended, at most once per window.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	l.nextSweep = now.Add(l.window)
+	for ip, access := range l.ips {
+		if now.After(access.WindowEnd) {
+			delete(l.ips, ip)
+		}
 	}
-	ip := strings.Split(r.RemoteAddr, ":")[0]
-	return ip
 }
 
-func RateLimiterMiddleware() gin.HandlerFunc {
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	retryAfter := strconv.Itoa(int(l.window.Seconds()))
 	return func(c *gin.Context) {
-		clientIP := GetClientIP(c.Request)
-		
-		if !CheckAndIncrementAccess(clientIP) {
-			c.Header("Retry-After", "60")
-			log.Printf("GIN RATE LIMIT: IP %s exceeded limit of %d requests per %s.", clientIP, MaxRequestsPerIP, WindowDuration)
-			c.String(http.StatusTooManyRequests, "Rate limit exceeded. Try again in 60 seconds.")
-			c.Abort() 
+		if c.GetBool(IPAllowedContextKey) {
+			c.Next()
+			return
+		}
+		clientIP := GetClientIP(c)
+
+		if !l.Allow(clientIP) {
+			metrics.RateLimited.Inc()
+			c.Header("Retry-After", retryAfter)
+			log.Printf("GIN RATE LIMIT: IP %s exceeded limit of %d requests per %s.", clientIP, l.limit, l.window)
+			c.String(http.StatusTooManyRequests, T(c, "error.rate_limited", int(l.window.Seconds())))
+			c.Abort()
 			return
 		}
 
-		c.Next() 
+		c.Next()
 	}
 }
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/errtrack"
+	"github.com/AnshulDekate/urlShortener/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery replaces gin.Recovery. A panicking handler is logged with its
+// stack and reported to tracker, when one is configured, and the client gets
+// a plain 500 that reveals nothing about the panic. Panics from writing to a
+// client that hung up are only logged: they are not bugs.
+func Recovery(tracker errtrack.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				// net/http's way to abort a response; it expects to see it.
+				panic(value)
+			}
+			if err, ok := value.(error); ok && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)) {
+				log.Printf("WARN: Client went away during %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+				c.Abort()
+				return
+			}
+
+			report := errtrack.Report{
+				Value:     value,
+				Stack:     errtrack.Callers(),
+				Method:    c.Request.Method,
+				URL:       requestURL(c.Request),
+				Route:     c.FullPath(),
+				ClientIP:  GetClientIP(c),
+				UserAgent: c.Request.UserAgent(),
+				Actor:     c.GetString(ActorContextKey),
+				Time:      time.Now(),
+			}
+			metrics.Panics.WithLabelValues(report.Route).Inc()
+			log.Printf("ERROR: Panic serving %s %s: %v\n%s", report.Method, c.Request.URL.Path, value, errtrack.FormatStack(report.Stack))
+			if tracker != nil {
+				go func() {
+					ctx, cancel := context.WithTimeout(context.Background(), errtrack.DefaultTimeout)
+					defer cancel()
+					if err := tracker.CapturePanic(ctx, report); err != nil {
+						log.Printf("ERROR: Failed to report panic: %v", err)
+					}
+				}()
+			}
+
+			if c.Writer.Written() {
+				// The status line is out; the client sees a truncated body.
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}()
+		c.Next()
+	}
+}
+
+// requestURL is the URL the client asked for, without the query string.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+)
+
+// LatestVersion is the newest migration version among the .sql files in
+// fsys, i.e. the schema a build embedding them was written against.
+func LatestVersion(fsys fs.FS) (int64, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, name := range names {
+		v, err := goose.NumericComponent(name)
+		if err != nil {
+			return 0, fmt.Errorf("bad migration file name %s: %w", name, err)
+		}
+		latest = max(latest, v)
+	}
+	return latest, nil
+}
+
+// CheckCompatibility compares the database's schema version with the
+// version this build expects. An older schema means migrations did not run,
+// e.g. because MIGRATIONS_PATH is from another release. A newer one means
+// this is an old build starting after a newer release migrated, as happens
+// when a blue instance restarts mid-deploy; that is refused unless
+// allowNewer, since the build cannot know whether the newer schema still
+// supports its queries.
+func CheckCompatibility(ctx context.Context, db *sql.DB, expected int64, allowNewer bool) error {
+	current, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	switch {
+	case current < expected:
+		return fmt.Errorf("database schema is at version %d but this build needs %d; check that MIGRATIONS_PATH holds the migrations of this release", current, expected)
+	case current > expected && !allowNewer:
+		return fmt.Errorf("database schema is at version %d, newer than the %d this build knows; a newer release has migrated it. Deploy that release, or set SCHEMA_ALLOW_NEWER=true if its migrations are backward compatible", current, expected)
+	}
+	return nil
+}
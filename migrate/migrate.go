@@ -0,0 +1,248 @@
+// Package migrate runs the goose migrations with guardrails for shared
+// databases: one instance migrates at a time, long-running transactions
+// that would queue behind a migration's locks stop it before it starts, and
+// migrations that drop or rewrite data need an explicit override.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// lockKey is the pg_advisory_lock key held while migrating. Every instance
+// of the service uses the same key, whatever database it points at.
+const lockKey int64 = 0x75726c73686f7274 // "urlshort"
+
+// Options control Up. Zero values disable the corresponding guardrail.
+type Options struct {
+	Dir string
+	// LockTimeout is how long to wait for another instance's migration to
+	// finish before giving up.
+	LockTimeout time.Duration
+	// MaxTransactionAge refuses to migrate while another session has had a
+	// transaction open for longer. ALTER TABLE waits for such transactions,
+	// and every query on the table waits behind the ALTER.
+	MaxTransactionAge time.Duration
+	// AllowDestructive lets migrations that drop, truncate, delete, rename
+	// or retype run.
+	AllowDestructive bool
+}
+
+// Pending is a migration not yet applied. Destructive lists the statements
+// that made it count as destructive.
+type Pending struct {
+	Version     int64
+	Source      string
+	Destructive []string
+}
+
+// Transaction is another session's open transaction.
+type Transaction struct {
+	PID   int
+	User  string
+	State string
+	Age   time.Duration
+	Query string
+}
+
+// destructivePatterns match statements that lose data or break the queries
+// of instances still running the previous release.
+var destructivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)\bDROP\s+(TABLE|COLUMN|SCHEMA)\b`),
+	regexp.MustCompile(`(?is)\bTRUNCATE\b`),
+	regexp.MustCompile(`(?is)\bDELETE\s+FROM\b`),
+	regexp.MustCompile(`(?is)\bALTER\s+COLUMN\s+\S+\s+(SET\s+DATA\s+)?TYPE\b`),
+	regexp.MustCompile(`(?is)\bRENAME\s+(TO|COLUMN)\b`),
+}
+
+var lineComment = regexp.MustCompile(`--[^\n]*`)
+
+// Plan lists the migrations in dir that the database has not applied yet.
+// On a database without any applied migration nothing is flagged
+// destructive, since there is nothing to lose.
+func Plan(ctx context.Context, db *sql.DB, dir string) ([]Pending, error) {
+	if err := goose.SetDialect("postgres"); err != nil {
+		return nil, fmt.Errorf("failed to set Goose dialect: %w", err)
+	}
+	current, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		if errors.Is(err, goose.ErrNoMigrationFiles) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+
+	var pending []Pending
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		p := Pending{Version: m.Version, Source: m.Source}
+		if current > 0 && strings.HasSuffix(m.Source, ".sql") {
+			if p.Destructive, err = destructiveStatements(m.Source); err != nil {
+				return nil, err
+			}
+		}
+		pending = append(pending, p)
+	}
+	return pending, nil
+}
+
+// destructiveStatements returns the matches of destructivePatterns in the
+// Up section of a SQL migration.
+func destructiveStatements(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration: %w", err)
+	}
+	up := string(b)
+	if i := strings.Index(up, "-- +goose Down"); i >= 0 {
+		up = up[:i]
+	}
+	up = lineComment.ReplaceAllString(up, "")
+
+	var found []string
+	for _, re := range destructivePatterns {
+		for _, m := range re.FindAllString(up, -1) {
+			found = append(found, strings.Join(strings.Fields(m), " "))
+		}
+	}
+	return found, nil
+}
+
+// LongTransactions lists other sessions on the database whose transaction
+// has been open for longer than maxAge.
+func LongTransactions(ctx context.Context, db *sql.DB, maxAge time.Duration) ([]Transaction, error) {
+	const query = `
+	SELECT pid, COALESCE(usename, ''), COALESCE(state, ''),
+		EXTRACT(EPOCH FROM NOW() - xact_start)::FLOAT8, LEFT(COALESCE(query, ''), 200)
+	FROM pg_stat_activity
+	WHERE datname = current_database()
+		AND pid <> pg_backend_pid()
+		AND backend_type = 'client backend'
+		AND xact_start < NOW() - $1 * INTERVAL '1 second'
+	ORDER BY xact_start
+	`
+	rows, err := db.QueryContext(ctx, query, maxAge.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var txs []Transaction
+	for rows.Next() {
+		var t Transaction
+		var age float64
+		if err := rows.Scan(&t.PID, &t.User, &t.State, &age, &t.Query); err != nil {
+			return nil, fmt.Errorf("failed to scan open transaction: %w", err)
+		}
+		t.Age = time.Duration(age * float64(time.Second)).Round(time.Second)
+		txs = append(txs, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during open transaction iteration: %w", err)
+	}
+	return txs, nil
+}
+
+// Up applies the pending migrations in opts.Dir while holding the
+// migration lock. The guardrails are checked after the lock is taken, so
+// they see the schema the migrations will actually run against.
+func Up(ctx context.Context, db *sql.DB, opts Options) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reserve a connection for the migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if err := acquireLock(ctx, conn, opts.LockTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			log.Printf("WARN: Failed to release migration lock: %v", err)
+		}
+	}()
+
+	pending, err := Plan(ctx, db, opts.Dir)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		log.Println("INFO: Schema is up to date.")
+		return nil
+	}
+
+	if !opts.AllowDestructive {
+		var refused []string
+		for _, p := range pending {
+			if len(p.Destructive) > 0 {
+				refused = append(refused, fmt.Sprintf("%d (%s)", p.Version, strings.Join(p.Destructive, "; ")))
+			}
+		}
+		if len(refused) > 0 {
+			return fmt.Errorf("refusing destructive migrations %s; set MIGRATIONS_ALLOW_DESTRUCTIVE=true once old instances are drained and a backup exists", strings.Join(refused, ", "))
+		}
+	}
+
+	if opts.MaxTransactionAge > 0 {
+		txs, err := LongTransactions(ctx, db, opts.MaxTransactionAge)
+		if err != nil {
+			return err
+		}
+		if len(txs) > 0 {
+			for _, t := range txs {
+				log.Printf("WARN: Transaction open for %s blocks migrations: pid %d, user %q, state %q, query %q", t.Age, t.PID, t.User, t.State, t.Query)
+			}
+			return fmt.Errorf("%d transactions have been open longer than %s; end them or raise MIGRATIONS_MAX_TX_AGE", len(txs), opts.MaxTransactionAge)
+		}
+	}
+
+	log.Printf("INFO: Applying %d migrations.", len(pending))
+	if err := goose.UpContext(ctx, db, opts.Dir); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+// acquireLock polls pg_try_advisory_lock rather than blocking in
+// pg_advisory_lock, so a wedged migration elsewhere turns into a startup
+// error after timeout instead of a hang.
+func acquireLock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	logged := false
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, lockKey).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to take migration lock: %w", err)
+		}
+		if locked {
+			return nil
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("another instance held the migration lock for over %s", timeout)
+		}
+		if !logged {
+			log.Println("INFO: Another instance is migrating; waiting for it to finish.")
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
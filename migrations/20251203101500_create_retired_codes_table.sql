@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE retired_codes (
+    short_url VARCHAR(10) PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    retired_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    grace_until TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_retired_codes_url_id ON retired_codes (url_id);
+
+-- +goose Down
+DROP TABLE retired_codes;
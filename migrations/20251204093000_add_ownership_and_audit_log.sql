@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_workspace_slug UNIQUE (slug)
+);
+
+ALTER TABLE urls
+    ADD COLUMN owner TEXT DEFAULT NULL,
+    ADD COLUMN workspace_id BIGINT DEFAULT NULL REFERENCES workspaces (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_urls_workspace_id ON urls (workspace_id);
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at DESC);
+
+-- +goose Down
+DROP TABLE audit_log;
+DROP INDEX idx_urls_workspace_id;
+ALTER TABLE urls DROP COLUMN workspace_id, DROP COLUMN owner;
+DROP TABLE workspaces;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    target_url TEXT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_webhook_url_id UNIQUE (url_id)
+);
+
+-- +goose Down
+DROP TABLE webhooks;
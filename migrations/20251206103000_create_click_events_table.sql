@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE click_events (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    event_type VARCHAR(16) NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    referer TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_click_events_url_id_occurred_at ON click_events (url_id, occurred_at DESC);
+
+-- +goose Down
+DROP TABLE click_events;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE link_utm (
+    url_id BIGINT PRIMARY KEY REFERENCES urls (id) ON DELETE CASCADE,
+    utm_source TEXT NOT NULL,
+    utm_medium TEXT NOT NULL,
+    utm_campaign TEXT NOT NULL,
+    utm_term TEXT NOT NULL DEFAULT '',
+    utm_content TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_link_utm_campaign ON link_utm (utm_campaign);
+CREATE INDEX idx_link_utm_source_medium ON link_utm (utm_source, utm_medium);
+
+-- +goose Down
+DROP TABLE link_utm;
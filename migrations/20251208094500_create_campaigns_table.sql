@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_campaign_name UNIQUE (name)
+);
+
+ALTER TABLE urls ADD COLUMN campaign_id BIGINT DEFAULT NULL REFERENCES campaigns (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_urls_campaign_id ON urls (campaign_id);
+
+-- +goose Down
+DROP INDEX idx_urls_campaign_id;
+ALTER TABLE urls DROP COLUMN campaign_id;
+DROP TABLE campaigns;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE report_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    frequency VARCHAR(16) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    next_run_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_report_schedule_name UNIQUE (name)
+);
+
+CREATE INDEX idx_report_schedules_next_run_at ON report_schedules (next_run_at);
+
+-- +goose Down
+DROP TABLE report_schedules;
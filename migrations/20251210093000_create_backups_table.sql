@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE backups (
+    id BIGSERIAL PRIMARY KEY,
+    storage_key TEXT NOT NULL,
+    row_count BIGINT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE backups;
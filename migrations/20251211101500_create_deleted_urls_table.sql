@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE deleted_urls (
+    id BIGINT PRIMARY KEY,
+    long_url TEXT NOT NULL,
+    short_url VARCHAR(10) NOT NULL,
+    click_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    last_accessed_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+    owner TEXT DEFAULT NULL,
+    workspace_id BIGINT DEFAULT NULL,
+    campaign_id BIGINT DEFAULT NULL,
+    deleted_by TEXT NOT NULL,
+    deleted_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_deleted_urls_deleted_at ON deleted_urls (deleted_at DESC);
+CREATE INDEX idx_deleted_urls_short_url ON deleted_urls (short_url);
+
+-- +goose Down
+DROP TABLE deleted_urls;
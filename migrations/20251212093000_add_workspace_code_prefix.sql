@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE workspaces
+    ADD COLUMN code_prefix VARCHAR(13) DEFAULT NULL,
+    ADD CONSTRAINT unique_workspace_code_prefix UNIQUE (code_prefix);
+
+ALTER TABLE urls ALTER COLUMN short_url TYPE VARCHAR(24);
+ALTER TABLE retired_codes ALTER COLUMN short_url TYPE VARCHAR(24);
+ALTER TABLE deleted_urls ALTER COLUMN short_url TYPE VARCHAR(24);
+
+-- +goose Down
+ALTER TABLE deleted_urls ALTER COLUMN short_url TYPE VARCHAR(10);
+ALTER TABLE retired_codes ALTER COLUMN short_url TYPE VARCHAR(10);
+ALTER TABLE urls ALTER COLUMN short_url TYPE VARCHAR(10);
+
+ALTER TABLE workspaces DROP CONSTRAINT unique_workspace_code_prefix, DROP COLUMN code_prefix;
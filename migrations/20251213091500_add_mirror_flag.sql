@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE urls ADD COLUMN mirror BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE deleted_urls ADD COLUMN mirror BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE deleted_urls DROP COLUMN mirror;
+ALTER TABLE urls DROP COLUMN mirror;
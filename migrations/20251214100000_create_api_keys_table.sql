@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+
+    CONSTRAINT unique_api_key_name UNIQUE (name),
+    CONSTRAINT unique_api_key_hash UNIQUE (key_hash)
+);
+
+-- +goose Down
+DROP TABLE api_keys;
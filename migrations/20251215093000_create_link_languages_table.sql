@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE link_languages (
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    lang VARCHAR(16) NOT NULL,
+    destination TEXT NOT NULL,
+
+    PRIMARY KEY (url_id, lang)
+);
+
+-- +goose Down
+DROP TABLE link_languages;
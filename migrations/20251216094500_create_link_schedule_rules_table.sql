@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE link_schedule_rules (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    days SMALLINT NOT NULL,
+    start_minute SMALLINT NOT NULL,
+    end_minute SMALLINT NOT NULL,
+    timezone TEXT NOT NULL,
+    destination TEXT NOT NULL,
+
+    CONSTRAINT unique_schedule_rule_position UNIQUE (url_id, position)
+);
+
+-- +goose Down
+DROP TABLE link_schedule_rules;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE link_splits (
+    url_id BIGINT PRIMARY KEY REFERENCES urls (id) ON DELETE CASCADE,
+    destination TEXT NOT NULL,
+    percent SMALLINT NOT NULL CHECK (percent BETWEEN 0 AND 100),
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE click_events ADD COLUMN variant VARCHAR(16) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE click_events DROP COLUMN variant;
+DROP TABLE link_splits;
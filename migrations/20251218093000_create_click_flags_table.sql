@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE click_events ADD COLUMN flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_click_events_occurred_at ON click_events (occurred_at);
+
+CREATE TABLE click_flags (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    ip TEXT NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    flagged_clicks INTEGER NOT NULL DEFAULT 0,
+    details JSONB NOT NULL DEFAULT '{}',
+    first_detected_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    last_detected_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_click_flag UNIQUE (url_id, ip, reason)
+);
+
+CREATE INDEX idx_click_flags_last_detected_at ON click_flags (last_detected_at DESC);
+
+-- +goose Down
+DROP TABLE click_flags;
+DROP INDEX idx_click_events_occurred_at;
+ALTER TABLE click_events DROP COLUMN flagged;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE click_events ADD COLUMN asn BIGINT;
+ALTER TABLE click_events ADD COLUMN as_org TEXT;
+
+CREATE INDEX idx_click_events_url_asn ON click_events (url_id, asn);
+
+-- +goose Down
+DROP INDEX idx_click_events_url_asn;
+ALTER TABLE click_events DROP COLUMN as_org;
+ALTER TABLE click_events DROP COLUMN asn;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Clicks recorded before this migration keep a NULL device and are classified
+-- from their stored user agent when read.
+ALTER TABLE click_events ADD COLUMN browser VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE click_events ADD COLUMN os VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE click_events ADD COLUMN device VARCHAR(16);
+
+-- +goose Down
+ALTER TABLE click_events DROP COLUMN device;
+ALTER TABLE click_events DROP COLUMN os;
+ALTER TABLE click_events DROP COLUMN browser;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE urls ADD COLUMN sample_rate REAL NOT NULL DEFAULT 1
+    CHECK (sample_rate > 0 AND sample_rate <= 1);
+
+-- Rate in effect when each event was kept; estimates weight a row by its inverse.
+ALTER TABLE click_events ADD COLUMN sample_rate REAL NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE click_events DROP COLUMN sample_rate;
+ALTER TABLE urls DROP COLUMN sample_rate;
@@ -0,0 +1,96 @@
+-- +goose Up
+-- Rebuild click_events as a table range-partitioned by month on occurred_at.
+-- Rows outside any monthly partition land in click_events_default; the
+-- partition maintenance job moves them out when it creates their month.
+ALTER TABLE click_events RENAME TO click_events_legacy;
+ALTER TABLE click_events_legacy RENAME CONSTRAINT click_events_pkey TO click_events_legacy_pkey;
+ALTER SEQUENCE click_events_id_seq RENAME TO click_events_legacy_id_seq;
+
+CREATE TABLE click_events (
+    id BIGSERIAL,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    event_type VARCHAR(16) NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    referer TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    variant VARCHAR(16) NOT NULL DEFAULT '',
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    asn BIGINT,
+    as_org TEXT,
+    browser VARCHAR(64) NOT NULL DEFAULT '',
+    os VARCHAR(64) NOT NULL DEFAULT '',
+    device VARCHAR(16),
+    sample_rate REAL NOT NULL DEFAULT 1,
+
+    PRIMARY KEY (id, occurred_at)
+) PARTITION BY RANGE (occurred_at);
+
+CREATE TABLE click_events_default PARTITION OF click_events DEFAULT;
+
+-- +goose StatementBegin
+DO $$
+DECLARE
+    m TIMESTAMP := date_trunc('month', COALESCE((SELECT MIN(occurred_at) FROM click_events_legacy), NOW()));
+    last_month TIMESTAMP := date_trunc('month', NOW()) + INTERVAL '2 months';
+BEGIN
+    WHILE m <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF click_events FOR VALUES FROM (%L) TO (%L)',
+            'click_events_p' || to_char(m, 'YYYY_MM'), m, m + INTERVAL '1 month'
+        );
+        m := m + INTERVAL '1 month';
+    END LOOP;
+END
+$$;
+-- +goose StatementEnd
+
+INSERT INTO click_events (id, url_id, event_type, ip, user_agent, referer, occurred_at, variant, flagged, asn, as_org, browser, os, device, sample_rate)
+SELECT id, url_id, event_type, ip, user_agent, referer, occurred_at, variant, flagged, asn, as_org, browser, os, device, sample_rate
+FROM click_events_legacy;
+
+SELECT setval(pg_get_serial_sequence('click_events', 'id'), GREATEST(COALESCE(MAX(id), 0), 1), COALESCE(MAX(id), 0) > 0)
+FROM click_events;
+
+DROP TABLE click_events_legacy;
+
+CREATE INDEX idx_click_events_url_id_occurred_at ON click_events (url_id, occurred_at DESC);
+CREATE INDEX idx_click_events_occurred_at ON click_events (occurred_at);
+CREATE INDEX idx_click_events_url_asn ON click_events (url_id, asn);
+
+-- +goose Down
+ALTER TABLE click_events RENAME TO click_events_partitioned;
+ALTER TABLE click_events_partitioned RENAME CONSTRAINT click_events_pkey TO click_events_partitioned_pkey;
+ALTER SEQUENCE click_events_id_seq RENAME TO click_events_partitioned_id_seq;
+ALTER INDEX idx_click_events_url_id_occurred_at RENAME TO idx_click_events_partitioned_url_id_occurred_at;
+ALTER INDEX idx_click_events_occurred_at RENAME TO idx_click_events_partitioned_occurred_at;
+ALTER INDEX idx_click_events_url_asn RENAME TO idx_click_events_partitioned_url_asn;
+
+CREATE TABLE click_events (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    event_type VARCHAR(16) NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    referer TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    variant VARCHAR(16) NOT NULL DEFAULT '',
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    asn BIGINT,
+    as_org TEXT,
+    browser VARCHAR(64) NOT NULL DEFAULT '',
+    os VARCHAR(64) NOT NULL DEFAULT '',
+    device VARCHAR(16),
+    sample_rate REAL NOT NULL DEFAULT 1
+);
+
+INSERT INTO click_events SELECT * FROM click_events_partitioned;
+
+SELECT setval(pg_get_serial_sequence('click_events', 'id'), GREATEST(COALESCE(MAX(id), 0), 1), COALESCE(MAX(id), 0) > 0)
+FROM click_events;
+
+DROP TABLE click_events_partitioned;
+
+CREATE INDEX idx_click_events_url_id_occurred_at ON click_events (url_id, occurred_at DESC);
+CREATE INDEX idx_click_events_occurred_at ON click_events (occurred_at);
+CREATE INDEX idx_click_events_url_asn ON click_events (url_id, asn);
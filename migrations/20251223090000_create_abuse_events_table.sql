@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE abuse_events (
+    id BIGSERIAL PRIMARY KEY,
+    ip TEXT NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    domain TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_abuse_events_created_at ON abuse_events (created_at DESC);
+
+-- +goose Down
+DROP TABLE abuse_events;
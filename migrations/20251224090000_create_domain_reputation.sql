@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE domain_reputation (
+    domain TEXT PRIMARY KEY,
+    reports INTEGER NOT NULL DEFAULT 0,
+    blocklist_hits INTEGER NOT NULL DEFAULT 0,
+    health_failures INTEGER NOT NULL DEFAULT 0,
+    score_override INTEGER CHECK (score_override BETWEEN 0 AND 100),
+    override_note TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE urls ADD COLUMN pending_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_urls_pending_review ON urls (created_at) WHERE pending_review;
+
+-- +goose Down
+DROP INDEX idx_urls_pending_review;
+ALTER TABLE urls DROP COLUMN pending_review;
+DROP TABLE domain_reputation;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE link_health (
+    url_id BIGINT PRIMARY KEY REFERENCES urls (id) ON DELETE CASCADE,
+    checked_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    dead_since TIMESTAMP WITHOUT TIME ZONE,
+    archive_url TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_link_health_checked_at ON link_health (checked_at);
+
+-- +goose Down
+DROP TABLE link_health;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE link_screenshots (
+    url_id BIGINT PRIMARY KEY REFERENCES urls (id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL DEFAULT '',
+    content_type TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    captured_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+-- +goose Down
+DROP TABLE link_screenshots;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE link_content (
+    url_id BIGINT PRIMARY KEY REFERENCES urls (id) ON DELETE CASCADE,
+    content_type TEXT NOT NULL DEFAULT '',
+    content_length BIGINT,
+    download BOOLEAN NOT NULL DEFAULT FALSE,
+    inspected_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE link_content;
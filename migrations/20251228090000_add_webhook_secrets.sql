@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE webhooks ADD COLUMN secret TEXT NOT NULL DEFAULT '';
+
+-- Existing endpoints get a secret too; gen_random_uuid() is cryptographically
+-- random. Receivers read it from the admin API before verifying signatures.
+UPDATE webhooks SET secret = 'whsec_' || replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', '');
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN secret;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Each region only ever writes its own rows, so counters replicated between
+-- active-active regions merge without conflicts. urls.click_count is kept as
+-- the local running total; the sum of these rows is the merged one.
+CREATE TABLE regional_click_counts (
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    region VARCHAR(32) NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (url_id, region)
+);
+
+-- Clicks counted before regions existed are carried over under region ''.
+INSERT INTO regional_click_counts (url_id, region, clicks)
+SELECT id, '', click_count FROM urls WHERE click_count > 0;
+
+-- +goose Down
+DROP TABLE regional_click_counts;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Each export covers [from_time, to_time) of one stream; the next export of
+-- the stream starts where the newest one ended.
+CREATE TABLE change_exports (
+    id BIGSERIAL PRIMARY KEY,
+    stream TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    from_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    to_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    row_count BIGINT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_change_exports_stream ON change_exports (stream, to_time DESC);
+
+-- +goose Down
+DROP TABLE change_exports;
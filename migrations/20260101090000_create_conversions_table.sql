@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE urls ADD COLUMN track_conversions BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One row per converted click. Click IDs are signed and carry the link and
+-- click time, so clicks themselves need no row to be converted later.
+CREATE TABLE conversions (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    click_id VARCHAR(64) NOT NULL UNIQUE,
+    clicked_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    value NUMERIC(18, 4),
+    reported_by TEXT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_conversions_url_id ON conversions (url_id, created_at);
+
+-- +goose Down
+DROP TABLE conversions;
+ALTER TABLE urls DROP COLUMN track_conversions;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- A bundle is a shareable list of links under its own code, served at /b/<code>.
+CREATE TABLE bundles (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    view_count BIGINT NOT NULL DEFAULT 0,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    last_viewed_at TIMESTAMP WITHOUT TIME ZONE,
+
+    CONSTRAINT unique_bundle_code UNIQUE (code)
+);
+
+CREATE TABLE bundle_links (
+    bundle_id BIGINT NOT NULL REFERENCES bundles (id) ON DELETE CASCADE,
+    url_id BIGINT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (bundle_id, url_id)
+);
+
+CREATE INDEX idx_bundle_links_url_id ON bundle_links (url_id);
+
+-- +goose Down
+DROP TABLE bundle_links;
+DROP TABLE bundles;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Templates and logos workspaces brand their public pages with.
+CREATE TABLE workspace_pages (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    page VARCHAR(32) NOT NULL,
+    template TEXT NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, page)
+);
+
+CREATE TABLE workspace_logos (
+    workspace_id BIGINT PRIMARY KEY REFERENCES workspaces (id) ON DELETE CASCADE,
+    content_type VARCHAR(64) NOT NULL,
+    data BYTEA NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE workspace_logos;
+DROP TABLE workspace_pages;
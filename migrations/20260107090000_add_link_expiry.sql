@@ -0,0 +1,13 @@
+-- +goose Up
+-- Links stop redirecting at expires_at. Owners are warned once before then,
+-- no earlier than expiry_notify_after when they snoozed the warning.
+ALTER TABLE urls
+    ADD COLUMN expires_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+    ADD COLUMN expiry_notify_after TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+    ADD COLUMN expiry_notified_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL;
+
+CREATE INDEX idx_urls_expires_at ON urls (expires_at) WHERE expires_at IS NOT NULL AND expiry_notified_at IS NULL;
+
+-- +goose Down
+DROP INDEX idx_urls_expires_at;
+ALTER TABLE urls DROP COLUMN expiry_notified_at, DROP COLUMN expiry_notify_after, DROP COLUMN expires_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Keys are stored only as hashes, which cannot verify an HMAC. Keys that sign
+-- their requests get a separate secret the server can read back; NULL means
+-- the key authenticates with the bearer header only.
+ALTER TABLE api_keys ADD COLUMN signing_secret TEXT DEFAULT NULL;
+
+-- Nonces of accepted signed requests, shared by all instances so a captured
+-- request cannot be replayed against another one. Rows are only needed while
+-- their timestamp is within the signature tolerance and are pruned after.
+CREATE TABLE api_request_nonces (
+    key_name TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    seen_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (key_name, nonce)
+);
+
+CREATE INDEX idx_api_request_nonces_seen_at ON api_request_nonces (seen_at);
+
+-- +goose Down
+DROP TABLE api_request_nonces;
+ALTER TABLE api_keys DROP COLUMN signing_secret;
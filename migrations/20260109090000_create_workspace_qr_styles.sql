@@ -0,0 +1,13 @@
+-- +goose Up
+-- Defaults for the QR codes of a workspace's links.
+CREATE TABLE workspace_qr_styles (
+    workspace_id BIGINT PRIMARY KEY REFERENCES workspaces (id) ON DELETE CASCADE,
+    foreground VARCHAR(7) NOT NULL,
+    background VARCHAR(7) NOT NULL,
+    size INTEGER NOT NULL,
+    logo BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE workspace_qr_styles;
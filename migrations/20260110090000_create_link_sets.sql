@@ -0,0 +1,23 @@
+-- +goose Up
+-- Links created together that share tags, expiry, campaign and workspace.
+-- The shared values are also set on each link, so expiry and campaign
+-- features treat them like any other link.
+CREATE TABLE link_sets (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+    campaign_id BIGINT DEFAULT NULL REFERENCES campaigns (id) ON DELETE SET NULL,
+    workspace_id BIGINT DEFAULT NULL REFERENCES workspaces (id) ON DELETE SET NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE urls ADD COLUMN link_set_id BIGINT DEFAULT NULL REFERENCES link_sets (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_urls_link_set_id ON urls (link_set_id) WHERE link_set_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_urls_link_set_id;
+ALTER TABLE urls DROP COLUMN link_set_id;
+DROP TABLE link_sets;
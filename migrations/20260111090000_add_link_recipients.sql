@@ -0,0 +1,9 @@
+-- +goose Up
+-- Links of a set issued to one recipient each, for personalized mailings.
+ALTER TABLE urls ADD COLUMN recipient TEXT DEFAULT NULL;
+
+CREATE UNIQUE INDEX idx_urls_link_set_recipient ON urls (link_set_id, recipient) WHERE recipient IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_urls_link_set_recipient;
+ALTER TABLE urls DROP COLUMN recipient;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Integrators' own key/value references on links, such as order IDs.
+ALTER TABLE urls ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE deleted_urls ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+-- Serves the containment filter of listings (metadata @> '{"key": "value"}').
+CREATE INDEX idx_urls_metadata ON urls USING GIN (metadata jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX idx_urls_metadata;
+ALTER TABLE deleted_urls DROP COLUMN metadata;
+ALTER TABLE urls DROP COLUMN metadata;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Plans of sampled repository queries, for spotting plan regressions as data
+-- grows. Arguments are not stored, since they hold users' URLs.
+CREATE TABLE query_plans (
+    id BIGSERIAL PRIMARY KEY,
+    query TEXT NOT NULL,
+    plan TEXT NOT NULL,
+    analyzed BOOLEAN NOT NULL,
+    duration_ms DOUBLE PRECISION NOT NULL,
+    sampled_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_query_plans_sampled_at ON query_plans (sampled_at DESC);
+
+-- +goose Down
+DROP TABLE query_plans;
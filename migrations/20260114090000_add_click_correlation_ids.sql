@@ -0,0 +1,7 @@
+-- +goose Up
+-- The correlation ID of the request that recorded the event, also sent in
+-- its webhook payload. Events recorded before this migration have none.
+ALTER TABLE click_events ADD COLUMN correlation_id VARCHAR(128);
+
+-- +goose Down
+ALTER TABLE click_events DROP COLUMN correlation_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- The alphabet and length of codes generated for a workspace's links. Empty
+-- and 0 fall back to base62 and the deployment's code length.
+ALTER TABLE workspaces
+    ADD COLUMN code_alphabet VARCHAR(62) NOT NULL DEFAULT '',
+    ADD COLUMN code_length SMALLINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE workspaces DROP COLUMN code_length, DROP COLUMN code_alphabet;
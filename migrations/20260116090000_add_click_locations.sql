@@ -0,0 +1,9 @@
+-- +goose Up
+-- Where a click came from, looked up in the city database and rounded when
+-- recorded. Clicks without a city database or a known location have none.
+ALTER TABLE click_events ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE click_events ADD COLUMN longitude DOUBLE PRECISION;
+
+-- +goose Down
+ALTER TABLE click_events DROP COLUMN longitude;
+ALTER TABLE click_events DROP COLUMN latitude;
@@ -0,0 +1,71 @@
+-- +goose Up
+-- Deleted links stay in urls, marked by deleted_at, so their clicks,
+-- webhooks and settings survive until the link is purged instead of being
+-- cascaded away on delete.
+ALTER TABLE urls ADD COLUMN deleted_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL;
+ALTER TABLE urls ADD COLUMN deleted_by TEXT DEFAULT NULL;
+
+-- Only live links need distinct codes and destinations: a deleted link's
+-- code can be reissued, and its destination shortened again.
+ALTER TABLE urls DROP CONSTRAINT unique_long_url;
+ALTER TABLE urls DROP CONSTRAINT unique_short_url;
+CREATE UNIQUE INDEX unique_long_url ON urls (long_url) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX unique_short_url ON urls (short_url) WHERE deleted_at IS NULL;
+CREATE INDEX idx_urls_deleted_at ON urls (deleted_at DESC) WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_urls_link_set_recipient;
+CREATE UNIQUE INDEX idx_urls_link_set_recipient ON urls (link_set_id, recipient) WHERE recipient IS NOT NULL AND deleted_at IS NULL;
+
+-- Links archived before this migration lost their related rows on delete;
+-- they move over as they are.
+INSERT INTO urls (id, long_url, short_url, click_count, created_at, updated_at, last_accessed_at, owner,
+    workspace_id, campaign_id, mirror, metadata, deleted_by, deleted_at)
+SELECT d.id, d.long_url, d.short_url, d.click_count, d.created_at, d.updated_at, d.last_accessed_at, d.owner,
+    (SELECT w.id FROM workspaces w WHERE w.id = d.workspace_id),
+    (SELECT c.id FROM campaigns c WHERE c.id = d.campaign_id),
+    d.mirror, d.metadata, d.deleted_by, d.deleted_at
+FROM deleted_urls d;
+
+DROP TABLE deleted_urls;
+
+-- +goose Down
+CREATE TABLE deleted_urls (
+    id BIGINT PRIMARY KEY,
+    long_url TEXT NOT NULL,
+    short_url VARCHAR(24) NOT NULL,
+    click_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    last_accessed_at TIMESTAMP WITHOUT TIME ZONE DEFAULT NULL,
+    owner TEXT DEFAULT NULL,
+    workspace_id BIGINT DEFAULT NULL,
+    campaign_id BIGINT DEFAULT NULL,
+    deleted_by TEXT NOT NULL,
+    deleted_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    mirror BOOLEAN NOT NULL DEFAULT FALSE,
+    metadata JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_deleted_urls_deleted_at ON deleted_urls (deleted_at DESC);
+CREATE INDEX idx_deleted_urls_short_url ON deleted_urls (short_url);
+
+INSERT INTO deleted_urls (id, long_url, short_url, click_count, created_at, updated_at, last_accessed_at, owner,
+    workspace_id, campaign_id, deleted_by, deleted_at, mirror, metadata)
+SELECT id, long_url, short_url, click_count, created_at, updated_at, last_accessed_at, owner,
+    workspace_id, campaign_id, COALESCE(deleted_by, ''), deleted_at, mirror, metadata
+FROM urls
+WHERE deleted_at IS NOT NULL;
+
+DELETE FROM urls WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_urls_link_set_recipient;
+CREATE UNIQUE INDEX idx_urls_link_set_recipient ON urls (link_set_id, recipient) WHERE recipient IS NOT NULL;
+
+DROP INDEX idx_urls_deleted_at;
+DROP INDEX unique_short_url;
+DROP INDEX unique_long_url;
+ALTER TABLE urls ADD CONSTRAINT unique_long_url UNIQUE (long_url);
+ALTER TABLE urls ADD CONSTRAINT unique_short_url UNIQUE (short_url);
+
+ALTER TABLE urls DROP COLUMN deleted_by;
+ALTER TABLE urls DROP COLUMN deleted_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- When a domain's signals were last halved; the decay-domain-signals job
+-- halves them again once DOMAIN_SIGNAL_HALF_LIFE has passed.
+ALTER TABLE domain_reputation ADD COLUMN decayed_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW();
+
+-- +goose Down
+ALTER TABLE domain_reputation DROP COLUMN decayed_at;
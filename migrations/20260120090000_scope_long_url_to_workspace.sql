@@ -0,0 +1,10 @@
+-- +goose Up
+-- A destination is shortened once per workspace, not once per deployment,
+-- so shortening it into one workspace never hands back another tenant's
+-- link. Links outside a workspace share one scope.
+DROP INDEX unique_long_url;
+CREATE UNIQUE INDEX unique_long_url ON urls (COALESCE(workspace_id, 0), long_url) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX unique_long_url;
+CREATE UNIQUE INDEX unique_long_url ON urls (long_url) WHERE deleted_at IS NULL;
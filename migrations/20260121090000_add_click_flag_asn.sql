@@ -0,0 +1,14 @@
+-- +goose Up
+-- Flags raised for a whole network carry its ASN and an empty ip; flags for
+-- one IP keep asn 0.
+ALTER TABLE click_flags ADD COLUMN asn BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE click_flags DROP CONSTRAINT unique_click_flag;
+ALTER TABLE click_flags ADD CONSTRAINT unique_click_flag UNIQUE (url_id, ip, asn, reason);
+
+-- +goose Down
+DELETE FROM click_flags WHERE asn <> 0;
+UPDATE click_events e SET flagged = FALSE
+WHERE e.flagged AND NOT EXISTS (SELECT 1 FROM click_flags f WHERE f.url_id = e.url_id AND f.ip = e.ip);
+ALTER TABLE click_flags DROP CONSTRAINT unique_click_flag;
+ALTER TABLE click_flags ADD CONSTRAINT unique_click_flag UNIQUE (url_id, ip, reason);
+ALTER TABLE click_flags DROP COLUMN asn;
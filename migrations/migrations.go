@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations so binaries know which
+// schema version they were built against. goose skips this file, as its
+// name has no version prefix.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
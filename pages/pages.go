@@ -0,0 +1,168 @@
+// Package pages renders every human-facing HTML page from one set of
+// templates. Pages share a layout that sets the document language and
+// direction and wraps content in <main>; their text comes from the i18n
+// catalogs. Deployments can replace any template, layout included, by
+// placing a file of the same name in a directory of their own, and each
+// workspace can replace the templates in Brandable through the admin API.
+package pages
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AnshulDekate/urlShortener/i18n"
+	"github.com/AnshulDekate/urlShortener/middleware"
+)
+
+//go:embed templates/*.html
+var builtin embed.FS
+
+// Names of the pages; each has a templates/<name>.html that defines "title",
+// "main" and optionally "head".
+const (
+	NotFound      = "not_found"
+	PendingReview = "pending_review"
+	Unfurl        = "unfurl"
+	Interstitial  = "interstitial"
+	AppLink       = "applink"
+	Bundle        = "bundle"
+	PublicStats   = "public_stats"
+	Bookmarklet   = "bookmarklet"
+)
+
+var names = []string{NotFound, PendingReview, Unfurl, Interstitial, AppLink, Bundle, PublicStats, Bookmarklet}
+
+// Layout is the template every page is wrapped in.
+const Layout = "layout"
+
+// Brandable are the templates a workspace may replace with its own.
+var Brandable = []string{Layout, NotFound, Unfurl, Interstitial}
+
+// Renderer holds every page parsed once per locale, so rendering needs no
+// per-request template work.
+type Renderer struct {
+	catalog *i18n.Catalog
+	sources map[string]string
+	sets    map[string]map[string]*template.Template
+}
+
+// New parses the built-in templates, with files from dir replacing them by
+// name, for every locale of catalog.
+func New(dir string, catalog *i18n.Catalog) (*Renderer, error) {
+	sources := map[string]string{}
+	for _, name := range append([]string{Layout}, names...) {
+		src, err := source(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = src
+	}
+	return parse(catalog, sources, nil)
+}
+
+// Branded returns a renderer in which the templates in overrides, keyed by
+// a name from Brandable, replace r's. funcs are made available to every
+// template, overriding the built-in "logo" and "workspace".
+func (r *Renderer) Branded(overrides map[string]string, funcs template.FuncMap) (*Renderer, error) {
+	sources := make(map[string]string, len(r.sources))
+	for name, src := range r.sources {
+		sources[name] = src
+	}
+	for name, src := range overrides {
+		if !slices.Contains(Brandable, name) {
+			return nil, fmt.Errorf("page %q cannot be replaced", name)
+		}
+		sources[name] = src
+	}
+	return parse(r.catalog, sources, funcs)
+}
+
+func parse(catalog *i18n.Catalog, sources map[string]string, extra template.FuncMap) (*Renderer, error) {
+	r := &Renderer{catalog: catalog, sources: sources, sets: map[string]map[string]*template.Template{}}
+	for _, locale := range catalog.Locales() {
+		funcs := r.funcs(locale)
+		for name, f := range extra {
+			funcs[name] = f
+		}
+		r.sets[locale] = map[string]*template.Template{}
+		for _, name := range names {
+			t, err := template.New(Layout).Funcs(funcs).Parse(sources[Layout])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse page layout: %w", err)
+			}
+			if _, err := t.New(name).Parse(sources[name]); err != nil {
+				return nil, fmt.Errorf("failed to parse page %s: %w", name, err)
+			}
+			r.sets[locale][name] = t
+		}
+	}
+	return r, nil
+}
+
+func source(dir string, name string) (string, error) {
+	if dir != "" {
+		b, err := os.ReadFile(filepath.Join(dir, name+".html"))
+		if err == nil {
+			return string(b), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read page template %s: %w", name, err)
+		}
+	}
+	b, err := builtin.ReadFile("templates/" + name + ".html")
+	return string(b), err
+}
+
+func (r *Renderer) funcs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"t":    func(key string, args ...any) string { return r.catalog.T(locale, key, args...) },
+		"lang": func() string { return locale },
+		"dir":  func() string { return i18n.Dir(locale) },
+		// Set by Branded; empty on unbranded pages.
+		"logo":      func() string { return "" },
+		"workspace": func() string { return "" },
+	}
+}
+
+// Render writes page in locale, or in the catalog's default locale when
+// locale has none. The page is rendered to a buffer first so a template error
+// never leaves half a page behind.
+func (r *Renderer) Render(w io.Writer, locale string, page string, data any) error {
+	set, ok := r.sets[strings.ToLower(locale)]
+	if !ok {
+		set = r.sets[r.catalog.Default()]
+	}
+	t, ok := set[page]
+	if !ok {
+		return fmt.Errorf("unknown page %q", page)
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, Layout, data); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Write answers with page as an HTML response in the locale negotiated
+// from the request's Accept-Language header.
+func (r *Renderer) Write(c *gin.Context, status int, page string, data any) {
+	var buf bytes.Buffer
+	if err := r.Render(&buf, middleware.Locale(c), page, data); err != nil {
+		log.Printf("ERROR: Failed to render %s page: %v", page, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render page."})
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,179 @@
+// Package queryplan samples a share of the queries sent to the database and
+// records their plans, so operators can spot plan regressions as tables
+// grow. Sampled reads are explained with ANALYZE and actually run a second
+// time; writes only get the planner's estimate.
+package queryplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+const (
+	// queueSize bounds the samples waiting to be explained. Beyond it
+	// samples are dropped rather than slow down the queries they came from.
+	queueSize      = 64
+	explainTimeout = 10 * time.Second
+	logQueryLength = 120
+
+	// readOnlyViolation is the SQLSTATE of a write in a read-only
+	// transaction.
+	readOnlyViolation = "25006"
+)
+
+type skipKey struct{}
+
+type startKey struct{}
+
+type sample struct {
+	query    string
+	args     []any
+	analyze  bool
+	start    time.Time
+	duration time.Duration
+}
+
+// Sampler is a pgx query tracer that picks queries at random and explains
+// them in the background once Start is called.
+type Sampler struct {
+	rate    float64
+	pending chan sample
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSampler samples percent of queries, between 0 and 100.
+func NewSampler(percent float64) (*Sampler, error) {
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("sample percentage must be above 0 and at most 100, got %g", percent)
+	}
+	return &Sampler{
+		rate:    percent / 100,
+		pending: make(chan sample, queueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Skip marks ctx so queries made with it are never sampled, as the
+// sampler's own are.
+func Skip(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+func (s *Sampler) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if ctx.Value(skipKey{}) != nil || rand.Float64() >= s.rate {
+		return ctx
+	}
+	explainable, read := classify(data.SQL)
+	if !explainable {
+		return ctx
+	}
+	args := append([]any(nil), data.Args...)
+	return context.WithValue(ctx, startKey{}, &sample{query: data.SQL, args: args, analyze: read, start: time.Now()})
+}
+
+func (s *Sampler) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	smp, ok := ctx.Value(startKey{}).(*sample)
+	if !ok || data.Err != nil {
+		return
+	}
+	smp.duration = time.Since(smp.start)
+	select {
+	case s.pending <- *smp:
+	default:
+	}
+}
+
+// classify reports whether a statement can be explained, and whether it
+// reads only, judging by its first keyword. A WITH may still hide a write,
+// which the read-only transaction of ExplainQuery catches. Statements that
+// take advisory locks are left alone: a session lock taken while explaining
+// would outlive the explain.
+func classify(query string) (explainable bool, read bool) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 || strings.Contains(strings.ToLower(query), "advisory") {
+		return false, false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH":
+		return true, true
+	case "INSERT", "UPDATE", "DELETE":
+		return true, false
+	}
+	return false, false
+}
+
+// Start explains samples with repo until Close.
+func (s *Sampler) Start(repo *repository.Repository) {
+	s.once.Do(func() {
+		go func() {
+			defer close(s.done)
+			for {
+				select {
+				case <-s.stop:
+					return
+				case smp := <-s.pending:
+					s.explain(repo, smp)
+				}
+			}
+		}()
+	})
+}
+
+// Close stops a started sampler, waiting for the explain in progress.
+func (s *Sampler) Close() error {
+	started := true
+	s.once.Do(func() { started = false })
+	close(s.stop)
+	if started {
+		<-s.done
+	}
+	return nil
+}
+
+func (s *Sampler) explain(repo *repository.Repository, smp sample) {
+	ctx, cancel := context.WithTimeout(Skip(context.Background()), explainTimeout)
+	defer cancel()
+
+	analyzed := smp.analyze
+	plan, err := repo.ExplainQuery(ctx, smp.query, smp.args, analyzed)
+	var pgErr *pgconn.PgError
+	if analyzed && errors.As(err, &pgErr) && pgErr.Code == readOnlyViolation {
+		analyzed = false
+		plan, err = repo.ExplainQuery(ctx, smp.query, smp.args, false)
+	}
+	query := strings.Join(strings.Fields(smp.query), " ")
+	if err != nil {
+		log.Printf("WARN: Failed to explain sampled query %q: %v", truncate(query), err)
+		return
+	}
+
+	ms := float64(smp.duration.Microseconds()) / 1000
+	err = repo.InsertQueryPlan(ctx, repository.QueryPlan{Query: query, Plan: plan, Analyzed: analyzed, DurationMS: ms})
+	if err != nil {
+		log.Printf("WARN: %v", err)
+		return
+	}
+	log.Printf("INFO: Recorded the plan of a %.1fms query: %s", ms, truncate(query))
+}
+
+func truncate(query string) string {
+	if len(query) <= logQueryLength {
+		return query
+	}
+	return query[:logQueryLength] + "..."
+}
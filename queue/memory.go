@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+// Memory is a bounded in-process queue. Events still queued when the
+// process dies are lost.
+type Memory struct {
+	ch chan events.Event
+}
+
+func NewMemory(size int) *Memory {
+	return &Memory{ch: make(chan events.Event, size)}
+}
+
+func (m *Memory) Publish(e events.Event) error {
+	select {
+	case m.ch <- e:
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+func (m *Memory) Receive(ctx context.Context, max int, wait time.Duration) ([]Message, error) {
+	var msgs []Message
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case e := <-m.ch:
+			msgs = append(msgs, Message{Event: e})
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	for len(msgs) < max {
+		select {
+		case e := <-m.ch:
+			msgs = append(msgs, Message{Event: e})
+		default:
+			return msgs, nil
+		}
+	}
+	return msgs, nil
+}
+
+func (m *Memory) Ack(ctx context.Context, msgs []Message) error { return nil }
+
+func (m *Memory) Depth(ctx context.Context) (int64, error) { return int64(len(m.ch)), nil }
+
+func (m *Memory) Durable() bool { return false }
+
+func (m *Memory) Close() error { return nil }
@@ -0,0 +1,98 @@
+// Package queue carries events from the request path to the loops that
+// persist and deliver them. Memory keeps events in a channel inside the
+// process, which is all a single instance needs. RedisStream shares them
+// between instances through a Redis stream and consumer group, so a worker
+// can deliver what any server published and events survive a restart.
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+var ErrFull = errors.New("queue is full")
+
+// Message is a received event. ID identifies it to Ack and is empty for
+// queues that need no acknowledgement.
+type Message struct {
+	ID    string
+	Event events.Event
+}
+
+type Queue interface {
+	// Publish enqueues e without waiting on the network. It returns ErrFull
+	// rather than block the caller when the queue cannot keep up.
+	Publish(e events.Event) error
+	// Receive waits up to wait for events and returns at most max of them.
+	// A wait of zero returns only what is available right away.
+	Receive(ctx context.Context, max int, wait time.Duration) ([]Message, error)
+	// Ack marks messages as handled so they are not delivered again.
+	Ack(ctx context.Context, msgs []Message) error
+	// Depth counts events waiting to be received: for a shared queue, all
+	// of them, not only those this process published.
+	Depth(ctx context.Context) (int64, error)
+	// Durable reports whether queued events outlive the process.
+	Durable() bool
+	Close() error
+}
+
+const ackTimeout = 5 * time.Second
+
+// Consume hands batches of events from q to handle until ctx is done. A
+// batch is handled once it holds size events or interval has passed since
+// the last one, and acknowledged after handle returns. On shutdown an
+// in-memory queue is drained, so its producers must already be stopped; a
+// durable queue keeps the rest for the next consumer.
+func Consume(ctx context.Context, q Queue, size int, interval time.Duration, handle func([]events.Event)) {
+	pending := make([]Message, 0, size)
+	deadline := time.Now().Add(interval)
+	for ctx.Err() == nil {
+		msgs, err := q.Receive(ctx, size-len(pending), max(time.Until(deadline), 0))
+		if err != nil && ctx.Err() == nil {
+			log.Printf("ERROR: Failed to read from event queue: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+		pending = append(pending, msgs...)
+		if len(pending) >= size || !time.Now().Before(deadline) {
+			deliver(q, pending, handle)
+			pending = make([]Message, 0, size)
+			deadline = time.Now().Add(interval)
+		}
+	}
+
+	deliver(q, pending, handle)
+	if q.Durable() {
+		return
+	}
+	for {
+		msgs, _ := q.Receive(context.Background(), size, 0)
+		if len(msgs) == 0 {
+			return
+		}
+		deliver(q, msgs, handle)
+	}
+}
+
+func deliver(q Queue, msgs []Message, handle func([]events.Event)) {
+	if len(msgs) == 0 {
+		return
+	}
+	batch := make([]events.Event, len(msgs))
+	for i, m := range msgs {
+		batch[i] = m.Event
+	}
+	handle(batch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ackTimeout)
+	defer cancel()
+	if err := q.Ack(ctx, msgs); err != nil {
+		log.Printf("ERROR: Failed to acknowledge %d queued events; they will be handled again: %v", len(msgs), err)
+	}
+}
@@ -0,0 +1,256 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+const (
+	DefaultStreamMaxLen = 1000000
+	DefaultClaimIdle    = time.Minute
+
+	publishBufferSize = 10000
+	publishBatchSize  = 100
+	publishInterval   = 50 * time.Millisecond
+	publishTimeout    = 5 * time.Second
+	eventField        = "event"
+)
+
+// RedisStream is a queue on a Redis stream read through a consumer group.
+// Every instance publishing or consuming the same stream and group shares
+// one queue. Publish buffers events and a background loop adds them in
+// pipelined batches, so the redirect path never waits on Redis.
+type RedisStream struct {
+	Client   *redis.Client
+	Stream   string
+	Group    string
+	Consumer string
+	// MaxLen caps the stream, trimming the oldest entries first, so a
+	// stalled consumer cannot exhaust Redis memory.
+	MaxLen int64
+	// ClaimIdle is how long a message may sit unacknowledged with another
+	// consumer, which has presumably died, before this one takes it over.
+	ClaimIdle time.Duration
+
+	buffer    chan events.Event
+	stop      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+	lastClaim time.Time
+}
+
+// NewRedisStream creates the consumer group if it does not exist yet and
+// starts the publishing loop. The consumer is named after the host and
+// process, which must be unique among the group's consumers.
+func NewRedisStream(ctx context.Context, client *redis.Client, stream, group string) (*RedisStream, error) {
+	err := client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group %s on %s: %w", group, stream, err)
+	}
+	host, _ := os.Hostname()
+	q := &RedisStream{
+		Client:    client,
+		Stream:    stream,
+		Group:     group,
+		Consumer:  fmt.Sprintf("%s-%d", host, os.Getpid()),
+		MaxLen:    DefaultStreamMaxLen,
+		ClaimIdle: DefaultClaimIdle,
+		buffer:    make(chan events.Event, publishBufferSize),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go q.publishLoop()
+	return q, nil
+}
+
+func (q *RedisStream) Publish(e events.Event) error {
+	select {
+	case q.buffer <- e:
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+func (q *RedisStream) publishLoop() {
+	defer close(q.stopped)
+	ticker := time.NewTicker(publishInterval)
+	defer ticker.Stop()
+
+	batch := make([]events.Event, 0, publishBatchSize)
+	for {
+		select {
+		case e := <-q.buffer:
+			batch = append(batch, e)
+			if len(batch) < publishBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-q.stop:
+			for {
+				select {
+				case e := <-q.buffer:
+					batch = append(batch, e)
+				default:
+					q.add(batch)
+					return
+				}
+			}
+		}
+		q.add(batch)
+		batch = batch[:0]
+	}
+}
+
+// add appends batch to the stream in one round trip. Events that fail to
+// encode or to reach Redis are dropped, as the in-memory queue drops them
+// when full.
+func (q *RedisStream) add(batch []events.Event) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	pipe := q.Client.Pipeline()
+	for _, e := range batch {
+		body, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("ERROR: Failed to encode %s event for %s: %v", e.Type, e.ShortCode, err)
+			continue
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.Stream,
+			MaxLen: q.MaxLen,
+			Approx: true,
+			Values: []any{eventField, body},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("ERROR: Failed to add %d events to stream %s: %v", len(batch), q.Stream, err)
+	}
+}
+
+// Receive first reclaims messages left pending by dead consumers, at most
+// once per ClaimIdle, then reads new ones.
+func (q *RedisStream) Receive(ctx context.Context, max int, wait time.Duration) ([]Message, error) {
+	if q.ClaimIdle > 0 && time.Since(q.lastClaim) >= q.ClaimIdle {
+		q.lastClaim = time.Now()
+		claimed, _, err := q.Client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   q.Stream,
+			Group:    q.Group,
+			Consumer: q.Consumer,
+			MinIdle:  q.ClaimIdle,
+			Start:    "0",
+			Count:    int64(max),
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim pending events: %w", err)
+		}
+		if len(claimed) > 0 {
+			log.Printf("INFO: Claimed %d unacknowledged events from %s.", len(claimed), q.Stream)
+			return q.decode(claimed), nil
+		}
+	}
+
+	// BLOCK 0 would wait forever; without BLOCK the read returns at once.
+	block := wait
+	if block < time.Millisecond {
+		block = -1
+	}
+	streams, err := q.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.Group,
+		Consumer: q.Consumer,
+		Streams:  []string{q.Stream, ">"},
+		Count:    int64(max),
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from stream %s: %w", q.Stream, err)
+	}
+	var msgs []Message
+	for _, s := range streams {
+		msgs = append(msgs, q.decode(s.Messages)...)
+	}
+	return msgs, nil
+}
+
+// decode skips entries that do not hold an event and acknowledges them, so
+// a malformed entry is not handed out again forever.
+func (q *RedisStream) decode(entries []redis.XMessage) []Message {
+	msgs := make([]Message, 0, len(entries))
+	var bad []string
+	for _, entry := range entries {
+		var e events.Event
+		raw, _ := entry.Values[eventField].(string)
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			log.Printf("WARN: Discarding malformed entry %s in %s: %v", entry.ID, q.Stream, err)
+			bad = append(bad, entry.ID)
+			continue
+		}
+		msgs = append(msgs, Message{ID: entry.ID, Event: e})
+	}
+	if len(bad) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), ackTimeout)
+		defer cancel()
+		q.Client.XAck(ctx, q.Stream, q.Group, bad...)
+	}
+	return msgs
+}
+
+func (q *RedisStream) Ack(ctx context.Context, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	if err := q.Client.XAck(ctx, q.Stream, q.Group, ids...).Err(); err != nil {
+		return fmt.Errorf("failed to acknowledge events on %s: %w", q.Stream, err)
+	}
+	return nil
+}
+
+// Depth is the group's lag, the entries no consumer has read yet, plus what
+// this process has not published. Redis before 7.0 does not track lag and
+// contributes nothing.
+func (q *RedisStream) Depth(ctx context.Context) (int64, error) {
+	groups, err := q.Client.XInfoGroups(ctx, q.Stream).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read consumer groups of %s: %w", q.Stream, err)
+	}
+	depth := int64(len(q.buffer))
+	for _, g := range groups {
+		if g.Name == q.Group && g.Lag > 0 {
+			depth += g.Lag
+		}
+	}
+	return depth, nil
+}
+
+func (q *RedisStream) Durable() bool { return true }
+
+// Close adds the events still buffered to the stream. The client is left
+// open for the caller to close.
+func (q *RedisStream) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.stop)
+		<-q.stopped
+	})
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package rdap looks up domain registration dates over RDAP, the JSON
+// successor of WHOIS.
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is a bootstrap service that redirects each query to the
+// registry responsible for the domain's TLD.
+const (
+	DefaultBaseURL = "https://rdap.org"
+	DefaultTimeout = 5 * time.Second
+)
+
+var ErrNoRegistrationDate = errors.New("registry did not report a registration date")
+
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTP: &http.Client{Timeout: DefaultTimeout}}
+}
+
+type domainResponse struct {
+	Events []struct {
+		Action string    `json:"eventAction"`
+		Date   time.Time `json:"eventDate"`
+	} `json:"events"`
+}
+
+// RegistrationDate returns when domain, a registrable domain such as
+// example.co.uk, was registered.
+func (c *Client) RegistrationDate(ctx context.Context, domain string) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/domain/"+domain, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to build RDAP request for %s: %w", domain, err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query RDAP for %s: %w", domain, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("RDAP lookup for %s returned status %d", domain, resp.StatusCode)
+	}
+
+	var body domainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode RDAP response for %s: %w", domain, err)
+	}
+	for _, e := range body.Events {
+		if e.Action == "registration" {
+			return e.Date, nil
+		}
+	}
+	return time.Time{}, ErrNoRegistrationDate
+}
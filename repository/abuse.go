@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AbuseEvent records a link creation attempt rejected as spam.
+type AbuseEvent struct {
+	ID        int64          `json:"id"`
+	IP        string         `json:"ip"`
+	Reason    string         `json:"reason"`
+	Domain    string         `json:"domain,omitempty"`
+	Details   map[string]any `json:"details"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (r *Repository) InsertAbuseEvent(ctx context.Context, e AbuseEvent) error {
+	payload, err := json.Marshal(e.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode abuse event details: %w", err)
+	}
+	const query = `INSERT INTO abuse_events (ip, reason, domain, details) VALUES ($1, $2, $3, $4)`
+	if _, err := r.DB.ExecContext(ctx, query, e.IP, e.Reason, e.Domain, payload); err != nil {
+		return fmt.Errorf("failed to insert abuse event: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListAbuseEvents(ctx context.Context, days int) ([]AbuseEvent, error) {
+	const query = `
+	SELECT id, ip, reason, domain, details, created_at
+	FROM abuse_events
+	WHERE created_at >= NOW() - $1 * INTERVAL '1 day'
+	ORDER BY created_at DESC
+	LIMIT 1000
+	`
+	rows, err := r.DB.QueryContext(ctx, query, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query abuse events: %w", err)
+	}
+	defer rows.Close()
+
+	abuse := []AbuseEvent{}
+	for rows.Next() {
+		var e AbuseEvent
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.IP, &e.Reason, &e.Domain, &details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan abuse event: %w", err)
+		}
+		if err := json.Unmarshal(details, &e.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode abuse event details: %w", err)
+		}
+		abuse = append(abuse, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during abuse event iteration: %w", err)
+	}
+	return abuse, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrAlreadyBootstrapped = errors.New("api keys already exist")
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"key_prefix"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Signing    bool       `json:"signing"`
+}
+
+const apiKeyColumns = `id, name, key_prefix, created_by, created_at, last_used_at, signing_secret IS NOT NULL`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*APIKey, error) {
+	var k APIKey
+	var lastUsedAt sql.NullTime
+	if err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.CreatedBy, &k.CreatedAt, &lastUsedAt, &k.Signing); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	return &k, nil
+}
+
+// CreateAPIKeyIfAbsent inserts a key unless one with the same name exists.
+// created reports which case happened; the existing key is returned otherwise.
+func (r *Repository) CreateAPIKeyIfAbsent(ctx context.Context, name string, prefix string, keyHash string, actor string) (*APIKey, bool, error) {
+	query := `
+	INSERT INTO api_keys (name, key_prefix, key_hash, created_by) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (name) DO NOTHING
+	RETURNING ` + apiKeyColumns
+	k, err := scanAPIKey(r.DB.QueryRowContext(ctx, query, name, prefix, keyHash, actor))
+	if err == nil {
+		return k, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to create API key %s: %w", name, err)
+	}
+
+	k, err = scanAPIKey(r.DB.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE name = $1`, name))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load existing API key %s: %w", name, err)
+	}
+	return k, false, nil
+}
+
+// CreateFirstAPIKey inserts a key only while the table is empty. The table
+// lock makes concurrent bootstraps from several instances yield one key.
+func (r *Repository) CreateFirstAPIKey(ctx context.Context, name string, prefix string, keyHash string, actor string) (*APIKey, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin bootstrap transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE api_keys IN EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("failed to lock api_keys: %w", err)
+	}
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM api_keys)`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check existing API keys: %w", err)
+	}
+	if exists {
+		return nil, ErrAlreadyBootstrapped
+	}
+
+	query := `INSERT INTO api_keys (name, key_prefix, key_hash, created_by) VALUES ($1, $2, $3, $4) RETURNING ` + apiKeyColumns
+	k, err := scanAPIKey(tx.QueryRowContext(ctx, query, name, prefix, keyHash, actor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bootstrap API key: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bootstrap: %w", err)
+	}
+	return k, nil
+}
+
+func (r *Repository) CountAPIKeys(ctx context.Context) (int, error) {
+	var n int
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_keys`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count API keys: %w", err)
+	}
+	return n, nil
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during API key iteration: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *Repository) DeleteAPIKey(ctx context.Context, name string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM api_keys WHERE name = $1`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API key %s: %w", name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read deleted API key count: %w", err)
+	}
+	return n > 0, nil
+}
+
+// TouchAPIKey resolves a key hash to its name and records the use.
+// Returns sql.ErrNoRows for unknown keys.
+func (r *Repository) TouchAPIKey(ctx context.Context, keyHash string) (string, error) {
+	const query = `UPDATE api_keys SET last_used_at = NOW() WHERE key_hash = $1 RETURNING name`
+	var name string
+	err := r.DB.QueryRowContext(ctx, query, keyHash).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to verify API key: %w", err)
+	}
+	return name, nil
+}
+
+// SetAPIKeySigningSecret replaces or, with an empty secret, removes the key's
+// request signing secret. Returns sql.ErrNoRows for unknown keys.
+func (r *Repository) SetAPIKeySigningSecret(ctx context.Context, name string, secret string) error {
+	const query = `UPDATE api_keys SET signing_secret = NULLIF($2, '') WHERE name = $1`
+	res, err := r.DB.ExecContext(ctx, query, name, secret)
+	if err != nil {
+		return fmt.Errorf("failed to set signing secret of API key %s: %w", name, err)
+	}
+	return requireRows(res)
+}
+
+// GetAPIKeySigningSecret returns sql.ErrNoRows for unknown keys and keys
+// without a signing secret alike.
+func (r *Repository) GetAPIKeySigningSecret(ctx context.Context, name string) (string, error) {
+	const query = `SELECT signing_secret FROM api_keys WHERE name = $1 AND signing_secret IS NOT NULL`
+	var secret string
+	err := r.DB.QueryRowContext(ctx, query, name).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query signing secret of API key %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// ClaimRequestNonce records a signed request's nonce and the key's use in
+// one transaction. It returns false, recording nothing, when the nonce was
+// claimed before.
+func (r *Repository) ClaimRequestNonce(ctx context.Context, name string, nonce string) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin nonce transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO api_request_nonces (key_name, nonce) VALUES ($1, $2) ON CONFLICT DO NOTHING`, name, nonce)
+	if err != nil {
+		return false, fmt.Errorf("failed to record request nonce for %s: %w", name, err)
+	}
+	if err := requireRows(res); err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE name = $1`, name); err != nil {
+		return false, fmt.Errorf("failed to record use of API key %s: %w", name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit request nonce: %w", err)
+	}
+	return true, nil
+}
+
+// PruneRequestNonces deletes nonces recorded more than olderThan ago.
+func (r *Repository) PruneRequestNonces(ctx context.Context, olderThan time.Duration) (int64, error) {
+	const query = `DELETE FROM api_request_nonces WHERE seen_at < NOW() - $1 * INTERVAL '1 second'`
+	res, err := r.DB.ExecContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune request nonces: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pruned nonce count: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLockNotAcquired    = errors.New("advisory lock held by another instance")
+	ErrDatabaseNotEmpty   = errors.New("target database already contains links")
+	ErrUnknownBackupTable = errors.New("unknown table in backup")
+)
+
+// BackupTables lists the tables included in logical backups, parents before
+// children so a restore can insert them in order.
+var BackupTables = []string{
+	"workspaces",
+	"workspace_pages",
+	"workspace_logos",
+	"workspace_qr_styles",
+	"api_keys",
+	"campaigns",
+	"link_sets",
+	"urls",
+	"retired_codes",
+	"webhooks",
+	"link_utm",
+	"link_languages",
+	"link_schedule_rules",
+	"link_splits",
+	"bundles",
+	"bundle_links",
+	"click_events",
+	"click_rollups_hourly",
+	"click_rollup_state",
+	"click_flags",
+	"conversions",
+	"abuse_events",
+	"domain_reputation",
+	"link_health",
+	"link_screenshots",
+	"link_content",
+	"link_open_graph",
+	"regional_click_counts",
+	"webhook_deliveries",
+	"webhook_delivery_attempts",
+	"report_schedules",
+	"audit_log",
+}
+
+// unsequencedTables are the BackupTables keyed by something other than a
+// serial id, whose sequences a restore does not reset.
+var unsequencedTables = map[string]bool{
+	"workspace_pages":       true,
+	"workspace_logos":       true,
+	"workspace_qr_styles":   true,
+	"retired_codes":         true,
+	"link_utm":              true,
+	"link_languages":        true,
+	"link_splits":           true,
+	"regional_click_counts": true,
+	"click_rollups_hourly":  true,
+	"click_rollup_state":    true,
+	"bundle_links":          true,
+	"link_open_graph":       true,
+}
+
+const backupLockKey = 944_001
+
+const restoreBatchSize = 500
+
+type Backup struct {
+	ID         int64     `json:"id"`
+	StorageKey string    `json:"storage_key"`
+	RowCount   int64     `json:"row_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func isBackupTable(table string) bool {
+	for _, t := range BackupTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportTables streams every row of BackupTables as JSON from a single
+// repeatable-read snapshot. Only one instance may export at a time.
+func (r *Repository) ExportTables(ctx context.Context, emit func(table string, row []byte) error) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin export transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", backupLockKey).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to acquire backup lock: %w", err)
+	}
+	if !locked {
+		return 0, ErrLockNotAcquired
+	}
+
+	var total int64
+	for _, table := range BackupTables {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t", table))
+		if err != nil {
+			return 0, fmt.Errorf("failed to export table %s: %w", table, err)
+		}
+		for rows.Next() {
+			var row []byte
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to scan row from %s: %w", table, err)
+			}
+			if err := emit(table, row); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			total++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, fmt.Errorf("error during export of %s: %w", table, err)
+		}
+	}
+	return total, nil
+}
+
+// RestoreTables loads rows produced by ExportTables into an empty database in
+// one transaction and resets serial sequences afterwards. read is called once
+// and must call emit for every row in backup order.
+func (r *Repository) RestoreTables(ctx context.Context, read func(emit func(table string, row []byte) error) error) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin restore transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var hasLinks bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM urls)").Scan(&hasLinks); err != nil {
+		return 0, fmt.Errorf("failed to check target database: %w", err)
+	}
+	if hasLinks {
+		return 0, ErrDatabaseNotEmpty
+	}
+
+	var total int64
+	var current string
+	batch := make([]string, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		query := fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1::json)", current, current)
+		payload := "[" + strings.Join(batch, ",") + "]"
+		if _, err := tx.ExecContext(ctx, query, payload); err != nil {
+			return fmt.Errorf("failed to restore rows into %s: %w", current, err)
+		}
+		total += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	err = read(func(table string, row []byte) error {
+		if !isBackupTable(table) {
+			return fmt.Errorf("%w: %s", ErrUnknownBackupTable, table)
+		}
+		if table != current || len(batch) >= restoreBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+			current = table
+		}
+		batch = append(batch, string(row))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	for _, table := range BackupTables {
+		if unsequencedTables[table] {
+			continue
+		}
+		resetQuery := fmt.Sprintf(`
+		SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), GREATEST(COALESCE(MAX(id), 0), 1), COALESCE(MAX(id), 0) > 0)
+		FROM %[1]s
+		`, table)
+		if _, err := tx.ExecContext(ctx, resetQuery); err != nil {
+			return 0, fmt.Errorf("failed to reset sequence for %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return total, nil
+}
+
+func (r *Repository) RecordBackup(ctx context.Context, storageKey string, rowCount int64) error {
+	const query = `INSERT INTO backups (storage_key, row_count) VALUES ($1, $2)`
+	if _, err := r.DB.ExecContext(ctx, query, storageKey, rowCount); err != nil {
+		return fmt.Errorf("failed to record backup %s: %w", storageKey, err)
+	}
+	return nil
+}
+
+func (r *Repository) ListBackups(ctx context.Context, limit int) ([]Backup, error) {
+	const query = `SELECT id, storage_key, row_count, created_at FROM backups ORDER BY created_at DESC LIMIT $1`
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backups: %w", err)
+	}
+	defer rows.Close()
+
+	backups := []Backup{}
+	for rows.Next() {
+		var b Backup
+		if err := rows.Scan(&b.ID, &b.StorageKey, &b.RowCount, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan backup row: %w", err)
+		}
+		backups = append(backups, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during backup iteration: %w", err)
+	}
+	return backups, nil
+}
+
+func (r *Repository) GetLastBackupTime(ctx context.Context) (time.Time, error) {
+	var last sql.NullTime
+	if err := r.DB.QueryRowContext(ctx, "SELECT MAX(created_at) FROM backups").Scan(&last); err != nil {
+		return time.Time{}, fmt.Errorf("failed to query last backup time: %w", err)
+	}
+	return last.Time, nil
+}
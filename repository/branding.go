@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WorkspacePages are the templates a workspace replaced, by page name, and
+// whether it uploaded a logo.
+type WorkspacePages struct {
+	WorkspaceID int64             `json:"-"`
+	Slug        string            `json:"workspace"`
+	Name        string            `json:"name"`
+	Templates   map[string]string `json:"templates"`
+	HasLogo     bool              `json:"logo"`
+}
+
+type WorkspaceLogo struct {
+	ContentType string
+	Data        []byte
+	UpdatedAt   time.Time
+}
+
+// GetWorkspacePages returns the branding of a workspace by ID.
+func (r *Repository) GetWorkspacePages(ctx context.Context, workspaceID int64) (*WorkspacePages, error) {
+	return r.workspacePages(ctx, `w.id = $1`, workspaceID)
+}
+
+// GetWorkspacePagesBySlug returns the branding of a workspace by slug.
+func (r *Repository) GetWorkspacePagesBySlug(ctx context.Context, slug string) (*WorkspacePages, error) {
+	return r.workspacePages(ctx, `w.slug = $1`, slug)
+}
+
+func (r *Repository) workspacePages(ctx context.Context, where string, arg any) (*WorkspacePages, error) {
+	query := `
+	SELECT w.id, w.slug, w.name,
+		EXISTS (SELECT 1 FROM workspace_logos l WHERE l.workspace_id = w.id),
+		(SELECT json_object_agg(p.page, p.template) FROM workspace_pages p WHERE p.workspace_id = w.id)
+	FROM workspaces w
+	WHERE ` + where
+
+	var wp WorkspacePages
+	var templates []byte
+	err := r.DB.QueryRowContext(ctx, query, arg).Scan(&wp.WorkspaceID, &wp.Slug, &wp.Name, &wp.HasLogo, &templates)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace pages: %w", err)
+	}
+	wp.Templates = map[string]string{}
+	if templates != nil {
+		if err := json.Unmarshal(templates, &wp.Templates); err != nil {
+			return nil, fmt.Errorf("failed to decode pages of workspace %s: %w", wp.Slug, err)
+		}
+	}
+	return &wp, nil
+}
+
+// SetWorkspacePage stores the template a workspace replaces page with.
+// Returns sql.ErrNoRows when the workspace does not exist.
+func (r *Repository) SetWorkspacePage(ctx context.Context, slug string, page string, template string) error {
+	const query = `
+	INSERT INTO workspace_pages (workspace_id, page, template)
+	SELECT id, $2, $3 FROM workspaces WHERE slug = $1
+	ON CONFLICT (workspace_id, page) DO UPDATE SET template = EXCLUDED.template, updated_at = NOW()
+	`
+	res, err := r.DB.ExecContext(ctx, query, slug, page, template)
+	if err != nil {
+		return fmt.Errorf("failed to store %s page of workspace %s: %w", page, slug, err)
+	}
+	return requireRows(res)
+}
+
+// DeleteWorkspacePage restores the default template of page for a
+// workspace. Returns sql.ErrNoRows when the workspace had not replaced it.
+func (r *Repository) DeleteWorkspacePage(ctx context.Context, slug string, page string) error {
+	const query = `
+	DELETE FROM workspace_pages p USING workspaces w
+	WHERE p.workspace_id = w.id AND w.slug = $1 AND p.page = $2
+	`
+	res, err := r.DB.ExecContext(ctx, query, slug, page)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s page of workspace %s: %w", page, slug, err)
+	}
+	return requireRows(res)
+}
+
+// SetWorkspaceLogo stores a workspace's logo. Returns sql.ErrNoRows when the
+// workspace does not exist.
+func (r *Repository) SetWorkspaceLogo(ctx context.Context, slug string, contentType string, data []byte) error {
+	const query = `
+	INSERT INTO workspace_logos (workspace_id, content_type, data)
+	SELECT id, $2, $3 FROM workspaces WHERE slug = $1
+	ON CONFLICT (workspace_id) DO UPDATE SET content_type = EXCLUDED.content_type, data = EXCLUDED.data, updated_at = NOW()
+	`
+	res, err := r.DB.ExecContext(ctx, query, slug, contentType, data)
+	if err != nil {
+		return fmt.Errorf("failed to store logo of workspace %s: %w", slug, err)
+	}
+	return requireRows(res)
+}
+
+// DeleteWorkspaceLogo returns sql.ErrNoRows when the workspace had no logo.
+func (r *Repository) DeleteWorkspaceLogo(ctx context.Context, slug string) error {
+	const query = `
+	DELETE FROM workspace_logos l USING workspaces w
+	WHERE l.workspace_id = w.id AND w.slug = $1
+	`
+	res, err := r.DB.ExecContext(ctx, query, slug)
+	if err != nil {
+		return fmt.Errorf("failed to delete logo of workspace %s: %w", slug, err)
+	}
+	return requireRows(res)
+}
+
+func (r *Repository) GetWorkspaceLogo(ctx context.Context, slug string) (*WorkspaceLogo, error) {
+	return r.workspaceLogo(ctx, `w.slug = $1`, slug)
+}
+
+func (r *Repository) GetWorkspaceLogoByID(ctx context.Context, workspaceID int64) (*WorkspaceLogo, error) {
+	return r.workspaceLogo(ctx, `w.id = $1`, workspaceID)
+}
+
+func (r *Repository) workspaceLogo(ctx context.Context, where string, arg any) (*WorkspaceLogo, error) {
+	query := `
+	SELECT l.content_type, l.data, l.updated_at
+	FROM workspace_logos l
+	JOIN workspaces w ON w.id = l.workspace_id
+	WHERE ` + where
+
+	var logo WorkspaceLogo
+	err := r.DB.QueryRowContext(ctx, query, arg).Scan(&logo.ContentType, &logo.Data, &logo.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logo of workspace %v: %w", arg, err)
+	}
+	return &logo, nil
+}
+
+// BrandedCodePrefixes maps the code prefixes of workspaces that replaced
+// their not-found page or layout to the workspace IDs. Unknown codes carrying
+// such a prefix get the workspace's 404 page.
+func (r *Repository) BrandedCodePrefixes(ctx context.Context) (map[string]int64, error) {
+	const query = `
+	SELECT w.code_prefix, w.id
+	FROM workspaces w
+	WHERE w.code_prefix IS NOT NULL
+		AND EXISTS (SELECT 1 FROM workspace_pages p WHERE p.workspace_id = w.id AND p.page IN ('layout', 'not_found'))
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branded code prefixes: %w", err)
+	}
+	defer rows.Close()
+
+	prefixes := map[string]int64{}
+	for rows.Next() {
+		var prefix string
+		var id int64
+		if err := rows.Scan(&prefix, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan branded code prefix: %w", err)
+		}
+		prefixes[prefix] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during branded code prefix iteration: %w", err)
+	}
+	return prefixes, nil
+}
+
+// requireRows turns an update or delete that matched nothing into
+// sql.ErrNoRows.
+func requireRows(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected row count: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
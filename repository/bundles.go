@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Bundle struct {
+	ID           int64      `json:"id"`
+	Code         string     `json:"short_url"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	LinkCount    int        `json:"link_count"`
+	ViewCount    int64      `json:"view_count"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastViewedAt *time.Time `json:"last_viewed_at"`
+}
+
+// BundleLink is one entry of a bundle, in the order it was added.
+type BundleLink struct {
+	ShortCode  string `json:"short_url"`
+	LongURL    string `json:"long_url"`
+	ClickCount int    `json:"click_count"`
+}
+
+const bundleColumns = `b.id, b.code, b.title, b.description,
+	(SELECT COUNT(*) FROM bundle_links l WHERE l.bundle_id = b.id),
+	b.view_count, b.created_by, b.created_at, b.last_viewed_at`
+
+func scanBundle(row interface{ Scan(...any) error }) (*Bundle, error) {
+	var b Bundle
+	var lastViewedAt sql.NullTime
+	if err := row.Scan(&b.ID, &b.Code, &b.Title, &b.Description, &b.LinkCount, &b.ViewCount, &b.CreatedBy, &b.CreatedAt, &lastViewedAt); err != nil {
+		return nil, err
+	}
+	if lastViewedAt.Valid {
+		b.LastViewedAt = &lastViewedAt.Time
+	}
+	return &b, nil
+}
+
+// CreateBundle inserts a bundle of the given links, kept in the order of
+// shortCodes. missing lists the codes that do not exist, in which case
+// nothing is created.
+func (r *Repository) CreateBundle(ctx context.Context, code string, title string, description string, shortCodes []string, actor string) (*Bundle, []string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin bundle transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `INSERT INTO bundles (code, title, description, created_by) VALUES ($1, $2, $3, $4) RETURNING id`,
+		code, title, description, actor).Scan(&id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create bundle %s: %w", code, err)
+	}
+
+	const linkQuery = `
+	WITH wanted AS (
+		SELECT u.id, u.short_url, MIN(c.position) AS position
+		FROM unnest($2::text[]) WITH ORDINALITY AS c (short_url, position)
+		JOIN urls u ON u.short_url = c.short_url AND u.deleted_at IS NULL
+		GROUP BY u.id, u.short_url
+	), added AS (
+		INSERT INTO bundle_links (bundle_id, url_id, position)
+		SELECT $1, id, position FROM wanted
+	)
+	SELECT short_url FROM wanted`
+	rows, err := tx.QueryContext(ctx, linkQuery, id, shortCodes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to add links to bundle %s: %w", code, err)
+	}
+	found := make(map[string]bool, len(shortCodes))
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan bundle link: %w", err)
+		}
+		found[c] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error during bundle link iteration: %w", err)
+	}
+
+	missing := []string{}
+	for _, c := range shortCodes {
+		if !found[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, missing, nil
+	}
+
+	b, err := scanBundle(tx.QueryRowContext(ctx, `SELECT `+bundleColumns+` FROM bundles b WHERE b.id = $1`, id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load bundle %s: %w", code, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit bundle %s: %w", code, err)
+	}
+	return b, nil, nil
+}
+
+// GetBundle loads a bundle by code, counting a view when view is true.
+// Returns sql.ErrNoRows for unknown codes.
+func (r *Repository) GetBundle(ctx context.Context, code string, view bool) (*Bundle, error) {
+	query := `SELECT ` + bundleColumns + ` FROM bundles b WHERE b.code = $1`
+	if view {
+		query = `
+		WITH b AS (
+			UPDATE bundles SET view_count = view_count + 1, last_viewed_at = NOW()
+			WHERE code = $1
+			RETURNING *
+		)
+		SELECT ` + bundleColumns + ` FROM b`
+	}
+	b, err := scanBundle(r.DB.QueryRowContext(ctx, query, code))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bundle %s: %w", code, err)
+	}
+	return b, nil
+}
+
+func (r *Repository) ListBundleLinks(ctx context.Context, bundleID int64) ([]BundleLink, error) {
+	const query = `
+	SELECT u.short_url, u.long_url, u.click_count
+	FROM bundle_links l
+	JOIN urls u ON u.id = l.url_id
+	WHERE l.bundle_id = $1 AND u.deleted_at IS NULL
+	ORDER BY l.position`
+	rows, err := r.DB.QueryContext(ctx, query, bundleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bundle links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []BundleLink{}
+	for rows.Next() {
+		var l BundleLink
+		if err := rows.Scan(&l.ShortCode, &l.LongURL, &l.ClickCount); err != nil {
+			return nil, fmt.Errorf("failed to scan bundle link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during bundle link iteration: %w", err)
+	}
+	return links, nil
+}
+
+func (r *Repository) ListBundles(ctx context.Context) ([]Bundle, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+bundleColumns+` FROM bundles b ORDER BY b.created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bundles: %w", err)
+	}
+	defer rows.Close()
+
+	bundles := []Bundle{}
+	for rows.Next() {
+		b, err := scanBundle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bundle: %w", err)
+		}
+		bundles = append(bundles, *b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during bundle iteration: %w", err)
+	}
+	return bundles, nil
+}
+
+// DeleteBundle removes a bundle; its links are untouched. Returns
+// sql.ErrNoRows for unknown codes.
+func (r *Repository) DeleteBundle(ctx context.Context, code string) error {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM bundles WHERE code = $1`, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete bundle %s: %w", code, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read deleted bundle count: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
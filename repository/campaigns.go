@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Campaign struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	LinkCount   int       `json:"link_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CampaignLinkStats struct {
+	ShortCode  string `json:"short_url"`
+	LongURL    string `json:"long_url"`
+	ClickCount int    `json:"click_count"`
+	ViewCount  int    `json:"view_count"`
+}
+
+type TimeBucket struct {
+	Bucket time.Time `json:"bucket"`
+	Clicks int       `json:"clicks"`
+}
+
+func (r *Repository) CreateCampaign(ctx context.Context, name string, description string) (*Campaign, error) {
+	const query = `
+	INSERT INTO campaigns (name, description) VALUES ($1, $2)
+	RETURNING id, name, description, created_at
+	`
+	var c Campaign
+	err := r.DB.QueryRowContext(ctx, query, name, description).Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create campaign %s: %w", name, err)
+	}
+	return &c, nil
+}
+
+func (r *Repository) GetCampaign(ctx context.Context, id int64) (*Campaign, error) {
+	const query = `
+	SELECT c.id, c.name, c.description, c.created_at,
+		(SELECT COUNT(*) FROM urls u WHERE u.campaign_id = c.id AND u.deleted_at IS NULL)
+	FROM campaigns c
+	WHERE c.id = $1
+	`
+	var c Campaign
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.LinkCount)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign %d: %w", id, err)
+	}
+	return &c, nil
+}
+
+func (r *Repository) ListCampaigns(ctx context.Context) ([]Campaign, error) {
+	const query = `
+	SELECT c.id, c.name, c.description, c.created_at, COUNT(u.id)
+	FROM campaigns c
+	LEFT JOIN urls u ON u.campaign_id = c.id AND u.deleted_at IS NULL
+	GROUP BY c.id
+	ORDER BY c.created_at DESC
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := []Campaign{}
+	for rows.Next() {
+		var c Campaign
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.LinkCount); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign row: %w", err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during campaign iteration: %w", err)
+	}
+	return campaigns, nil
+}
+
+func (r *Repository) AttachLinksToCampaign(ctx context.Context, campaignID int64, shortCodes []string) ([]string, error) {
+	const query = `
+	UPDATE urls SET campaign_id = $1, updated_at = NOW()
+	WHERE short_url = ANY($2) AND deleted_at IS NULL
+	RETURNING short_url
+	`
+	rows, err := r.DB.QueryContext(ctx, query, campaignID, shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach links to campaign %d: %w", campaignID, err)
+	}
+	defer rows.Close()
+
+	attached := []string{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan attached link: %w", err)
+		}
+		attached = append(attached, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during attach iteration: %w", err)
+	}
+	return attached, nil
+}
+
+func (r *Repository) GetCampaignLinkStats(ctx context.Context, campaignID int64) ([]CampaignLinkStats, error) {
+	const query = `
+	SELECT u.short_url, u.long_url, u.click_count,
+		(SELECT COUNT(*) FROM click_events e WHERE e.url_id = u.id AND e.event_type = 'view')
+	FROM urls u
+	WHERE u.campaign_id = $1 AND u.deleted_at IS NULL
+	ORDER BY u.click_count DESC
+	`
+	rows, err := r.DB.QueryContext(ctx, query, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign link stats: %w", err)
+	}
+	defer rows.Close()
+
+	links := []CampaignLinkStats{}
+	for rows.Next() {
+		var l CampaignLinkStats
+		if err := rows.Scan(&l.ShortCode, &l.LongURL, &l.ClickCount, &l.ViewCount); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign link stats: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during campaign link stats iteration: %w", err)
+	}
+	return links, nil
+}
+
+func (r *Repository) GetCampaignDailyClicks(ctx context.Context, campaignID int64, since time.Time) ([]TimeBucket, error) {
+	const query = `
+	SELECT date_trunc('day', e.occurred_at) AS bucket, ` + estimatedClicks + `
+	FROM click_events e
+	JOIN urls u ON u.id = e.url_id
+	WHERE u.campaign_id = $1 AND e.event_type = 'click' AND e.occurred_at >= $2
+	GROUP BY bucket
+	ORDER BY bucket
+	`
+	rows, err := r.DB.QueryContext(ctx, query, campaignID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign time series: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []TimeBucket{}
+	for rows.Next() {
+		var b TimeBucket
+		if err := rows.Scan(&b.Bucket, &b.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign time bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during campaign time series iteration: %w", err)
+	}
+	return buckets, nil
+}
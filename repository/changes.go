@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const changeExportLockKey = 986_001
+
+// ChangeStreams are the incremental feeds available for export, in the
+// order they are exported.
+var ChangeStreams = []string{"links", "clicks"}
+
+// changeStream describes how one feed is windowed and read. cutoff is the
+// exclusive end of the next window, held back so rows from transactions
+// still in flight are not skipped. rows selects one JSON object per row in
+// [$1, $2).
+type changeStream struct {
+	cutoff string
+	rows   string
+}
+
+var changeStreams = map[string]changeStream{
+	"links": {
+		cutoff: `NOW() - INTERVAL '1 minute'`,
+		rows: `
+		SELECT row_to_json(t)::text FROM (
+			SELECT u.id, u.short_url, u.long_url, u.owner, w.slug AS workspace, c.name AS campaign, u.created_at
+			FROM urls u
+			LEFT JOIN workspaces w ON w.id = u.workspace_id
+			LEFT JOIN campaigns c ON c.id = u.campaign_id
+			WHERE u.created_at >= $1 AND u.created_at < $2 AND u.short_url <> ''
+			ORDER BY u.id
+		) t`,
+	},
+	// Hourly rollups are only exported once the hour has passed.
+	"clicks": {
+		cutoff: `date_trunc('hour', NOW() - INTERVAL '5 minutes')`,
+		rows: `
+		SELECT row_to_json(t)::text FROM (
+			SELECT date_trunc('hour', e.occurred_at) AS hour, u.short_url,
+				COUNT(*) AS events,
+				ROUND(SUM(1 / e.sample_rate))::bigint AS estimated_clicks,
+				COUNT(*) FILTER (WHERE e.flagged) AS flagged
+			FROM click_events e
+			JOIN urls u ON u.id = e.url_id
+			WHERE e.occurred_at >= $1 AND e.occurred_at < $2 AND e.event_type = 'click'
+			GROUP BY 1, 2
+			ORDER BY 1, 2
+		) t`,
+	},
+}
+
+type ChangeExport struct {
+	ID         int64     `json:"id"`
+	Stream     string    `json:"stream"`
+	StorageKey string    `json:"storage_key"`
+	FromTime   time.Time `json:"from"`
+	ToTime     time.Time `json:"to"`
+	RowCount   int64     `json:"row_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportChanges reads the rows of stream added since its last export and
+// hands them to write, which stores them and returns the storage key. The
+// export is only recorded once write succeeds, so a failed upload is
+// retried from the same point. It returns sql.ErrNoRows when the window is
+// empty and ErrLockNotAcquired while another instance exports the stream.
+func (r *Repository) ExportChanges(ctx context.Context, stream string, write func(from, to time.Time, rows func(emit func(row []byte) error) (int64, error)) (string, int64, error)) (*ChangeExport, error) {
+	cs, ok := changeStreams[stream]
+	if !ok {
+		return nil, fmt.Errorf("unknown change stream %q", stream)
+	}
+
+	tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin change export transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1, hashtext($2))", changeExportLockKey, stream).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to acquire change export lock: %w", err)
+	}
+	if !locked {
+		return nil, ErrLockNotAcquired
+	}
+
+	var from sql.NullTime
+	var to time.Time
+	windowQuery := `SELECT (SELECT MAX(to_time) FROM change_exports WHERE stream = $1), ` + cs.cutoff
+	if err := tx.QueryRowContext(ctx, windowQuery, stream).Scan(&from, &to); err != nil {
+		return nil, fmt.Errorf("failed to read change export window for %s: %w", stream, err)
+	}
+	// The first export of a stream covers everything before the cutoff.
+	if from.Valid && !to.After(from.Time) {
+		return nil, sql.ErrNoRows
+	}
+
+	rows := func(emit func(row []byte) error) (int64, error) {
+		result, err := tx.QueryContext(ctx, cs.rows, from.Time, to)
+		if err != nil {
+			return 0, fmt.Errorf("failed to export %s changes: %w", stream, err)
+		}
+		defer result.Close()
+
+		var n int64
+		for result.Next() {
+			var row []byte
+			if err := result.Scan(&row); err != nil {
+				return 0, fmt.Errorf("failed to scan %s change: %w", stream, err)
+			}
+			if err := emit(row); err != nil {
+				return 0, err
+			}
+			n++
+		}
+		if err := result.Err(); err != nil {
+			return 0, fmt.Errorf("error during %s change export: %w", stream, err)
+		}
+		return n, nil
+	}
+
+	key, n, err := write(from.Time, to, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ChangeExport{Stream: stream, StorageKey: key, FromTime: from.Time, ToTime: to, RowCount: n}
+	const insertQuery = `
+	INSERT INTO change_exports (stream, storage_key, from_time, to_time, row_count)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	if err := tx.QueryRowContext(ctx, insertQuery, stream, key, from.Time, to, n).Scan(&export.ID, &export.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to record %s change export: %w", stream, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit %s change export: %w", stream, err)
+	}
+	return export, nil
+}
+
+func (r *Repository) ListChangeExports(ctx context.Context, stream string, limit int) ([]ChangeExport, error) {
+	const query = `
+	SELECT id, stream, storage_key, from_time, to_time, row_count, created_at
+	FROM change_exports
+	WHERE $1 = '' OR stream = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2`
+	rows, err := r.DB.QueryContext(ctx, query, stream, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query change exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []ChangeExport{}
+	for rows.Next() {
+		var e ChangeExport
+		if err := rows.Scan(&e.ID, &e.Stream, &e.StorageKey, &e.FromTime, &e.ToTime, &e.RowCount, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan change export row: %w", err)
+		}
+		exports = append(exports, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during change export iteration: %w", err)
+	}
+	return exports, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CodeFormat is what codes generated for a workspace look like. An empty
+// Alphabet and a zero Length stand for the deployment's defaults.
+type CodeFormat struct {
+	Prefix   string
+	Alphabet string
+	Length   int
+}
+
+// SetWorkspaceCodeFormat changes the alphabet and length of the codes a
+// workspace is issued. Existing codes keep their value.
+func (r *Repository) SetWorkspaceCodeFormat(ctx context.Context, slug string, alphabet string, length int) (*Workspace, error) {
+	query := `UPDATE workspaces SET code_alphabet = $2, code_length = $3 WHERE slug = $1 RETURNING ` + workspaceColumns
+	w, err := scanWorkspace(r.DB.QueryRowContext(ctx, query, slug, alphabet, length))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set code format for workspace %s: %w", slug, err)
+	}
+	return w, nil
+}
+
+// GetLinkCodeFormat returns the code format of the workspace a link belongs
+// to, or the zero format when it has none.
+func (r *Repository) GetLinkCodeFormat(ctx context.Context, shortCode string) (CodeFormat, error) {
+	const query = `
+	SELECT COALESCE(w.code_prefix, ''), COALESCE(w.code_alphabet, ''), COALESCE(w.code_length, 0)
+	FROM urls u
+	LEFT JOIN workspaces w ON w.id = u.workspace_id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	`
+	var f CodeFormat
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&f.Prefix, &f.Alphabet, &f.Length)
+	if err == sql.ErrNoRows {
+		return CodeFormat{}, sql.ErrNoRows
+	}
+	if err != nil {
+		return CodeFormat{}, fmt.Errorf("failed to query code format for %s: %w", shortCode, err)
+	}
+	return f, nil
+}
+
+// GetDeletedLinkCodeFormat is GetLinkCodeFormat for a deleted link, by its
+// ID.
+func (r *Repository) GetDeletedLinkCodeFormat(ctx context.Context, id int64) (CodeFormat, error) {
+	const query = `
+	SELECT COALESCE(w.code_prefix, ''), COALESCE(w.code_alphabet, ''), COALESCE(w.code_length, 0)
+	FROM urls d
+	LEFT JOIN workspaces w ON w.id = d.workspace_id
+	WHERE d.id = $1 AND d.deleted_at IS NOT NULL
+	`
+	var f CodeFormat
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&f.Prefix, &f.Alphabet, &f.Length)
+	if err == sql.ErrNoRows {
+		return CodeFormat{}, sql.ErrNoRows
+	}
+	if err != nil {
+		return CodeFormat{}, fmt.Errorf("failed to query code format for deleted link %d: %w", id, err)
+	}
+	return f, nil
+}
+
+func (r *Repository) CountWorkspaceLinks(ctx context.Context, workspaceID int64) (int64, error) {
+	var n int64
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM urls WHERE workspace_id = $1 AND deleted_at IS NULL`, workspaceID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count links of workspace %d: %w", workspaceID, err)
+	}
+	return n, nil
+}
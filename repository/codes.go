@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// EachShortCode calls fn with every live short code, streaming the rows so
+// that large tables are never held in memory.
+func (r *Repository) EachShortCode(ctx context.Context, fn func(shortCode string)) error {
+	rows, err := r.DB.QueryContext(ctx, "SELECT short_url FROM urls WHERE short_url != '' AND deleted_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to query short codes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return fmt.Errorf("failed to scan short code: %w", err)
+		}
+		fn(code)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during short code iteration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ContentInfo describes what a link's destination serves. ContentLength is
+// nil when the server did not report a size.
+type ContentInfo struct {
+	ContentType   string    `json:"content_type"`
+	ContentLength *int64    `json:"content_length"`
+	Download      bool      `json:"download"`
+	InspectedAt   time.Time `json:"inspected_at"`
+}
+
+// ListUninspectedLinks returns links created within maxAge whose destination
+// has not been inspected yet, oldest first.
+func (r *Repository) ListUninspectedLinks(ctx context.Context, maxAge time.Duration, limit int) ([]HealthTarget, error) {
+	const query = `
+	SELECT u.id, u.short_url, u.long_url
+	FROM urls u
+	WHERE u.short_url != '' AND u.deleted_at IS NULL AND NOT u.mirror
+		AND u.created_at > NOW() - $1 * INTERVAL '1 second'
+		AND NOT EXISTS (SELECT 1 FROM link_content c WHERE c.url_id = u.id)
+	ORDER BY u.created_at
+	LIMIT $2
+	`
+	rows, err := r.DB.QueryContext(ctx, query, int64(maxAge.Seconds()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uninspected links: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []HealthTarget
+	for rows.Next() {
+		var t HealthTarget
+		if err := rows.Scan(&t.URLID, &t.ShortCode, &t.LongURL); err != nil {
+			return nil, fmt.Errorf("failed to scan uninspected link: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during uninspected link iteration: %w", err)
+	}
+	return targets, nil
+}
+
+func (r *Repository) RecordLinkContent(ctx context.Context, urlID int64, info ContentInfo) error {
+	const query = `
+	INSERT INTO link_content (url_id, content_type, content_length, download, inspected_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (url_id) DO UPDATE SET
+		content_type = EXCLUDED.content_type,
+		content_length = EXCLUDED.content_length,
+		download = EXCLUDED.download,
+		inspected_at = NOW()
+	`
+	if _, err := r.DB.ExecContext(ctx, query, urlID, info.ContentType, info.ContentLength, info.Download); err != nil {
+		return fmt.Errorf("failed to record content of URL %d: %w", urlID, err)
+	}
+	return nil
+}
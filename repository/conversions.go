@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Conversion struct {
+	ID         int64     `json:"id"`
+	ShortCode  string    `json:"short_url"`
+	ClickID    string    `json:"click_id"`
+	ClickedAt  time.Time `json:"clicked_at"`
+	Value      *float64  `json:"value"`
+	ReportedBy string    `json:"reported_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+const conversionColumns = `c.id, u.short_url, c.click_id, c.clicked_at, c.value::float8, c.reported_by, c.created_at`
+
+func scanConversion(row interface{ Scan(...any) error }) (*Conversion, error) {
+	var conv Conversion
+	var value sql.NullFloat64
+	if err := row.Scan(&conv.ID, &conv.ShortCode, &conv.ClickID, &conv.ClickedAt, &value, &conv.ReportedBy, &conv.CreatedAt); err != nil {
+		return nil, err
+	}
+	if value.Valid {
+		conv.Value = &value.Float64
+	}
+	return &conv, nil
+}
+
+// InsertConversion records the conversion of one click. A click converts at
+// most once: reporting it again returns the first conversion with created
+// false. Returns sql.ErrNoRows when the link no longer exists.
+func (r *Repository) InsertConversion(ctx context.Context, urlID int64, clickID string, clickedAt time.Time, value *float64, actor string) (*Conversion, bool, error) {
+	query := `
+	WITH c AS (
+		INSERT INTO conversions (url_id, click_id, clicked_at, value, reported_by)
+		SELECT id, $2, $3, $4, $5 FROM urls WHERE id = $1
+		ON CONFLICT (click_id) DO NOTHING
+		RETURNING *
+	)
+	SELECT ` + conversionColumns + ` FROM c JOIN urls u ON u.id = c.url_id`
+	conv, err := scanConversion(r.DB.QueryRowContext(ctx, query, urlID, clickID, clickedAt, value, actor))
+	if err == nil {
+		return conv, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to record conversion of %s: %w", clickID, err)
+	}
+
+	query = `SELECT ` + conversionColumns + ` FROM conversions c JOIN urls u ON u.id = c.url_id WHERE c.click_id = $1`
+	conv, err = scanConversion(r.DB.QueryRowContext(ctx, query, clickID))
+	if err == sql.ErrNoRows {
+		return nil, false, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load conversion of %s: %w", clickID, err)
+	}
+	return conv, false, nil
+}
+
+// SetConversionTracking turns click IDs on or off for a link. Returns
+// sql.ErrNoRows when the short code does not exist.
+func (r *Repository) SetConversionTracking(ctx context.Context, shortCode string, enabled bool) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE urls SET track_conversions = $2, updated_at = NOW() WHERE short_url = $1 AND deleted_at IS NULL`, shortCode, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to set conversion tracking for %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read updated link count: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repository) GetConversionTracking(ctx context.Context, shortCode string) (bool, error) {
+	var enabled bool
+	err := r.DB.QueryRowContext(ctx, `SELECT track_conversions FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, sql.ErrNoRows
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to query conversion tracking for %s: %w", shortCode, err)
+	}
+	return enabled, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type DeletedURL struct {
+	ID         int64     `json:"id"`
+	LongURL    string    `json:"long_url"`
+	ShortCode  string    `json:"short_url"`
+	ClickCount int       `json:"click_count"`
+	DeletedBy  string    `json:"deleted_by"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	// WorkspaceID is the link's workspace, 0 for none.
+	WorkspaceID int64 `json:"-"`
+}
+
+// DeleteURL marks a link deleted. The row and everything hanging off it,
+// clicks, webhooks, schedules and the like, stay until the link is purged,
+// so RestoreDeletedURL brings it back whole. Deleted links are left out of
+// every lookup, and their code can be issued again meanwhile.
+func (r *Repository) DeleteURL(ctx context.Context, shortCode string, actor string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin delete transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+	UPDATE urls SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW()
+	WHERE short_url = $1 AND deleted_at IS NULL
+	RETURNING id, click_count
+	`
+	var id int64
+	var clicks int
+	err = tx.QueryRowContext(ctx, query, shortCode, actor).Scan(&id, &clicks)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete short code %s: %w", shortCode, err)
+	}
+
+	err = insertAuditEntry(ctx, tx, AuditEntry{
+		Actor:   actor,
+		Action:  "link.delete",
+		Target:  shortCode,
+		Details: map[string]any{"url_id": id, "click_count": clicks},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListDeletedURLs(ctx context.Context, days int, shortCodes []string) ([]DeletedURL, error) {
+	query := `
+	SELECT id, long_url, short_url, click_count, COALESCE(deleted_by, ''), deleted_at, COALESCE(workspace_id, 0)
+	FROM urls
+	WHERE deleted_at IS NOT NULL AND deleted_at >= NOW() - $1 * INTERVAL '1 day' AND (cardinality($2::text[]) = 0 OR short_url = ANY($2))
+	ORDER BY deleted_at DESC
+	`
+	if shortCodes == nil {
+		shortCodes = []string{}
+	}
+	rows, err := r.DB.QueryContext(ctx, query, days, shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted URLs: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := []DeletedURL{}
+	for rows.Next() {
+		var d DeletedURL
+		if err := rows.Scan(&d.ID, &d.LongURL, &d.ShortCode, &d.ClickCount, &d.DeletedBy, &d.DeletedAt, &d.WorkspaceID); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted URL: %w", err)
+		}
+		deleted = append(deleted, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during deleted URL iteration: %w", err)
+	}
+	return deleted, nil
+}
+
+// RestoreDeletedURL undeletes a link under shortCode, which differs from
+// the original code when that code was reissued meanwhile.
+func (r *Repository) RestoreDeletedURL(ctx context.Context, id int64, shortCode string, actor string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin restore transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+	UPDATE urls SET deleted_at = NULL, deleted_by = NULL, short_url = $2, updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NOT NULL
+	RETURNING short_url
+	`
+	var restoredCode string
+	err = tx.QueryRowContext(ctx, query, id, shortCode).Scan(&restoredCode)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore deleted URL %d: %w", id, err)
+	}
+
+	err = insertAuditEntry(ctx, tx, AuditEntry{
+		Actor:   actor,
+		Action:  "link.restore",
+		Target:  restoredCode,
+		Details: map[string]any{"url_id": id},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedURLs removes links deleted longer than retention ago, and with
+// them their clicks and settings.
+func (r *Repository) PurgeDeletedURLs(ctx context.Context, retention time.Duration) (int64, error) {
+	const query = `DELETE FROM urls WHERE deleted_at < NOW() - $1 * INTERVAL '1 second'`
+	res, err := r.DB.ExecContext(ctx, query, int64(retention.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted URLs: %w", err)
+	}
+	return res.RowsAffected()
+}
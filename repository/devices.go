@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeviceClicks counts clicks sharing a browser, OS and device class. Rows for
+// clicks recorded before classification existed have an empty Device and carry
+// the raw UserAgent instead.
+type DeviceClicks struct {
+	Browser   string
+	OS        string
+	Device    string
+	UserAgent string
+	Clicks    int
+}
+
+// GetDeviceClicks groups a link's clicks since the given time by device.
+// Returns sql.ErrNoRows when the short code does not exist.
+func (r *Repository) GetDeviceClicks(ctx context.Context, shortCode string, since time.Time) ([]DeviceClicks, error) {
+	var urlID int64
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode).Scan(&urlID)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up short code %s: %w", shortCode, err)
+	}
+
+	const query = `
+	SELECT e.browser, e.os, COALESCE(e.device, ''), CASE WHEN e.device IS NULL THEN e.user_agent ELSE '' END AS ua, ` + estimatedClicks + `
+	FROM click_events e
+	WHERE e.url_id = $1 AND e.event_type = 'click' AND e.occurred_at >= $2
+	GROUP BY e.browser, e.os, e.device, ua
+	`
+	rows, err := r.DB.QueryContext(ctx, query, urlID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device clicks for %s: %w", shortCode, err)
+	}
+	defer rows.Close()
+
+	clicks := []DeviceClicks{}
+	for rows.Next() {
+		var d DeviceClicks
+		if err := rows.Scan(&d.Browser, &d.OS, &d.Device, &d.UserAgent, &d.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan device clicks: %w", err)
+		}
+		clicks = append(clicks, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during device click iteration: %w", err)
+	}
+	return clicks, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LinkSummary is what the duplicate report shows of each link.
+type LinkSummary struct {
+	ID         int64     `json:"-"`
+	ShortCode  string    `json:"short_url"`
+	LongURL    string    `json:"long_url"`
+	ClickCount int       `json:"click_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	Workspace  string    `json:"workspace,omitempty"`
+}
+
+// EachLinkSummary calls fn with every live link, streaming the rows.
+func (r *Repository) EachLinkSummary(ctx context.Context, fn func(LinkSummary)) error {
+	const query = `
+	SELECT u.id, u.short_url, u.long_url, u.click_count, u.created_at, COALESCE(w.slug, '')
+	FROM urls u
+	LEFT JOIN workspaces w ON w.id = u.workspace_id
+	WHERE u.short_url != '' AND u.deleted_at IS NULL
+	ORDER BY u.id
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l LinkSummary
+		if err := rows.Scan(&l.ID, &l.ShortCode, &l.LongURL, &l.ClickCount, &l.CreatedAt, &l.Workspace); err != nil {
+			return fmt.Errorf("failed to scan link: %w", err)
+		}
+		fn(l)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during link iteration: %w", err)
+	}
+	return nil
+}
+
+// GetLinkSummaries returns the links of codes that exist, in no particular
+// order.
+func (r *Repository) GetLinkSummaries(ctx context.Context, codes []string) ([]LinkSummary, error) {
+	const query = `
+	SELECT u.id, u.short_url, u.long_url, u.click_count, u.created_at, COALESCE(w.slug, '')
+	FROM urls u
+	LEFT JOIN workspaces w ON w.id = u.workspace_id
+	WHERE u.short_url = ANY($1::text[]) AND u.deleted_at IS NULL
+	`
+	rows, err := r.DB.QueryContext(ctx, query, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []LinkSummary
+	for rows.Next() {
+		var l LinkSummary
+		if err := rows.Scan(&l.ID, &l.ShortCode, &l.LongURL, &l.ClickCount, &l.CreatedAt, &l.Workspace); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during link iteration: %w", err)
+	}
+	return links, nil
+}
+
+// MergeLinks folds the duplicate links into canonical in one transaction.
+// Their click counts, click events, flags, conversions and bundle entries
+// move to canonical, and their codes become retired codes of canonical that
+// never expire, so they keep redirecting there. Settings of the duplicates,
+// such as webhooks or splits, are dropped with them. Returns sql.ErrNoRows
+// when canonical or any duplicate no longer exists.
+func (r *Repository) MergeLinks(ctx context.Context, canonical string, duplicates []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin merge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var canonicalID int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL FOR UPDATE", canonical).Scan(&canonicalID)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock link %s: %w", canonical, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM urls WHERE short_url = ANY($1::text[]) AND deleted_at IS NULL FOR UPDATE", duplicates)
+	if err != nil {
+		return fmt.Errorf("failed to lock duplicate links: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan duplicate link: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during duplicate link iteration: %w", err)
+	}
+	if len(ids) != len(duplicates) {
+		return sql.ErrNoRows
+	}
+
+	// Rows with a unique key per link are copied with conflicts skipped or
+	// summed; the originals go with the duplicates below.
+	statements := []struct{ what, query string }{
+		{"click counts", `
+		UPDATE urls c SET click_count = c.click_count + d.clicks,
+			last_accessed_at = GREATEST(c.last_accessed_at, d.last_accessed_at), updated_at = NOW()
+		FROM (SELECT SUM(click_count) AS clicks, MAX(last_accessed_at) AS last_accessed_at FROM urls WHERE id = ANY($2::bigint[])) d
+		WHERE c.id = $1`},
+		{"regional click counts", `
+		INSERT INTO regional_click_counts (url_id, region, clicks)
+		SELECT $1::bigint, region, SUM(clicks) FROM regional_click_counts WHERE url_id = ANY($2::bigint[]) GROUP BY region
+		ON CONFLICT (url_id, region) DO UPDATE SET clicks = regional_click_counts.clicks + EXCLUDED.clicks, updated_at = NOW()`},
+		{"click rollups", `
+		INSERT INTO click_rollups_hourly (url_id, hour, clicks)
+		SELECT $1::bigint, hour, SUM(clicks) FROM click_rollups_hourly WHERE url_id = ANY($2::bigint[]) GROUP BY hour
+		ON CONFLICT (url_id, hour) DO UPDATE SET clicks = click_rollups_hourly.clicks + EXCLUDED.clicks`},
+		{"click events", `UPDATE click_events SET url_id = $1 WHERE url_id = ANY($2::bigint[])`},
+		{"conversions", `UPDATE conversions SET url_id = $1 WHERE url_id = ANY($2::bigint[])`},
+		{"click flags", `
+		INSERT INTO click_flags (url_id, ip, asn, reason, flagged_clicks, details, first_detected_at, last_detected_at)
+		SELECT $1::bigint, ip, asn, reason, SUM(flagged_clicks), '{}', MIN(first_detected_at), MAX(last_detected_at)
+		FROM click_flags WHERE url_id = ANY($2::bigint[]) GROUP BY ip, asn, reason
+		ON CONFLICT (url_id, ip, asn, reason) DO UPDATE SET
+			flagged_clicks = click_flags.flagged_clicks + EXCLUDED.flagged_clicks,
+			first_detected_at = LEAST(click_flags.first_detected_at, EXCLUDED.first_detected_at),
+			last_detected_at = GREATEST(click_flags.last_detected_at, EXCLUDED.last_detected_at)`},
+		{"bundle entries", `
+		INSERT INTO bundle_links (bundle_id, url_id, position)
+		SELECT bundle_id, $1::bigint, MIN(position) FROM bundle_links WHERE url_id = ANY($2::bigint[]) GROUP BY bundle_id
+		ON CONFLICT (bundle_id, url_id) DO NOTHING`},
+		{"retired codes", `UPDATE retired_codes SET url_id = $1 WHERE url_id = ANY($2::bigint[])`},
+		{"duplicate codes", `
+		INSERT INTO retired_codes (short_url, url_id, grace_until)
+		SELECT short_url, $1::bigint, TIMESTAMP '9999-12-31 00:00:00' FROM urls WHERE id = ANY($2::bigint[])`},
+		{"duplicate links", `DELETE FROM urls WHERE id = ANY($2::bigint[])`},
+	}
+	for _, s := range statements {
+		if _, err := tx.ExecContext(ctx, s.query, canonicalID, ids); err != nil {
+			return fmt.Errorf("failed to merge %s into %s: %w", s.what, canonical, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return nil
+}
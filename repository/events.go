@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+type LinkStats struct {
+	ShortCode      string     `json:"short_url"`
+	LongURL        string     `json:"long_url"`
+	ClickCount     int        `json:"click_count"`
+	ViewCount      int        `json:"view_count"`
+	FlaggedClicks  int        `json:"flagged_clicks"`
+	Conversions    int        `json:"conversions"`
+	ConversionRate float64    `json:"conversion_rate"`
+	SampleRate     float64    `json:"sample_rate"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+}
+
+func (r *Repository) resolveURLIDs(ctx context.Context, shortCodes []string) (map[string]int64, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT short_url, id FROM urls WHERE short_url = ANY($1) AND deleted_at IS NULL", shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve URL IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int64, len(shortCodes))
+	for rows.Next() {
+		var code string
+		var id int64
+		if err := rows.Scan(&code, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan URL ID: %w", err)
+		}
+		ids[code] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during URL ID iteration: %w", err)
+	}
+	return ids, nil
+}
+
+// clickEventColumns are written, in this order, by both insert paths.
+var clickEventColumns = []string{
+	"url_id", "event_type", "ip", "user_agent", "referer", "occurred_at", "variant",
+	"asn", "as_org", "browser", "os", "device", "sample_rate", "correlation_id",
+	"latitude", "longitude",
+}
+
+// eventRows pairs each event with its link ID, dropping events for codes that
+// no longer exist.
+func (r *Repository) eventRows(ctx context.Context, batch []events.Event) ([][]any, error) {
+	seen := make(map[string]bool)
+	codes := make([]string, 0)
+	for _, e := range batch {
+		if !seen[e.ShortCode] {
+			seen[e.ShortCode] = true
+			codes = append(codes, e.ShortCode)
+		}
+	}
+
+	ids, err := r.resolveURLIDs(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, 0, len(batch))
+	for _, e := range batch {
+		id, ok := ids[e.ShortCode]
+		if !ok {
+			continue
+		}
+		var asn, asOrg, device, correlationID, latitude, longitude any
+		if e.ASN != 0 {
+			asn = int64(e.ASN)
+		}
+		if e.ASOrg != "" {
+			asOrg = e.ASOrg
+		}
+		if e.Device != "" {
+			device = e.Device
+		}
+		if e.CorrelationID != "" {
+			correlationID = e.CorrelationID
+		}
+		if e.Latitude != 0 || e.Longitude != 0 {
+			latitude, longitude = e.Latitude, e.Longitude
+		}
+		sampleRate := e.SampleRate
+		if sampleRate <= 0 || sampleRate > 1 {
+			sampleRate = 1
+		}
+		rows = append(rows, []any{id, e.Type, e.IP, e.UserAgent, e.Referer, e.OccurredAt, e.Variant, asn, asOrg, e.Browser, e.OS, device, float32(sampleRate), correlationID, latitude, longitude})
+	}
+	return rows, nil
+}
+
+// InsertEvents writes a batch of click/view events with the COPY protocol.
+// Events for codes that no longer exist are dropped.
+func (r *Repository) InsertEvents(ctx context.Context, batch []events.Event) (int, error) {
+	rows, err := r.eventRows(ctx, batch)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection for event copy: %w", err)
+	}
+	defer conn.Close()
+
+	var copied int64
+	err = conn.Raw(func(driverConn any) error {
+		// Connections wrapped for fault injection expose pgx underneath.
+		if wrapped, ok := driverConn.(interface{ Unwrap() driver.Conn }); ok {
+			driverConn = wrapped.Unwrap()
+		}
+		pgxConn := driverConn.(*stdlib.Conn).Conn()
+		copied, err = pgxConn.CopyFrom(ctx, pgx.Identifier{"click_events"}, clickEventColumns, pgx.CopyFromRows(rows))
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy %d events: %w", len(rows), err)
+	}
+	return int(copied), nil
+}
+
+// InsertEventsMultiRow writes a batch with one multi-row INSERT. It is the
+// previous ingestion path, kept for comparison in cmd/clickbench.
+func (r *Repository) InsertEventsMultiRow(ctx context.Context, batch []events.Event) (int, error) {
+	rows, err := r.eventRows(ctx, batch)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	columns := len(clickEventColumns)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO click_events (%s) VALUES ", strings.Join(clickEventColumns, ", "))
+	args := make([]any, 0, len(rows)*columns)
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j := range row {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "$%d", len(args)+j+1)
+		}
+		sb.WriteByte(')')
+		args = append(args, row...)
+	}
+
+	if _, err := r.DB.ExecContext(ctx, sb.String(), args...); err != nil {
+		return 0, fmt.Errorf("failed to insert %d events: %w", len(rows), err)
+	}
+	return len(rows), nil
+}
+
+func (r *Repository) GetLinkStats(ctx context.Context, shortCode string) (*LinkStats, error) {
+	const query = `
+	SELECT u.short_url, u.long_url, u.click_count, u.created_at, u.last_accessed_at, u.sample_rate,
+		(SELECT COUNT(*) FROM click_events e WHERE e.url_id = u.id AND e.event_type = 'view'),
+		(SELECT COALESCE(` + estimatedClicks + `, 0) FROM click_events e WHERE e.url_id = u.id AND e.event_type = 'click' AND e.flagged),
+		(SELECT COUNT(*) FROM conversions c WHERE c.url_id = u.id)
+	FROM urls u
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	`
+	var stats LinkStats
+	var lastAccessedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(
+		&stats.ShortCode,
+		&stats.LongURL,
+		&stats.ClickCount,
+		&stats.CreatedAt,
+		&lastAccessedAt,
+		&stats.SampleRate,
+		&stats.ViewCount,
+		&stats.FlaggedClicks,
+		&stats.Conversions,
+	)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats for short code %s: %w", shortCode, err)
+	}
+	if lastAccessedAt.Valid {
+		stats.LastAccessedAt = &lastAccessedAt.Time
+	}
+	return &stats, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LinkExpiry is when a link stops redirecting and where its owner's warning
+// stands. NotifyAfter is set while the warning is snoozed; NotifiedAt once
+// it has been sent.
+type LinkExpiry struct {
+	ShortCode   string     `json:"short_url"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	NotifyAfter *time.Time `json:"notify_after,omitempty"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"`
+}
+
+// ExpiringLink is a link whose owner is due a warning.
+type ExpiringLink struct {
+	ShortCode string
+	ExpiresAt time.Time
+}
+
+const linkExpiryColumns = `short_url, expires_at, expiry_notify_after, expiry_notified_at`
+
+func scanLinkExpiry(row interface{ Scan(...any) error }) (*LinkExpiry, error) {
+	var e LinkExpiry
+	var expiresAt, notifyAfter, notifiedAt sql.NullTime
+	if err := row.Scan(&e.ShortCode, &expiresAt, &notifyAfter, &notifiedAt); err != nil {
+		return nil, err
+	}
+	e.ExpiresAt = utcTime(expiresAt)
+	e.NotifyAfter = utcTime(notifyAfter)
+	e.NotifiedAt = utcTime(notifiedAt)
+	return &e, nil
+}
+
+func utcTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+func (r *Repository) GetLinkExpiry(ctx context.Context, shortCode string) (*LinkExpiry, error) {
+	e, err := scanLinkExpiry(r.DB.QueryRowContext(ctx, `SELECT `+linkExpiryColumns+` FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expiry of %s: %w", shortCode, err)
+	}
+	return e, nil
+}
+
+// SetLinkExpiry sets or, with nil, clears when a link expires. The owner's
+// warning is reset, so a new expiry is warned about again. Returns
+// sql.ErrNoRows when the short code does not exist.
+func (r *Repository) SetLinkExpiry(ctx context.Context, shortCode string, expiresAt *time.Time) (*LinkExpiry, error) {
+	query := `
+	UPDATE urls SET expires_at = $2, expiry_notify_after = NULL, expiry_notified_at = NULL, updated_at = NOW()
+	WHERE short_url = $1 AND deleted_at IS NULL
+	RETURNING ` + linkExpiryColumns
+	e, err := scanLinkExpiry(r.DB.QueryRowContext(ctx, query, shortCode, expiresAt))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set expiry of %s: %w", shortCode, err)
+	}
+	return e, nil
+}
+
+// ExtendLinkExpiry pushes an expiring link's expiry back by d and resets the
+// owner's warning. Returns sql.ErrNoRows when the link does not exist or
+// never expires.
+func (r *Repository) ExtendLinkExpiry(ctx context.Context, shortCode string, d time.Duration) (*LinkExpiry, error) {
+	query := `
+	UPDATE urls SET expires_at = expires_at + $2 * INTERVAL '1 second',
+		expiry_notify_after = NULL, expiry_notified_at = NULL, updated_at = NOW()
+	WHERE short_url = $1 AND deleted_at IS NULL AND expires_at IS NOT NULL
+	RETURNING ` + linkExpiryColumns
+	e, err := scanLinkExpiry(r.DB.QueryRowContext(ctx, query, shortCode, d.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend expiry of %s: %w", shortCode, err)
+	}
+	return e, nil
+}
+
+// SnoozeExpiryNotice holds back the owner's warning until until, sending it
+// again if it had already gone out. Returns sql.ErrNoRows when the link does
+// not exist or never expires.
+func (r *Repository) SnoozeExpiryNotice(ctx context.Context, shortCode string, until time.Time) (*LinkExpiry, error) {
+	query := `
+	UPDATE urls SET expiry_notify_after = $2, expiry_notified_at = NULL, updated_at = NOW()
+	WHERE short_url = $1 AND deleted_at IS NULL AND expires_at IS NOT NULL
+	RETURNING ` + linkExpiryColumns
+	e, err := scanLinkExpiry(r.DB.QueryRowContext(ctx, query, shortCode, until))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snooze expiry notice of %s: %w", shortCode, err)
+	}
+	return e, nil
+}
+
+// ClaimExpiringLinks marks up to limit links expiring between now and
+// horizon as notified and returns them. Marking and reading happen in one
+// statement, and rows another instance is claiming are skipped, so each
+// owner is warned once however many workers scan.
+func (r *Repository) ClaimExpiringLinks(ctx context.Context, now time.Time, horizon time.Time, limit int) ([]ExpiringLink, error) {
+	const query = `
+	UPDATE urls SET expiry_notified_at = $1
+	WHERE id IN (
+		SELECT id FROM urls
+		WHERE expires_at > $1 AND expires_at <= $2 AND deleted_at IS NULL
+			AND expiry_notified_at IS NULL
+			AND (expiry_notify_after IS NULL OR expiry_notify_after <= $1)
+		ORDER BY expires_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING short_url, expires_at
+	`
+	rows, err := r.DB.QueryContext(ctx, query, now, horizon, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim expiring links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []ExpiringLink
+	for rows.Next() {
+		var l ExpiringLink
+		if err := rows.Scan(&l.ShortCode, &l.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expiring link: %w", err)
+		}
+		l.ExpiresAt = l.ExpiresAt.UTC()
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during expiring link iteration: %w", err)
+	}
+	return links, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SuspectTraffic is clicks on one link from one IP, or from every IP of one
+// network when ASN is set and IP is empty, that matched a detector.
+type SuspectTraffic struct {
+	URLID  int64
+	IP     string
+	ASN    uint32
+	Clicks int
+}
+
+// NetworkTraffic is the clicks on one link from one network within a
+// detection window, beside all of the link's clicks in it.
+type NetworkTraffic struct {
+	URLID        int64
+	ASN          uint32
+	Organization string
+	Clicks       int
+	UniqueIPs    int
+	LinkClicks   int
+}
+
+type ClickFlag struct {
+	ID              int64          `json:"id"`
+	ShortCode       string         `json:"short_url"`
+	IP              string         `json:"ip"`
+	ASN             uint32         `json:"asn,omitempty"`
+	Reason          string         `json:"reason"`
+	FlaggedClicks   int            `json:"flagged_clicks"`
+	Details         map[string]any `json:"details"`
+	FirstDetectedAt time.Time      `json:"first_detected_at"`
+	LastDetectedAt  time.Time      `json:"last_detected_at"`
+}
+
+func (r *Repository) querySuspects(ctx context.Context, query string, args ...any) ([]SuspectTraffic, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suspect traffic: %w", err)
+	}
+	defer rows.Close()
+
+	suspects := []SuspectTraffic{}
+	for rows.Next() {
+		var s SuspectTraffic
+		if err := rows.Scan(&s.URLID, &s.IP, &s.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan suspect traffic: %w", err)
+		}
+		suspects = append(suspects, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during suspect traffic iteration: %w", err)
+	}
+	return suspects, nil
+}
+
+// FindClickSpikes returns IPs that produced at least minClicks clicks on a link
+// since the given time and at least minShare of that link's clicks.
+func (r *Repository) FindClickSpikes(ctx context.Context, since time.Time, minClicks int, minShare float64) ([]SuspectTraffic, error) {
+	const query = `
+	WITH per_ip AS (
+		SELECT url_id, ip, COUNT(*) AS clicks
+		FROM click_events
+		WHERE event_type = 'click' AND occurred_at >= $1 AND ip <> ''
+		GROUP BY url_id, ip
+	), per_link AS (
+		SELECT url_id, SUM(clicks) AS total FROM per_ip GROUP BY url_id
+	)
+	SELECT p.url_id, p.ip, p.clicks
+	FROM per_ip p
+	JOIN per_link l ON l.url_id = p.url_id
+	WHERE p.clicks >= $2 AND p.clicks >= l.total * $3
+	`
+	return r.querySuspects(ctx, query, since, minClicks, minShare)
+}
+
+// CountNetworkClicks returns the networks that produced at least minClicks
+// clicks on a link since the given time, with the link's total clicks over
+// the same time. Clicks recorded without an ASN are only counted in the
+// total.
+func (r *Repository) CountNetworkClicks(ctx context.Context, since time.Time, minClicks int) ([]NetworkTraffic, error) {
+	const query = `
+	WITH per_asn AS (
+		SELECT url_id, asn, COALESCE(MAX(as_org), '') AS org, COUNT(*) AS clicks, COUNT(DISTINCT ip) AS ips
+		FROM click_events
+		WHERE event_type = 'click' AND occurred_at >= $1 AND COALESCE(asn, 0) <> 0
+		GROUP BY url_id, asn
+		HAVING COUNT(*) >= $2
+	), per_link AS (
+		SELECT url_id, COUNT(*) AS total
+		FROM click_events
+		WHERE event_type = 'click' AND occurred_at >= $1 AND url_id IN (SELECT url_id FROM per_asn)
+		GROUP BY url_id
+	)
+	SELECT p.url_id, p.asn, p.org, p.clicks, p.ips, l.total
+	FROM per_asn p
+	JOIN per_link l ON l.url_id = p.url_id
+	`
+	rows, err := r.DB.QueryContext(ctx, query, since, minClicks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query network traffic: %w", err)
+	}
+	defer rows.Close()
+
+	traffic := []NetworkTraffic{}
+	for rows.Next() {
+		var t NetworkTraffic
+		var asn int64
+		if err := rows.Scan(&t.URLID, &asn, &t.Organization, &t.Clicks, &t.UniqueIPs, &t.LinkClicks); err != nil {
+			return nil, fmt.Errorf("failed to scan network traffic: %w", err)
+		}
+		t.ASN = uint32(asn)
+		traffic = append(traffic, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during network traffic iteration: %w", err)
+	}
+	return traffic, nil
+}
+
+// FindRapidClicks returns IPs that clicked a link at least minCount times
+// within minGap of their previous click since the given time.
+func (r *Repository) FindRapidClicks(ctx context.Context, since time.Time, minGap time.Duration, minCount int) ([]SuspectTraffic, error) {
+	const query = `
+	SELECT url_id, ip, COUNT(*)
+	FROM (
+		SELECT url_id, ip,
+			occurred_at - LAG(occurred_at) OVER (PARTITION BY url_id, ip ORDER BY occurred_at) AS gap
+		FROM click_events
+		WHERE event_type = 'click' AND occurred_at >= $1 AND ip <> ''
+	) g
+	WHERE gap < $2 * INTERVAL '1 millisecond'
+	GROUP BY url_id, ip
+	HAVING COUNT(*) >= $3
+	`
+	return r.querySuspects(ctx, query, since, minGap.Milliseconds(), minCount)
+}
+
+// FlagClicks marks the suspect's clicks since the given time as flagged and
+// records or refreshes the matching flag. A suspect network's clicks are
+// flagged whichever IP they came from.
+func (r *Repository) FlagClicks(ctx context.Context, suspect SuspectTraffic, reason string, since time.Time, details map[string]any) error {
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode flag details: %w", err)
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin flag transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const flagEvents = `
+	UPDATE click_events SET flagged = TRUE
+	WHERE url_id = $1 AND ($2 = '' OR ip = $2) AND ($4 = 0 OR asn = $4)
+		AND event_type = 'click' AND occurred_at >= $3 AND NOT flagged
+	`
+	if _, err := tx.ExecContext(ctx, flagEvents, suspect.URLID, suspect.IP, since, int64(suspect.ASN)); err != nil {
+		return fmt.Errorf("failed to flag clicks for URL %d: %w", suspect.URLID, err)
+	}
+
+	const upsertFlag = `
+	INSERT INTO click_flags (url_id, ip, asn, reason, flagged_clicks, details)
+	SELECT $1, $2, $5, $3, COUNT(*), $4
+	FROM click_events WHERE url_id = $1 AND ($2 = '' OR ip = $2) AND ($5 = 0 OR asn = $5) AND flagged
+	ON CONFLICT (url_id, ip, asn, reason) DO UPDATE SET
+		flagged_clicks = EXCLUDED.flagged_clicks,
+		details = EXCLUDED.details,
+		last_detected_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, upsertFlag, suspect.URLID, suspect.IP, reason, payload, int64(suspect.ASN)); err != nil {
+		return fmt.Errorf("failed to record flag for URL %d: %w", suspect.URLID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit flag: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListClickFlags(ctx context.Context, days int) ([]ClickFlag, error) {
+	const query = `
+	SELECT f.id, u.short_url, f.ip, f.asn, f.reason, f.flagged_clicks, f.details, f.first_detected_at, f.last_detected_at
+	FROM click_flags f
+	JOIN urls u ON u.id = f.url_id
+	WHERE f.last_detected_at >= NOW() - $1 * INTERVAL '1 day' AND u.deleted_at IS NULL
+	ORDER BY f.last_detected_at DESC
+	`
+	rows, err := r.DB.QueryContext(ctx, query, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []ClickFlag{}
+	for rows.Next() {
+		var f ClickFlag
+		var details []byte
+		var asn int64
+		if err := rows.Scan(&f.ID, &f.ShortCode, &f.IP, &asn, &f.Reason, &f.FlaggedClicks, &details, &f.FirstDetectedAt, &f.LastDetectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan click flag: %w", err)
+		}
+		if err := json.Unmarshal(details, &f.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode click flag details: %w", err)
+		}
+		f.ASN = uint32(asn)
+		flags = append(flags, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during click flag iteration: %w", err)
+	}
+	return flags, nil
+}
+
+// DismissClickFlag deletes a flag and clears the flagged mark on its clicks
+// unless another flag of the link still covers their IP or network.
+func (r *Repository) DismissClickFlag(ctx context.Context, id int64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin dismiss transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var urlID, asn int64
+	var ip string
+	err = tx.QueryRowContext(ctx, `DELETE FROM click_flags WHERE id = $1 RETURNING url_id, ip, asn`, id).Scan(&urlID, &ip, &asn)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete click flag %d: %w", id, err)
+	}
+
+	const unflag = `
+	UPDATE click_events e SET flagged = FALSE
+	WHERE e.url_id = $1 AND ($2 = '' OR e.ip = $2) AND ($3 = 0 OR e.asn = $3) AND e.flagged
+		AND NOT EXISTS (
+			SELECT 1 FROM click_flags f
+			WHERE f.url_id = e.url_id AND (f.ip = '' OR f.ip = e.ip) AND (f.asn = 0 OR f.asn = e.asn)
+		)
+	`
+	if _, err := tx.ExecContext(ctx, unflag, urlID, ip, asn); err != nil {
+		return fmt.Errorf("failed to unflag clicks for URL %d: %w", urlID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit dismiss: %w", err)
+	}
+	return nil
+}
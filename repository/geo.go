@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GeoClicks counts a link's clicks from one square of a latitude/longitude
+// grid. Located is false for the clicks recorded without a location, which
+// are counted in one row. UniqueIPs is counted over sampled clicks only.
+type GeoClicks struct {
+	Latitude  float64
+	Longitude float64
+	Located   bool
+	Clicks    int
+	UniqueIPs int
+}
+
+// GetGeoClicks groups a link's clicks since the given time by their location
+// rounded to places decimal places. Returns sql.ErrNoRows when the short code
+// does not exist.
+func (r *Repository) GetGeoClicks(ctx context.Context, shortCode string, since time.Time, places int) ([]GeoClicks, error) {
+	var urlID int64
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode).Scan(&urlID)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up short code %s: %w", shortCode, err)
+	}
+
+	const query = `
+	SELECT ROUND(e.latitude::numeric, $3)::float8 AS lat, ROUND(e.longitude::numeric, $3)::float8 AS lng,
+		` + estimatedClicks + ` AS clicks, COUNT(DISTINCT e.ip)
+	FROM click_events e
+	WHERE e.url_id = $1 AND e.event_type = 'click' AND e.occurred_at >= $2
+	GROUP BY lat, lng
+	ORDER BY clicks DESC, lat, lng
+	`
+	rows, err := r.DB.QueryContext(ctx, query, urlID, since, places)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query geo clicks for %s: %w", shortCode, err)
+	}
+	defer rows.Close()
+
+	cells := []GeoClicks{}
+	for rows.Next() {
+		var g GeoClicks
+		var lat, lng sql.NullFloat64
+		if err := rows.Scan(&lat, &lng, &g.Clicks, &g.UniqueIPs); err != nil {
+			return nil, fmt.Errorf("failed to scan geo clicks: %w", err)
+		}
+		g.Latitude, g.Longitude, g.Located = lat.Float64, lng.Float64, lat.Valid && lng.Valid
+		cells = append(cells, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during geo click iteration: %w", err)
+	}
+	return cells, nil
+}
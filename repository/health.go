@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// HealthTarget is a link due for a destination health check, with the state
+// left by its previous check.
+type HealthTarget struct {
+	URLID     int64
+	ShortCode string
+	LongURL   string
+	Failures  int
+	Dead      bool
+}
+
+// LinkHealth is the latest check of a link's destination.
+type LinkHealth struct {
+	URLID      int64      `json:"-"`
+	ShortCode  string     `json:"short_url"`
+	LongURL    string     `json:"long_url"`
+	CheckedAt  time.Time  `json:"checked_at"`
+	StatusCode int        `json:"status_code"`
+	Error      string     `json:"error,omitempty"`
+	Failures   int        `json:"consecutive_failures"`
+	DeadSince  *time.Time `json:"dead_since"`
+	ArchiveURL string     `json:"archive_url,omitempty"`
+}
+
+// ListHealthTargets returns links never checked or last checked before
+// olderThan ago, never-checked first. Mirrors point at another shortener and
+// are skipped.
+func (r *Repository) ListHealthTargets(ctx context.Context, olderThan time.Duration, limit int) ([]HealthTarget, error) {
+	const query = `
+	SELECT u.id, u.short_url, u.long_url, COALESCE(h.consecutive_failures, 0), h.dead_since IS NOT NULL
+	FROM urls u
+	LEFT JOIN link_health h ON h.url_id = u.id
+	WHERE u.short_url != '' AND u.deleted_at IS NULL AND NOT u.mirror
+		AND (h.checked_at IS NULL OR h.checked_at < NOW() - $1 * INTERVAL '1 second')
+	ORDER BY h.checked_at NULLS FIRST
+	LIMIT $2
+	`
+	rows, err := r.DB.QueryContext(ctx, query, int64(olderThan.Seconds()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links due for health checks: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []HealthTarget
+	for rows.Next() {
+		var t HealthTarget
+		if err := rows.Scan(&t.URLID, &t.ShortCode, &t.LongURL, &t.Failures, &t.Dead); err != nil {
+			return nil, fmt.Errorf("failed to scan health target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during health target iteration: %w", err)
+	}
+	return targets, nil
+}
+
+// RecordLinkHealth stores a check. dead_since keeps the time the link first
+// died while it stays dead; archiveURL is only replaced when non-empty.
+func (r *Repository) RecordLinkHealth(ctx context.Context, h LinkHealth, dead bool) error {
+	const query = `
+	INSERT INTO link_health (url_id, checked_at, status_code, error, consecutive_failures, dead_since, archive_url)
+	VALUES ($1, NOW(), $2, $3, $4, CASE WHEN $5 THEN NOW() END, $6)
+	ON CONFLICT (url_id) DO UPDATE SET
+		checked_at = NOW(),
+		status_code = EXCLUDED.status_code,
+		error = EXCLUDED.error,
+		consecutive_failures = EXCLUDED.consecutive_failures,
+		dead_since = CASE WHEN $5 THEN COALESCE(link_health.dead_since, NOW()) END,
+		archive_url = CASE WHEN NOT $5 THEN '' WHEN $6 != '' THEN $6 ELSE link_health.archive_url END
+	`
+	_, err := r.DB.ExecContext(ctx, query, h.URLID, h.StatusCode, h.Error, h.Failures, dead, h.ArchiveURL)
+	if err != nil {
+		return fmt.Errorf("failed to record health of URL %d: %w", h.URLID, err)
+	}
+	return nil
+}
+
+func (r *Repository) ListDeadLinks(ctx context.Context) ([]LinkHealth, error) {
+	const query = `
+	SELECT u.short_url, u.long_url, h.checked_at, h.status_code, h.error, h.consecutive_failures, h.dead_since, h.archive_url
+	FROM link_health h
+	JOIN urls u ON u.id = h.url_id
+	WHERE h.dead_since IS NOT NULL AND u.deleted_at IS NULL
+	ORDER BY h.dead_since DESC
+	LIMIT 1000
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []LinkHealth{}
+	for rows.Next() {
+		var l LinkHealth
+		var deadSince sql.NullTime
+		if err := rows.Scan(&l.ShortCode, &l.LongURL, &l.CheckedAt, &l.StatusCode, &l.Error, &l.Failures, &deadSince, &l.ArchiveURL); err != nil {
+			return nil, fmt.Errorf("failed to scan dead link: %w", err)
+		}
+		if deadSince.Valid {
+			l.DeadSince = &deadSince.Time
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during dead link iteration: %w", err)
+	}
+	return links, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type ImportedLink struct {
+	LongURL     string
+	ShortCode   string
+	CreatedAt   time.Time
+	ClickCount  int
+	WorkspaceID *int64
+}
+
+// InsertImportedURL stores a link carried over from another shortener with its
+// original creation date and click count. A zero CreatedAt means now.
+func (r *Repository) InsertImportedURL(ctx context.Context, link ImportedLink) (int64, error) {
+	const query = `
+	INSERT INTO urls (long_url, short_url, created_at, updated_at, click_count, workspace_id)
+	VALUES ($1, $2, COALESCE($3, NOW()), NOW(), $4, $5)
+	RETURNING id
+	`
+	var createdAt *time.Time
+	if !link.CreatedAt.IsZero() {
+		createdAt = &link.CreatedAt
+	}
+	var id int64
+	err := r.DB.QueryRowContext(ctx, query, link.LongURL, link.ShortCode, createdAt, link.ClickCount, link.WorkspaceID).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert imported URL %s: %w", link.ShortCode, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SetLinkInterstitial turns the interstitial page on or off for one link.
+// Returns sql.ErrNoRows when the short code does not exist.
+func (r *Repository) SetLinkInterstitial(ctx context.Context, shortCode string, enabled bool) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE urls SET interstitial = $2, updated_at = NOW() WHERE short_url = $1 AND deleted_at IS NULL`, shortCode, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to set interstitial for %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read updated link count: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetWorkspaceInterstitial turns the interstitial page on or off for every
+// link in a workspace. Links that enable it themselves keep it.
+func (r *Repository) SetWorkspaceInterstitial(ctx context.Context, slug string, enabled bool) (*Workspace, error) {
+	query := `UPDATE workspaces SET interstitial = $2 WHERE slug = $1 RETURNING ` + workspaceColumns
+	w, err := scanWorkspace(r.DB.QueryRowContext(ctx, query, slug, enabled))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set interstitial for workspace %s: %w", slug, err)
+	}
+	return w, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// EstimateIssuedCodes returns the planner's row estimates for urls and
+// retired_codes, the two tables a new code must not collide with. Counting
+// the rows exactly would scan both tables; the estimate is refreshed by
+// autovacuum and is close enough for capacity planning.
+func (r *Repository) EstimateIssuedCodes(ctx context.Context) (int64, error) {
+	const query = `
+	SELECT COALESCE(SUM(GREATEST(reltuples, 0)), 0)::BIGINT
+	FROM pg_class
+	WHERE oid IN ('urls'::regclass, 'retired_codes'::regclass)
+	`
+	var n int64
+	if err := r.DB.QueryRowContext(ctx, query).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to estimate issued codes: %w", err)
+	}
+	return n, nil
+}
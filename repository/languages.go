@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SetLinkLanguages replaces the per-language destinations of a link. An empty
+// map removes them all.
+func (r *Repository) SetLinkLanguages(ctx context.Context, shortCode string, destinations map[string]string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin language update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var urlID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL FOR UPDATE`, shortCode).Scan(&urlID)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up short code %s: %w", shortCode, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM link_languages WHERE url_id = $1`, urlID); err != nil {
+		return fmt.Errorf("failed to clear languages for %s: %w", shortCode, err)
+	}
+	for lang, destination := range destinations {
+		const query = `INSERT INTO link_languages (url_id, lang, destination) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, urlID, lang, destination); err != nil {
+			return fmt.Errorf("failed to store %s destination for %s: %w", lang, shortCode, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit language update: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetLinkLanguages(ctx context.Context, shortCode string) (map[string]string, error) {
+	const query = `
+	SELECT u.id, l.lang, l.destination
+	FROM urls u
+	LEFT JOIN link_languages l ON l.url_id = u.id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	ORDER BY l.lang
+	`
+	rows, err := r.DB.QueryContext(ctx, query, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query languages for %s: %w", shortCode, err)
+	}
+	defer rows.Close()
+
+	found := false
+	destinations := map[string]string{}
+	for rows.Next() {
+		var id int64
+		var lang, destination sql.NullString
+		if err := rows.Scan(&id, &lang, &destination); err != nil {
+			return nil, fmt.Errorf("failed to scan language row: %w", err)
+		}
+		found = true
+		if lang.Valid {
+			destinations[lang.String] = destination.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during language iteration: %w", err)
+	}
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return destinations, nil
+}
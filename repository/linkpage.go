@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PagedURL is a URL as listed by keyset pagination, with the campaign it
+// belongs to, if any.
+type PagedURL struct {
+	URL
+	CampaignID *int64 `json:"campaign_id,omitempty"`
+}
+
+// ListURLsBefore returns up to limit links matching filter with an ID below
+// before, newest first; before 0 starts at the newest link. Unlike ListURLs
+// the cost does not grow with the page number.
+func (r *Repository) ListURLsBefore(ctx context.Context, filter URLFilter, before int64, limit int) ([]PagedURL, error) {
+	where, args := filter.where()
+	conds := []string{"u.short_url <> ''"}
+	if where != "" {
+		conds = append(conds, strings.TrimPrefix(where, "WHERE "))
+	}
+	if before > 0 {
+		args = append(args, before)
+		conds = append(conds, fmt.Sprintf("u.id < $%d", len(args)))
+	}
+	query := fmt.Sprintf(`
+	SELECT u.id, u.long_url, u.short_url, u.click_count, u.created_at, u.updated_at, u.last_accessed_at,
+		COALESCE(u.owner, ''), COALESCE(w.slug, ''), u.campaign_id,
+		t.utm_source, t.utm_medium, t.utm_campaign, t.utm_term, t.utm_content, u.metadata
+	FROM urls u
+	LEFT JOIN workspaces w ON w.id = u.workspace_id
+	LEFT JOIN link_utm t ON t.url_id = u.id
+	WHERE %s
+	ORDER BY u.id DESC
+	LIMIT $%d`, strings.Join(conds, " AND "), len(args)+1)
+	rows, err := r.DB.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query URL page: %w", err)
+	}
+	defer rows.Close()
+
+	urls := []PagedURL{}
+	for rows.Next() {
+		var u PagedURL
+		var lastAccessedAt sql.NullTime
+		var campaignID sql.NullInt64
+		var utmSource, utmMedium, utmCampaign, utmTerm, utmContent sql.NullString
+		var metadata []byte
+		if err := rows.Scan(&u.ID, &u.LongURL, &u.ShortCode, &u.ClickCount, &u.CreatedAt, &u.UpdatedAt, &lastAccessedAt,
+			&u.Owner, &u.Workspace, &campaignID,
+			&utmSource, &utmMedium, &utmCampaign, &utmTerm, &utmContent, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan URL page row: %w", err)
+		}
+		var err error
+		if u.Metadata, err = decodeMetadata(metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata of %s: %w", u.ShortCode, err)
+		}
+		if lastAccessedAt.Valid {
+			u.LastAccessedAt = lastAccessedAt.Time
+		}
+		if campaignID.Valid {
+			u.CampaignID = &campaignID.Int64
+		}
+		if utmSource.Valid {
+			u.UTM = &UTMParams{
+				Source:   utmSource.String,
+				Medium:   utmMedium.String,
+				Campaign: utmCampaign.String,
+				Term:     utmTerm.String,
+				Content:  utmContent.String,
+			}
+		}
+		urls = append(urls, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during URL page iteration: %w", err)
+	}
+	return urls, nil
+}
+
+// GetPagedURL returns one link in the shape ListURLsBefore lists it.
+func (r *Repository) GetPagedURL(ctx context.Context, shortCode string) (*PagedURL, error) {
+	urls, err := r.ListURLsBefore(ctx, URLFilter{ShortCode: shortCode}, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &urls[0], nil
+}
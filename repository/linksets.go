@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LinkSet is a batch of links created at once. Tags, ExpiresAt, CampaignID
+// and Workspace apply to every link in it.
+type LinkSet struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Tags       []string   `json:"tags"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CampaignID *int64     `json:"campaign_id"`
+	Workspace  string     `json:"workspace,omitempty"`
+	LinkCount  int        `json:"link_count"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewLinkSet is what CreateLinkSet stores. ShortCodes, LongURLs and
+// Recipients pair up by index; Recipients is empty, or has an empty entry,
+// for links issued to no one in particular.
+type NewLinkSet struct {
+	Name        string
+	Tags        []string
+	ExpiresAt   *time.Time
+	CampaignID  *int64
+	WorkspaceID *int64
+	CreatedBy   string
+	ShortCodes  []string
+	LongURLs    []string
+	Recipients  []string
+}
+
+type LinkSetLink struct {
+	Recipient  string `json:"recipient,omitempty"`
+	ShortCode  string `json:"short_url"`
+	LongURL    string `json:"long_url"`
+	ClickCount int    `json:"click_count"`
+}
+
+const linkSetColumns = `s.id, s.name, array_to_json(s.tags), s.expires_at, s.campaign_id, COALESCE(w.slug, ''),
+	(SELECT COUNT(*) FROM urls u WHERE u.link_set_id = s.id AND u.deleted_at IS NULL), s.created_by, s.created_at`
+
+func scanLinkSet(row interface{ Scan(...any) error }) (*LinkSet, error) {
+	var s LinkSet
+	var tags []byte
+	var expiresAt sql.NullTime
+	var campaignID sql.NullInt64
+	if err := row.Scan(&s.ID, &s.Name, &tags, &expiresAt, &campaignID, &s.Workspace, &s.LinkCount, &s.CreatedBy, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &s.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode tags of link set %d: %w", s.ID, err)
+	}
+	if expiresAt.Valid {
+		s.ExpiresAt = &expiresAt.Time
+	}
+	if campaignID.Valid {
+		s.CampaignID = &campaignID.Int64
+	}
+	return &s, nil
+}
+
+// CreateLinkSet inserts a link set and all its links in one transaction, so
+// a failure leaves none of them behind.
+func (r *Repository) CreateLinkSet(ctx context.Context, set NewLinkSet) (*LinkSet, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin link set transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+	INSERT INTO link_sets (name, tags, expires_at, campaign_id, workspace_id, created_by)
+	VALUES ($1, $2::text[], $3, $4, $5, $6) RETURNING id`,
+		set.Name, set.Tags, set.ExpiresAt, set.CampaignID, set.WorkspaceID, set.CreatedBy).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create link set %s: %w", set.Name, err)
+	}
+
+	const linkQuery = `
+	INSERT INTO urls (short_url, long_url, updated_at, expires_at, campaign_id, workspace_id, link_set_id, recipient)
+	SELECT l.short_url, l.long_url, NOW(), s.expires_at, s.campaign_id, s.workspace_id, s.id, NULLIF(l.recipient, '')
+	FROM unnest($2::text[], $3::text[], $4::text[]) AS l (short_url, long_url, recipient)
+	CROSS JOIN link_sets s
+	WHERE s.id = $1`
+	recipients := set.Recipients
+	if len(recipients) == 0 {
+		recipients = make([]string, len(set.ShortCodes))
+	}
+	if _, err := tx.ExecContext(ctx, linkQuery, id, set.ShortCodes, set.LongURLs, recipients); err != nil {
+		return nil, fmt.Errorf("failed to create links of link set %s: %w", set.Name, err)
+	}
+
+	s, err := scanLinkSet(tx.QueryRowContext(ctx, `SELECT `+linkSetColumns+` FROM link_sets s LEFT JOIN workspaces w ON w.id = s.workspace_id WHERE s.id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load link set %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit link set %s: %w", set.Name, err)
+	}
+	return s, nil
+}
+
+// GetLinkSet returns sql.ErrNoRows for unknown IDs.
+func (r *Repository) GetLinkSet(ctx context.Context, id int64) (*LinkSet, error) {
+	s, err := scanLinkSet(r.DB.QueryRowContext(ctx, `SELECT `+linkSetColumns+` FROM link_sets s LEFT JOIN workspaces w ON w.id = s.workspace_id WHERE s.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query link set %d: %w", id, err)
+	}
+	return s, nil
+}
+
+// ListLinkSetLinks returns the links of a set in the order they were
+// created.
+func (r *Repository) ListLinkSetLinks(ctx context.Context, id int64) ([]LinkSetLink, error) {
+	const query = `
+	SELECT COALESCE(recipient, ''), short_url, long_url, click_count
+	FROM urls
+	WHERE link_set_id = $1 AND deleted_at IS NULL
+	ORDER BY id
+	`
+	rows, err := r.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links of link set %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	links := []LinkSetLink{}
+	for rows.Next() {
+		var l LinkSetLink
+		if err := rows.Scan(&l.Recipient, &l.ShortCode, &l.LongURL, &l.ClickCount); err != nil {
+			return nil, fmt.Errorf("failed to scan link set link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during link set link iteration: %w", err)
+	}
+	return links, nil
+}
+
+// GetLinkSetRecipientCode returns the code issued to recipient in a set, or
+// sql.ErrNoRows when there is none.
+func (r *Repository) GetLinkSetRecipientCode(ctx context.Context, id int64, recipient string) (string, error) {
+	var code string
+	err := r.DB.QueryRowContext(ctx, `SELECT short_url FROM urls WHERE link_set_id = $1 AND recipient = $2 AND deleted_at IS NULL`, id, recipient).Scan(&code)
+	if err == sql.ErrNoRows {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query recipient of link set %d: %w", id, err)
+	}
+	return code, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyMetadataKeys is returned by PatchLinkMetadata when the patched
+// metadata would have more keys than allowed.
+var ErrTooManyMetadataKeys = errors.New("too many metadata keys")
+
+func (r *Repository) SetURLMetadata(ctx context.Context, id int64, metadata map[string]string) error {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata for ID %d: %w", id, err)
+	}
+	const query = `UPDATE urls SET metadata = $2::jsonb, updated_at = NOW() WHERE id = $1`
+	if _, err := r.DB.ExecContext(ctx, query, id, string(raw)); err != nil {
+		return fmt.Errorf("failed to store metadata for ID %d: %w", id, err)
+	}
+	return nil
+}
+
+// PatchLinkMetadata sets the keys of set and removes those of remove on a
+// link's metadata, leaving other keys alone, and returns the result. The
+// change is rolled back when the result has more than maxKeys keys.
+func (r *Repository) PatchLinkMetadata(ctx context.Context, shortCode string, set map[string]string, remove []string, maxKeys int) (map[string]string, error) {
+	raw, err := json.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata for short code %s: %w", shortCode, err)
+	}
+	if remove == nil {
+		remove = []string{}
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin metadata transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+	UPDATE urls SET metadata = (metadata || $2::jsonb) - $3::text[], updated_at = NOW()
+	WHERE short_url = $1 AND deleted_at IS NULL
+	RETURNING metadata
+	`
+	var patched []byte
+	err = tx.QueryRowContext(ctx, query, shortCode, string(raw), remove).Scan(&patched)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch metadata for short code %s: %w", shortCode, err)
+	}
+	metadata, err := decodeMetadata(patched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metadata for short code %s: %w", shortCode, err)
+	}
+	if len(metadata) > maxKeys {
+		return nil, ErrTooManyMetadataKeys
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// decodeMetadata reads a metadata column. Listings omit empty metadata, so
+// {} decodes to nil.
+func decodeMetadata(raw []byte) (map[string]string, error) {
+	var metadata map[string]string
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+func (r *Repository) SetURLMirror(ctx context.Context, id int64) error {
+	const query = `UPDATE urls SET mirror = TRUE, updated_at = NOW() WHERE id = $1`
+	if _, err := r.DB.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark ID %d as mirror: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// NetworkClicks counts a link's clicks from one autonomous system. Clicks
+// recorded without network data have ASN 0. UniqueIPs is counted over sampled
+// clicks only.
+type NetworkClicks struct {
+	ASN          uint32 `json:"asn"`
+	Organization string `json:"organization"`
+	Clicks       int    `json:"clicks"`
+	UniqueIPs    int    `json:"unique_ips"`
+}
+
+// GetNetworkClicks groups a link's clicks since the given time by ASN, busiest
+// first. Returns sql.ErrNoRows when the short code does not exist.
+func (r *Repository) GetNetworkClicks(ctx context.Context, shortCode string, since time.Time) ([]NetworkClicks, error) {
+	var urlID int64
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode).Scan(&urlID)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up short code %s: %w", shortCode, err)
+	}
+
+	const query = `
+	SELECT COALESCE(e.asn, 0), COALESCE(MAX(e.as_org), ''), ` + estimatedClicks + ` AS clicks, COUNT(DISTINCT e.ip)
+	FROM click_events e
+	WHERE e.url_id = $1 AND e.event_type = 'click' AND e.occurred_at >= $2
+	GROUP BY COALESCE(e.asn, 0)
+	ORDER BY clicks DESC, 1
+	`
+	rows, err := r.DB.QueryContext(ctx, query, urlID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query network clicks for %s: %w", shortCode, err)
+	}
+	defer rows.Close()
+
+	networks := []NetworkClicks{}
+	for rows.Next() {
+		var n NetworkClicks
+		var asn int64
+		if err := rows.Scan(&asn, &n.Organization, &n.Clicks, &n.UniqueIPs); err != nil {
+			return nil, fmt.Errorf("failed to scan network clicks: %w", err)
+		}
+		n.ASN = uint32(asn)
+		networks = append(networks, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during network click iteration: %w", err)
+	}
+	return networks, nil
+}
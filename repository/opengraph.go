@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OpenGraph is how a link unfurls when shared. Empty fields fall back to
+// what the preview page shows without them.
+type OpenGraph struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ImageURL    string    `json:"image_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (r *Repository) UpsertOpenGraph(ctx context.Context, shortCode string, og OpenGraph) (*OpenGraph, error) {
+	const query = `
+	INSERT INTO link_open_graph (url_id, title, description, image_url)
+	SELECT id, $2, $3, $4 FROM urls WHERE short_url = $1 AND deleted_at IS NULL
+	ON CONFLICT (url_id) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description,
+		image_url = EXCLUDED.image_url, updated_at = NOW()
+	RETURNING title, description, image_url, updated_at
+	`
+	var saved OpenGraph
+	err := r.DB.QueryRowContext(ctx, query, shortCode, og.Title, og.Description, og.ImageURL).Scan(
+		&saved.Title, &saved.Description, &saved.ImageURL, &saved.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert Open Graph tags for short code %s: %w", shortCode, err)
+	}
+	return &saved, nil
+}
+
+func (r *Repository) GetOpenGraph(ctx context.Context, shortCode string) (*OpenGraph, error) {
+	const query = `
+	SELECT g.title, g.description, g.image_url, g.updated_at
+	FROM link_open_graph g
+	JOIN urls u ON u.id = g.url_id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	`
+	var og OpenGraph
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&og.Title, &og.Description, &og.ImageURL, &og.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Open Graph tags for short code %s: %w", shortCode, err)
+	}
+	return &og, nil
+}
+
+func (r *Repository) DeleteOpenGraph(ctx context.Context, shortCode string) (bool, error) {
+	const query = `DELETE FROM link_open_graph g USING urls u WHERE u.id = g.url_id AND u.short_url = $1 AND u.deleted_at IS NULL`
+	res, err := r.DB.ExecContext(ctx, query, shortCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete Open Graph tags for short code %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read deleted Open Graph count: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Workspace struct {
+	ID           int64     `json:"id"`
+	Slug         string    `json:"slug"`
+	Name         string    `json:"name"`
+	CodePrefix   string    `json:"code_prefix,omitempty"`
+	CodeAlphabet string    `json:"code_alphabet,omitempty"`
+	CodeLength   int       `json:"code_length,omitempty"`
+	Interstitial bool      `json:"interstitial"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const workspaceColumns = `id, slug, name, COALESCE(code_prefix, ''), code_alphabet, code_length, interstitial, created_at`
+
+func scanWorkspace(row interface{ Scan(...any) error }) (*Workspace, error) {
+	var w Workspace
+	if err := row.Scan(&w.ID, &w.Slug, &w.Name, &w.CodePrefix, &w.CodeAlphabet, &w.CodeLength, &w.Interstitial, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+type AuditEntry struct {
+	Actor   string
+	Action  string
+	Target  string
+	Details map[string]any
+}
+
+type TransferredLink struct {
+	ShortCode         string `json:"short_url"`
+	PreviousOwner     string `json:"previous_owner,omitempty"`
+	PreviousWorkspace string `json:"previous_workspace,omitempty"`
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertAuditEntry(ctx context.Context, db execer, entry AuditEntry) error {
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+
+	const query = `INSERT INTO audit_log (actor, action, target, details) VALUES ($1, $2, $3, $4)`
+	if _, err := db.ExecContext(ctx, query, entry.Actor, entry.Action, entry.Target, payload); err != nil {
+		return fmt.Errorf("failed to write audit entry for %s: %w", entry.Target, err)
+	}
+	return nil
+}
+
+func (r *Repository) InsertAuditEntry(ctx context.Context, entry AuditEntry) error {
+	return insertAuditEntry(ctx, r.DB, entry)
+}
+
+func (r *Repository) CreateWorkspace(ctx context.Context, slug string, name string, codePrefix string) (*Workspace, error) {
+	query := `
+	INSERT INTO workspaces (slug, name, code_prefix) VALUES ($1, $2, NULLIF($3, ''))
+	RETURNING ` + workspaceColumns
+	w, err := scanWorkspace(r.DB.QueryRowContext(ctx, query, slug, name, codePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace %s: %w", slug, err)
+	}
+	return w, nil
+}
+
+func (r *Repository) GetWorkspaceBySlug(ctx context.Context, slug string) (*Workspace, error) {
+	w, err := scanWorkspace(r.DB.QueryRowContext(ctx, `SELECT `+workspaceColumns+` FROM workspaces WHERE slug = $1`, slug))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace %s: %w", slug, err)
+	}
+	return w, nil
+}
+
+// SetWorkspaceCodePrefix reserves codePrefix for a workspace. Existing codes
+// keep their value; only codes issued afterwards carry the prefix.
+func (r *Repository) SetWorkspaceCodePrefix(ctx context.Context, slug string, codePrefix string) (*Workspace, error) {
+	query := `UPDATE workspaces SET code_prefix = NULLIF($2, '') WHERE slug = $1 RETURNING ` + workspaceColumns
+	w, err := scanWorkspace(r.DB.QueryRowContext(ctx, query, slug, codePrefix))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set code prefix for workspace %s: %w", slug, err)
+	}
+	return w, nil
+}
+
+// DeletePendingURL removes a row inserted by InsertURL that never received a
+// short code, so the empty placeholder does not block the next insert.
+func (r *Repository) DeletePendingURL(ctx context.Context, id int64) error {
+	const query = `DELETE FROM urls WHERE id = $1 AND short_url = ''`
+	if _, err := r.DB.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete pending URL %d: %w", id, err)
+	}
+	return nil
+}
+
+// TransferLinks reassigns owner and workspace for the given codes and writes one
+// audit entry per moved link in the same transaction.
+func (r *Repository) TransferLinks(ctx context.Context, codes []string, owner string, workspace *Workspace, actor string) ([]TransferredLink, error) {
+	var workspaceID *int64
+	var workspaceSlug string
+	if workspace != nil {
+		workspaceID = &workspace.ID
+		workspaceSlug = workspace.Slug
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transfer transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const transferQuery = `
+	WITH previous AS (
+		SELECT u.id, u.owner, w.slug AS workspace
+		FROM urls u
+		LEFT JOIN workspaces w ON w.id = u.workspace_id
+		WHERE u.short_url = ANY($1) AND u.deleted_at IS NULL
+		FOR UPDATE OF u
+	)
+	UPDATE urls u
+	SET owner = COALESCE(NULLIF($2, ''), u.owner), workspace_id = COALESCE($3, u.workspace_id), updated_at = NOW()
+	FROM previous
+	WHERE u.id = previous.id
+	RETURNING u.short_url, COALESCE(previous.owner, ''), COALESCE(previous.workspace, '')
+	`
+	rows, err := tx.QueryContext(ctx, transferQuery, codes, owner, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer links: %w", err)
+	}
+
+	var transferred []TransferredLink
+	for rows.Next() {
+		var t TransferredLink
+		if err := rows.Scan(&t.ShortCode, &t.PreviousOwner, &t.PreviousWorkspace); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan transferred link: %w", err)
+		}
+		transferred = append(transferred, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during transfer iteration: %w", err)
+	}
+
+	for _, t := range transferred {
+		err := insertAuditEntry(ctx, tx, AuditEntry{
+			Actor:  actor,
+			Action: "link.transfer",
+			Target: t.ShortCode,
+			Details: map[string]any{
+				"from_owner":     t.PreviousOwner,
+				"from_workspace": t.PreviousWorkspace,
+				"to_owner":       owner,
+				"to_workspace":   workspaceSlug,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transfer: %w", err)
+	}
+	return transferred, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const partitionLockKey = 964_001
+
+// ClickPartition is one monthly partition of click_events covering
+// [From, From+1 month).
+type ClickPartition struct {
+	Name string
+	From time.Time
+}
+
+var clickPartitionName = regexp.MustCompile(`^click_events_p(\d{4})_(\d{2})$`)
+
+// ClickPartitionName returns the partition holding clicks of month's month.
+func ClickPartitionName(month time.Time) string {
+	return fmt.Sprintf("click_events_p%04d_%02d", month.Year(), int(month.Month()))
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// EnsureClickPartition creates the monthly partition containing month unless
+// it already exists. Clicks for that month already sitting in the default
+// partition are moved into it. Returns ErrLockNotAcquired when another
+// instance is maintaining partitions.
+func (r *Repository) EnsureClickPartition(ctx context.Context, month time.Time) (bool, error) {
+	from := monthStart(month)
+	to := from.AddDate(0, 1, 0)
+	name := ClickPartitionName(from)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin partition transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", partitionLockKey).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to acquire partition lock: %w", err)
+	}
+	if !locked {
+		return false, ErrLockNotAcquired
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check partition %s: %w", name, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	// name is built from a date, so it is safe to interpolate.
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s (LIKE click_events INCLUDING DEFAULTS)", name),
+		fmt.Sprintf(`
+		WITH moved AS (
+			DELETE FROM click_events_default WHERE occurred_at >= $1 AND occurred_at < $2 RETURNING *
+		)
+		INSERT INTO %s SELECT * FROM moved`, name),
+		fmt.Sprintf("ALTER TABLE click_events ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')",
+			name, from.Format("2006-01-02"), to.Format("2006-01-02")),
+	}
+	for i, stmt := range statements {
+		var args []any
+		if i == 1 {
+			args = []any{from, to}
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return false, fmt.Errorf("failed to create partition %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit partition %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// ListClickPartitions returns the monthly partitions of click_events, oldest
+// first. The default partition is not included.
+func (r *Repository) ListClickPartitions(ctx context.Context) ([]ClickPartition, error) {
+	const query = `
+	SELECT c.relname
+	FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	WHERE i.inhparent = 'click_events'::regclass
+	ORDER BY c.relname
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list click partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := []ClickPartition{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan click partition: %w", err)
+		}
+		m := clickPartitionName.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		from, err := time.Parse("2006-01", m[1]+"-"+m[2])
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, ClickPartition{Name: name, From: from})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during click partition iteration: %w", err)
+	}
+	return partitions, nil
+}
+
+// DropClickPartition removes a monthly partition and every click in it.
+func (r *Repository) DropClickPartition(ctx context.Context, partition ClickPartition) error {
+	if !clickPartitionName.MatchString(partition.Name) {
+		return fmt.Errorf("refusing to drop %q: not a click partition", partition.Name)
+	}
+	if _, err := r.DB.ExecContext(ctx, "DROP TABLE "+partition.Name); err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", partition.Name, err)
+	}
+	return nil
+}
+
+// PurgeDefaultClickEvents deletes clicks older than before from the default
+// partition, which holds rows no monthly partition covers.
+func (r *Repository) PurgeDefaultClickEvents(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM click_events_default WHERE occurred_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge default click partition: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read purged click count: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// PolledLink and PolledClick are shaped for no-code polling triggers: a
+// string ID that never changes for the same item, newest first.
+type PolledLink struct {
+	ID        string    `json:"id"`
+	ShortCode string    `json:"short_url"`
+	LongURL   string    `json:"long_url"`
+	Owner     string    `json:"owner,omitempty"`
+	Workspace string    `json:"workspace,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PolledClick struct {
+	ID         string    `json:"id"`
+	ShortCode  string    `json:"short_url"`
+	Referer    string    `json:"referer"`
+	Browser    string    `json:"browser"`
+	OS         string    `json:"os"`
+	Device     string    `json:"device"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// pollOrder picks which end of the range past the cursor a poll reads: the
+// newest items on a first poll, the oldest after a cursor.
+func pollOrder(since int64) string {
+	if since == 0 {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// pollSettle holds back items younger than this. IDs are taken before the
+// inserting transaction commits, so a newer item can become visible before
+// an older one; a page ends below the lowest ID still settling, and a
+// cursor past it cannot skip an item committed late.
+const pollSettle = time.Minute
+
+// PollLinks returns links created after the link with ID since. With since
+// 0 it returns the newest links; otherwise it returns the oldest links past
+// the cursor, so a caller paging forward misses none. Either way the result
+// is ordered newest first, and links appear once pollSettle has passed.
+func (r *Repository) PollLinks(ctx context.Context, since int64, limit int) ([]PolledLink, error) {
+	const query = `
+	SELECT id, short_url, long_url, owner, workspace, created_at FROM (
+		SELECT u.id, u.short_url, u.long_url, COALESCE(u.owner, '') AS owner, COALESCE(w.slug, '') AS workspace, u.created_at
+		FROM urls u
+		LEFT JOIN workspaces w ON w.id = u.workspace_id
+		WHERE u.id > $1 AND u.id < COALESCE((
+			SELECT MIN(id) FROM urls WHERE id > $1 AND created_at > NOW() - $3 * INTERVAL '1 second'
+		), $4) AND u.short_url <> '' AND u.deleted_at IS NULL
+		ORDER BY u.id %s
+		LIMIT $2
+	) page
+	ORDER BY id DESC
+	`
+	rows, err := r.DB.QueryContext(ctx, fmt.Sprintf(query, pollOrder(since)), since, limit, pollSettle.Seconds(), int64(math.MaxInt64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to poll links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []PolledLink{}
+	for rows.Next() {
+		var l PolledLink
+		var id int64
+		if err := rows.Scan(&id, &l.ShortCode, &l.LongURL, &l.Owner, &l.Workspace, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan polled link: %w", err)
+		}
+		l.ID = strconv.FormatInt(id, 10)
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during polled link iteration: %w", err)
+	}
+	return links, nil
+}
+
+// PollClicks returns recorded clicks after the click with ID since, with the
+// same paging and settling as PollLinks. Clicks are settled by the time they
+// occurred, so one recorded more than pollSettle after it happened, such as
+// from a backed-up queue, can still be passed by the cursor.
+func (r *Repository) PollClicks(ctx context.Context, since int64, limit int) ([]PolledClick, error) {
+	const query = `
+	SELECT id, short_url, referer, browser, os, device, occurred_at, correlation_id FROM (
+		SELECT e.id, u.short_url, e.referer, e.browser, e.os, COALESCE(e.device, '') AS device, e.occurred_at,
+			COALESCE(e.correlation_id, '') AS correlation_id
+		FROM click_events e
+		JOIN urls u ON u.id = e.url_id
+		WHERE e.id > $1 AND e.id < COALESCE((
+			SELECT MIN(id) FROM click_events WHERE id > $1 AND occurred_at > NOW() - $3 * INTERVAL '1 second'
+		), $4) AND e.event_type = 'click'
+		ORDER BY e.id %s
+		LIMIT $2
+	) page
+	ORDER BY id DESC
+	`
+	rows, err := r.DB.QueryContext(ctx, fmt.Sprintf(query, pollOrder(since)), since, limit, pollSettle.Seconds(), int64(math.MaxInt64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to poll clicks: %w", err)
+	}
+	defer rows.Close()
+
+	clicks := []PolledClick{}
+	for rows.Next() {
+		var c PolledClick
+		var id int64
+		if err := rows.Scan(&id, &c.ShortCode, &c.Referer, &c.Browser, &c.OS, &c.Device, &c.OccurredAt, &c.CorrelationID); err != nil {
+			return nil, fmt.Errorf("failed to scan polled click: %w", err)
+		}
+		c.ID = strconv.FormatInt(id, 10)
+		clicks = append(clicks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during polled click iteration: %w", err)
+	}
+	return clicks, nil
+}
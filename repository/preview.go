@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LinkPreview describes where a short link leads, for showing a visitor
+// before they follow it.
+type LinkPreview struct {
+	ShortCode     string       `json:"short_url"`
+	LongURL       string       `json:"long_url"`
+	CreatedAt     time.Time    `json:"created_at"`
+	ScreenshotURL string       `json:"screenshot_url,omitempty"`
+	CapturedAt    *time.Time   `json:"screenshot_captured_at,omitempty"`
+	Content       *ContentInfo `json:"content,omitempty"`
+	OpenGraph     *OpenGraph   `json:"open_graph,omitempty"`
+
+	URLID          int64  `json:"-"`
+	ScreenshotKey  string `json:"-"`
+	ScreenshotType string `json:"-"`
+}
+
+// ScreenshotTarget is a link still waiting for a screenshot.
+type ScreenshotTarget struct {
+	URLID     int64
+	ShortCode string
+	LongURL   string
+}
+
+func (r *Repository) GetLinkPreview(ctx context.Context, shortCode string) (*LinkPreview, error) {
+	const query = `
+	SELECT u.id, u.short_url, u.long_url, u.created_at,
+		COALESCE(s.storage_key, ''), COALESCE(s.content_type, ''), s.captured_at,
+		c.content_type, c.content_length, COALESCE(c.download, FALSE), c.inspected_at,
+		g.title, g.description, g.image_url, g.updated_at
+	FROM urls u
+	LEFT JOIN link_screenshots s ON s.url_id = u.id
+	LEFT JOIN link_content c ON c.url_id = u.id
+	LEFT JOIN link_open_graph g ON g.url_id = u.id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	`
+	var p LinkPreview
+	var capturedAt, inspectedAt sql.NullTime
+	var contentType sql.NullString
+	var contentLength sql.NullInt64
+	var download bool
+	var ogTitle, ogDescription, ogImage sql.NullString
+	var ogUpdatedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&p.URLID, &p.ShortCode, &p.LongURL, &p.CreatedAt,
+		&p.ScreenshotKey, &p.ScreenshotType, &capturedAt,
+		&contentType, &contentLength, &download, &inspectedAt,
+		&ogTitle, &ogDescription, &ogImage, &ogUpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query preview of %s: %w", shortCode, err)
+	}
+	if capturedAt.Valid {
+		p.CapturedAt = &capturedAt.Time
+	}
+	if inspectedAt.Valid {
+		p.Content = &ContentInfo{ContentType: contentType.String, Download: download, InspectedAt: inspectedAt.Time}
+		if contentLength.Valid {
+			p.Content.ContentLength = &contentLength.Int64
+		}
+	}
+	if ogUpdatedAt.Valid {
+		p.OpenGraph = &OpenGraph{Title: ogTitle.String, Description: ogDescription.String, ImageURL: ogImage.String, UpdatedAt: ogUpdatedAt.Time}
+	}
+	return &p, nil
+}
+
+// ListScreenshotTargets returns links created within maxAge that have no
+// screenshot and fewer than maxAttempts failed captures, oldest first.
+func (r *Repository) ListScreenshotTargets(ctx context.Context, maxAge time.Duration, maxAttempts int, limit int) ([]ScreenshotTarget, error) {
+	const query = `
+	SELECT u.id, u.short_url, u.long_url
+	FROM urls u
+	LEFT JOIN link_screenshots s ON s.url_id = u.id
+	WHERE u.short_url != '' AND u.deleted_at IS NULL AND NOT u.mirror
+		AND u.created_at > NOW() - $1 * INTERVAL '1 second'
+		AND (s.url_id IS NULL OR (s.storage_key = '' AND s.attempts < $2))
+	ORDER BY u.created_at
+	LIMIT $3
+	`
+	rows, err := r.DB.QueryContext(ctx, query, int64(maxAge.Seconds()), maxAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links without screenshots: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []ScreenshotTarget
+	for rows.Next() {
+		var t ScreenshotTarget
+		if err := rows.Scan(&t.URLID, &t.ShortCode, &t.LongURL); err != nil {
+			return nil, fmt.Errorf("failed to scan screenshot target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during screenshot target iteration: %w", err)
+	}
+	return targets, nil
+}
+
+// RecordScreenshot stores a capture attempt. An empty key records a failure.
+func (r *Repository) RecordScreenshot(ctx context.Context, urlID int64, key string, contentType string, captureErr string) error {
+	const query = `
+	INSERT INTO link_screenshots (url_id, storage_key, content_type, attempts, error, captured_at)
+	VALUES ($1, $2, $3, 1, $4, CASE WHEN $2 != '' THEN NOW() END)
+	ON CONFLICT (url_id) DO UPDATE SET
+		storage_key = EXCLUDED.storage_key,
+		content_type = EXCLUDED.content_type,
+		attempts = link_screenshots.attempts + 1,
+		error = EXCLUDED.error,
+		captured_at = EXCLUDED.captured_at
+	`
+	if _, err := r.DB.ExecContext(ctx, query, urlID, key, contentType, captureErr); err != nil {
+		return fmt.Errorf("failed to record screenshot of URL %d: %w", urlID, err)
+	}
+	return nil
+}
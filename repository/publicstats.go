@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PublicStats is what anyone may see about a link whose owner published its
+// stats. Flagged clicks and visitor details are left out.
+type PublicStats struct {
+	ShortCode  string       `json:"short_url"`
+	LongURL    string       `json:"long_url"`
+	ClickCount int          `json:"click_count"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Daily      []TimeBucket `json:"daily_clicks"`
+
+	URLID int64 `json:"-"`
+}
+
+// GetPublicStats loads a link's totals only if its stats are public.
+// Returns sql.ErrNoRows for unknown codes and private links alike.
+func (r *Repository) GetPublicStats(ctx context.Context, shortCode string) (*PublicStats, error) {
+	const query = `SELECT id, short_url, long_url, click_count, created_at FROM urls WHERE short_url = $1 AND deleted_at IS NULL AND public_stats`
+	var s PublicStats
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&s.URLID, &s.ShortCode, &s.LongURL, &s.ClickCount, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query public stats for %s: %w", shortCode, err)
+	}
+	return &s, nil
+}
+
+// GetLinkDailyClicks returns estimated clicks per UTC day since since, for
+// days that had any.
+func (r *Repository) GetLinkDailyClicks(ctx context.Context, urlID int64, since time.Time) ([]TimeBucket, error) {
+	const query = `
+	SELECT date_trunc('day', e.occurred_at) AS bucket, ` + estimatedClicks + `
+	FROM click_events e
+	WHERE e.url_id = $1 AND e.event_type = 'click' AND e.occurred_at >= $2
+	GROUP BY bucket
+	ORDER BY bucket
+	`
+	rows, err := r.DB.QueryContext(ctx, query, urlID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query link time series: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []TimeBucket{}
+	for rows.Next() {
+		var b TimeBucket
+		if err := rows.Scan(&b.Bucket, &b.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan link time bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during link time series iteration: %w", err)
+	}
+	return buckets, nil
+}
+
+// SetPublicStats publishes or hides a link's stats. Returns sql.ErrNoRows
+// when the short code does not exist.
+func (r *Repository) SetPublicStats(ctx context.Context, shortCode string, public bool) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE urls SET public_stats = $2, updated_at = NOW() WHERE short_url = $1 AND deleted_at IS NULL`, shortCode, public)
+	if err != nil {
+		return fmt.Errorf("failed to set public stats for %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read updated link count: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repository) IsPublicStats(ctx context.Context, shortCode string) (bool, error) {
+	var public bool
+	err := r.DB.QueryRowContext(ctx, `SELECT public_stats FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode).Scan(&public)
+	if err == sql.ErrNoRows {
+		return false, sql.ErrNoRows
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to query public stats flag for %s: %w", shortCode, err)
+	}
+	return public, nil
+}
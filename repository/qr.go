@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// QRStyle is how a QR code is drawn: module and background colors as
+// #rrggbb, the image size in pixels, and whether the workspace logo is
+// overlaid in the middle.
+type QRStyle struct {
+	Foreground string `json:"foreground"`
+	Background string `json:"background"`
+	Size       int    `json:"size"`
+	Logo       bool   `json:"logo"`
+}
+
+// GetWorkspaceQRStyle returns the QR defaults of a workspace by ID, or
+// sql.ErrNoRows when it has none.
+func (r *Repository) GetWorkspaceQRStyle(ctx context.Context, workspaceID int64) (*QRStyle, error) {
+	const query = `SELECT foreground, background, size, logo FROM workspace_qr_styles WHERE workspace_id = $1`
+	var style QRStyle
+	err := r.DB.QueryRowContext(ctx, query, workspaceID).Scan(&style.Foreground, &style.Background, &style.Size, &style.Logo)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query QR style of workspace %d: %w", workspaceID, err)
+	}
+	return &style, nil
+}
+
+// GetWorkspaceQRStyleBySlug returns the ID of the workspace and its QR
+// defaults, nil when it has none. Returns sql.ErrNoRows when the workspace
+// does not exist.
+func (r *Repository) GetWorkspaceQRStyleBySlug(ctx context.Context, slug string) (int64, *QRStyle, error) {
+	const query = `
+	SELECT w.id, q.foreground, q.background, q.size, q.logo
+	FROM workspaces w
+	LEFT JOIN workspace_qr_styles q ON q.workspace_id = w.id
+	WHERE w.slug = $1
+	`
+	var id int64
+	var fg, bg sql.NullString
+	var size sql.NullInt64
+	var logo sql.NullBool
+	err := r.DB.QueryRowContext(ctx, query, slug).Scan(&id, &fg, &bg, &size, &logo)
+	if err == sql.ErrNoRows {
+		return 0, nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to query QR style of workspace %s: %w", slug, err)
+	}
+	if !fg.Valid {
+		return id, nil, nil
+	}
+	return id, &QRStyle{Foreground: fg.String, Background: bg.String, Size: int(size.Int64), Logo: logo.Bool}, nil
+}
+
+// SetWorkspaceQRStyle stores the QR defaults of a workspace. Returns
+// sql.ErrNoRows when the workspace does not exist.
+func (r *Repository) SetWorkspaceQRStyle(ctx context.Context, slug string, style QRStyle) error {
+	const query = `
+	INSERT INTO workspace_qr_styles (workspace_id, foreground, background, size, logo)
+	SELECT id, $2, $3, $4, $5 FROM workspaces WHERE slug = $1
+	ON CONFLICT (workspace_id) DO UPDATE SET foreground = EXCLUDED.foreground, background = EXCLUDED.background,
+		size = EXCLUDED.size, logo = EXCLUDED.logo, updated_at = NOW()
+	`
+	res, err := r.DB.ExecContext(ctx, query, slug, style.Foreground, style.Background, style.Size, style.Logo)
+	if err != nil {
+		return fmt.Errorf("failed to store QR style of workspace %s: %w", slug, err)
+	}
+	return requireRows(res)
+}
+
+// DeleteWorkspaceQRStyle returns sql.ErrNoRows when the workspace had no QR
+// defaults.
+func (r *Repository) DeleteWorkspaceQRStyle(ctx context.Context, slug string) error {
+	const query = `
+	DELETE FROM workspace_qr_styles q USING workspaces w
+	WHERE q.workspace_id = w.id AND w.slug = $1
+	`
+	res, err := r.DB.ExecContext(ctx, query, slug)
+	if err != nil {
+		return fmt.Errorf("failed to delete QR style of workspace %s: %w", slug, err)
+	}
+	return requireRows(res)
+}
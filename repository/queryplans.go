@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QueryPlan is the plan of one sampled query. Analyzed plans carry actual
+// row counts and timings; the others are the planner's estimates.
+type QueryPlan struct {
+	ID         int64     `json:"id"`
+	Query      string    `json:"query"`
+	Plan       string    `json:"plan"`
+	Analyzed   bool      `json:"analyzed"`
+	DurationMS float64   `json:"duration_ms"`
+	SampledAt  time.Time `json:"sampled_at"`
+}
+
+// ExplainQuery returns the plan of query with args. With analyze the query
+// runs again, in a read-only transaction that is rolled back, so a query
+// that writes fails instead of writing twice.
+func (r *Repository) ExplainQuery(ctx context.Context, query string, args []any, analyze bool) (string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin explain transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	explain := "EXPLAIN "
+	if analyze {
+		if _, err := tx.ExecContext(ctx, "SET TRANSACTION READ ONLY"); err != nil {
+			return "", fmt.Errorf("failed to make explain transaction read-only: %w", err)
+		}
+		explain = "EXPLAIN (ANALYZE, BUFFERS) "
+	}
+	rows, err := tx.QueryContext(ctx, explain+query, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to explain query: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", fmt.Errorf("failed to scan plan line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error during plan iteration: %w", err)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (r *Repository) InsertQueryPlan(ctx context.Context, p QueryPlan) error {
+	const query = `INSERT INTO query_plans (query, plan, analyzed, duration_ms) VALUES ($1, $2, $3, $4)`
+	if _, err := r.DB.ExecContext(ctx, query, p.Query, p.Plan, p.Analyzed, p.DurationMS); err != nil {
+		return fmt.Errorf("failed to store query plan: %w", err)
+	}
+	return nil
+}
+
+// ListQueryPlans returns the newest plans, of queries containing contains
+// when it is set.
+func (r *Repository) ListQueryPlans(ctx context.Context, contains string, limit int) ([]QueryPlan, error) {
+	const query = `
+	SELECT id, query, plan, analyzed, duration_ms, sampled_at
+	FROM query_plans
+	WHERE $1 = '' OR strpos(query, $1) > 0
+	ORDER BY sampled_at DESC
+	LIMIT $2
+	`
+	rows, err := r.DB.QueryContext(ctx, query, contains, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []QueryPlan{}
+	for rows.Next() {
+		var p QueryPlan
+		if err := rows.Scan(&p.ID, &p.Query, &p.Plan, &p.Analyzed, &p.DurationMS, &p.SampledAt); err != nil {
+			return nil, fmt.Errorf("failed to scan query plan: %w", err)
+		}
+		plans = append(plans, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during query plan iteration: %w", err)
+	}
+	return plans, nil
+}
+
+func (r *Repository) PurgeQueryPlans(ctx context.Context, retention time.Duration) (int64, error) {
+	const query = `DELETE FROM query_plans WHERE sampled_at < NOW() - $1 * INTERVAL '1 second'`
+	res, err := r.DB.ExecContext(ctx, query, int64(retention.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge query plans: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// ClickRecount is a link whose click_count disagrees with its recorded
+// click events. Recounted weighs each event by its sample rate.
+type ClickRecount struct {
+	ShortCode  string `json:"short_url"`
+	ClickCount int64  `json:"click_count"`
+	Recounted  int64  `json:"recounted"`
+	Difference int64  `json:"difference"`
+}
+
+// recountQuery selects id, short_url, click_count and the recounted clicks
+// of the links in $1, or of all links when $1 is empty.
+const recountQuery = `
+	SELECT u.id, u.short_url, u.click_count::bigint AS click_count, COALESCE(e.clicks, 0) AS clicks
+	FROM urls u
+	LEFT JOIN (
+		SELECT url_id, ROUND(SUM(1 / sample_rate))::bigint AS clicks
+		FROM click_events
+		WHERE event_type = 'click'
+			AND (COALESCE(cardinality($1::text[]), 0) = 0 OR url_id IN (SELECT id FROM urls WHERE short_url = ANY($1::text[]) AND deleted_at IS NULL))
+		GROUP BY url_id
+	) e ON e.url_id = u.id
+	WHERE u.short_url != '' AND u.deleted_at IS NULL AND (COALESCE(cardinality($1::text[]), 0) = 0 OR u.short_url = ANY($1::text[]))`
+
+// ListClickRecounts returns up to limit links of codes, or of all links
+// when codes is empty, whose click_count differs from their recounted
+// click events, largest difference first.
+func (r *Repository) ListClickRecounts(ctx context.Context, codes []string, limit int) ([]ClickRecount, error) {
+	query := `
+	SELECT short_url, click_count, clicks
+	FROM (` + recountQuery + `) c
+	WHERE click_count <> clicks
+	ORDER BY ABS(click_count - clicks) DESC, id
+	LIMIT $2
+	`
+	rows, err := r.DB.QueryContext(ctx, query, codes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recount clicks: %w", err)
+	}
+	defer rows.Close()
+
+	recounts := []ClickRecount{}
+	for rows.Next() {
+		var c ClickRecount
+		if err := rows.Scan(&c.ShortCode, &c.ClickCount, &c.Recounted); err != nil {
+			return nil, fmt.Errorf("failed to scan click recount: %w", err)
+		}
+		c.Difference = c.ClickCount - c.Recounted
+		recounts = append(recounts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during click recount iteration: %w", err)
+	}
+	return recounts, nil
+}
+
+// RepairClickCounts raises the click_count of links of codes, or of all
+// links when codes is empty, to their recounted click events, and returns
+// how many links changed. The clicks added are credited to this region's
+// counter so the regional totals agree. Counts above the recount are left
+// alone: events may have been purged by retention, and imported links
+// carry clicks that never had events.
+func (r *Repository) RepairClickCounts(ctx context.Context, codes []string) (int64, error) {
+	query := `
+	WITH recount AS (` + recountQuery + `),
+	updated AS (
+		UPDATE urls u SET click_count = r.clicks, updated_at = NOW()
+		FROM recount r
+		WHERE u.id = r.id AND r.click_count < r.clicks
+		RETURNING u.id, r.clicks - r.click_count AS added
+	)
+	INSERT INTO regional_click_counts (url_id, region, clicks)
+	SELECT id, $2, added FROM updated
+	ON CONFLICT (url_id, region) DO UPDATE SET
+		clicks = regional_click_counts.clicks + EXCLUDED.clicks,
+		updated_at = NOW()
+	`
+	res, err := r.DB.ExecContext(ctx, query, codes, r.Region)
+	if err != nil {
+		return 0, fmt.Errorf("failed to repair click counts: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count repaired links: %w", err)
+	}
+	return n, nil
+}
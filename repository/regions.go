@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ClickDrift is a link whose local click_count disagrees with the sum of
+// its per-region counters.
+type ClickDrift struct {
+	ShortCode   string           `json:"short_url"`
+	ClickCount  int64            `json:"click_count"`
+	MergedCount int64            `json:"merged_count"`
+	Drift       int64            `json:"drift"`
+	Regions     map[string]int64 `json:"regions"`
+}
+
+// ListClickDrift returns up to limit links whose click_count differs from
+// the merged regional total, largest difference first.
+func (r *Repository) ListClickDrift(ctx context.Context, limit int) ([]ClickDrift, error) {
+	const query = `
+	SELECT u.short_url, u.click_count, m.total, m.regions
+	FROM urls u
+	JOIN (
+		SELECT url_id, SUM(clicks) AS total, json_object_agg(region, clicks) AS regions
+		FROM regional_click_counts
+		GROUP BY url_id
+	) m ON m.url_id = u.id
+	WHERE u.click_count <> m.total
+	ORDER BY ABS(u.click_count - m.total) DESC, u.id
+	LIMIT $1
+	`
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click drift: %w", err)
+	}
+	defer rows.Close()
+
+	drift := []ClickDrift{}
+	for rows.Next() {
+		var d ClickDrift
+		var regions []byte
+		if err := rows.Scan(&d.ShortCode, &d.ClickCount, &d.MergedCount, &regions); err != nil {
+			return nil, fmt.Errorf("failed to scan click drift row: %w", err)
+		}
+		if err := json.Unmarshal(regions, &d.Regions); err != nil {
+			return nil, fmt.Errorf("failed to decode regional counts for %s: %w", d.ShortCode, err)
+		}
+		d.Drift = d.ClickCount - d.MergedCount
+		drift = append(drift, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during click drift iteration: %w", err)
+	}
+	return drift, nil
+}
+
+// ReconcileClickCounts raises click_count to the merged regional total
+// wherever replication dropped increments, and returns how many links
+// changed. Counts above the total, such as imported links, are left alone.
+func (r *Repository) ReconcileClickCounts(ctx context.Context) (int64, error) {
+	const query = `
+	UPDATE urls u SET click_count = m.total, updated_at = NOW()
+	FROM (SELECT url_id, SUM(clicks) AS total FROM regional_click_counts GROUP BY url_id) m
+	WHERE m.url_id = u.id AND u.click_count < m.total
+	`
+	res, err := r.DB.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reconcile click counts: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reconciled links: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type ReportSchedule struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Frequency string     `json:"frequency"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ExportedLink struct {
+	LinkStats
+	Owner     string
+	Workspace string
+	Campaign  string
+}
+
+const reportScheduleColumns = `id, name, frequency, next_run_at, last_run_at, last_error, created_at`
+
+func scanReportSchedule(row interface{ Scan(...any) error }) (*ReportSchedule, error) {
+	var s ReportSchedule
+	var lastRunAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.Name, &s.Frequency, &s.NextRunAt, &lastRunAt, &s.LastError, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if lastRunAt.Valid {
+		s.LastRunAt = &lastRunAt.Time
+	}
+	return &s, nil
+}
+
+func (r *Repository) CreateReportSchedule(ctx context.Context, name string, frequency string, firstRun time.Time) (*ReportSchedule, error) {
+	query := `INSERT INTO report_schedules (name, frequency, next_run_at) VALUES ($1, $2, $3) RETURNING ` + reportScheduleColumns
+	s, err := scanReportSchedule(r.DB.QueryRowContext(ctx, query, name, frequency, firstRun))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report schedule %s: %w", name, err)
+	}
+	return s, nil
+}
+
+func (r *Repository) ListReportSchedules(ctx context.Context) ([]ReportSchedule, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+reportScheduleColumns+` FROM report_schedules ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []ReportSchedule{}
+	for rows.Next() {
+		s, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report schedule: %w", err)
+		}
+		schedules = append(schedules, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during report schedule iteration: %w", err)
+	}
+	return schedules, nil
+}
+
+func (r *Repository) DeleteReportSchedule(ctx context.Context, id int64) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM report_schedules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete report schedule %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read deleted schedule count: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ClaimDueReportSchedule advances the next run of one due schedule and returns it.
+// Claiming by advancing next_run_at keeps concurrent instances from running the
+// same report twice. Returns sql.ErrNoRows when nothing is due.
+func (r *Repository) ClaimDueReportSchedule(ctx context.Context) (*ReportSchedule, error) {
+	query := `
+	UPDATE report_schedules
+	SET next_run_at = GREATEST(next_run_at, NOW()) +
+		CASE frequency WHEN 'weekly' THEN INTERVAL '7 days' ELSE INTERVAL '1 day' END
+	WHERE id = (
+		SELECT id FROM report_schedules
+		WHERE next_run_at <= NOW()
+		ORDER BY next_run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + reportScheduleColumns
+	s, err := scanReportSchedule(r.DB.QueryRowContext(ctx, query))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due report schedule: %w", err)
+	}
+	return s, nil
+}
+
+func (r *Repository) RecordReportRun(ctx context.Context, id int64, runErr string) error {
+	const query = `UPDATE report_schedules SET last_run_at = NOW(), last_error = $2 WHERE id = $1`
+	if _, err := r.DB.ExecContext(ctx, query, id, runErr); err != nil {
+		return fmt.Errorf("failed to record report run for schedule %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Repository) ForEachExportedLink(ctx context.Context, fn func(ExportedLink) error) error {
+	const query = `
+	SELECT u.short_url, u.long_url, u.click_count, COALESCE(v.views, 0), u.created_at, u.last_accessed_at,
+		COALESCE(u.owner, ''), COALESCE(w.slug, ''), COALESCE(c.name, '')
+	FROM urls u
+	LEFT JOIN (
+		SELECT url_id, COUNT(*) AS views FROM click_events WHERE event_type = 'view' GROUP BY url_id
+	) v ON v.url_id = u.id
+	LEFT JOIN workspaces w ON w.id = u.workspace_id
+	LEFT JOIN campaigns c ON c.id = u.campaign_id
+	WHERE u.deleted_at IS NULL
+	ORDER BY u.id
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query links for export: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l ExportedLink
+		var lastAccessedAt sql.NullTime
+		err := rows.Scan(&l.ShortCode, &l.LongURL, &l.ClickCount, &l.ViewCount, &l.CreatedAt, &lastAccessedAt,
+			&l.Owner, &l.Workspace, &l.Campaign)
+		if err != nil {
+			return fmt.Errorf("failed to scan exported link: %w", err)
+		}
+		if lastAccessedAt.Valid {
+			l.LastAccessedAt = &lastAccessedAt.Time
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during export iteration: %w", err)
+	}
+	return nil
+}
@@ -3,34 +3,74 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 )
 type URL struct {
-	ID             int64     `json:"id"`
-	LongURL        string    `json:"long_url"`
-	ShortCode      string    `json:"short_url"`
-	ClickCount     int       `json:"click_count"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	LastAccessedAt time.Time `json:"last_accessed_at"`
+	ID             int64             `json:"id"`
+	LongURL        string            `json:"long_url"`
+	ShortCode      string            `json:"short_url"`
+	ClickCount     int               `json:"click_count"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	LastAccessedAt time.Time         `json:"last_accessed_at"`
+	Owner          string            `json:"owner,omitempty"`
+	Workspace      string            `json:"workspace,omitempty"`
+	UTM            *UTMParams        `json:"utm,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+// Destination is where a short code redirects. Mirror links point at a short
+// URL on another service and redirect permanently. Languages maps language
+// tags to localized alternatives of LongURL; Schedule overrides both during
+// its time windows. When SplitDestination is set, SplitPercent of visitors
+// go there instead. SampleRate is the fraction of clicks recorded in detail.
+// Links PendingReview must not redirect until approved. ArchiveURL is set when
+// health checks found LongURL dead and an archived copy exists.
+// TrackConversions links carry a click ID to their destination.
+// Interstitial links, or links in a workspace that enables it, show a
+// countdown page before redirecting. WorkspaceID is 0 for links outside any
+// workspace. ExpiresAt is zero for links that never expire.
+type Destination struct {
+	ID               int64
+	WorkspaceID      int64
+	ExpiresAt        time.Time
+	LongURL          string
+	Mirror           bool
+	Languages        map[string]string
+	Schedule         []ScheduleRule
+	SplitDestination string
+	SplitPercent     int
+	SampleRate       float64
+	PendingReview    bool
+	ArchiveURL       string
+	TrackConversions bool
+	Interstitial     bool
 }
 
 type Repository struct {
 	DB *sql.DB
+	// Replica, when set, serves redirect lookups from a read-local copy of
+	// the database. Writes and everything else go to DB.
+	Replica *sql.DB
+	// Region labels the click counters this instance writes.
+	Region string
 }
 
 func (r *Repository) HealthCheck(ctx context.Context) error {
 	return r.DB.PingContext(ctx)
 }
 
-func (r *Repository) InsertURL(longURL string) (int64, error) {
+// InsertURL adds a link without a code to workspaceID, or to no workspace
+// when it is 0.
+func (r *Repository) InsertURL(longURL string, workspaceID int64) (int64, error) {
 	const insertQuery = `
-	INSERT INTO urls (long_url, short_url, updated_at) 
-	VALUES ($1, '', NOW()) RETURNING id
+	INSERT INTO urls (long_url, short_url, workspace_id, updated_at) 
+	VALUES ($1, '', NULLIF($2, 0), NOW()) RETURNING id
 	`
 	var id int64
-	err := r.DB.QueryRowContext(context.Background(), insertQuery, longURL).Scan(&id)
+	err := r.DB.QueryRowContext(context.Background(), insertQuery, longURL, workspaceID).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert URL: %w", err)
 	}
@@ -48,11 +88,13 @@ func (r *Repository) UpdateShortCode(id int64, shortCode string) error {
 	return nil
 }
 
-func (r *Repository) FindExistingShortCode(longURL string) (string, error) {
-	const query = "SELECT short_url FROM urls WHERE long_url = $1 AND short_url != ''"
+// FindExistingShortCode returns the code of the live link to longURL in
+// workspaceID, or in no workspace when it is 0.
+func (r *Repository) FindExistingShortCode(longURL string, workspaceID int64) (string, error) {
+	const query = "SELECT short_url FROM urls WHERE long_url = $1 AND COALESCE(workspace_id, 0) = $2 AND short_url != '' AND deleted_at IS NULL"
 	var shortCode string
 	
-	err := r.DB.QueryRowContext(context.Background(), query, longURL).Scan(&shortCode)
+	err := r.DB.QueryRowContext(context.Background(), query, longURL, workspaceID).Scan(&shortCode)
 	
 	if err == sql.ErrNoRows {
 		return "", nil 
@@ -65,7 +107,10 @@ func (r *Repository) FindExistingShortCode(longURL string) (string, error) {
 }
 
 func (r *Repository) IsShortCodeUnique(code string) (bool, error) {
-	const query = "SELECT EXISTS (SELECT 1 FROM urls WHERE short_url = $1)"
+	const query = `
+	SELECT EXISTS (SELECT 1 FROM urls WHERE short_url = $1 AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM retired_codes WHERE short_url = $1)
+	`
 	var exists bool
 	
 	err := r.DB.QueryRowContext(context.Background(), query, code).Scan(&exists)
@@ -76,40 +121,124 @@ func (r *Repository) IsShortCodeUnique(code string) (bool, error) {
 	return !exists, nil
 }
 
+func (r *Repository) LinkExists(ctx context.Context, shortCode string) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM urls WHERE short_url = $1 AND deleted_at IS NULL)", shortCode).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check short code %s: %w", shortCode, err)
+	}
+	return exists, nil
+}
+
+
+// LookupDestination reads where a short code redirects. Clicks are counted
+// separately through IncrementClickCounts.
+func (r *Repository) LookupDestination(ctx context.Context, shortCode string) (Destination, error) {
+	const selectQuery = `
+	SELECT id, long_url, mirror,
+		(SELECT json_object_agg(l.lang, l.destination) FROM link_languages l WHERE l.url_id = urls.id),
+		` + scheduleRulesSubquery + `,
+		COALESCE((SELECT s.destination FROM link_splits s WHERE s.url_id = urls.id), ''),
+		COALESCE((SELECT s.percent FROM link_splits s WHERE s.url_id = urls.id), 0),
+		sample_rate, pending_review,
+		COALESCE((SELECT h.archive_url FROM link_health h WHERE h.url_id = urls.id AND h.dead_since IS NOT NULL), ''),
+		track_conversions,
+		interstitial OR COALESCE((SELECT w.interstitial FROM workspaces w WHERE w.id = urls.workspace_id), FALSE),
+		COALESCE(workspace_id, 0), expires_at
+	FROM urls
+	WHERE short_url = $1 AND deleted_at IS NULL`
+
+	var dest Destination
+	var languages, schedule []byte
+	var expiresAt sql.NullTime
+
+	scan := func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, selectQuery, shortCode).Scan(&dest.ID, &dest.LongURL, &dest.Mirror, &languages, &schedule,
+			&dest.SplitDestination, &dest.SplitPercent, &dest.SampleRate, &dest.PendingReview, &dest.ArchiveURL,
+			&dest.TrackConversions, &dest.Interstitial, &dest.WorkspaceID, &expiresAt)
+	}
+	var err error
+	if r.Replica != nil {
+		// A link created moments ago may not have replicated yet, so a miss
+		// on the replica is confirmed against the primary.
+		if err = scan(r.Replica); err == sql.ErrNoRows {
+			err = scan(r.DB)
+		}
+	} else {
+		err = scan(r.DB)
+	}
 
-func (r *Repository) LookupAndTrack(shortCode string) (string, error) {
-	const selectAndUpdateQuery = `
-	UPDATE urls 
-	SET 
-		click_count = click_count + 1, 
-		last_accessed_at = NOW(), 
-		updated_at = NOW() 
-	WHERE short_url = $1
-	RETURNING long_url`
-	
-	var longURL string
-	
-	err := r.DB.QueryRowContext(context.Background(), selectAndUpdateQuery, shortCode).Scan(&longURL)
-	
 	if err == sql.ErrNoRows {
-		return "", sql.ErrNoRows 
+		return Destination{}, sql.ErrNoRows
 	}
 	if err != nil {
-		return "", fmt.Errorf("error tracking click for short code %s: %w", shortCode, err)
+		return Destination{}, fmt.Errorf("error looking up short code %s: %w", shortCode, err)
 	}
-	
-	return longURL, nil
+	if expiresAt.Valid {
+		dest.ExpiresAt = expiresAt.Time.UTC()
+	}
+	if languages != nil {
+		if err := json.Unmarshal(languages, &dest.Languages); err != nil {
+			return Destination{}, fmt.Errorf("failed to decode languages for %s: %w", shortCode, err)
+		}
+	}
+	if schedule != nil {
+		if dest.Schedule, err = decodeScheduleRules(schedule); err != nil {
+			return Destination{}, fmt.Errorf("failed to decode schedule for %s: %w", shortCode, err)
+		}
+	}
+
+	return dest, nil
+}
+
+// IncrementClickCounts adds clicks per short code and marks the links as
+// accessed, in one statement. The clicks are also added to this region's
+// counter row, which replication can merge without losing increments.
+func (r *Repository) IncrementClickCounts(ctx context.Context, counts map[string]int) error {
+	codes := make([]string, 0, len(counts))
+	clicks := make([]int64, 0, len(counts))
+	for code, n := range counts {
+		codes = append(codes, code)
+		clicks = append(clicks, int64(n))
+	}
+
+	const query = `
+	WITH updated AS (
+		UPDATE urls u SET
+			click_count = u.click_count + v.clicks,
+			last_accessed_at = NOW(),
+			updated_at = NOW()
+		FROM (SELECT unnest($1::text[]) AS short_url, unnest($2::bigint[]) AS clicks) v
+		WHERE u.short_url = v.short_url AND u.deleted_at IS NULL
+		RETURNING u.id, v.clicks
+	)
+	INSERT INTO regional_click_counts (url_id, region, clicks)
+	SELECT id, $3, clicks FROM updated
+	ON CONFLICT (url_id, region) DO UPDATE SET
+		clicks = regional_click_counts.clicks + EXCLUDED.clicks,
+		updated_at = NOW()
+	`
+	if _, err := r.DB.ExecContext(ctx, query, codes, clicks, r.Region); err != nil {
+		return fmt.Errorf("failed to add clicks for %d links: %w", len(codes), err)
+	}
+	return nil
 }
 
 
-func (r *Repository) ListURLs(ctx context.Context, limit int, offset int) ([]URL, error) {
-    query := `
-        SELECT id, long_url, short_url, click_count, created_at, updated_at, last_accessed_at
-        FROM urls
-        ORDER BY created_at DESC
-        LIMIT $1 OFFSET $2
-    `
-    rows, err := r.DB.QueryContext(ctx, query, limit, offset)
+func (r *Repository) ListURLs(ctx context.Context, filter URLFilter, limit int, offset int) ([]URL, error) {
+    where, args := filter.where()
+    query := fmt.Sprintf(`
+        SELECT u.id, u.long_url, u.short_url, u.click_count, u.created_at, u.updated_at, u.last_accessed_at,
+            COALESCE(u.owner, ''), COALESCE(w.slug, ''),
+            t.utm_source, t.utm_medium, t.utm_campaign, t.utm_term, t.utm_content, u.metadata
+        FROM urls u
+        LEFT JOIN workspaces w ON w.id = u.workspace_id
+        LEFT JOIN link_utm t ON t.url_id = u.id
+        %s
+        ORDER BY u.created_at DESC
+        LIMIT $%d OFFSET $%d
+    `, where, len(args)+1, len(args)+2)
+    rows, err := r.DB.QueryContext(ctx, query, append(args, limit, offset)...)
     if err != nil {
         return nil, fmt.Errorf("failed to query URLs: %w", err)
     }
@@ -119,6 +248,8 @@ func (r *Repository) ListURLs(ctx context.Context, limit int, offset int) ([]URL
     for rows.Next() {
         var u URL
         var lastAccessedAt sql.NullTime
+        var utmSource, utmMedium, utmCampaign, utmTerm, utmContent sql.NullString
+        var metadata []byte
         
         err := rows.Scan(
             &u.ID,
@@ -128,14 +259,34 @@ func (r *Repository) ListURLs(ctx context.Context, limit int, offset int) ([]URL
             &u.CreatedAt,
             &u.UpdatedAt,
             &lastAccessedAt,
+            &u.Owner,
+            &u.Workspace,
+            &utmSource,
+            &utmMedium,
+            &utmCampaign,
+            &utmTerm,
+            &utmContent,
+            &metadata,
         )
         if err != nil {
             return nil, fmt.Errorf("failed to scan URL row: %w", err)
         }
+        if u.Metadata, err = decodeMetadata(metadata); err != nil {
+            return nil, fmt.Errorf("failed to decode metadata of %s: %w", u.ShortCode, err)
+        }
         
         if lastAccessedAt.Valid {
             u.LastAccessedAt = lastAccessedAt.Time
         }
+        if utmSource.Valid {
+            u.UTM = &UTMParams{
+                Source:   utmSource.String,
+                Medium:   utmMedium.String,
+                Campaign: utmCampaign.String,
+                Term:     utmTerm.String,
+                Content:  utmContent.String,
+            }
+        }
         
         urls = append(urls, u)
     }
@@ -147,14 +298,69 @@ func (r *Repository) ListURLs(ctx context.Context, limit int, offset int) ([]URL
     return urls, nil
 }
 
-func (r *Repository) GetTotalURLCount(ctx context.Context) (int, error) {
+func (r *Repository) GetTotalURLCount(ctx context.Context, filter URLFilter) (int, error) {
     var count int
-    query := `SELECT COUNT(id) FROM urls`
+    where, args := filter.where()
+    query := `SELECT COUNT(u.id) FROM urls u LEFT JOIN link_utm t ON t.url_id = u.id ` + where
     
-    err := r.DB.QueryRowContext(ctx, query).Scan(&count)
+    err := r.DB.QueryRowContext(ctx, query, args...).Scan(&count)
     if err != nil {
         return 0, fmt.Errorf("failed to query total count: %w", err)
     }
     return count, nil
 }
 
+func (r *Repository) RotateShortCode(ctx context.Context, oldCode string, newCode string, grace time.Duration) (time.Time, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin rotation transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL FOR UPDATE", oldCode).Scan(&id)
+	if err == sql.ErrNoRows {
+		return time.Time{}, sql.ErrNoRows
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to lock URL for short code %s: %w", oldCode, err)
+	}
+
+	const retireQuery = `
+	INSERT INTO retired_codes (short_url, url_id, grace_until)
+	VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+	RETURNING grace_until
+	`
+	var graceUntil time.Time
+	if err := tx.QueryRowContext(ctx, retireQuery, oldCode, id, int64(grace.Seconds())).Scan(&graceUntil); err != nil {
+		return time.Time{}, fmt.Errorf("failed to retire short code %s: %w", oldCode, err)
+	}
+
+	const updateQuery = `UPDATE urls SET short_url = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, updateQuery, newCode, id); err != nil {
+		return time.Time{}, fmt.Errorf("failed to assign rotated short code for ID %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit rotation: %w", err)
+	}
+	return graceUntil, nil
+}
+
+func (r *Repository) FindRotatedShortCode(ctx context.Context, retiredCode string) (string, error) {
+	const query = `
+	SELECT u.short_url
+	FROM retired_codes r
+	JOIN urls u ON u.id = r.url_id
+	WHERE r.short_url = $1 AND r.grace_until > NOW() AND u.deleted_at IS NULL
+	`
+	var shortCode string
+	err := r.DB.QueryRowContext(ctx, query, retiredCode).Scan(&shortCode)
+	if err == sql.ErrNoRows {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("error looking up retired short code %s: %w", retiredCode, err)
+	}
+	return shortCode, nil
+}
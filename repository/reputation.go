@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Domain signal columns. Each counts events that lower a domain's score.
+const (
+	SignalReport        = "reports"
+	SignalBlocklistHit  = "blocklist_hits"
+	SignalHealthFailure = "health_failures"
+)
+
+// DomainReputation holds the signals collected for a destination domain.
+// ScoreOverride, when set by an admin, replaces the computed score.
+type DomainReputation struct {
+	Domain         string    `json:"domain"`
+	Reports        int       `json:"reports"`
+	BlocklistHits  int       `json:"blocklist_hits"`
+	HealthFailures int       `json:"health_failures"`
+	ScoreOverride  *int      `json:"score_override"`
+	OverrideNote   string    `json:"override_note,omitempty"`
+	Score          int       `json:"score"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PendingLink is a link held for review because of its domain's reputation.
+type PendingLink struct {
+	ShortCode string    `json:"short_url"`
+	LongURL   string    `json:"long_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const domainReputationColumns = `domain, reports, blocklist_hits, health_failures, score_override, override_note, updated_at`
+
+func scanDomainReputation(row interface{ Scan(...any) error }) (DomainReputation, error) {
+	var d DomainReputation
+	var override sql.NullInt64
+	if err := row.Scan(&d.Domain, &d.Reports, &d.BlocklistHits, &d.HealthFailures, &override, &d.OverrideNote, &d.UpdatedAt); err != nil {
+		return DomainReputation{}, err
+	}
+	if override.Valid {
+		score := int(override.Int64)
+		d.ScoreOverride = &score
+	}
+	return d, nil
+}
+
+func (r *Repository) GetDomainReputation(ctx context.Context, domain string) (DomainReputation, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+domainReputationColumns+` FROM domain_reputation WHERE domain = $1`, domain)
+	d, err := scanDomainReputation(row)
+	if err == sql.ErrNoRows {
+		return DomainReputation{}, sql.ErrNoRows
+	}
+	if err != nil {
+		return DomainReputation{}, fmt.Errorf("failed to query reputation of %s: %w", domain, err)
+	}
+	return d, nil
+}
+
+// ListDomainReputations returns domains with any signal or override, most
+// recently changed first.
+func (r *Repository) ListDomainReputations(ctx context.Context, limit int) ([]DomainReputation, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+domainReputationColumns+` FROM domain_reputation ORDER BY updated_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query domain reputations: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []DomainReputation{}
+	for rows.Next() {
+		d, err := scanDomainReputation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain reputation: %w", err)
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during domain reputation iteration: %w", err)
+	}
+	return domains, nil
+}
+
+// AddDomainSignal increments one of the Signal* counters for a domain.
+func (r *Repository) AddDomainSignal(ctx context.Context, domain string, signal string) error {
+	switch signal {
+	case SignalReport, SignalBlocklistHit, SignalHealthFailure:
+	default:
+		return fmt.Errorf("unknown domain signal %q", signal)
+	}
+	// A domain without signals starts its half-life with this one.
+	query := fmt.Sprintf(`
+	INSERT INTO domain_reputation (domain, %[1]s) VALUES ($1, 1)
+	ON CONFLICT (domain) DO UPDATE SET %[1]s = domain_reputation.%[1]s + 1, updated_at = NOW(),
+		decayed_at = CASE WHEN domain_reputation.reports + domain_reputation.blocklist_hits + domain_reputation.health_failures = 0
+			THEN NOW() ELSE domain_reputation.decayed_at END
+	`, signal)
+	if _, err := r.DB.ExecContext(ctx, query, domain); err != nil {
+		return fmt.Errorf("failed to record %s for %s: %w", signal, domain, err)
+	}
+	return nil
+}
+
+// DecayDomainSignals halves the signal counters of domains last halved more
+// than halfLife ago, then deletes domains without signals or an override.
+func (r *Repository) DecayDomainSignals(ctx context.Context, halfLife time.Duration) (decayed int64, forgotten int64, err error) {
+	const decay = `
+	UPDATE domain_reputation
+	SET reports = reports / 2, blocklist_hits = blocklist_hits / 2, health_failures = health_failures / 2, decayed_at = NOW()
+	WHERE decayed_at < NOW() - $1 * INTERVAL '1 second' AND reports + blocklist_hits + health_failures > 0
+	`
+	res, err := r.DB.ExecContext(ctx, decay, int64(halfLife.Seconds()))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decay domain signals: %w", err)
+	}
+	if decayed, err = res.RowsAffected(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read decayed domain count: %w", err)
+	}
+
+	const forget = `
+	DELETE FROM domain_reputation
+	WHERE reports + blocklist_hits + health_failures = 0 AND score_override IS NULL
+	`
+	res, err = r.DB.ExecContext(ctx, forget)
+	if err != nil {
+		return decayed, 0, fmt.Errorf("failed to delete domains without signals: %w", err)
+	}
+	if forgotten, err = res.RowsAffected(); err != nil {
+		return decayed, 0, fmt.Errorf("failed to read forgotten domain count: %w", err)
+	}
+	return decayed, forgotten, nil
+}
+
+// SetDomainScoreOverride pins a domain's score, or clears the pin when score
+// is nil.
+func (r *Repository) SetDomainScoreOverride(ctx context.Context, domain string, score *int, note string) error {
+	const query = `
+	INSERT INTO domain_reputation (domain, score_override, override_note) VALUES ($1, $2, $3)
+	ON CONFLICT (domain) DO UPDATE SET score_override = $2, override_note = $3, updated_at = NOW()
+	`
+	if _, err := r.DB.ExecContext(ctx, query, domain, score, note); err != nil {
+		return fmt.Errorf("failed to override score of %s: %w", domain, err)
+	}
+	return nil
+}
+
+func (r *Repository) SetPendingReview(ctx context.Context, shortCode string, pending bool) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE urls SET pending_review = $2, updated_at = NOW() WHERE short_url = $1 AND deleted_at IS NULL`, shortCode, pending)
+	if err != nil {
+		return fmt.Errorf("failed to update review state of %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read updated link count: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repository) ListPendingLinks(ctx context.Context) ([]PendingLink, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT short_url, long_url, created_at FROM urls WHERE pending_review AND deleted_at IS NULL ORDER BY created_at LIMIT 1000`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links pending review: %w", err)
+	}
+	defer rows.Close()
+
+	links := []PendingLink{}
+	for rows.Next() {
+		var l PendingLink
+		if err := rows.Scan(&l.ShortCode, &l.LongURL, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during pending link iteration: %w", err)
+	}
+	return links, nil
+}
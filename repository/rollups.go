@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const rollupLockKey = 991_001
+
+// maxRollupWindow bounds the clicks one RollUpClicks call reads, so catching
+// up on a large click_events table happens a week at a time.
+const maxRollupWindow = 7 * 24 * time.Hour
+
+// rollupRecheck is how far back each roll-up counts the hours it already
+// rolled up again. Clicks reach click_events late when a queue backs up or
+// the recorder flushes after a restart; ones up to this late are counted.
+const rollupRecheck = 24 * time.Hour
+
+// RollUpClicks rolls the hours from the last roll-up up to until, an hour
+// boundary, into click_rollups_hourly, at most maxRollupWindow at a time,
+// starting at the oldest click the first time. The rollupRecheck hours
+// before are counted again, and every hour counted replaces its rows, so
+// late clicks are picked up without counting any click twice. It returns
+// the new hours rolled up, sql.ErrNoRows when there were none, and
+// ErrLockNotAcquired while another instance rolls up.
+func (r *Repository) RollUpClicks(ctx context.Context, until time.Time) (from time.Time, to time.Time, err error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return from, to, fmt.Errorf("failed to begin click roll-up transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", rollupLockKey).Scan(&locked); err != nil {
+		return from, to, fmt.Errorf("failed to acquire click roll-up lock: %w", err)
+	}
+	if !locked {
+		return from, to, ErrLockNotAcquired
+	}
+
+	const windowQuery = `
+	SELECT COALESCE(
+		(SELECT rolled_up_to FROM click_rollup_state),
+		(SELECT date_trunc('hour', MIN(occurred_at)) FROM click_events)
+	)`
+	var start sql.NullTime
+	if err := tx.QueryRowContext(ctx, windowQuery).Scan(&start); err != nil {
+		return from, to, fmt.Errorf("failed to read click roll-up window: %w", err)
+	}
+	if !start.Valid {
+		return from, to, sql.ErrNoRows
+	}
+	from = start.Time
+	to = until
+	if to.Sub(from) > maxRollupWindow {
+		to = from.Add(maxRollupWindow)
+	}
+	if !to.After(from) {
+		return from, to, sql.ErrNoRows
+	}
+
+	recount := from.Add(-rollupRecheck)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM click_rollups_hourly WHERE hour >= $1 AND hour < $2`, recount, to); err != nil {
+		return from, to, fmt.Errorf("failed to clear click rollups: %w", err)
+	}
+	const rollup = `
+	INSERT INTO click_rollups_hourly (url_id, hour, clicks)
+	SELECT e.url_id, date_trunc('hour', e.occurred_at), ` + estimatedClicks + `
+	FROM click_events e
+	WHERE e.occurred_at >= $1 AND e.occurred_at < $2 AND e.event_type = 'click'
+	GROUP BY 1, 2
+	`
+	if _, err := tx.ExecContext(ctx, rollup, recount, to); err != nil {
+		return from, to, fmt.Errorf("failed to roll up clicks: %w", err)
+	}
+	const advance = `
+	INSERT INTO click_rollup_state (rolled_up_to) VALUES ($1)
+	ON CONFLICT (id) DO UPDATE SET rolled_up_to = EXCLUDED.rolled_up_to
+	`
+	if _, err := tx.ExecContext(ctx, advance, to); err != nil {
+		return from, to, fmt.Errorf("failed to record click roll-up: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return from, to, fmt.Errorf("failed to commit click roll-up: %w", err)
+	}
+	return from, to, nil
+}
+
+// GetClickSeries returns a link's estimated clicks in [from, to) per unit
+// ("hour", "day" or "week"), for buckets that had any. Hours the roll-up
+// will not count again are read from click_rollups_hourly, and the rest
+// from click_events; from and to must be hour boundaries. Returns sql.ErrNoRows when the short code does not
+// exist.
+func (r *Repository) GetClickSeries(ctx context.Context, shortCode string, unit string, from time.Time, to time.Time) ([]TimeBucket, error) {
+	var urlID int64
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL`, shortCode).Scan(&urlID)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up short code %s: %w", shortCode, err)
+	}
+
+	const query = `
+	WITH state AS (
+		SELECT COALESCE((SELECT rolled_up_to FROM click_rollup_state), '-infinity'::timestamp) - $5 * INTERVAL '1 second' AS rolled_up_to
+	), hourly AS (
+		SELECT h.hour, h.clicks::float8 AS clicks
+		FROM click_rollups_hourly h, state
+		WHERE h.url_id = $1 AND h.hour >= $2 AND h.hour < $3 AND h.hour < state.rolled_up_to
+		UNION ALL
+		SELECT e.occurred_at, (1 / e.sample_rate)::float8
+		FROM click_events e, state
+		WHERE e.url_id = $1 AND e.event_type = 'click' AND e.occurred_at >= GREATEST($2, state.rolled_up_to) AND e.occurred_at < $3
+	)
+	SELECT date_trunc($4, hour) AS bucket, ROUND(SUM(clicks))::int
+	FROM hourly
+	GROUP BY bucket
+	ORDER BY bucket
+	`
+	rows, err := r.DB.QueryContext(ctx, query, urlID, from, to, unit, int64(rollupRecheck.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click series for %s: %w", shortCode, err)
+	}
+	defer rows.Close()
+
+	buckets := []TimeBucket{}
+	for rows.Next() {
+		var b TimeBucket
+		if err := rows.Scan(&b.Bucket, &b.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan click series bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during click series iteration: %w", err)
+	}
+	return buckets, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ScheduleRule sends matching clicks to Destination. Days is a bitmask indexed
+// by time.Weekday; the window runs from StartMinute to EndMinute after local
+// midnight in Timezone and wraps past midnight when EndMinute <= StartMinute.
+type ScheduleRule struct {
+	Days        int    `json:"days"`
+	StartMinute int    `json:"start_minute"`
+	EndMinute   int    `json:"end_minute"`
+	Timezone    string `json:"timezone"`
+	Destination string `json:"destination"`
+	// Location is Timezone loaded, when the service has resolved it.
+	Location *time.Location `json:"-"`
+}
+
+// scheduleRulesSubquery yields a link's rules as a JSON array in evaluation
+// order. It must be embedded in a query on urls that does not alias the table.
+const scheduleRulesSubquery = `(
+	SELECT json_agg(json_build_object(
+		'days', s.days, 'start_minute', s.start_minute, 'end_minute', s.end_minute,
+		'timezone', s.timezone, 'destination', s.destination
+	) ORDER BY s.position)
+	FROM link_schedule_rules s WHERE s.url_id = urls.id
+)`
+
+// SetScheduleRules replaces the schedule rules of a link, keeping their order.
+func (r *Repository) SetScheduleRules(ctx context.Context, shortCode string, rules []ScheduleRule) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin schedule update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var urlID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL FOR UPDATE`, shortCode).Scan(&urlID)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up short code %s: %w", shortCode, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM link_schedule_rules WHERE url_id = $1`, urlID); err != nil {
+		return fmt.Errorf("failed to clear schedule rules for %s: %w", shortCode, err)
+	}
+	for i, rule := range rules {
+		const query = `
+		INSERT INTO link_schedule_rules (url_id, position, days, start_minute, end_minute, timezone, destination)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		_, err := tx.ExecContext(ctx, query, urlID, i, rule.Days, rule.StartMinute, rule.EndMinute, rule.Timezone, rule.Destination)
+		if err != nil {
+			return fmt.Errorf("failed to store schedule rule %d for %s: %w", i, shortCode, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schedule update: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetScheduleRules(ctx context.Context, shortCode string) ([]ScheduleRule, error) {
+	query := `SELECT COALESCE(` + scheduleRulesSubquery + `, '[]')::text FROM urls WHERE short_url = $1 AND deleted_at IS NULL`
+	var payload []byte
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule rules for %s: %w", shortCode, err)
+	}
+	return decodeScheduleRules(payload)
+}
+
+func decodeScheduleRules(payload []byte) ([]ScheduleRule, error) {
+	rules := []ScheduleRule{}
+	if err := json.Unmarshal(payload, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
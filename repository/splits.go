@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Split sends Percent of a link's clicks to Destination (the canary) and the
+// rest to the link's regular destination (the control).
+type Split struct {
+	Destination string    `json:"destination"`
+	Percent     int       `json:"percent"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type VariantClicks struct {
+	Variant string `json:"variant"`
+	Clicks  int    `json:"clicks"`
+}
+
+func (r *Repository) UpsertSplit(ctx context.Context, shortCode string, destination string, percent int) (*Split, error) {
+	const query = `
+	INSERT INTO link_splits (url_id, destination, percent)
+	SELECT id, $2, $3 FROM urls WHERE short_url = $1 AND deleted_at IS NULL
+	ON CONFLICT (url_id) DO UPDATE SET destination = EXCLUDED.destination, percent = EXCLUDED.percent, updated_at = NOW()
+	RETURNING destination, percent, created_at, updated_at
+	`
+	var s Split
+	err := r.DB.QueryRowContext(ctx, query, shortCode, destination, percent).Scan(&s.Destination, &s.Percent, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert split for short code %s: %w", shortCode, err)
+	}
+	return &s, nil
+}
+
+// UpdateSplitPercent changes the canary share of an existing split.
+// Returns sql.ErrNoRows when the link has no split.
+func (r *Repository) UpdateSplitPercent(ctx context.Context, shortCode string, percent int) (*Split, error) {
+	const query = `
+	UPDATE link_splits s SET percent = $2, updated_at = NOW()
+	FROM urls u
+	WHERE u.id = s.url_id AND u.short_url = $1 AND u.deleted_at IS NULL
+	RETURNING s.destination, s.percent, s.created_at, s.updated_at
+	`
+	var s Split
+	err := r.DB.QueryRowContext(ctx, query, shortCode, percent).Scan(&s.Destination, &s.Percent, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update split for short code %s: %w", shortCode, err)
+	}
+	return &s, nil
+}
+
+func (r *Repository) GetSplit(ctx context.Context, shortCode string) (*Split, error) {
+	const query = `
+	SELECT s.destination, s.percent, s.created_at, s.updated_at
+	FROM link_splits s
+	JOIN urls u ON u.id = s.url_id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	`
+	var s Split
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&s.Destination, &s.Percent, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query split for short code %s: %w", shortCode, err)
+	}
+	return &s, nil
+}
+
+func (r *Repository) DeleteSplit(ctx context.Context, shortCode string) (bool, error) {
+	const query = `DELETE FROM link_splits s USING urls u WHERE u.id = s.url_id AND u.short_url = $1 AND u.deleted_at IS NULL`
+	res, err := r.DB.ExecContext(ctx, query, shortCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete split for short code %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read deleted split count: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetVariantClicks estimates clicks per split variant from the recorded
+// sample. Clicks from before the split existed are reported under an empty
+// variant.
+func (r *Repository) GetVariantClicks(ctx context.Context, shortCode string) ([]VariantClicks, error) {
+	const query = `
+	SELECT e.variant, ` + estimatedClicks + `
+	FROM click_events e
+	JOIN urls u ON u.id = e.url_id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL AND e.event_type = 'click'
+	GROUP BY e.variant
+	ORDER BY e.variant
+	`
+	rows, err := r.DB.QueryContext(ctx, query, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query variant clicks for %s: %w", shortCode, err)
+	}
+	defer rows.Close()
+
+	clicks := []VariantClicks{}
+	for rows.Next() {
+		var v VariantClicks
+		if err := rows.Scan(&v.Variant, &v.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan variant clicks: %w", err)
+		}
+		clicks = append(clicks, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during variant click iteration: %w", err)
+	}
+	return clicks, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type UTMParams struct {
+	Source   string `json:"utm_source"`
+	Medium   string `json:"utm_medium"`
+	Campaign string `json:"utm_campaign"`
+	Term     string `json:"utm_term,omitempty"`
+	Content  string `json:"utm_content,omitempty"`
+}
+
+// URLFilter narrows ListURLs and GetTotalURLCount. Queries using it must alias
+// urls as u and LEFT JOIN link_utm as t.
+type URLFilter struct {
+	UTMSource   string
+	UTMMedium   string
+	UTMCampaign string
+	CampaignID  int64
+	ShortCode   string
+	// Metadata matches links whose metadata has all of these pairs.
+	Metadata map[string]string
+}
+
+func (f URLFilter) where() (string, []any) {
+	conds := []string{"u.deleted_at IS NULL"}
+	var args []any
+	add := func(column string, value string) {
+		if value == "" {
+			return
+		}
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	add("t.utm_source", f.UTMSource)
+	add("t.utm_medium", f.UTMMedium)
+	add("t.utm_campaign", f.UTMCampaign)
+	add("u.short_url", f.ShortCode)
+	if f.CampaignID != 0 {
+		args = append(args, f.CampaignID)
+		conds = append(conds, fmt.Sprintf("u.campaign_id = $%d", len(args)))
+	}
+	if len(f.Metadata) > 0 {
+		raw, _ := json.Marshal(f.Metadata)
+		args = append(args, string(raw))
+		conds = append(conds, fmt.Sprintf("u.metadata @> $%d::jsonb", len(args)))
+	}
+
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *Repository) UpsertUTMParams(ctx context.Context, shortCode string, p UTMParams) error {
+	const query = `
+	INSERT INTO link_utm (url_id, utm_source, utm_medium, utm_campaign, utm_term, utm_content)
+	SELECT id, $2, $3, $4, $5, $6 FROM urls WHERE short_url = $1 AND deleted_at IS NULL
+	ON CONFLICT (url_id) DO UPDATE SET
+		utm_source = EXCLUDED.utm_source,
+		utm_medium = EXCLUDED.utm_medium,
+		utm_campaign = EXCLUDED.utm_campaign,
+		utm_term = EXCLUDED.utm_term,
+		utm_content = EXCLUDED.utm_content
+	`
+	_, err := r.DB.ExecContext(ctx, query, shortCode, p.Source, p.Medium, p.Campaign, p.Term, p.Content)
+	if err != nil {
+		return fmt.Errorf("failed to store UTM params for short code %s: %w", shortCode, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/webhook"
+)
+
+// WebhookDelivery is a batch of events sent, or being sent, to a webhook.
+type WebhookDelivery struct {
+	ID        string                   `json:"id"`
+	WebhookID int64                    `json:"webhook_id"`
+	Status    string                   `json:"status"`
+	Attempts  []WebhookDeliveryAttempt `json:"attempts"`
+	Payload   json.RawMessage          `json:"payload"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ResponseCode *int      `json:"response_code"`
+	Error        string    `json:"error,omitempty"`
+	DurationMS   int       `json:"duration_ms"`
+	Manual       bool      `json:"manual"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
+
+// RecordWebhookAttempt stores an attempt and moves its delivery to the
+// attempt's status, creating the delivery on its first attempt.
+func (r *Repository) RecordWebhookAttempt(ctx context.Context, a webhook.Attempt) error {
+	const query = `
+	WITH delivery AS (
+		INSERT INTO webhook_deliveries (id, webhook_id, payload, status, attempts)
+		VALUES ($1, $2, $3, $4, 1)
+		ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, attempts = webhook_deliveries.attempts + 1, updated_at = NOW()
+		RETURNING id
+	)
+	INSERT INTO webhook_delivery_attempts (delivery_id, response_code, error, duration_ms, manual)
+	SELECT id, $5, $6, $7, $8 FROM delivery
+	`
+	var code sql.NullInt64
+	if a.StatusCode != 0 {
+		code = sql.NullInt64{Int64: int64(a.StatusCode), Valid: true}
+	}
+	_, err := r.DB.ExecContext(ctx, query, a.DeliveryID, a.WebhookID, a.Payload, a.Status, code, a.Err, a.Duration.Milliseconds(), a.Manual)
+	if err != nil {
+		return fmt.Errorf("failed to record attempt for webhook delivery %s: %w", a.DeliveryID, err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns a webhook's most recent deliveries with their
+// attempts, optionally only those in one status.
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, webhookID int64, status string, limit int) ([]WebhookDelivery, error) {
+	const query = `
+	SELECT id, webhook_id, status, payload, created_at, updated_at
+	FROM webhook_deliveries
+	WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY created_at DESC
+	LIMIT $3
+	`
+	rows, err := r.DB.QueryContext(ctx, query, webhookID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	ids := []string{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Status, &d.Payload, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.Attempts = []WebhookDeliveryAttempt{}
+		deliveries = append(deliveries, d)
+		ids = append(ids, d.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during webhook delivery iteration: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	attempts, err := r.webhookDeliveryAttempts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		if a, ok := attempts[deliveries[i].ID]; ok {
+			deliveries[i].Attempts = a
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *Repository) webhookDeliveryAttempts(ctx context.Context, deliveryIDs []string) (map[string][]WebhookDeliveryAttempt, error) {
+	const query = `
+	SELECT delivery_id, response_code, error, duration_ms, manual, attempted_at
+	FROM webhook_delivery_attempts
+	WHERE delivery_id = ANY($1)
+	ORDER BY id
+	`
+	rows, err := r.DB.QueryContext(ctx, query, deliveryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make(map[string][]WebhookDeliveryAttempt)
+	for rows.Next() {
+		var id string
+		var a WebhookDeliveryAttempt
+		var code sql.NullInt64
+		if err := rows.Scan(&id, &code, &a.Error, &a.DurationMS, &a.Manual, &a.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery attempt: %w", err)
+		}
+		if code.Valid {
+			c := int(code.Int64)
+			a.ResponseCode = &c
+		}
+		attempts[id] = append(attempts[id], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during webhook delivery attempt iteration: %w", err)
+	}
+	return attempts, nil
+}
+
+// GetWebhookDelivery returns one delivery with its attempts.
+func (r *Repository) GetWebhookDelivery(ctx context.Context, webhookID int64, deliveryID string) (*WebhookDelivery, error) {
+	const query = `
+	SELECT id, webhook_id, status, payload, created_at, updated_at
+	FROM webhook_deliveries
+	WHERE webhook_id = $1 AND id = $2
+	`
+	var d WebhookDelivery
+	err := r.DB.QueryRowContext(ctx, query, webhookID, deliveryID).Scan(&d.ID, &d.WebhookID, &d.Status, &d.Payload, &d.CreatedAt, &d.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery %s: %w", deliveryID, err)
+	}
+
+	attempts, err := r.webhookDeliveryAttempts(ctx, []string{d.ID})
+	if err != nil {
+		return nil, err
+	}
+	d.Attempts = attempts[d.ID]
+	if d.Attempts == nil {
+		d.Attempts = []WebhookDeliveryAttempt{}
+	}
+	return &d, nil
+}
+
+// GetWebhookTarget returns where and with which secret a webhook's
+// deliveries are sent.
+func (r *Repository) GetWebhookTarget(ctx context.Context, webhookID int64) (webhook.Target, error) {
+	const query = `SELECT id, target_url, secret FROM webhooks WHERE id = $1`
+	var t webhook.Target
+	err := r.DB.QueryRowContext(ctx, query, webhookID).Scan(&t.ID, &t.URL, &t.Secret)
+	if err == sql.ErrNoRows {
+		return t, sql.ErrNoRows
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to query webhook %d: %w", webhookID, err)
+	}
+	return t, nil
+}
+
+func (r *Repository) PurgeWebhookDeliveries(ctx context.Context, retention time.Duration) (int64, error) {
+	const query = `DELETE FROM webhook_deliveries WHERE created_at < NOW() - $1 * INTERVAL '1 second'`
+	res, err := r.DB.ExecContext(ctx, query, int64(retention.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
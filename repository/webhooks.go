@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/webhook"
+)
+
+type Webhook struct {
+	ID        int64     `json:"id"`
+	ShortCode string    `json:"short_url"`
+	TargetURL string    `json:"target_url"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpsertLinkWebhook registers or retargets a link's webhook. secret is only
+// used for a new registration; retargeting keeps the existing secret.
+func (r *Repository) UpsertLinkWebhook(ctx context.Context, shortCode string, targetURL string, secret string) (*Webhook, error) {
+	const query = `
+	INSERT INTO webhooks (url_id, target_url, secret)
+	SELECT id, $2, $3 FROM urls WHERE short_url = $1 AND deleted_at IS NULL
+	ON CONFLICT (url_id) DO UPDATE SET target_url = EXCLUDED.target_url, updated_at = NOW()
+	RETURNING id, target_url, secret, created_at, updated_at
+	`
+	w := Webhook{ShortCode: shortCode}
+	err := r.DB.QueryRowContext(ctx, query, shortCode, targetURL, secret).Scan(&w.ID, &w.TargetURL, &w.Secret, &w.CreatedAt, &w.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert webhook for short code %s: %w", shortCode, err)
+	}
+	return &w, nil
+}
+
+func (r *Repository) GetLinkWebhook(ctx context.Context, shortCode string) (*Webhook, error) {
+	const query = `
+	SELECT w.id, w.target_url, w.secret, w.created_at, w.updated_at
+	FROM webhooks w
+	JOIN urls u ON u.id = w.url_id
+	WHERE u.short_url = $1 AND u.deleted_at IS NULL
+	`
+	w := Webhook{ShortCode: shortCode}
+	err := r.DB.QueryRowContext(ctx, query, shortCode).Scan(&w.ID, &w.TargetURL, &w.Secret, &w.CreatedAt, &w.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook for short code %s: %w", shortCode, err)
+	}
+	return &w, nil
+}
+
+func (r *Repository) RotateWebhookSecret(ctx context.Context, shortCode string, secret string) (*Webhook, error) {
+	const query = `
+	UPDATE webhooks SET secret = $2, updated_at = NOW()
+	WHERE url_id = (SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL)
+	RETURNING id, target_url, secret, created_at, updated_at
+	`
+	w := Webhook{ShortCode: shortCode}
+	err := r.DB.QueryRowContext(ctx, query, shortCode, secret).Scan(&w.ID, &w.TargetURL, &w.Secret, &w.CreatedAt, &w.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate webhook secret for short code %s: %w", shortCode, err)
+	}
+	return &w, nil
+}
+
+func (r *Repository) DeleteLinkWebhook(ctx context.Context, shortCode string) (bool, error) {
+	const query = `DELETE FROM webhooks WHERE url_id = (SELECT id FROM urls WHERE short_url = $1 AND deleted_at IS NULL)`
+	res, err := r.DB.ExecContext(ctx, query, shortCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook for short code %s: %w", shortCode, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read deleted webhook count: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) FindWebhookTargets(ctx context.Context, shortCodes []string) (map[string]webhook.Target, error) {
+	const query = `
+	SELECT u.short_url, w.id, w.target_url, w.secret
+	FROM webhooks w
+	JOIN urls u ON u.id = w.url_id
+	WHERE u.short_url = ANY($1) AND u.deleted_at IS NULL
+	`
+	rows, err := r.DB.QueryContext(ctx, query, shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := make(map[string]webhook.Target)
+	for rows.Next() {
+		var code string
+		var target webhook.Target
+		if err := rows.Scan(&code, &target.ID, &target.URL, &target.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook target: %w", err)
+		}
+		targets[code] = target
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during webhook target iteration: %w", err)
+	}
+	return targets, nil
+}
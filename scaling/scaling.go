@@ -0,0 +1,264 @@
+// Package scaling samples the load signals an autoscaler needs, request
+// rate, database pool saturation, queue depths and the destination cache
+// hit ratio, and flags the ones that stray from this instance's recent
+// baseline.
+//
+// Current values are measured over the last minute and baselines over the
+// last hour, both from samples taken by Sample. Every value is for this
+// instance only; autoscalers sum or average across instances themselves.
+package scaling
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	DefaultInterval = 15 * time.Second
+
+	currentWindow  = time.Minute
+	baselineWindow = time.Hour
+	depthTimeout   = 2 * time.Second
+)
+
+const (
+	RecommendScaleUp   = "scale_up"
+	RecommendHold      = "hold"
+	RecommendScaleDown = "scale_down"
+)
+
+// Thresholds for anomalies and recommendations.
+const (
+	requestSpikeFactor = 2.0
+	minSpikeRPS        = 10.0
+	poolSaturated      = 0.8
+	poolIdle           = 0.2
+	maxWaitsPerSecond  = 1.0
+	queueBacklog       = 1000
+	cacheDropRatio     = 0.2
+)
+
+// QueueDepth reports how many events wait in one queue.
+type QueueDepth func(ctx context.Context) (int64, error)
+
+type sample struct {
+	at          time.Time
+	requests    float64
+	cacheHits   float64
+	cacheMisses float64
+	dbWaits     int64
+	dbInUse     int
+	dbMaxOpen   int
+	queues      map[string]int64
+}
+
+// Signal is one measurement. Baseline is nil until an hour of history
+// exists, and Current is nil when nothing could be measured, such as a hit
+// ratio without lookups or a queue whose depth could not be read.
+type Signal struct {
+	Current  *float64 `json:"current"`
+	Baseline *float64 `json:"baseline"`
+}
+
+// Report is what /admin/scaling answers.
+type Report struct {
+	SampledAt      *time.Time        `json:"sampled_at"`
+	Signals        map[string]Signal `json:"signals"`
+	Anomalies      []string          `json:"anomalies"`
+	Recommendation string            `json:"recommendation"`
+}
+
+// Monitor keeps an hour of samples.
+type Monitor struct {
+	db       *sql.DB
+	queues   map[string]QueueDepth
+	gatherer prometheus.Gatherer
+
+	mu      sync.Mutex
+	samples []sample
+}
+
+// NewMonitor watches db's connection pool and the named queues, and reads
+// request and cache counters from the default Prometheus registry.
+func NewMonitor(db *sql.DB, queues map[string]QueueDepth) *Monitor {
+	return &Monitor{db: db, queues: queues, gatherer: prometheus.DefaultGatherer}
+}
+
+// Sample records the current counters, pool state and queue depths; run it
+// every DefaultInterval from a job.
+func (m *Monitor) Sample(ctx context.Context) error {
+	families, err := m.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	totals := make(map[string]float64)
+	for _, mf := range families {
+		for _, metric := range mf.GetMetric() {
+			if c := metric.GetCounter(); c != nil {
+				totals[mf.GetName()] += c.GetValue()
+			}
+		}
+	}
+
+	stats := m.db.Stats()
+	s := sample{
+		at:          time.Now(),
+		requests:    totals["urlshortener_http_requests_total"],
+		cacheHits:   totals["urlshortener_destination_cache_hits_total"],
+		cacheMisses: totals["urlshortener_destination_cache_misses_total"],
+		dbWaits:     stats.WaitCount,
+		dbInUse:     stats.InUse,
+		dbMaxOpen:   stats.MaxOpenConnections,
+		queues:      make(map[string]int64, len(m.queues)),
+	}
+	for name, depth := range m.queues {
+		depthCtx, cancel := context.WithTimeout(ctx, depthTimeout)
+		n, err := depth(depthCtx)
+		cancel()
+		if err != nil {
+			log.Printf("WARN: Failed to read depth of the %s queue: %v", name, err)
+			n = -1
+		}
+		s.queues[name] = n
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = append(m.samples, s)
+	for len(m.samples) > 2 && s.at.Sub(m.samples[1].at) >= baselineWindow {
+		m.samples = m.samples[1:]
+	}
+	return nil
+}
+
+// rates holds the per-window values derived from two samples.
+type rates struct {
+	rps, waitsPerSecond float64
+	hitRatio            *float64
+}
+
+func between(from, to sample) rates {
+	seconds := to.at.Sub(from.at).Seconds()
+	r := rates{
+		rps:            (to.requests - from.requests) / seconds,
+		waitsPerSecond: float64(to.dbWaits-from.dbWaits) / seconds,
+	}
+	hits := to.cacheHits - from.cacheHits
+	if lookups := hits + to.cacheMisses - from.cacheMisses; lookups > 0 {
+		ratio := hits / lookups
+		r.hitRatio = &ratio
+	}
+	return r
+}
+
+func saturation(s sample) float64 {
+	if s.dbMaxOpen <= 0 {
+		return 0
+	}
+	return float64(s.dbInUse) / float64(s.dbMaxOpen)
+}
+
+// Report derives the signals from the samples taken so far.
+func (m *Monitor) Report() Report {
+	m.mu.Lock()
+	samples := make([]sample, len(m.samples))
+	copy(samples, m.samples)
+	m.mu.Unlock()
+
+	report := Report{Signals: map[string]Signal{}, Anomalies: []string{}, Recommendation: RecommendHold}
+	if len(samples) < 2 {
+		return report
+	}
+	latest := samples[len(samples)-1]
+	report.SampledAt = &latest.at
+
+	// The current window starts at the newest sample at least a minute old,
+	// or the oldest one while the history is shorter.
+	start := samples[0]
+	for _, s := range samples {
+		if latest.at.Sub(s.at) < currentWindow {
+			break
+		}
+		start = s
+	}
+	current := between(start, latest)
+	baseline := between(samples[0], latest)
+	haveBaseline := latest.at.Sub(samples[0].at) >= baselineWindow-DefaultInterval
+
+	var meanSaturation float64
+	meanDepth := make(map[string]float64)
+	for _, s := range samples {
+		meanSaturation += saturation(s) / float64(len(samples))
+		for name, n := range s.queues {
+			meanDepth[name] += float64(n) / float64(len(samples))
+		}
+	}
+
+	signal := func(current float64, baseline float64) Signal {
+		if !haveBaseline {
+			return Signal{Current: &current}
+		}
+		return Signal{Current: &current, Baseline: &baseline}
+	}
+	report.Signals["requests_per_second"] = signal(current.rps, baseline.rps)
+	report.Signals["db_pool_saturation"] = signal(saturation(latest), meanSaturation)
+	report.Signals["db_waits_per_second"] = signal(current.waitsPerSecond, baseline.waitsPerSecond)
+	report.Signals["cache_hit_ratio"] = Signal{Current: current.hitRatio}
+	if haveBaseline {
+		report.Signals["cache_hit_ratio"] = Signal{Current: current.hitRatio, Baseline: baseline.hitRatio}
+	}
+	for name, n := range latest.queues {
+		if n < 0 {
+			report.Signals[name+"_queue_depth"] = Signal{}
+			continue
+		}
+		report.Signals[name+"_queue_depth"] = signal(float64(n), meanDepth[name])
+	}
+
+	scaleUp := false
+	flag := func(name string, up bool) {
+		report.Anomalies = append(report.Anomalies, name)
+		scaleUp = scaleUp || up
+	}
+	if haveBaseline && current.rps >= minSpikeRPS && current.rps >= requestSpikeFactor*baseline.rps {
+		flag("requests_per_second", true)
+	}
+	if saturation(latest) >= poolSaturated {
+		flag("db_pool_saturation", true)
+	}
+	if current.waitsPerSecond > maxWaitsPerSecond {
+		flag("db_waits_per_second", true)
+	}
+	if haveBaseline && current.hitRatio != nil && baseline.hitRatio != nil && *current.hitRatio < *baseline.hitRatio-cacheDropRatio {
+		// A cold or thrashing cache moves load to the database, and more
+		// instances would only make it colder.
+		flag("cache_hit_ratio", false)
+	}
+	for name, n := range latest.queues {
+		if n >= queueBacklog && float64(n) >= 2*meanDepth[name] {
+			flag(name+"_queue_depth", true)
+		}
+	}
+	sort.Strings(report.Anomalies)
+
+	switch {
+	case scaleUp:
+		report.Recommendation = RecommendScaleUp
+	case len(report.Anomalies) == 0 && haveBaseline && meanSaturation < poolIdle && saturation(latest) < poolIdle && current.rps <= baseline.rps:
+		report.Recommendation = RecommendScaleDown
+	}
+	return report
+}
+
+// Handler serves the report.
+func (m *Monitor) Handler(c *gin.Context) {
+	c.JSON(http.StatusOK, m.Report())
+}
@@ -0,0 +1,88 @@
+// Package screenshot captures images of web pages through an external
+// rendering service.
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+	// MaxImageSize bounds how much of a rendering service response is read.
+	MaxImageSize = 5 << 20
+)
+
+// Client calls a rendering service. URLTemplate is the service endpoint
+// with a {url} placeholder, e.g.
+// https://render.internal/screenshot?width=1280&url={url}; the placeholder is
+// replaced by the query-escaped page URL and the response body must be an
+// image.
+type Client struct {
+	URLTemplate string
+	HTTP        *http.Client
+}
+
+func NewClient(urlTemplate string) (*Client, error) {
+	if !strings.Contains(urlTemplate, "{url}") {
+		return nil, fmt.Errorf("screenshot service URL %q has no {url} placeholder", urlTemplate)
+	}
+	return &Client{URLTemplate: urlTemplate, HTTP: &http.Client{Timeout: DefaultTimeout}}, nil
+}
+
+// Image is a captured screenshot.
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+// Extension returns a file extension for the image's content type.
+func (i Image) Extension() string {
+	switch i.ContentType {
+	case "image/jpeg":
+		return "jpg"
+	case "image/webp":
+		return "webp"
+	default:
+		return "png"
+	}
+}
+
+func (c *Client) Capture(ctx context.Context, pageURL string) (Image, error) {
+	endpoint := strings.ReplaceAll(c.URLTemplate, "{url}", url.QueryEscape(pageURL))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to build screenshot request for %s: %w", pageURL, err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to reach screenshot service for %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Image{}, fmt.Errorf("screenshot service returned status %d for %s", resp.StatusCode, pageURL)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(contentType, "image/") {
+		return Image{}, fmt.Errorf("screenshot service returned %q instead of an image for %s", contentType, pageURL)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(resp.Body, MaxImageSize+1))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read screenshot of %s: %w", pageURL, err)
+	}
+	if n > MaxImageSize {
+		return Image{}, fmt.Errorf("screenshot of %s exceeds %d bytes", pageURL, MaxImageSize)
+	}
+	return Image{Data: buf.Bytes(), ContentType: contentType}, nil
+}
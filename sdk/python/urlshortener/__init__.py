@@ -0,0 +1,180 @@
+# Code generated by cmd/sdkgen from api/openapi.yaml. DO NOT EDIT.
+"""Client for the urlShortener API 1.0.0."""
+
+from __future__ import annotations
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from dataclasses import asdict, dataclass
+from typing import Any, Dict, Optional
+from urllib.parse import quote
+
+
+@dataclass
+class ErrorResponse:
+    error: str
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ErrorResponse":
+        return cls(
+            error=d["error"],
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {k: v for k, v in asdict(self).items() if v is not None}
+
+
+@dataclass
+class ExpandResponse:
+    long_url: str
+    short_url: str
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ExpandResponse":
+        return cls(
+            long_url=d["long_url"],
+            short_url=d["short_url"],
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {k: v for k, v in asdict(self).items() if v is not None}
+
+
+@dataclass
+class LinkStats:
+    click_count: int
+    conversion_rate: float
+    conversions: int
+    created_at: str
+    flagged_clicks: int
+    last_accessed_at: Optional[str]
+    long_url: str
+    sample_rate: float
+    short_url: str
+    view_count: int
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "LinkStats":
+        return cls(
+            click_count=d["click_count"],
+            conversion_rate=d["conversion_rate"],
+            conversions=d["conversions"],
+            created_at=d["created_at"],
+            flagged_clicks=d["flagged_clicks"],
+            last_accessed_at=d["last_accessed_at"],
+            long_url=d["long_url"],
+            sample_rate=d["sample_rate"],
+            short_url=d["short_url"],
+            view_count=d["view_count"],
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {k: v for k, v in asdict(self).items() if v is not None}
+
+
+@dataclass
+class ShortenRequest:
+    long_url: str
+    alias: Optional[str] = None
+    metadata: Optional[Dict[str, str]] = None
+    workspace: Optional[str] = None
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ShortenRequest":
+        return cls(
+            long_url=d["long_url"],
+            alias=d.get("alias"),
+            metadata=d.get("metadata"),
+            workspace=d.get("workspace"),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {k: v for k, v in asdict(self).items() if v is not None}
+
+
+@dataclass
+class ShortenResponse:
+    short_url: str
+    warning: Optional[ShortenWarning] = None
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ShortenResponse":
+        return cls(
+            short_url=d["short_url"],
+            warning=ShortenWarning.from_dict(d["warning"]) if d.get("warning") is not None else None,
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {k: v for k, v in asdict(self).items() if v is not None}
+
+
+@dataclass
+class ShortenWarning:
+    code: str
+    message: str
+    submitted_code: str
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ShortenWarning":
+        return cls(
+            code=d["code"],
+            message=d["message"],
+            submitted_code=d["submitted_code"],
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {k: v for k, v in asdict(self).items() if v is not None}
+
+
+class APIError(Exception):
+    """Raised for responses outside 2xx."""
+
+    def __init__(self, status: int, message: str) -> None:
+        super().__init__(message)
+        self.status = status
+
+
+class Client:
+    def __init__(self, base_url: str = "http://localhost:8080", token: Optional[str] = None, timeout: float = 10.0) -> None:
+        """token is ADMIN_TOKEN or an API key, sent as a bearer token."""
+        self.base_url = base_url.rstrip("/")
+        self.token = token
+        self.timeout = timeout
+
+    def link_stats(self, code: str, exclude_flagged: Optional[bool] = None) -> LinkStats:
+        """Click, view and conversion counts of a link."""
+        query = {"exclude_flagged": exclude_flagged}
+        return LinkStats.from_dict(self._request("GET", f"/urls/{quote(str(code), safe='')}/stats", query))
+
+    def resolve(self, code: str) -> ExpandResponse:
+        """Resolve a short code or URL without counting a click."""
+        query = {"code": code}
+        return ExpandResponse.from_dict(self._request("GET", "/api/expand", query))
+
+    def shorten(self, body: ShortenRequest) -> ShortenResponse:
+        """Create a short link for a long URL."""
+        query = {}
+        return ShortenResponse.from_dict(self._request("POST", "/shorten", query, body.to_dict()))
+
+    def _request(self, method: str, path: str, query: Dict[str, Any], body: Optional[Dict[str, Any]] = None) -> Dict[str, Any]:
+        params = {k: str(v).lower() if isinstance(v, bool) else str(v) for k, v in query.items() if v is not None}
+        url = self.base_url + path + ("?" + urllib.parse.urlencode(params) if params else "")
+        headers = {"Accept": "application/json"}
+        data = None
+        if body is not None:
+            headers["Content-Type"] = "application/json"
+            data = json.dumps(body).encode()
+        if self.token:
+            headers["Authorization"] = "Bearer " + self.token
+        req = urllib.request.Request(url, data=data, headers=headers, method=method)
+        try:
+            with urllib.request.urlopen(req, timeout=self.timeout) as res:
+                return json.load(res)
+        except urllib.error.HTTPError as err:
+            try:
+                message = json.load(err).get("error", err.reason)
+            except ValueError:
+                message = err.reason
+            raise APIError(err.code, message) from None
@@ -0,0 +1,120 @@
+// Code generated by cmd/sdkgen from api/openapi.yaml. DO NOT EDIT.
+// urlShortener API 1.0.0
+
+export interface ErrorResponse {
+  error: string;
+}
+
+export interface ExpandResponse {
+  long_url: string;
+  short_url: string;
+}
+
+export interface LinkStats {
+  click_count: number;
+  conversion_rate: number;
+  conversions: number;
+  created_at: string;
+  flagged_clicks: number;
+  last_accessed_at: string | null;
+  long_url: string;
+  sample_rate: number;
+  short_url: string;
+  view_count: number;
+}
+
+export interface ShortenRequest {
+  long_url: string;
+  /** A custom code instead of a generated one. */
+  alias?: string;
+  /** Your own references to store on the link, such as order IDs. */
+  metadata?: Record<string, string>;
+  /** Slug of the workspace to create the link in. */
+  workspace?: string;
+}
+
+export interface ShortenResponse {
+  short_url: string;
+  warning?: ShortenWarning;
+}
+
+export interface ShortenWarning {
+  code: string;
+  message: string;
+  submitted_code: string;
+}
+
+/** APIError is thrown for responses outside 2xx. */
+export class APIError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+  ) {
+    super(message);
+    this.name = "APIError";
+  }
+}
+
+export interface ClientOptions {
+  /** Base URL of the service, without a trailing slash. */
+  baseURL?: string;
+  /** ADMIN_TOKEN or an API key, sent as a bearer token. */
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+type Query = Record<string, string | number | boolean | undefined>;
+
+export class Client {
+  private readonly baseURL: string;
+  private readonly token?: string;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseURL = (options.baseURL ?? "http://localhost:8080").replace(/\/+$/, "");
+    this.token = options.token;
+    this.fetchImpl = options.fetch ?? fetch;
+  }
+
+  /** Click, view and conversion counts of a link. */
+  async linkStats(code: string, options: { exclude_flagged?: boolean } = {}): Promise<LinkStats> {
+    return this.request<LinkStats>("GET", `/urls/${encodeURIComponent(code)}/stats`, { exclude_flagged: options.exclude_flagged });
+  }
+
+  /** Resolve a short code or URL without counting a click. */
+  async resolve(code: string): Promise<ExpandResponse> {
+    return this.request<ExpandResponse>("GET", "/api/expand", { code });
+  }
+
+  /** Create a short link for a long URL. */
+  async shorten(body: ShortenRequest): Promise<ShortenResponse> {
+    return this.request<ShortenResponse>("POST", "/shorten", {}, body);
+  }
+
+  private async request<T>(method: string, path: string, query: Query, body?: unknown): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [name, value] of Object.entries(query)) {
+      if (value !== undefined) {
+        params.set(name, String(value));
+      }
+    }
+    const search = params.toString();
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    if (this.token) {
+      headers.Authorization = `Bearer ${this.token}`;
+    }
+    const res = await this.fetchImpl(this.baseURL + path + (search ? `?${search}` : ""), {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const payload = await res.json().catch(() => undefined);
+    if (!res.ok) {
+      throw new APIError(res.status, payload?.error ?? res.statusText);
+    }
+    return payload as T;
+  }
+}
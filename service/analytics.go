@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrStreamingDisabled = errors.New("click streaming is not enabled")
+
+// TrackEvent fans an event out to analytics, webhooks and live consumers.
+// Only analytics storage is subject to the link's sample rate.
+func (s *Service) TrackEvent(e events.Event) {
+	if s.Analytics != nil && sampled(e) {
+		s.Analytics.Record(e)
+	}
+	if s.Webhooks != nil && e.Type == events.TypeClick {
+		s.Webhooks.Enqueue(e)
+	}
+	if s.Clicks != nil && e.Type == events.TypeClick {
+		s.Clicks.Publish(e)
+	}
+	if s.Live != nil && e.Type == events.TypeClick {
+		s.Live.Record(e)
+	}
+}
+
+// SubscribeClicks streams a link's clicks as they happen. The returned
+// function must be called to release the subscription.
+func (s *Service) SubscribeClicks(ctx context.Context, shortCode string) (<-chan events.Event, func(), error) {
+	if s.Clicks == nil {
+		return nil, nil, ErrStreamingDisabled
+	}
+	exists, err := s.Repo.LinkExists(ctx, shortCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, ErrNotFound
+	}
+	ch, cancel := s.Clicks.Subscribe(shortCode)
+	return ch, cancel, nil
+}
+
+// GetLinkStats returns a link's counters. With excludeFlagged, clicks flagged
+// by fraud detection are subtracted from the click count.
+func (s *Service) GetLinkStats(ctx context.Context, shortCode string, excludeFlagged bool) (*repository.LinkStats, error) {
+	stats, err := s.Repo.GetLinkStats(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if excludeFlagged {
+		stats.ClickCount = max(stats.ClickCount-stats.FlaggedClicks, 0)
+	}
+	if stats.ClickCount > 0 {
+		stats.ConversionRate = float64(stats.Conversions) / float64(stats.ClickCount)
+	}
+	return stats, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidAPIKeyName     = errors.New("invalid api key name")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+	ErrInvalidBootstrapToken = errors.New("invalid bootstrap token")
+	ErrAlreadyBootstrapped   = errors.New("admin api already bootstrapped")
+)
+
+const (
+	apiKeySecretPrefix  = "usk_"
+	apiKeySecretLength  = 40
+	apiKeyDisplayLength = 12
+
+	signingSecretPrefix = "ussk_"
+
+	// requestNonceRetention covers a signed request's tolerance on either
+	// side of the server clock, after which its nonce cannot be replayed.
+	requestNonceRetention = 10 * time.Minute
+)
+
+var apiKeyNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,63}$`)
+
+// IssuedAPIKey carries the plaintext key, which is only available in the
+// response that created it.
+type IssuedAPIKey struct {
+	repository.APIKey
+	Key string `json:"key,omitempty"`
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKeySecret() (string, error) {
+	code, err := generateRandomCode(apiKeySecretLength)
+	if err != nil {
+		return "", err
+	}
+	return apiKeySecretPrefix + code, nil
+}
+
+// EnsureAPIKey creates the named key unless it exists, so provisioning tools
+// can apply it repeatedly. created is false when the key was already there.
+func (s *Service) EnsureAPIKey(ctx context.Context, name string, actor string) (*IssuedAPIKey, bool, error) {
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, false, err
+	}
+	return s.ensureAPIKeyWithSecret(ctx, name, secret, actor)
+}
+
+func (s *Service) ensureAPIKeyWithSecret(ctx context.Context, name string, secret string, actor string) (*IssuedAPIKey, bool, error) {
+	if !apiKeyNamePattern.MatchString(name) {
+		return nil, false, ErrInvalidAPIKeyName
+	}
+
+	key, created, err := s.Repo.CreateAPIKeyIfAbsent(ctx, name, secret[:apiKeyDisplayLength], hashAPIKey(secret), actor)
+	if err != nil {
+		log.Printf("FATAL ERROR: CreateAPIKeyIfAbsent failed for %s: %v", name, err)
+		return nil, false, err
+	}
+	if !created {
+		return &IssuedAPIKey{APIKey: *key}, false, nil
+	}
+
+	s.auditAPIKey(ctx, actor, "api_key.create", name)
+	log.Printf("INFO: %s created API key %s.", actor, name)
+	return &IssuedAPIKey{APIKey: *key, Key: secret}, true, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) ([]repository.APIKey, error) {
+	return s.Repo.ListAPIKeys(ctx)
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, name string, actor string) error {
+	deleted, err := s.Repo.DeleteAPIKey(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+	s.auditAPIKey(ctx, actor, "api_key.revoke", name)
+	log.Printf("INFO: %s revoked API key %s.", actor, name)
+	return nil
+}
+
+// VerifyAPIKey returns the name of the key, with ok false for unknown keys.
+func (s *Service) VerifyAPIKey(ctx context.Context, key string) (string, bool, error) {
+	name, err := s.Repo.TouchAPIKey(ctx, hashAPIKey(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return name, true, nil
+}
+
+// RotateAPIKeySigningSecret issues a new signing secret for the key, enabling
+// signed requests for it. The secret is only returned here; requests signed
+// with the previous one stop verifying at once.
+func (s *Service) RotateAPIKeySigningSecret(ctx context.Context, name string, actor string) (string, error) {
+	code, err := generateRandomCode(apiKeySecretLength)
+	if err != nil {
+		return "", err
+	}
+	secret := signingSecretPrefix + code
+	err = s.Repo.SetAPIKeySigningSecret(ctx, name, secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	s.auditAPIKey(ctx, actor, "api_key.signing.rotate", name)
+	log.Printf("INFO: %s issued a signing secret for API key %s.", actor, name)
+	return secret, nil
+}
+
+// DisableAPIKeySigning removes the key's signing secret; the key itself keeps
+// working as a bearer token.
+func (s *Service) DisableAPIKeySigning(ctx context.Context, name string, actor string) error {
+	err := s.Repo.SetAPIKeySigningSecret(ctx, name, "")
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.auditAPIKey(ctx, actor, "api_key.signing.disable", name)
+	log.Printf("INFO: %s disabled signed requests for API key %s.", actor, name)
+	return nil
+}
+
+// APIKeySigningSecret returns the key's signing secret, with ok false for
+// unknown keys and keys that do not sign.
+func (s *Service) APIKeySigningSecret(ctx context.Context, name string) (string, bool, error) {
+	secret, err := s.Repo.GetAPIKeySigningSecret(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return secret, true, nil
+}
+
+// AcceptSignedRequest claims the nonce of a request whose signature has been
+// verified. fresh is false when any instance accepted the nonce before.
+func (s *Service) AcceptSignedRequest(ctx context.Context, name string, nonce string) (bool, error) {
+	return s.Repo.ClaimRequestNonce(ctx, name, nonce)
+}
+
+// PruneRequestNonces forgets nonces too old to be replayed.
+func (s *Service) PruneRequestNonces(ctx context.Context) error {
+	n, err := s.Repo.PruneRequestNonces(ctx, requestNonceRetention)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("INFO: Pruned %d signed request nonces.", n)
+	}
+	return nil
+}
+
+// EnableBootstrap arms the one-time bootstrap endpoint when no API key exists
+// yet. An empty token generates one. The returned token is empty once the
+// instance has been bootstrapped.
+func (s *Service) EnableBootstrap(ctx context.Context, token string) (string, error) {
+	n, err := s.Repo.CountAPIKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+	if n > 0 {
+		return "", nil
+	}
+	if token == "" {
+		if token, err = newAPIKeySecret(); err != nil {
+			return "", err
+		}
+		token = "boot_" + token[len(apiKeySecretPrefix):]
+	}
+	s.bootstrapToken.Store(&token)
+	return token, nil
+}
+
+// Bootstrap exchanges the bootstrap token for the first admin API key. It
+// succeeds at most once across all instances sharing the database.
+func (s *Service) Bootstrap(ctx context.Context, token string, name string) (*IssuedAPIKey, error) {
+	expected := s.bootstrapToken.Load()
+	if expected == nil {
+		return nil, ErrAlreadyBootstrapped
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(*expected)) != 1 {
+		return nil, ErrInvalidBootstrapToken
+	}
+	if !apiKeyNamePattern.MatchString(name) {
+		return nil, ErrInvalidAPIKeyName
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.Repo.CreateFirstAPIKey(ctx, name, secret[:apiKeyDisplayLength], hashAPIKey(secret), "bootstrap")
+	if errors.Is(err, repository.ErrAlreadyBootstrapped) {
+		s.bootstrapToken.Store(nil)
+		return nil, ErrAlreadyBootstrapped
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: Bootstrap failed: %v", err)
+		return nil, err
+	}
+	s.bootstrapToken.Store(nil)
+
+	s.auditAPIKey(ctx, "bootstrap", "api_key.create", name)
+	log.Printf("INFO: Admin API bootstrapped with key %s. Bootstrap token is now invalid.", name)
+	return &IssuedAPIKey{APIKey: *key, Key: secret}, nil
+}
+
+func (s *Service) auditAPIKey(ctx context.Context, actor string, action string, name string) {
+	err := s.Repo.InsertAuditEntry(ctx, repository.AuditEntry{Actor: actor, Action: action, Target: name})
+	if err != nil {
+		log.Printf("ERROR: Failed to audit %s for %s: %v", action, name, err)
+	}
+}
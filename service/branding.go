@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// MaxLogoSize caps uploaded workspace logos.
+const MaxLogoSize = 512 << 10
+
+var (
+	ErrPageNotReplaced = errors.New("workspace has not replaced this page")
+	ErrNoLogo          = errors.New("workspace has no logo")
+	ErrInvalidLogo     = errors.New("logo must be a PNG, JPEG, GIF, WebP or SVG image of at most 512 KiB")
+)
+
+var logoTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "image/svg+xml"}
+
+func (s *Service) GetWorkspacePages(ctx context.Context, workspaceID int64) (*repository.WorkspacePages, error) {
+	wp, err := s.Repo.GetWorkspacePages(ctx, workspaceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+	return wp, err
+}
+
+func (s *Service) GetWorkspacePagesBySlug(ctx context.Context, slug string) (*repository.WorkspacePages, error) {
+	wp, err := s.Repo.GetWorkspacePagesBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+	return wp, err
+}
+
+// SetWorkspacePage stores a workspace's template for page. Callers check
+// that it parses; the service does not know about templates.
+func (s *Service) SetWorkspacePage(ctx context.Context, slug string, page string, template string) error {
+	err := s.Repo.SetWorkspacePage(ctx, slug, page, template)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("INFO: Workspace %s replaced its %s page.", slug, page)
+	return nil
+}
+
+func (s *Service) DeleteWorkspacePage(ctx context.Context, slug string, page string) error {
+	err := s.Repo.DeleteWorkspacePage(ctx, slug, page)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPageNotReplaced
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("INFO: Workspace %s restored the default %s page.", slug, page)
+	return nil
+}
+
+func (s *Service) SetWorkspaceLogo(ctx context.Context, slug string, contentType string, data []byte) error {
+	contentType, _, _ = strings.Cut(contentType, ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if len(data) == 0 || len(data) > MaxLogoSize || !isLogoType(contentType) {
+		return ErrInvalidLogo
+	}
+	err := s.Repo.SetWorkspaceLogo(ctx, slug, contentType, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("INFO: Workspace %s uploaded a %d byte %s logo.", slug, len(data), contentType)
+	return nil
+}
+
+func (s *Service) DeleteWorkspaceLogo(ctx context.Context, slug string) error {
+	err := s.Repo.DeleteWorkspaceLogo(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoLogo
+	}
+	return err
+}
+
+func (s *Service) GetWorkspaceLogo(ctx context.Context, slug string) (*repository.WorkspaceLogo, error) {
+	logo, err := s.Repo.GetWorkspaceLogo(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoLogo
+	}
+	return logo, err
+}
+
+// BrandedCodePrefixes maps code prefixes to the workspaces whose not-found
+// page applies to unknown codes carrying them.
+func (s *Service) BrandedCodePrefixes(ctx context.Context) (map[string]int64, error) {
+	return s.Repo.BrandedCodePrefixes(ctx)
+}
+
+func isLogoType(contentType string) bool {
+	for _, t := range logoTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
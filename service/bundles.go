@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidBundle       = errors.New("a bundle needs a title and 1 to 100 links")
+	ErrBundleNotFound      = errors.New("bundle not found")
+	ErrBundleLinksNotFound = errors.New("links not found")
+)
+
+const (
+	maxBundleLinks      = 100
+	maxBundleTitleBytes = 200
+)
+
+// BundleDetail is a bundle with its links, as rendered on its page.
+// TotalClicks counts every click of those links, not only clicks that came
+// through the bundle.
+type BundleDetail struct {
+	repository.Bundle
+	TotalClicks int                     `json:"total_clicks"`
+	Links       []repository.BundleLink `json:"links"`
+}
+
+// CreateBundle groups existing links under a new bundle code. A duplicate
+// code in links keeps its first position.
+func (s *Service) CreateBundle(ctx context.Context, title string, description string, links []string, actor string) (*repository.Bundle, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || len(title) > maxBundleTitleBytes || len(links) == 0 || len(links) > maxBundleLinks {
+		return nil, ErrInvalidBundle
+	}
+
+	for attempt := 0; ; attempt++ {
+		code, err := generateRandomCode(s.DesiredLength)
+		if err != nil {
+			return nil, err
+		}
+		bundle, missing, err := s.Repo.CreateBundle(ctx, code, title, description, links, actor)
+		if err != nil && strings.Contains(err.Error(), "unique_bundle_code") && attempt < s.MaxRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrBundleLinksNotFound, strings.Join(missing, ", "))
+		}
+		log.Printf("INFO: %s created bundle %s with %d links.", actor, code, bundle.LinkCount)
+		return bundle, nil
+	}
+}
+
+// ViewBundle loads a bundle for its public page and counts the view unless
+// countView is false, e.g. for link-preview fetchers.
+func (s *Service) ViewBundle(ctx context.Context, code string, countView bool) (*BundleDetail, error) {
+	bundle, err := s.Repo.GetBundle(ctx, code, countView)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBundleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.Repo.ListBundleLinks(ctx, bundle.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &BundleDetail{Bundle: *bundle, Links: links}
+	for _, l := range links {
+		detail.TotalClicks += l.ClickCount
+	}
+	return detail, nil
+}
+
+func (s *Service) ListBundles(ctx context.Context) ([]repository.Bundle, error) {
+	return s.Repo.ListBundles(ctx)
+}
+
+func (s *Service) DeleteBundle(ctx context.Context, code string, actor string) error {
+	err := s.Repo.DeleteBundle(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrBundleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("INFO: %s deleted bundle %s.", actor, code)
+	return nil
+}
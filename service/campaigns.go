@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrCampaignNotFound = errors.New("campaign not found")
+	ErrCampaignExists   = errors.New("campaign already exists")
+	ErrInvalidCampaign  = errors.New("invalid campaign request")
+)
+
+const (
+	DefaultCampaignStatsDays = 30
+	MaxCampaignStatsDays     = 365
+)
+
+type CampaignStats struct {
+	Campaign    *repository.Campaign           `json:"campaign"`
+	TotalClicks int                            `json:"total_clicks"`
+	TotalViews  int                            `json:"total_views"`
+	Links       []repository.CampaignLinkStats `json:"links"`
+	TimeSeries  []repository.TimeBucket        `json:"time_series"`
+}
+
+type AttachResult struct {
+	Attached []string `json:"attached"`
+	NotFound []string `json:"not_found"`
+}
+
+func (s *Service) CreateCampaign(ctx context.Context, name string, description string) (*repository.Campaign, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidCampaign
+	}
+
+	c, err := s.Repo.CreateCampaign(ctx, name, description)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique_campaign_name") {
+			return nil, ErrCampaignExists
+		}
+		log.Printf("FATAL ERROR: CreateCampaign failed for %s: %v", name, err)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Service) ListCampaigns(ctx context.Context) ([]repository.Campaign, error) {
+	return s.Repo.ListCampaigns(ctx)
+}
+
+func (s *Service) GetCampaign(ctx context.Context, campaignID int64) (*repository.Campaign, error) {
+	c, err := s.Repo.GetCampaign(ctx, campaignID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	return c, err
+}
+
+func (s *Service) AttachLinksToCampaign(ctx context.Context, campaignID int64, codes []string) (*AttachResult, error) {
+	if len(codes) == 0 || len(codes) > MaxTransferBatchSize {
+		return nil, ErrInvalidCampaign
+	}
+	if _, err := s.Repo.GetCampaign(ctx, campaignID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+
+	attached, err := s.Repo.AttachLinksToCampaign(ctx, campaignID, codes)
+	if err != nil {
+		log.Printf("FATAL ERROR: AttachLinksToCampaign failed for campaign %d: %v", campaignID, err)
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(attached))
+	for _, code := range attached {
+		found[code] = true
+	}
+	result := &AttachResult{Attached: attached, NotFound: []string{}}
+	for _, code := range codes {
+		if !found[code] {
+			result.NotFound = append(result.NotFound, code)
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) GetCampaignStats(ctx context.Context, campaignID int64, days int) (*CampaignStats, error) {
+	if days < 1 {
+		days = DefaultCampaignStatsDays
+	}
+	if days > MaxCampaignStatsDays {
+		days = MaxCampaignStatsDays
+	}
+
+	campaign, err := s.Repo.GetCampaign(ctx, campaignID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.Repo.GetCampaignLinkStats(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	series, err := s.Repo.GetCampaignDailyClicks(ctx, campaignID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CampaignStats{Campaign: campaign, Links: links, TimeSeries: series}
+	for _, l := range links {
+		stats.TotalClicks += l.ClickCount
+		stats.TotalViews += l.ViewCount
+	}
+	return stats, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrChangeExportInProgress = errors.New("another change export is already running")
+	ErrUnknownChangeStream    = errors.New("unknown change stream")
+)
+
+// ExportChanges writes the rows of every change stream added since its last
+// export to object storage as gzip'd newline-delimited JSON, one object per
+// line, under changes/<stream>/. Streams with nothing new are skipped.
+func (s *Service) ExportChanges(ctx context.Context) ([]repository.ChangeExport, error) {
+	if s.Storage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+
+	exports := []repository.ChangeExport{}
+	for _, stream := range repository.ChangeStreams {
+		export, err := s.Repo.ExportChanges(ctx, stream, func(from, to time.Time, rows func(emit func(row []byte) error) (int64, error)) (string, int64, error) {
+			return s.uploadChanges(ctx, stream, to, rows)
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if errors.Is(err, repository.ErrLockNotAcquired) {
+			return exports, ErrChangeExportInProgress
+		}
+		if err != nil {
+			return exports, err
+		}
+		log.Printf("INFO: Exported %d %s changes up to %s to %s.", export.RowCount, stream, export.ToTime.Format(time.RFC3339), export.StorageKey)
+		exports = append(exports, *export)
+	}
+	return exports, nil
+}
+
+func (s *Service) uploadChanges(ctx context.Context, stream string, to time.Time, rows func(emit func(row []byte) error) (int64, error)) (string, int64, error) {
+	key := fmt.Sprintf("changes/%s/%s-%s.ndjson.gz", stream, stream, to.UTC().Format("20060102T150405Z"))
+
+	pr, pw := io.Pipe()
+	type exportResult struct {
+		rows int64
+		err  error
+	}
+	done := make(chan exportResult, 1)
+
+	go func() {
+		gz := gzip.NewWriter(pw)
+		n, err := rows(func(row []byte) error {
+			if _, err := gz.Write(row); err != nil {
+				return err
+			}
+			_, err := gz.Write([]byte{'\n'})
+			return err
+		})
+		if err == nil {
+			err = gz.Close()
+		}
+		pw.CloseWithError(err)
+		done <- exportResult{rows: n, err: err}
+	}()
+
+	putErr := s.Storage.Put(ctx, key, pr, -1, "application/gzip")
+	pr.CloseWithError(putErr)
+	result := <-done
+
+	if result.err != nil {
+		return "", 0, fmt.Errorf("%s change export failed: %w", stream, result.err)
+	}
+	if putErr != nil {
+		return "", 0, fmt.Errorf("%s change upload failed: %w", stream, putErr)
+	}
+	return key, result.rows, nil
+}
+
+// RunChangeExport is the job-runner entry point. Another instance exporting
+// at the same time is not an error.
+func (s *Service) RunChangeExport(ctx context.Context) error {
+	_, err := s.ExportChanges(ctx)
+	if errors.Is(err, ErrChangeExportInProgress) {
+		return nil
+	}
+	return err
+}
+
+// ListChangeExports returns the newest exports, of one stream or of all
+// streams when stream is empty.
+func (s *Service) ListChangeExports(ctx context.Context, stream string) ([]repository.ChangeExport, error) {
+	if stream != "" {
+		known := false
+		for _, name := range repository.ChangeStreams {
+			known = known || name == stream
+		}
+		if !known {
+			return nil, ErrUnknownChangeStream
+		}
+	}
+	return s.Repo.ListChangeExports(ctx, stream, 100)
+}
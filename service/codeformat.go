@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// MinCodeLength is the shortest code length a workspace may choose.
+const MinCodeLength = 4
+
+const (
+	// smallKeyspace is the keyspace below which a format is warned about:
+	// a workspace issuing a few thousand links fills a noticeable share.
+	smallKeyspace = 1e6
+	// crowdedKeyspace is the share of a keyspace a workspace's existing
+	// links may take before collisions are warned about.
+	crowdedKeyspace = 0.1
+)
+
+var ErrInvalidCodeFormat = errors.New("invalid code format")
+
+// CodeFormatResult is a workspace after its code format changed, with the
+// number of codes the format allows and anything worth reconsidering.
+type CodeFormatResult struct {
+	Workspace    *repository.Workspace `json:"workspace"`
+	KeyspaceSize float64               `json:"keyspace_size"`
+	Warnings     []string              `json:"warnings,omitempty"`
+}
+
+func workspaceCodeFormat(w *repository.Workspace) repository.CodeFormat {
+	if w == nil {
+		return repository.CodeFormat{}
+	}
+	return repository.CodeFormat{Prefix: w.CodePrefix, Alphabet: w.CodeAlphabet, Length: w.CodeLength}
+}
+
+// codeAlphabetAndLength fills in the deployment's defaults for what format
+// leaves unset.
+func (s *Service) codeAlphabetAndLength(format repository.CodeFormat) (string, int) {
+	alphabet, length := format.Alphabet, format.Length
+	if alphabet == "" {
+		alphabet = Base62Alphabet
+	}
+	if length == 0 {
+		length = s.DesiredLength
+	}
+	if length == 0 {
+		length = MaxShortCodeLength
+	}
+	return alphabet, length
+}
+
+// codeKeyspace is the number of distinct random codes format allows, after
+// the region character.
+func (s *Service) codeKeyspace(format repository.CodeFormat) float64 {
+	alphabet, length := s.codeAlphabetAndLength(format)
+	return math.Pow(float64(len(alphabet)), float64(length-len(s.RegionCode)))
+}
+
+// validCodeAlphabet accepts 2 or more distinct base62 characters. Staying
+// within base62 keeps generated codes valid aliases and free of the dash
+// that ends code prefixes.
+func validCodeAlphabet(alphabet string) bool {
+	if len(alphabet) < 2 {
+		return false
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if !strings.ContainsRune(Base62Alphabet, rune(alphabet[i])) || strings.IndexByte(alphabet[i+1:], alphabet[i]) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetWorkspaceCodeFormat sets the alphabet and length of the codes issued
+// for a workspace's links from now on; "" and 0 restore the deployment's.
+// Aliases in the workspace must use the alphabet too.
+func (s *Service) SetWorkspaceCodeFormat(ctx context.Context, slug string, alphabet string, length int) (*CodeFormatResult, error) {
+	if alphabet != "" && !validCodeAlphabet(alphabet) {
+		return nil, fmt.Errorf("%w: alphabet must be at least 2 distinct letters or digits", ErrInvalidCodeFormat)
+	}
+	if length != 0 && (length < MinCodeLength || length > MaxShortCodeLength) {
+		return nil, fmt.Errorf("%w: length must be between %d and %d", ErrInvalidCodeFormat, MinCodeLength, MaxShortCodeLength)
+	}
+	if length != 0 && length <= len(s.RegionCode) {
+		return nil, fmt.Errorf("%w: length leaves no room after the region character", ErrInvalidCodeFormat)
+	}
+
+	w, err := s.Repo.SetWorkspaceCodeFormat(ctx, slug, alphabet, length)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	format := workspaceCodeFormat(w)
+	result := &CodeFormatResult{Workspace: w, KeyspaceSize: s.codeKeyspace(format), Warnings: []string{}}
+	if result.KeyspaceSize < smallKeyspace {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("only %.0f codes are possible; generation fails once most are taken", result.KeyspaceSize))
+	}
+	links, err := s.Repo.CountWorkspaceLinks(ctx, w.ID)
+	if err != nil {
+		return nil, err
+	}
+	if float64(links) > crowdedKeyspace*result.KeyspaceSize {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("the workspace already has %d links, so new codes will often collide", links))
+	}
+	if s.RegionCode != "" && alphabet != "" && !strings.Contains(alphabet, s.RegionCode) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("codes start with this region's character %q, which is outside the alphabet", s.RegionCode))
+	}
+
+	log.Printf("INFO: Workspace %s now issues codes of %q, length %d.", w.Slug, w.CodeAlphabet, w.CodeLength)
+	for _, warning := range result.Warnings {
+		log.Printf("WARN: Code format of workspace %s: %s.", w.Slug, warning)
+	}
+	return result, nil
+}
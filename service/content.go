@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/linkcheck"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+const (
+	inspectionMaxAge      = 24 * time.Hour
+	inspectionBatch       = 100
+	inspectionConcurrency = 8
+)
+
+// InspectNewDestinations probes the destinations of newly created links to learn
+// whether they are web pages or file downloads. Health checks refresh the
+// same information later on.
+func (s *Service) InspectNewDestinations(ctx context.Context) error {
+	if s.LinkChecker == nil {
+		return nil
+	}
+	targets, err := s.Repo.ListUninspectedLinks(ctx, inspectionMaxAge, inspectionBatch)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, inspectionConcurrency)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := s.LinkChecker.Check(ctx, t.LongURL)
+			// Unreachable destinations are stored as unknown content so they
+			// are not probed again on every run.
+			s.recordContent(ctx, t.URLID, result)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Service) recordContent(ctx context.Context, urlID int64, result linkcheck.Result) {
+	info := repository.ContentInfo{ContentType: result.ContentType, Download: result.Download()}
+	if result.ContentLength >= 0 && result.Status >= 200 && result.Status < 300 {
+		info.ContentLength = &result.ContentLength
+	}
+	if err := s.Repo.RecordLinkContent(ctx, urlID, info); err != nil {
+		log.Printf("ERROR: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"log"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrConversionsDisabled    = errors.New("conversion tracking is not configured")
+	ErrInvalidClickID         = errors.New("invalid click id")
+	ErrClickIDExpired         = errors.New("click id is older than the conversion window")
+	ErrInvalidConversionValue = errors.New("conversion value must be a non-negative number")
+	ErrConversionLinkNotFound = errors.New("link of this click no longer exists")
+)
+
+const (
+	DefaultConversionWindow = 30 * 24 * time.Hour
+	DefaultClickIDParam     = "click_id"
+)
+
+// A click ID is the link ID, the click's Unix time and a random nonce,
+// followed by a truncated HMAC of the three, in unpadded base64url: 35
+// characters that need no database row until the click converts.
+const (
+	clickIDPayloadLen = 8 + 4 + 4
+	clickIDMACLen     = 10
+)
+
+func (s *Service) newClickID(urlID int64, now time.Time) (string, error) {
+	buf := make([]byte, clickIDPayloadLen, clickIDPayloadLen+sha256.Size)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(urlID))
+	binary.BigEndian.PutUint32(buf[8:12], uint32(now.Unix()))
+	if _, err := rand.Read(buf[12:16]); err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, s.ConversionSecret)
+	mac.Write(buf)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(buf)[:clickIDPayloadLen+clickIDMACLen]), nil
+}
+
+func (s *Service) parseClickID(clickID string) (int64, time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(clickID)
+	if err != nil || len(raw) != clickIDPayloadLen+clickIDMACLen {
+		return 0, time.Time{}, ErrInvalidClickID
+	}
+	mac := hmac.New(sha256.New, s.ConversionSecret)
+	mac.Write(raw[:clickIDPayloadLen])
+	if !hmac.Equal(mac.Sum(nil)[:clickIDMACLen], raw[clickIDPayloadLen:]) {
+		return 0, time.Time{}, ErrInvalidClickID
+	}
+	urlID := int64(binary.BigEndian.Uint64(raw[0:8]))
+	clickedAt := time.Unix(int64(binary.BigEndian.Uint32(raw[8:12])), 0).UTC()
+	return urlID, clickedAt, nil
+}
+
+// TagClick appends a fresh click ID to target when dest tracks conversions.
+// The parameter is added to the raw query so the destination's own
+// parameters keep their order and encoding.
+func (s *Service) TagClick(dest repository.Destination, target string, now time.Time) string {
+	if !dest.TrackConversions || len(s.ConversionSecret) == 0 {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return target
+	}
+	clickID, err := s.newClickID(dest.ID, now)
+	if err != nil {
+		log.Printf("ERROR: Failed to issue click ID: %v", err)
+		return target
+	}
+	param := url.QueryEscape(s.ClickIDParam) + "=" + clickID
+	if u.RawQuery == "" {
+		u.RawQuery = param
+	} else {
+		u.RawQuery += "&" + param
+	}
+	return u.String()
+}
+
+// RecordConversion attributes a conversion to the click that issued clickID.
+// Reporting the same click again is not an error: the first conversion is
+// returned with created false, so advertisers can retry postbacks safely.
+func (s *Service) RecordConversion(ctx context.Context, clickID string, value *float64, actor string) (*repository.Conversion, bool, error) {
+	if len(s.ConversionSecret) == 0 {
+		return nil, false, ErrConversionsDisabled
+	}
+	urlID, clickedAt, err := s.parseClickID(clickID)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now().UTC()
+	if clickedAt.After(now.Add(time.Minute)) {
+		return nil, false, ErrInvalidClickID
+	}
+	if now.Sub(clickedAt) > s.ConversionWindow {
+		return nil, false, ErrClickIDExpired
+	}
+	if value != nil && (*value < 0 || math.IsInf(*value, 0) || math.IsNaN(*value)) {
+		return nil, false, ErrInvalidConversionValue
+	}
+
+	conv, created, err := s.Repo.InsertConversion(ctx, urlID, clickID, clickedAt, value, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, ErrConversionLinkNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		log.Printf("INFO: %s reported a conversion on %s.", actor, conv.ShortCode)
+	}
+	return conv, created, nil
+}
+
+func (s *Service) SetConversionTracking(ctx context.Context, shortCode string, enabled bool) error {
+	if enabled && len(s.ConversionSecret) == 0 {
+		return ErrConversionsDisabled
+	}
+	err := s.Repo.SetConversionTracking(ctx, shortCode, enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.invalidateDestination(shortCode)
+	return nil
+}
+
+func (s *Service) GetConversionTracking(ctx context.Context, shortCode string) (bool, error) {
+	enabled, err := s.Repo.GetConversionTracking(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	return enabled, err
+}
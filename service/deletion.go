@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidRestore = errors.New("invalid restore request")
+)
+
+const (
+	DefaultDeletedLinkRetention = 30 * 24 * time.Hour
+	MaxRestoreBatchSize         = 500
+)
+
+const (
+	CodeConflictSkip    = "skip"
+	CodeConflictNewCode = "new_code"
+)
+
+type RestoredLink struct {
+	OriginalCode string `json:"original_short_url"`
+	ShortCode    string `json:"short_url"`
+	ClickCount   int    `json:"click_count"`
+}
+
+type RestoreConflict struct {
+	ShortCode string `json:"short_url"`
+	Reason    string `json:"reason"`
+}
+
+type RestoreResult struct {
+	Restored  []RestoredLink    `json:"restored"`
+	Conflicts []RestoreConflict `json:"conflicts"`
+}
+
+func (s *Service) DeleteURL(ctx context.Context, shortCode string, actor string) error {
+	err := s.Repo.DeleteURL(ctx, shortCode, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: DeleteURL failed for code %s: %v", shortCode, err)
+		return err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: %s deleted short code %s.", actor, shortCode)
+	return nil
+}
+
+// RestoreDeletedURLs brings back links deleted in the last `days` days. When the
+// original code has been reissued, onCodeConflict decides whether the link gets
+// a fresh code or is reported as a conflict. Links whose destination was
+// shortened again after deletion are always reported as conflicts.
+func (s *Service) RestoreDeletedURLs(ctx context.Context, days int, codes []string, onCodeConflict string, actor string) (*RestoreResult, error) {
+	if days < 1 || len(codes) > MaxRestoreBatchSize {
+		return nil, ErrInvalidRestore
+	}
+	if onCodeConflict == "" {
+		onCodeConflict = CodeConflictSkip
+	}
+	if onCodeConflict != CodeConflictSkip && onCodeConflict != CodeConflictNewCode {
+		return nil, ErrInvalidRestore
+	}
+
+	deleted, err := s.Repo.ListDeletedURLs(ctx, days, codes)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) > MaxRestoreBatchSize {
+		deleted = deleted[:MaxRestoreBatchSize]
+	}
+
+	result := &RestoreResult{Restored: []RestoredLink{}, Conflicts: []RestoreConflict{}}
+	for _, d := range deleted {
+		existing, err := s.Repo.FindExistingShortCode(d.LongURL, d.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != "" {
+			result.Conflicts = append(result.Conflicts, RestoreConflict{ShortCode: d.ShortCode, Reason: "destination was shortened again as " + existing})
+			continue
+		}
+
+		code := d.ShortCode
+		free, err := s.Repo.IsShortCodeUnique(code)
+		if err != nil {
+			return nil, err
+		}
+		if !free {
+			if onCodeConflict == CodeConflictSkip {
+				result.Conflicts = append(result.Conflicts, RestoreConflict{ShortCode: d.ShortCode, Reason: "code was reissued"})
+				continue
+			}
+			format, err := s.Repo.GetDeletedLinkCodeFormat(ctx, d.ID)
+			if err != nil {
+				return nil, err
+			}
+			if code, err = s.generateUniqueCode(format); err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.Repo.RestoreDeletedURL(ctx, d.ID, code, actor)
+		if err != nil {
+			if strings.Contains(err.Error(), "unique_short_url") || strings.Contains(err.Error(), "unique_long_url") {
+				result.Conflicts = append(result.Conflicts, RestoreConflict{ShortCode: d.ShortCode, Reason: "concurrent change while restoring"})
+				continue
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			log.Printf("FATAL ERROR: RestoreDeletedURL failed for ID %d: %v", d.ID, err)
+			return nil, err
+		}
+		s.codeCreated(code)
+		result.Restored = append(result.Restored, RestoredLink{OriginalCode: d.ShortCode, ShortCode: code, ClickCount: d.ClickCount})
+	}
+
+	log.Printf("INFO: %s restored %d deleted links (%d conflicts).", actor, len(result.Restored), len(result.Conflicts))
+	return result, nil
+}
+
+func (s *Service) ListDeletedURLs(ctx context.Context, days int) ([]repository.DeletedURL, error) {
+	if days < 1 {
+		return nil, ErrInvalidRestore
+	}
+	return s.Repo.ListDeletedURLs(ctx, days, nil)
+}
+
+func (s *Service) PurgeDeletedURLs(ctx context.Context) error {
+	retention := s.DeletedLinkRetention
+	if retention == 0 {
+		retention = DefaultDeletedLinkRetention
+	}
+	n, err := s.Repo.PurgeDeletedURLs(ctx, retention)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("INFO: Purged %d deleted links older than %s.", n, retention)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// maxCachedDestinations bounds the destination cache. When it is full an
+// arbitrary entry makes room, which is good enough for a short TTL.
+const maxCachedDestinations = 100_000
+
+// maxDestinationRefreshes bounds the stale entries refreshed in the
+// background at once. Past it, stale entries are served without a refresh
+// until one finishes, so a slow database sees a fixed number of refresh
+// queries rather than one per expired code.
+const maxDestinationRefreshes = 16
+
+type cachedDestination struct {
+	dest       repository.Destination
+	expires    time.Time
+	staleUntil time.Time
+}
+
+// destinationCache keeps recently resolved destinations for a TTL, and past
+// it for a stale window in which they are still served while being
+// refreshed. Entries are invalidated on this instance when a link's routing
+// changes; other instances see the change once their entry is refreshed.
+type destinationCache struct {
+	mu         sync.RWMutex
+	entries    map[string]cachedDestination
+	refreshing atomic.Int32
+}
+
+// get returns the cached destination of shortCode and whether it is stale,
+// that is past its TTL but within its stale window.
+func (c *destinationCache) get(shortCode string, now time.Time) (dest repository.Destination, stale bool, ok bool) {
+	c.mu.RLock()
+	e, ok := c.entries[shortCode]
+	c.mu.RUnlock()
+	if !ok || now.After(e.staleUntil) {
+		return repository.Destination{}, false, false
+	}
+	return e.dest, now.After(e.expires), true
+}
+
+// startRefresh reserves one of the background refreshes, reporting false
+// when all are taken. finishRefresh releases it.
+func (c *destinationCache) startRefresh() bool {
+	if c.refreshing.Add(1) > maxDestinationRefreshes {
+		c.refreshing.Add(-1)
+		return false
+	}
+	return true
+}
+
+func (c *destinationCache) finishRefresh() {
+	c.refreshing.Add(-1)
+}
+
+func (c *destinationCache) put(shortCode string, dest repository.Destination, expires time.Time, staleUntil time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedDestination)
+	}
+	if _, ok := c.entries[shortCode]; !ok && len(c.entries) >= maxCachedDestinations {
+		for code := range c.entries {
+			delete(c.entries, code)
+			break
+		}
+	}
+	c.entries[shortCode] = cachedDestination{dest: dest, expires: expires, staleUntil: staleUntil}
+}
+
+func (c *destinationCache) invalidate(shortCode string) {
+	c.mu.Lock()
+	delete(c.entries, shortCode)
+	c.mu.Unlock()
+}
+
+// purge drops every entry, for changes that affect many links at once.
+func (c *destinationCache) purge() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// maxNegativeEntries bounds the not-found cache, which scanners probing random
+// codes can otherwise grow without limit. When full it is reset.
+const maxNegativeEntries = 200_000
+
+// DefaultNegativeCacheTTL is how long a code that resolved to nothing keeps
+// answering 404 without a database query.
+const DefaultNegativeCacheTTL = 10 * time.Second
+
+// negativeCache remembers codes that are neither live nor retired. Creating
+// a code on this instance forgets it immediately; other instances may keep
+// answering 404 for it until the entry expires.
+type negativeCache struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func (c *negativeCache) has(shortCode string, now time.Time) bool {
+	c.mu.RLock()
+	expires, ok := c.entries[shortCode]
+	c.mu.RUnlock()
+	return ok && now.Before(expires)
+}
+
+func (c *negativeCache) add(shortCode string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || len(c.entries) >= maxNegativeEntries {
+		c.entries = make(map[string]time.Time)
+	}
+	c.entries[shortCode] = expires
+}
+
+func (c *negativeCache) forget(shortCode string) {
+	c.mu.Lock()
+	delete(c.entries, shortCode)
+	c.mu.Unlock()
+}
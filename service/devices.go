@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/devices"
+)
+
+type BreakdownEntry struct {
+	Name   string `json:"name"`
+	Clicks int    `json:"clicks"`
+}
+
+type DeviceStats struct {
+	Days        int              `json:"days"`
+	TotalClicks int              `json:"total_clicks"`
+	Browsers    []BreakdownEntry `json:"browsers"`
+	OS          []BreakdownEntry `json:"os"`
+	Devices     []BreakdownEntry `json:"devices"`
+}
+
+func (s *Service) GetDeviceStats(ctx context.Context, shortCode string, days int) (*DeviceStats, error) {
+	if days < 1 {
+		days = DefaultCampaignStatsDays
+	}
+	if days > MaxCampaignStatsDays {
+		days = MaxCampaignStatsDays
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	rows, err := s.Repo.GetDeviceClicks(ctx, shortCode, since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	browsers := make(map[string]int)
+	systems := make(map[string]int)
+	classes := make(map[string]int)
+	stats := &DeviceStats{Days: days}
+	for _, row := range rows {
+		info := devices.Info{Browser: row.Browser, OS: row.OS, Device: row.Device}
+		if row.Device == "" {
+			info = devices.Parse(row.UserAgent)
+		}
+		browsers[info.Browser] += row.Clicks
+		systems[info.OS] += row.Clicks
+		classes[info.Device] += row.Clicks
+		stats.TotalClicks += row.Clicks
+	}
+	stats.Browsers = sortedBreakdown(browsers)
+	stats.OS = sortedBreakdown(systems)
+	stats.Devices = sortedBreakdown(classes)
+	return stats, nil
+}
+
+// sortedBreakdown orders entries by clicks, most first, then by name.
+func sortedBreakdown(counts map[string]int) []BreakdownEntry {
+	entries := make([]BreakdownEntry, 0, len(counts))
+	for name, clicks := range counts {
+		entries = append(entries, BreakdownEntry{Name: name, Clicks: clicks})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Clicks != entries[j].Clicks {
+			return entries[i].Clicks > entries[j].Clicks
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
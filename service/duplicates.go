@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidMerge = errors.New("a merge needs a canonical code and at least one other, distinct code")
+	ErrNotDuplicate = errors.New("links do not point to the same destination")
+)
+
+// DefaultDuplicateReportLimit caps the clusters of one duplicate report.
+const DefaultDuplicateReportLimit = 100
+
+// DuplicateCluster is a set of links whose destinations only differ in
+// ways that do not change the page, such as host case or a trailing slash.
+// Suggested is the link the others would best be merged into: the one with
+// the most clicks, or the oldest.
+type DuplicateCluster struct {
+	Destination string                   `json:"normalized_url"`
+	Suggested   string                   `json:"suggested_canonical"`
+	Links       []repository.LinkSummary `json:"links"`
+}
+
+type DuplicateReport struct {
+	Clusters       []DuplicateCluster `json:"clusters"`
+	TotalClusters  int                `json:"total_clusters"`
+	DuplicateLinks int                `json:"duplicate_links"`
+}
+
+type MergeResult struct {
+	Canonical string   `json:"canonical"`
+	Merged    []string `json:"merged"`
+}
+
+// FindDuplicateLinks groups every link by its normalized destination and
+// reports the groups of more than one link, largest first. Links created
+// before idempotent shortening, or with a differently written URL, end up
+// in such groups. The whole table is read, so this is an occasional admin
+// report rather than something to poll.
+func (s *Service) FindDuplicateLinks(ctx context.Context, limit int) (*DuplicateReport, error) {
+	if limit <= 0 {
+		limit = DefaultDuplicateReportLimit
+	}
+	groups := make(map[string][]repository.LinkSummary)
+	err := s.Repo.EachLinkSummary(ctx, func(l repository.LinkSummary) {
+		key := normalizeDestination(l.LongURL)
+		groups[key] = append(groups[key], l)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DuplicateReport{Clusters: []DuplicateCluster{}}
+	for key, links := range groups {
+		if len(links) < 2 {
+			continue
+		}
+		report.TotalClusters++
+		report.DuplicateLinks += len(links) - 1
+		sort.Slice(links, func(i, j int) bool {
+			if links[i].ClickCount != links[j].ClickCount {
+				return links[i].ClickCount > links[j].ClickCount
+			}
+			return links[i].CreatedAt.Before(links[j].CreatedAt)
+		})
+		report.Clusters = append(report.Clusters, DuplicateCluster{Destination: key, Suggested: links[0].ShortCode, Links: links})
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		a, b := report.Clusters[i], report.Clusters[j]
+		if len(a.Links) != len(b.Links) {
+			return len(a.Links) > len(b.Links)
+		}
+		return a.Destination < b.Destination
+	})
+	if len(report.Clusters) > limit {
+		report.Clusters = report.Clusters[:limit]
+	}
+	return report, nil
+}
+
+// MergeDuplicateLinks folds duplicates into canonical: their stats are added
+// to it and their codes permanently redirect to it. The links must share a
+// normalized destination.
+func (s *Service) MergeDuplicateLinks(ctx context.Context, canonical string, duplicates []string) (*MergeResult, error) {
+	if canonical == "" || len(duplicates) == 0 {
+		return nil, ErrInvalidMerge
+	}
+	seen := map[string]bool{canonical: true}
+	for _, code := range duplicates {
+		if seen[code] {
+			return nil, ErrInvalidMerge
+		}
+		seen[code] = true
+	}
+
+	links, err := s.Repo.GetLinkSummaries(ctx, append([]string{canonical}, duplicates...))
+	if err != nil {
+		return nil, err
+	}
+	if len(links) != len(duplicates)+1 {
+		return nil, ErrNotFound
+	}
+	destination := normalizeDestination(links[0].LongURL)
+	for _, l := range links[1:] {
+		if normalizeDestination(l.LongURL) != destination {
+			return nil, ErrNotDuplicate
+		}
+	}
+
+	err = s.Repo.MergeLinks(ctx, canonical, duplicates)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateDestination(canonical)
+	for _, code := range duplicates {
+		s.invalidateDestination(code)
+	}
+	log.Printf("INFO: Merged %d duplicate links into %s: %s", len(duplicates), canonical, strings.Join(duplicates, ", "))
+	return &MergeResult{Canonical: canonical, Merged: duplicates}, nil
+}
+
+// normalizeDestination rewrites a URL into the form its duplicates share:
+// lower-case scheme and host, no default port, no trailing slash or dot, no
+// fragment, and query parameters in a fixed order. Anything that does not
+// parse is compared as written.
+func normalizeDestination(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	host = strings.TrimSuffix(host, ".")
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment, u.RawFragment = "", ""
+	if u.Path == "" || u.Path == "/" {
+		u.Path, u.RawPath = "/", ""
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	}
+	if query, err := url.ParseQuery(u.RawQuery); err == nil {
+		u.RawQuery = query.Encode()
+	}
+	u.ForceQuery = false
+	return u.String()
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"strings"
+)
+
+// chiSquareCritical001 is the chi-square value with 61 degrees of freedom
+// (62 characters) that a uniform generator exceeds with probability 0.001.
+const chiSquareCritical001 = 100.88
+
+// legacyBiasedChars are the characters the modulo mapping used before
+// rejection sampling favoured: byte values 248-255 wrapped onto them.
+const legacyBiasedChars = 256 % len(Base62Alphabet)
+
+type CharFrequency struct {
+	Char  string  `json:"char"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// EntropyReport describes how evenly characters are spread over generated
+// codes. Only codes whose body, after any workspace prefix, is base62 of the
+// configured generated length are analyzed; the rest are counted as skipped.
+//
+// BiasedShare is the observed share of the first 8 alphabet characters. A
+// uniform generator gives 8/62 (12.9%), the legacy modulo mapping 40/256
+// (15.6%), so the value shows what share of codes predate the fix.
+type EntropyReport struct {
+	CodesAnalyzed       int             `json:"codes_analyzed"`
+	CodesSkipped        int             `json:"codes_skipped"`
+	Characters          int             `json:"characters"`
+	ChiSquare           float64         `json:"chi_square"`
+	ChiSquareCritical   float64         `json:"chi_square_critical"`
+	Biased              bool            `json:"biased"`
+	BiasedShare         float64         `json:"biased_share"`
+	UniformBiasedShare  float64         `json:"uniform_biased_share"`
+	LegacyBiasedShare   float64         `json:"legacy_biased_share"`
+	SnowflakeGeneration bool            `json:"snowflake_generation"`
+	Frequencies         []CharFrequency `json:"frequencies"`
+}
+
+// AuditCodeEntropy tests the character distribution of existing codes against
+// a uniform one. Snowflake codes encode timestamps, not random bytes, so the
+// report is only meaningful for codes issued by the random generator.
+func (s *Service) AuditCodeEntropy(ctx context.Context) (*EntropyReport, error) {
+	length := s.DesiredLength
+	if length == 0 {
+		length = MaxShortCodeLength
+	}
+
+	report := &EntropyReport{
+		ChiSquareCritical:   chiSquareCritical001,
+		UniformBiasedShare:  float64(legacyBiasedChars) / float64(len(Base62Alphabet)),
+		LegacyBiasedShare:   float64(legacyBiasedChars*5) / 256,
+		SnowflakeGeneration: s.Snowflake != nil,
+	}
+	var counts [len(Base62Alphabet)]int
+
+	err := s.Repo.EachShortCode(ctx, func(code string) {
+		body := strings.TrimPrefix(code, codePrefixOf(code))
+		if len(body) != length {
+			report.CodesSkipped++
+			return
+		}
+		for i := 0; i < len(body); i++ {
+			if strings.IndexByte(Base62Alphabet, body[i]) < 0 {
+				report.CodesSkipped++
+				return
+			}
+		}
+		for i := 0; i < len(body); i++ {
+			counts[strings.IndexByte(Base62Alphabet, body[i])]++
+		}
+		report.CodesAnalyzed++
+		report.Characters += len(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Frequencies = make([]CharFrequency, 0, len(counts))
+	if report.Characters == 0 {
+		return report, nil
+	}
+
+	expected := float64(report.Characters) / float64(len(counts))
+	biased := 0
+	for i, n := range counts {
+		d := float64(n) - expected
+		report.ChiSquare += d * d / expected
+		if i < legacyBiasedChars {
+			biased += n
+		}
+		report.Frequencies = append(report.Frequencies, CharFrequency{
+			Char:  Base62Alphabet[i : i+1],
+			Count: n,
+			Share: float64(n) / float64(report.Characters),
+		})
+	}
+	report.BiasedShare = float64(biased) / float64(report.Characters)
+	report.Biased = report.ChiSquare > chiSquareCritical001
+	return report, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrNoExpiry = errors.New("link does not expire")
+
+// expiryClaimBatch bounds how many warnings one claim marks as sent, so a
+// crash mid-run loses at most one batch.
+const expiryClaimBatch = 500
+
+func (s *Service) GetLinkExpiry(ctx context.Context, shortCode string) (*repository.LinkExpiry, error) {
+	e, err := s.Repo.GetLinkExpiry(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return e, err
+}
+
+// SetLinkExpiry sets when a link stops redirecting, or with nil makes it
+// permanent again.
+func (s *Service) SetLinkExpiry(ctx context.Context, shortCode string, expiresAt *time.Time) (*repository.LinkExpiry, error) {
+	if expiresAt != nil {
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+	e, err := s.Repo.SetLinkExpiry(ctx, shortCode, expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	if expiresAt == nil {
+		log.Printf("INFO: Link %s no longer expires.", shortCode)
+	} else {
+		log.Printf("INFO: Link %s expires at %s.", shortCode, expiresAt.Format(time.RFC3339))
+	}
+	return e, nil
+}
+
+func (s *Service) ExtendLinkExpiry(ctx context.Context, shortCode string, d time.Duration) (*repository.LinkExpiry, error) {
+	e, err := s.Repo.ExtendLinkExpiry(ctx, shortCode, d)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.noExpiryError(ctx, shortCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: Link %s extended by %s to %s.", shortCode, d, e.ExpiresAt.Format(time.RFC3339))
+	return e, nil
+}
+
+// SnoozeExpiryNotice holds back the warning about a link's expiry for d.
+func (s *Service) SnoozeExpiryNotice(ctx context.Context, shortCode string, d time.Duration) (*repository.LinkExpiry, error) {
+	e, err := s.Repo.SnoozeExpiryNotice(ctx, shortCode, time.Now().UTC().Add(d))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.noExpiryError(ctx, shortCode)
+	}
+	return e, err
+}
+
+// noExpiryError tells a missing link from one that never expires after an
+// update matched neither.
+func (s *Service) noExpiryError(ctx context.Context, shortCode string) error {
+	exists, err := s.Repo.LinkExists(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrNoExpiry
+}
+
+// NotifyExpiringLinks sends an expiring event to the webhooks of links that
+// expire within ExpiryNotice and whose owners have not been warned yet.
+// Links are marked as warned when claimed, so a failed delivery is not
+// retried by the next run; the webhook dispatcher's own retries cover it.
+func (s *Service) NotifyExpiringLinks(ctx context.Context) error {
+	if s.Webhooks == nil {
+		return nil
+	}
+	notice := s.ExpiryNotice
+	if notice == 0 {
+		notice = DefaultExpiryNotice
+	}
+
+	sent := 0
+	for {
+		now := time.Now().UTC()
+		links, err := s.Repo.ClaimExpiringLinks(ctx, now, now.Add(notice), expiryClaimBatch)
+		if err != nil {
+			return err
+		}
+		for _, l := range links {
+			expiresAt := l.ExpiresAt
+			s.Webhooks.Enqueue(events.Event{
+				Type:       events.TypeExpiring,
+				ShortCode:  l.ShortCode,
+				OccurredAt: now,
+				ExpiresAt:  &expiresAt,
+			})
+		}
+		sent += len(links)
+		if len(links) < expiryClaimBatch {
+			break
+		}
+	}
+	if sent > 0 {
+		log.Printf("INFO: Warned owners of %d expiring links.", sent)
+	}
+	return nil
+}
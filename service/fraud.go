@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/geoip"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrFlagNotFound = errors.New("click flag not found")
+
+const (
+	FlagReasonIPSpike     = "ip_spike"
+	FlagReasonASNSpike    = "asn_spike"
+	FlagReasonRapidClicks = "rapid_clicks"
+)
+
+// Detection thresholds. A spike is one IP producing at least fraudSpikeMinClicks
+// clicks and fraudSpikeMinShare of a link's clicks within fraudWindow. A
+// network spike is the same from one hosting network spread over at least
+// fraudASNSpikeMinIPs addresses, which the per-IP check misses; residential
+// and mobile networks are left out, as a popular link can draw most of its
+// clicks from one ISP. Rapid clicking is fraudRapidMinCount clicks arriving
+// within fraudRapidGap of the previous one from the same IP.
+const (
+	fraudWindow            = 15 * time.Minute
+	fraudSpikeMinClicks    = 50
+	fraudSpikeMinShare     = 0.5
+	fraudASNSpikeMinClicks = 100
+	fraudASNSpikeMinIPs    = 5
+	fraudRapidGap          = 300 * time.Millisecond
+	fraudRapidMinCount     = 10
+)
+
+// RunFraudDetection scans recent clicks for abnormal patterns and flags them.
+// Windows overlap between runs; flagging is idempotent.
+func (s *Service) RunFraudDetection(ctx context.Context) error {
+	since := time.Now().UTC().Add(-fraudWindow)
+
+	spikes, err := s.Repo.FindClickSpikes(ctx, since, fraudSpikeMinClicks, fraudSpikeMinShare)
+	if err != nil {
+		return err
+	}
+	for _, suspect := range spikes {
+		details := map[string]any{"window": fraudWindow.String(), "clicks_in_window": suspect.Clicks}
+		if err := s.Repo.FlagClicks(ctx, suspect, FlagReasonIPSpike, since, details); err != nil {
+			return err
+		}
+	}
+
+	traffic, err := s.Repo.CountNetworkClicks(ctx, since, fraudASNSpikeMinClicks)
+	if err != nil {
+		return err
+	}
+	networkSpikes := asnSpikes(traffic)
+	for _, suspect := range networkSpikes {
+		details := map[string]any{"window": fraudWindow.String(), "clicks_in_window": suspect.Clicks}
+		if err := s.Repo.FlagClicks(ctx, suspect, FlagReasonASNSpike, since, details); err != nil {
+			return err
+		}
+	}
+
+	rapid, err := s.Repo.FindRapidClicks(ctx, since, fraudRapidGap, fraudRapidMinCount)
+	if err != nil {
+		return err
+	}
+	for _, suspect := range rapid {
+		details := map[string]any{"window": fraudWindow.String(), "min_gap": fraudRapidGap.String(), "rapid_clicks": suspect.Clicks}
+		if err := s.Repo.FlagClicks(ctx, suspect, FlagReasonRapidClicks, since, details); err != nil {
+			return err
+		}
+	}
+
+	if len(spikes)+len(networkSpikes)+len(rapid) > 0 {
+		log.Printf("WARN: Fraud detection flagged %d IP spikes, %d network spikes and %d rapid-click sources.", len(spikes), len(networkSpikes), len(rapid))
+	}
+	return nil
+}
+
+// asnSpikes picks the network spikes out of a window's network traffic.
+func asnSpikes(traffic []repository.NetworkTraffic) []repository.SuspectTraffic {
+	var suspects []repository.SuspectTraffic
+	for _, t := range traffic {
+		if t.Clicks < fraudASNSpikeMinClicks || float64(t.Clicks) < fraudSpikeMinShare*float64(t.LinkClicks) {
+			continue
+		}
+		if t.UniqueIPs < fraudASNSpikeMinIPs || !geoip.IsHosting(t.ASN, t.Organization) {
+			continue
+		}
+		suspects = append(suspects, repository.SuspectTraffic{URLID: t.URLID, ASN: t.ASN, Clicks: t.Clicks})
+	}
+	return suspects
+}
+
+func (s *Service) ListClickFlags(ctx context.Context, days int) ([]repository.ClickFlag, error) {
+	if days < 1 {
+		days = 7
+	}
+	return s.Repo.ListClickFlags(ctx, days)
+}
+
+func (s *Service) DismissClickFlag(ctx context.Context, id int64, actor string) error {
+	err := s.Repo.DismissClickFlag(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrFlagNotFound
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("INFO: %s dismissed click flag %d.", actor, id)
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+func TestASNSpikes(t *testing.T) {
+	const amazon = 16509
+	const residential = 3320
+
+	tests := []struct {
+		name    string
+		traffic repository.NetworkTraffic
+		want    bool
+	}{
+		{
+			name:    "hosting network spread over many IPs",
+			traffic: repository.NetworkTraffic{URLID: 1, ASN: amazon, Organization: "AMAZON-02", Clicks: 120, UniqueIPs: 40, LinkClicks: 150},
+			want:    true,
+		},
+		{
+			name:    "hosting network named by organization",
+			traffic: repository.NetworkTraffic{URLID: 1, ASN: 64500, Organization: "Example Cloud Hosting", Clicks: 100, UniqueIPs: 5, LinkClicks: 200},
+			want:    true,
+		},
+		{
+			name:    "too few clicks",
+			traffic: repository.NetworkTraffic{URLID: 1, ASN: amazon, Organization: "AMAZON-02", Clicks: 99, UniqueIPs: 40, LinkClicks: 100},
+		},
+		{
+			name:    "small share of the link's clicks",
+			traffic: repository.NetworkTraffic{URLID: 1, ASN: amazon, Organization: "AMAZON-02", Clicks: 120, UniqueIPs: 40, LinkClicks: 241},
+		},
+		{
+			name:    "few addresses, left to the per-IP check",
+			traffic: repository.NetworkTraffic{URLID: 1, ASN: amazon, Organization: "AMAZON-02", Clicks: 120, UniqueIPs: 4, LinkClicks: 120},
+		},
+		{
+			name:    "residential network",
+			traffic: repository.NetworkTraffic{URLID: 1, ASN: residential, Organization: "Deutsche Telekom AG", Clicks: 500, UniqueIPs: 300, LinkClicks: 520},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := asnSpikes([]repository.NetworkTraffic{tt.traffic})
+			if !tt.want {
+				if len(got) != 0 {
+					t.Errorf("asnSpikes = %+v, want none", got)
+				}
+				return
+			}
+			want := []repository.SuspectTraffic{{URLID: tt.traffic.URLID, ASN: tt.traffic.ASN, Clicks: tt.traffic.Clicks}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("asnSpikes = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
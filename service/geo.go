@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/geoip"
+)
+
+// MinGeoCellVisitors is the fewest distinct IPs a grid square must have
+// clicked from to be listed. Sparser squares could single out a visitor in a
+// village, so their clicks are only counted in the totals.
+const MinGeoCellVisitors = 5
+
+// GeoPoint is one square of the heat map grid, named by its centre.
+type GeoPoint struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+	Clicks    int     `json:"clicks"`
+}
+
+// GeoStats places a link's clicks on a grid of Precision decimal degrees.
+// Unlocated clicks were recorded without a city database or from addresses
+// it could not place; suppressed clicks came from squares with fewer than
+// MinGeoCellVisitors visitors.
+type GeoStats struct {
+	Days             int        `json:"days"`
+	Precision        int        `json:"precision"`
+	TotalClicks      int        `json:"total_clicks"`
+	UnlocatedClicks  int        `json:"unlocated_clicks"`
+	SuppressedClicks int        `json:"suppressed_clicks"`
+	Points           []GeoPoint `json:"points"`
+}
+
+// GetGeoStats buckets a link's clicks over the last days by location, for
+// rendering as a heat map. precision is the number of decimal places of the
+// grid: 1 gives squares of about 11km, 0 about 110km.
+func (s *Service) GetGeoStats(ctx context.Context, shortCode string, days int, precision int) (*GeoStats, error) {
+	// Clicks are stored at geoip.CoordinatePrecision; a finer grid would
+	// only pretend to be more precise.
+	precision = max(0, min(precision, geoip.CoordinatePrecision))
+	if days < 1 {
+		days = DefaultCampaignStatsDays
+	}
+	if days > MaxCampaignStatsDays {
+		days = MaxCampaignStatsDays
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	cells, err := s.Repo.GetGeoClicks(ctx, shortCode, since, precision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &GeoStats{Days: days, Precision: precision, Points: []GeoPoint{}}
+	for _, c := range cells {
+		stats.TotalClicks += c.Clicks
+		switch {
+		case !c.Located:
+			stats.UnlocatedClicks += c.Clicks
+		case c.UniqueIPs < MinGeoCellVisitors:
+			stats.SuppressedClicks += c.Clicks
+		default:
+			stats.Points = append(stats.Points, GeoPoint{Latitude: c.Latitude, Longitude: c.Longitude, Clicks: c.Clicks})
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/wayback"
+)
+
+const (
+	// A destination is dead after this many failed checks in a row, so one
+	// outage does not send visitors to an archive.
+	deadAfterFailures = 3
+
+	healthCheckBatch       = 200
+	healthCheckConcurrency = 8
+)
+
+// CheckLinkHealth probes destinations not checked within HealthRecheck. When
+// a destination is dead and Archive is set, its latest Wayback Machine
+// snapshot becomes the redirect target until the destination recovers.
+func (s *Service) CheckLinkHealth(ctx context.Context) error {
+	if s.LinkChecker == nil || s.HealthRecheck <= 0 {
+		return nil
+	}
+	targets, err := s.Repo.ListHealthTargets(ctx, s.HealthRecheck, healthCheckBatch)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, healthCheckConcurrency)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.checkLink(ctx, t)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Service) checkLink(ctx context.Context, t repository.HealthTarget) {
+	result := s.LinkChecker.Check(ctx, t.LongURL)
+	health := repository.LinkHealth{URLID: t.URLID, StatusCode: result.Status}
+	if result.Err != nil {
+		health.Error = result.Err.Error()
+	}
+	if result.Dead() {
+		health.Failures = t.Failures + 1
+	}
+	dead := health.Failures >= deadAfterFailures
+	if result.Status >= 200 && result.Status < 300 {
+		s.recordContent(ctx, t.URLID, result)
+	}
+
+	if dead && !t.Dead {
+		log.Printf("WARN: Destination of %s is dead after %d failed checks: %s", t.ShortCode, health.Failures, t.LongURL)
+		if domain := registrableDomain(t.LongURL); domain != "" {
+			if err := s.RecordDomainSignal(ctx, domain, "health_failure"); err != nil {
+				log.Printf("ERROR: Failed to report %s: %v", domain, err)
+			}
+		}
+	}
+	if dead && s.Archive != nil {
+		snapshot, err := s.Archive.LatestSnapshot(ctx, t.LongURL)
+		if err != nil && !errors.Is(err, wayback.ErrNoSnapshot) {
+			log.Printf("ERROR: Wayback lookup for %s failed: %v", t.ShortCode, err)
+		}
+		health.ArchiveURL = snapshot
+	}
+
+	if err := s.Repo.RecordLinkHealth(ctx, health, dead); err != nil {
+		log.Printf("ERROR: %v", err)
+		return
+	}
+	if dead != t.Dead || health.ArchiveURL != "" {
+		s.invalidateDestination(t.ShortCode)
+	}
+	if !dead && t.Dead {
+		log.Printf("INFO: Destination of %s is reachable again.", t.ShortCode)
+	}
+}
+
+func (s *Service) ListDeadLinks(ctx context.Context) ([]repository.LinkHealth, error) {
+	return s.Repo.ListDeadLinks(ctx)
+}
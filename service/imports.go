@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/importer"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+type ImportedLink struct {
+	Line       int    `json:"line"`
+	SourceCode string `json:"source_code"`
+	ShortCode  string `json:"short_url"`
+	ClickCount int    `json:"click_count"`
+	Preserved  bool   `json:"code_preserved"`
+}
+
+type ImportConflict struct {
+	Line       int    `json:"line"`
+	SourceCode string `json:"source_code,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// ImportResult lists links that were created and rows that were not. Links
+// whose code had to be replaced appear in Imported with Preserved unset.
+type ImportResult struct {
+	Imported  []ImportedLink   `json:"imported"`
+	Conflicts []ImportConflict `json:"conflicts"`
+}
+
+// ImportLinks loads a bit.ly or TinyURL CSV export, keeping each original code
+// when it is a valid alias in the target namespace and not already taken.
+func (s *Service) ImportLinks(ctx context.Context, format string, export io.Reader, workspaceSlug string, actor string) (*ImportResult, error) {
+	var workspace *repository.Workspace
+	if workspaceSlug != "" {
+		w, err := s.Repo.GetWorkspaceBySlug(ctx, workspaceSlug)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		workspace = w
+	}
+
+	records, err := importer.Read(export, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Imported: []ImportedLink{}, Conflicts: []ImportConflict{}}
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		link, reason, err := s.importRecord(ctx, rec, workspace)
+		if err != nil {
+			log.Printf("FATAL ERROR: Import of line %d failed: %v", rec.Line, err)
+			return nil, err
+		}
+		if reason != "" {
+			result.Conflicts = append(result.Conflicts, ImportConflict{Line: rec.Line, SourceCode: rec.Code, Reason: reason})
+			continue
+		}
+		result.Imported = append(result.Imported, *link)
+	}
+
+	err = s.Repo.InsertAuditEntry(ctx, repository.AuditEntry{
+		Actor:  actor,
+		Action: "links.import",
+		Target: format,
+		Details: map[string]any{
+			"workspace": workspaceSlug,
+			"imported":  len(result.Imported),
+			"conflicts": len(result.Conflicts),
+		},
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to audit %s import: %v", format, err)
+	}
+
+	log.Printf("INFO: %s imported %d %s links (%d conflicts).", actor, len(result.Imported), format, len(result.Conflicts))
+	return result, nil
+}
+
+// importRecord returns a non-empty reason when the row is skipped.
+func (s *Service) importRecord(ctx context.Context, rec importer.Record, workspace *repository.Workspace) (*ImportedLink, string, error) {
+	if _, err := url.ParseRequestURI(rec.Destination); err != nil {
+		return nil, "missing or invalid destination", nil
+	}
+
+	var workspaceID *int64
+	var scope int64
+	if workspace != nil {
+		workspaceID = &workspace.ID
+		scope = workspace.ID
+	}
+
+	existing, err := s.Repo.FindExistingShortCode(rec.Destination, scope)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing != "" {
+		return nil, "destination already shortened as " + existing, nil
+	}
+
+	code := ""
+	if rec.Code != "" && validateAlias(rec.Code, workspace) == nil {
+		free, err := s.Repo.IsShortCodeUnique(rec.Code)
+		if err != nil {
+			return nil, "", err
+		}
+		if free {
+			code = rec.Code
+		}
+	}
+	if code == "" {
+		if code, err = s.generateUniqueCode(workspaceCodeFormat(workspace)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	_, err = s.Repo.InsertImportedURL(ctx, repository.ImportedLink{
+		LongURL:     rec.Destination,
+		ShortCode:   code,
+		CreatedAt:   rec.CreatedAt,
+		ClickCount:  rec.Clicks,
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unique_short_url") || strings.Contains(err.Error(), "unique_long_url") {
+			return nil, "concurrent change while importing", nil
+		}
+		return nil, "", err
+	}
+	s.codeCreated(code)
+
+	return &ImportedLink{
+		Line:       rec.Line,
+		SourceCode: rec.Code,
+		ShortCode:  code,
+		ClickCount: rec.Clicks,
+		Preserved:  code == rec.Code,
+	}, "", nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+func (s *Service) SetLinkInterstitial(ctx context.Context, shortCode string, enabled bool) error {
+	err := s.Repo.SetLinkInterstitial(ctx, shortCode, enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: Interstitial for %s set to %t.", shortCode, enabled)
+	return nil
+}
+
+// SetWorkspaceInterstitial changes the interstitial of all of a workspace's
+// links. The whole destination cache is dropped on this instance, since
+// any cached link may belong to the workspace.
+func (s *Service) SetWorkspaceInterstitial(ctx context.Context, slug string, enabled bool) (*repository.Workspace, error) {
+	w, err := s.Repo.SetWorkspaceInterstitial(ctx, slug, enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.destinations.purge()
+	log.Printf("INFO: Interstitial for workspace %s set to %t.", slug, enabled)
+	return w, nil
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"github.com/AnshulDekate/urlShortener/metrics"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// KeyspaceSize is the number of distinct random codes the generator can
+// draw: the configured length minus the region character, over base62.
+// Workspace prefixes are ignored, which makes the figure conservative, and
+// so are workspace code formats, which SetWorkspaceCodeFormat reports on.
+func (s *Service) KeyspaceSize() float64 {
+	return s.codeKeyspace(repository.CodeFormat{})
+}
+
+// UpdateKeyspaceUtilization publishes the share of the random code space
+// already taken. Each candidate collides with about that probability, so
+// generation exhausts its retries with roughly utilization^MaxRetries.
+func (s *Service) UpdateKeyspaceUtilization(ctx context.Context) error {
+	issued, err := s.Repo.EstimateIssuedCodes(ctx)
+	if err != nil {
+		return err
+	}
+	metrics.KeyspaceUtilization.Set(min(float64(issued)/s.KeyspaceSize(), 1))
+	return nil
+}
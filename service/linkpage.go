@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	cursorPrefix = "link:"
+)
+
+// LinkPage is one page of links in cursor order, newest first. EndCursor
+// continues after the last link on the page.
+type LinkPage struct {
+	Links       []repository.PagedURL
+	EndCursor   string
+	HasNextPage bool
+}
+
+// LinkCursor is the opaque cursor of a link in LinkPage order.
+func LinkCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatInt(id, 10)))
+}
+
+func decodeLinkCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(raw), cursorPrefix), 10, 64)
+	if err != nil || id < 1 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// ListLinkPage returns up to first links matching filter after the cursor
+// after, or from the newest link when after is empty.
+func (s *Service) ListLinkPage(ctx context.Context, filter repository.URLFilter, first int, after string) (*LinkPage, error) {
+	if first == 0 {
+		first = DefaultPageSize
+	}
+	if first < 1 || first > MaxPageSize {
+		return nil, ErrInvalidCursor
+	}
+	var before int64
+	if after != "" {
+		var err error
+		if before, err = decodeLinkCursor(after); err != nil {
+			return nil, err
+		}
+	}
+
+	// One extra row tells whether another page follows.
+	links, err := s.Repo.ListURLsBefore(ctx, filter, before, first+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &LinkPage{Links: links}
+	if len(links) > first {
+		page.Links, page.HasNextPage = links[:first], true
+	}
+	if n := len(page.Links); n > 0 {
+		page.EndCursor = LinkCursor(page.Links[n-1].ID)
+	}
+	return page, nil
+}
+
+func (s *Service) GetLink(ctx context.Context, shortCode string) (*repository.PagedURL, error) {
+	link, err := s.Repo.GetPagedURL(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return link, err
+}
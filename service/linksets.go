@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidLinkSet    = errors.New("invalid link set")
+	ErrLinkSetNotFound   = errors.New("link set not found")
+	ErrRecipientNotFound = errors.New("no link for this recipient")
+)
+
+const (
+	MaxLinkSetSize      = 1000
+	maxLinkSetTags      = 20
+	maxLinkSetTagBytes  = 64
+	maxLinkSetNameBytes = 200
+	maxRecipientBytes   = 256
+
+	// LinkSetCodePlaceholder in a link set's URL is replaced by each link's
+	// code.
+	LinkSetCodePlaceholder = "{code}"
+	// linkSetCodeParam carries each link's code when the URL has no
+	// placeholder. Long URLs are unique, so every link needs its own.
+	linkSetCodeParam = "code"
+)
+
+// LinkSetRequest describes a link set to create. Count links are created
+// for URL, or one per entry of Recipients, all tagged with Tags, expiring
+// at ExpiresAt and attached to CampaignID and Workspace when those are set.
+type LinkSetRequest struct {
+	Name       string
+	URL        string
+	Count      int
+	Recipients []string
+	Tags       []string
+	ExpiresAt  *time.Time
+	CampaignID *int64
+	Workspace  string
+}
+
+// LinkSetDetail is a link set with its links.
+type LinkSetDetail struct {
+	repository.LinkSet
+	Links []repository.LinkSetLink `json:"links"`
+}
+
+// RecipientStats are the stats of the link issued to one recipient.
+type RecipientStats struct {
+	Recipient string `json:"recipient"`
+	*repository.LinkStats
+}
+
+// CreateLinkSet issues req.Count fresh codes and creates their links in one
+// go. Each link's destination is req.URL with the code in place of
+// LinkSetCodePlaceholder, or appended as a code query parameter.
+func (s *Service) CreateLinkSet(ctx context.Context, req LinkSetRequest, actor string) (*LinkSetDetail, error) {
+	if len(req.Recipients) > 0 {
+		recipients, err := normalizeRecipients(req.Recipients)
+		if err != nil {
+			return nil, err
+		}
+		req.Recipients = recipients
+		if req.Count == 0 {
+			req.Count = len(recipients)
+		}
+	}
+	set, err := s.newLinkSet(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	set.CreatedBy = actor
+
+	var format repository.CodeFormat
+	if req.Workspace != "" {
+		w, err := s.Repo.GetWorkspaceBySlug(ctx, req.Workspace)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		set.WorkspaceID, format = &w.ID, workspaceCodeFormat(w)
+	}
+
+	for attempt := 0; ; attempt++ {
+		set.ShortCodes, set.LongURLs = set.ShortCodes[:0], set.LongURLs[:0]
+		for i := 0; i < req.Count; i++ {
+			code, err := s.generateUniqueCode(format)
+			if err != nil {
+				return nil, err
+			}
+			set.ShortCodes = append(set.ShortCodes, code)
+			set.LongURLs = append(set.LongURLs, linkSetDestination(req.URL, code))
+		}
+
+		// A code taken between the uniqueness check and the insert fails
+		// the whole set; it is retried with fresh codes.
+		set.Recipients = req.Recipients
+		created, err := s.Repo.CreateLinkSet(ctx, set)
+		if err != nil && strings.Contains(err.Error(), "unique_short_url") && attempt < s.MaxRetries {
+			log.Printf("WARN: A code of link set %s was taken concurrently. Retrying with new codes.", req.Name)
+			continue
+		}
+		if err != nil && strings.Contains(err.Error(), "unique_long_url") {
+			return nil, fmt.Errorf("%w: a destination of this set is already shortened", ErrInvalidLinkSet)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range set.ShortCodes {
+			s.codeCreated(code)
+		}
+		log.Printf("INFO: %s created link set %d (%s) with %d links.", actor, created.ID, created.Name, created.LinkCount)
+		return s.GetLinkSet(ctx, created.ID)
+	}
+}
+
+// newLinkSet validates req into the stored form of the set, without codes.
+func (s *Service) newLinkSet(ctx context.Context, req LinkSetRequest) (repository.NewLinkSet, error) {
+	set := repository.NewLinkSet{Name: strings.TrimSpace(req.Name), ExpiresAt: req.ExpiresAt, CampaignID: req.CampaignID}
+	if set.Name == "" || len(set.Name) > maxLinkSetNameBytes {
+		return set, fmt.Errorf("%w: name must be 1 to %d bytes", ErrInvalidLinkSet, maxLinkSetNameBytes)
+	}
+	if req.Count < 1 || req.Count > MaxLinkSetSize {
+		return set, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidLinkSet, MaxLinkSetSize)
+	}
+	if len(req.Recipients) > 0 && len(req.Recipients) != req.Count {
+		return set, fmt.Errorf("%w: count must match the number of recipients", ErrInvalidLinkSet)
+	}
+	u, err := url.Parse(strings.ReplaceAll(req.URL, LinkSetCodePlaceholder, "x"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return set, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidLinkSet)
+	}
+	if set.ExpiresAt != nil {
+		if !set.ExpiresAt.After(time.Now()) {
+			return set, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidLinkSet)
+		}
+		utc := set.ExpiresAt.UTC()
+		set.ExpiresAt = &utc
+	}
+
+	set.Tags = []string{}
+	seen := map[string]bool{}
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > maxLinkSetTagBytes {
+			return set, fmt.Errorf("%w: tags must be 1 to %d bytes", ErrInvalidLinkSet, maxLinkSetTagBytes)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			set.Tags = append(set.Tags, tag)
+		}
+	}
+	if len(set.Tags) > maxLinkSetTags {
+		return set, fmt.Errorf("%w: at most %d tags", ErrInvalidLinkSet, maxLinkSetTags)
+	}
+
+	if set.CampaignID != nil {
+		if _, err := s.GetCampaign(ctx, *set.CampaignID); err != nil {
+			return set, err
+		}
+	}
+	set.ShortCodes = make([]string, 0, req.Count)
+	set.LongURLs = make([]string, 0, req.Count)
+	return set, nil
+}
+
+func (s *Service) GetLinkSet(ctx context.Context, id int64) (*LinkSetDetail, error) {
+	set, err := s.Repo.GetLinkSet(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLinkSetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.Repo.ListLinkSetLinks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &LinkSetDetail{LinkSet: *set, Links: links}, nil
+}
+
+// LinkSetRecipientStats returns the stats of the link a set issued to
+// recipient.
+func (s *Service) LinkSetRecipientStats(ctx context.Context, id int64, recipient string, excludeFlagged bool) (*RecipientStats, error) {
+	code, err := s.Repo.GetLinkSetRecipientCode(ctx, id, strings.TrimSpace(recipient))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.GetLinkStats(ctx, code, excludeFlagged)
+	if err != nil {
+		return nil, err
+	}
+	return &RecipientStats{Recipient: strings.TrimSpace(recipient), LinkStats: stats}, nil
+}
+
+// normalizeRecipients trims recipient identifiers and rejects empty, overly
+// long and repeated ones, since each must map to exactly one link.
+func normalizeRecipients(recipients []string) ([]string, error) {
+	out := make([]string, len(recipients))
+	seen := make(map[string]bool, len(recipients))
+	for i, r := range recipients {
+		r = strings.TrimSpace(r)
+		if r == "" || len(r) > maxRecipientBytes {
+			return nil, fmt.Errorf("%w: recipients must be 1 to %d bytes", ErrInvalidLinkSet, maxRecipientBytes)
+		}
+		if seen[r] {
+			return nil, fmt.Errorf("%w: recipient %q is listed twice", ErrInvalidLinkSet, r)
+		}
+		seen[r] = true
+		out[i] = r
+	}
+	return out, nil
+}
+
+// linkSetDestination is where the link with code in a set for rawURL
+// points.
+func linkSetDestination(rawURL string, code string) string {
+	if strings.Contains(rawURL, LinkSetCodePlaceholder) {
+		return strings.ReplaceAll(rawURL, LinkSetCodePlaceholder, url.QueryEscape(code))
+	}
+	base, fragment, hasFragment := strings.Cut(rawURL, "#")
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	dest := base + sep + linkSetCodeParam + "=" + url.QueryEscape(code)
+	if hasFragment {
+		dest += "#" + fragment
+	}
+	return dest
+}
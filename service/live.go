@@ -0,0 +1,30 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/analytics"
+)
+
+const liveTrendingLimit = 10
+
+// LiveStats covers only clicks served by this instance.
+type LiveStats struct {
+	WindowSeconds int                      `json:"window_seconds"`
+	Clicks        int                      `json:"clicks"`
+	Trending      []analytics.TrendingLink `json:"trending"`
+	GeneratedAt   time.Time                `json:"generated_at"`
+}
+
+func (s *Service) GetLiveStats() *LiveStats {
+	now := time.Now().UTC()
+	stats := &LiveStats{
+		WindowSeconds: int(analytics.LiveWindow / time.Second),
+		Trending:      []analytics.TrendingLink{},
+		GeneratedAt:   now,
+	}
+	if s.Live != nil {
+		stats.Clicks, stats.Trending = s.Live.Snapshot(now, liveTrendingLimit)
+	}
+	return stats
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+const (
+	MaxMetadataKeys       = 32
+	maxMetadataValueBytes = 512
+)
+
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// metadataKeyPattern keeps keys usable in the metadata.<key>=<value> filter
+// of listings.
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateMetadata checks the keys and values of link metadata.
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataKeys {
+		return fmt.Errorf("%w: at most %d keys", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+	for key, value := range metadata {
+		if !metadataKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: key %q must be 1 to 64 letters, digits, _ or -", ErrInvalidMetadata, key)
+		}
+		if len(value) > maxMetadataValueBytes {
+			return fmt.Errorf("%w: value of %q exceeds %d bytes", ErrInvalidMetadata, key, maxMetadataValueBytes)
+		}
+	}
+	return nil
+}
+
+// PatchLinkMetadata merges patch into a link's metadata: keys with a value
+// are set and keys with nil are removed. It returns the resulting metadata.
+func (s *Service) PatchLinkMetadata(ctx context.Context, shortCode string, patch map[string]*string, actor string) (map[string]string, error) {
+	set := make(map[string]string, len(patch))
+	var remove []string
+	for key, value := range patch {
+		if value == nil {
+			remove = append(remove, key)
+			continue
+		}
+		set[key] = *value
+	}
+	if err := ValidateMetadata(set); err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.Repo.PatchLinkMetadata(ctx, shortCode, set, remove, MaxMetadataKeys)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if errors.Is(err, repository.ErrTooManyMetadataKeys) {
+		return nil, fmt.Errorf("%w: at most %d keys", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INFO: %s set %d and removed %d metadata keys of %s.", actor, len(set), len(remove), shortCode)
+	return metadata, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+
+	"github.com/AnshulDekate/urlShortener/importer"
+)
+
+var ErrInvalidMirror = errors.New("invalid mirror source")
+
+type MirroredLink struct {
+	Source    string `json:"source"`
+	ShortCode string `json:"short_url"`
+	Preserved bool   `json:"code_preserved"`
+}
+
+type MirrorFailure struct {
+	Line   int    `json:"line"`
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+}
+
+type MirrorImportResult struct {
+	Mirrored []MirroredLink  `json:"mirrored"`
+	Failed   []MirrorFailure `json:"failed"`
+}
+
+// CreateMirror creates a local code that permanently redirects to a short URL
+// on another service. The external code is reused locally when it is a valid,
+// free alias so links can be migrated by swapping the domain only.
+func (s *Service) CreateMirror(ctx context.Context, source string, workspace string) (*MirroredLink, error) {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidMirror
+	}
+
+	opts := ShortenOptions{Workspace: workspace, Mirror: true}
+	if code := u.Path; len(code) > 1 {
+		opts.Alias = code[1:]
+	}
+
+	if opts.Alias != "" {
+		code, err := s.CreateShortURLWithOptions(ctx, source, opts)
+		if err == nil {
+			return &MirroredLink{Source: source, ShortCode: code, Preserved: code == opts.Alias}, nil
+		}
+		if !errors.Is(err, ErrInvalidAlias) && !errors.Is(err, ErrAliasTaken) {
+			return nil, err
+		}
+		opts.Alias = ""
+	}
+
+	code, err := s.CreateShortURLWithOptions(ctx, source, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &MirroredLink{Source: source, ShortCode: code}, nil
+}
+
+// ImportBitlyMirrors mirrors every link of a bit.ly export. Rows are handled
+// independently; failures are reported without aborting the import.
+func (s *Service) ImportBitlyMirrors(ctx context.Context, export io.Reader, workspace string) (*MirrorImportResult, error) {
+	records, err := importer.ReadBitly(export)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MirrorImportResult{Mirrored: []MirroredLink{}, Failed: []MirrorFailure{}}
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if rec.ShortURL == "" {
+			result.Failed = append(result.Failed, MirrorFailure{Line: rec.Line, Reason: "missing or malformed short link"})
+			continue
+		}
+
+		link, err := s.CreateMirror(ctx, rec.ShortURL, workspace)
+		if err != nil {
+			if errors.Is(err, ErrWorkspaceNotFound) {
+				return nil, err
+			}
+			result.Failed = append(result.Failed, MirrorFailure{Line: rec.Line, Source: rec.ShortURL, Reason: err.Error()})
+			continue
+		}
+		result.Mirrored = append(result.Mirrored, *link)
+	}
+
+	log.Printf("INFO: Imported %d bit.ly mirrors (%d failed).", len(result.Mirrored), len(result.Failed))
+	return result, nil
+}
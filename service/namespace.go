@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidCodePrefix   = errors.New("invalid code prefix")
+	ErrCodePrefixTaken     = errors.New("code prefix already reserved")
+	ErrInvalidAlias        = errors.New("invalid alias")
+	ErrAliasTaken          = errors.New("alias already in use")
+	ErrURLAlreadyShortened = errors.New("destination already shortened under a different code")
+)
+
+// Code prefixes are the only source of dashes in short codes: generated codes
+// are pure base62 and unprefixed aliases may not contain one. Since a prefix
+// ends at its single dash, no prefix can start another and tenant namespaces
+// never overlap.
+var (
+	codePrefixPattern = regexp.MustCompile(`^[a-z0-9]{2,12}-$`)
+	aliasBodyPattern  = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+const MaxAliasLength = 24
+
+// reservedAliases shadow top-level routes and can never be used as codes.
+var reservedAliases = map[string]bool{
+	"admin":       true,
+	"api":         true,
+	"b":           true,
+	"graphql":     true,
+	"healthcheck": true,
+	"metrics":     true,
+	"shorten":     true,
+	"tools":       true,
+	"urls":        true,
+}
+
+// codePrefixOf returns the tenant prefix a code was issued under, if any.
+func codePrefixOf(code string) string {
+	if i := strings.IndexByte(code, '-'); i >= 0 {
+		return code[:i+1]
+	}
+	return ""
+}
+
+// validateAlias checks a requested code against the namespace of the
+// workspace it is created in. workspace may be nil.
+func validateAlias(alias string, workspace *repository.Workspace) error {
+	if len(alias) > MaxAliasLength || reservedAliases[strings.ToLower(alias)] {
+		return ErrInvalidAlias
+	}
+
+	prefix := ""
+	if workspace != nil {
+		prefix = workspace.CodePrefix
+	}
+	if codePrefixOf(alias) != prefix {
+		return ErrInvalidAlias
+	}
+
+	body := strings.TrimPrefix(alias, prefix)
+	if len(body) < 3 || !aliasBodyPattern.MatchString(body) {
+		return ErrInvalidAlias
+	}
+	if workspace != nil && workspace.CodeAlphabet != "" && strings.Trim(body, workspace.CodeAlphabet) != "" {
+		return ErrInvalidAlias
+	}
+	return nil
+}
+
+func (s *Service) SetWorkspaceCodePrefix(ctx context.Context, slug string, prefix string) (*repository.Workspace, error) {
+	if prefix != "" && !codePrefixPattern.MatchString(prefix) {
+		return nil, ErrInvalidCodePrefix
+	}
+
+	w, err := s.Repo.SetWorkspaceCodePrefix(ctx, slug, prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "unique_workspace_code_prefix") {
+			return nil, ErrCodePrefixTaken
+		}
+		log.Printf("FATAL ERROR: SetWorkspaceCodePrefix failed for %s: %v", slug, err)
+		return nil, err
+	}
+	log.Printf("INFO: Workspace %s now issues codes with prefix %q.", w.Slug, w.CodePrefix)
+	return w, nil
+}
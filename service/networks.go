@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/geoip"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// maxNetworkBreakdownRows caps the listed networks; the totals still count all.
+const maxNetworkBreakdownRows = 50
+
+type NetworkBreakdown struct {
+	repository.NetworkClicks
+	Hosting bool `json:"hosting"`
+}
+
+// NetworkStats splits a link's clicks by the network they came from. Hosting
+// clicks come from cloud and datacenter ASNs and are usually automated;
+// unknown clicks were recorded without an ASN database or from unlisted IPs.
+type NetworkStats struct {
+	Days              int                `json:"days"`
+	TotalClicks       int                `json:"total_clicks"`
+	HostingClicks     int                `json:"hosting_clicks"`
+	ResidentialClicks int                `json:"residential_clicks"`
+	UnknownClicks     int                `json:"unknown_clicks"`
+	Networks          []NetworkBreakdown `json:"networks"`
+}
+
+func (s *Service) GetNetworkStats(ctx context.Context, shortCode string, days int) (*NetworkStats, error) {
+	if days < 1 {
+		days = DefaultCampaignStatsDays
+	}
+	if days > MaxCampaignStatsDays {
+		days = MaxCampaignStatsDays
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	rows, err := s.Repo.GetNetworkClicks(ctx, shortCode, since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &NetworkStats{Days: days, Networks: make([]NetworkBreakdown, 0, min(len(rows), maxNetworkBreakdownRows))}
+	for _, n := range rows {
+		b := NetworkBreakdown{NetworkClicks: n, Hosting: n.ASN != 0 && geoip.IsHosting(n.ASN, n.Organization)}
+		stats.TotalClicks += n.Clicks
+		switch {
+		case n.ASN == 0:
+			stats.UnknownClicks += n.Clicks
+		case b.Hosting:
+			stats.HostingClicks += n.Clicks
+		default:
+			stats.ResidentialClicks += n.Clicks
+		}
+		if len(stats.Networks) < maxNetworkBreakdownRows {
+			stats.Networks = append(stats.Networks, b)
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidOpenGraph  = errors.New("invalid open graph tags")
+	ErrOpenGraphNotFound = errors.New("link has no custom open graph tags")
+)
+
+const (
+	maxOpenGraphTitle       = 200
+	maxOpenGraphDescription = 500
+)
+
+// SetOpenGraph sets the title, description and image a link unfurls with on
+// social platforms and in messaging apps, whatever the destination declares.
+func (s *Service) SetOpenGraph(ctx context.Context, shortCode string, og repository.OpenGraph) (*repository.OpenGraph, error) {
+	og.Title = strings.TrimSpace(og.Title)
+	og.Description = strings.TrimSpace(og.Description)
+	og.ImageURL = strings.TrimSpace(og.ImageURL)
+	if og.Title == "" && og.Description == "" && og.ImageURL == "" {
+		return nil, ErrInvalidOpenGraph
+	}
+	if utf8.RuneCountInString(og.Title) > maxOpenGraphTitle || utf8.RuneCountInString(og.Description) > maxOpenGraphDescription {
+		return nil, ErrInvalidOpenGraph
+	}
+	if og.ImageURL != "" && !isAbsoluteHTTPURL(og.ImageURL) {
+		return nil, ErrInvalidOpenGraph
+	}
+
+	saved, err := s.Repo.UpsertOpenGraph(ctx, shortCode, og)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INFO: Set Open Graph tags for %s.", shortCode)
+	return saved, nil
+}
+
+func (s *Service) GetOpenGraph(ctx context.Context, shortCode string) (*repository.OpenGraph, error) {
+	og, err := s.Repo.GetOpenGraph(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOpenGraphNotFound
+	}
+	return og, err
+}
+
+func (s *Service) DeleteOpenGraph(ctx context.Context, shortCode string) error {
+	deleted, err := s.Repo.DeleteOpenGraph(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrOpenGraphNotFound
+	}
+	log.Printf("INFO: Removed Open Graph tags from %s.", shortCode)
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceExists   = errors.New("workspace already exists")
+	ErrInvalidWorkspace  = errors.New("invalid workspace slug")
+	ErrInvalidTransfer   = errors.New("invalid transfer request")
+	ErrTransferConflict  = errors.New("destination already shortened in the target workspace")
+)
+
+const MaxTransferBatchSize = 500
+
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,63}$`)
+
+type TransferResult struct {
+	Transferred []repository.TransferredLink `json:"transferred"`
+	NotFound    []string                     `json:"not_found"`
+}
+
+func (s *Service) CreateWorkspace(ctx context.Context, slug string, name string, codePrefix string) (*repository.Workspace, error) {
+	if !workspaceSlugPattern.MatchString(slug) {
+		return nil, ErrInvalidWorkspace
+	}
+	if codePrefix != "" && !codePrefixPattern.MatchString(codePrefix) {
+		return nil, ErrInvalidCodePrefix
+	}
+	if name == "" {
+		name = slug
+	}
+
+	w, err := s.Repo.CreateWorkspace(ctx, slug, name, codePrefix)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique_workspace_slug") {
+			return nil, ErrWorkspaceExists
+		}
+		if strings.Contains(err.Error(), "unique_workspace_code_prefix") {
+			return nil, ErrCodePrefixTaken
+		}
+		log.Printf("FATAL ERROR: CreateWorkspace failed for %s: %v", slug, err)
+		return nil, err
+	}
+	log.Printf("INFO: Created workspace %s (ID %d).", w.Slug, w.ID)
+	return w, nil
+}
+
+// TransferLinks records a new owner and/or workspace for the given links.
+// Neither grants access: the owner is a label, and every admin credential
+// can manage every link whoever owns it.
+func (s *Service) TransferLinks(ctx context.Context, codes []string, owner string, workspaceSlug string, actor string) (*TransferResult, error) {
+	if len(codes) == 0 || len(codes) > MaxTransferBatchSize {
+		return nil, ErrInvalidTransfer
+	}
+	if owner == "" && workspaceSlug == "" {
+		return nil, ErrInvalidTransfer
+	}
+
+	var workspace *repository.Workspace
+	if workspaceSlug != "" {
+		w, err := s.Repo.GetWorkspaceBySlug(ctx, workspaceSlug)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		workspace = w
+	}
+
+	transferred, err := s.Repo.TransferLinks(ctx, codes, owner, workspace, actor)
+	if err != nil && strings.Contains(err.Error(), "unique_long_url") {
+		return nil, ErrTransferConflict
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: TransferLinks failed for %d codes: %v", len(codes), err)
+		return nil, err
+	}
+
+	moved := make(map[string]bool, len(transferred))
+	for _, t := range transferred {
+		moved[t.ShortCode] = true
+	}
+	result := &TransferResult{Transferred: transferred, NotFound: []string{}}
+	if result.Transferred == nil {
+		result.Transferred = []repository.TransferredLink{}
+	}
+	for _, code := range codes {
+		if !moved[code] {
+			result.NotFound = append(result.NotFound, code)
+		}
+	}
+
+	log.Printf("INFO: %s transferred %d links to owner=%q workspace=%q (%d not found).", actor, len(transferred), owner, workspaceSlug, len(result.NotFound))
+	return result, nil
+}
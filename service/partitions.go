@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// ClickPartitionsAhead is how many future months of click partitions are kept
+// created so inserts never fall through to the default partition.
+const ClickPartitionsAhead = 2
+
+// MaintainClickPartitions creates upcoming monthly click partitions and, when
+// ClickEventRetention is set, drops the months that have aged out entirely.
+func (s *Service) MaintainClickPartitions(ctx context.Context) error {
+	now := time.Now().UTC()
+	for i := 0; i <= ClickPartitionsAhead; i++ {
+		month := now.AddDate(0, i, 0)
+		created, err := s.Repo.EnsureClickPartition(ctx, time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC))
+		if errors.Is(err, repository.ErrLockNotAcquired) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Printf("INFO: Created click partition %s.", repository.ClickPartitionName(month))
+		}
+	}
+
+	if s.ClickEventRetention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-s.ClickEventRetention)
+
+	partitions, err := s.Repo.ListClickPartitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range partitions {
+		if p.From.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if err := s.Repo.DropClickPartition(ctx, p); err != nil {
+			return err
+		}
+		log.Printf("INFO: Dropped click partition %s (older than %s).", p.Name, s.ClickEventRetention)
+	}
+
+	n, err := s.Repo.PurgeDefaultClickEvents(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("INFO: Purged %d unpartitioned clicks older than %s.", n, s.ClickEventRetention)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrInvalidPoll = errors.New("invalid poll cursor or limit")
+
+const (
+	DefaultPollLimit = 50
+	maxPollLimit     = 100
+)
+
+func pollLimit(limit int) (int, error) {
+	if limit == 0 {
+		return DefaultPollLimit, nil
+	}
+	if limit < 1 || limit > maxPollLimit {
+		return 0, ErrInvalidPoll
+	}
+	return limit, nil
+}
+
+// PollLinks returns links created after the cursor since, the ID of the
+// newest link a caller has seen, or the newest links when since is 0.
+func (s *Service) PollLinks(ctx context.Context, since int64, limit int) ([]repository.PolledLink, error) {
+	limit, err := pollLimit(limit)
+	if err != nil || since < 0 {
+		return nil, ErrInvalidPoll
+	}
+	return s.Repo.PollLinks(ctx, since, limit)
+}
+
+// PollClicks is PollLinks for recorded clicks. Clicks dropped by sampling
+// are never returned.
+func (s *Service) PollClicks(ctx context.Context, since int64, limit int) ([]repository.PolledClick, error) {
+	limit, err := pollLimit(limit)
+	if err != nil || since < 0 {
+		return nil, ErrInvalidPoll
+	}
+	return s.Repo.PollClicks(ctx, since, limit)
+}
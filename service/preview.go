@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/linkcheck"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/screenshot"
+)
+
+var ErrNoScreenshot = errors.New("no screenshot captured")
+
+// Links are captured soon after creation: only links younger than
+// screenshotMaxAge are picked up, so enabling the integration does not send
+// the whole table to the rendering service.
+const (
+	screenshotMaxAge      = 24 * time.Hour
+	screenshotMaxAttempts = 3
+	screenshotBatch       = 20
+	screenshotConcurrency = 4
+)
+
+// CaptureScreenshots renders new links' destinations and stores the images
+// in object storage.
+func (s *Service) CaptureScreenshots(ctx context.Context) error {
+	if s.Screenshots == nil {
+		return nil
+	}
+	targets, err := s.Repo.ListScreenshotTargets(ctx, screenshotMaxAge, screenshotMaxAttempts, screenshotBatch)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, screenshotConcurrency)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.captureScreenshot(ctx, t)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// captureScreenshot leaves out destinations on internal addresses, like the
+// link checker does: the renderer fetches them from inside the deployment
+// and would otherwise store pages of the internal network for anyone to see.
+func (s *Service) captureScreenshot(ctx context.Context, t repository.ScreenshotTarget) {
+	var key, contentType, captureErr string
+	var img screenshot.Image
+	err := linkcheck.CheckPublic(ctx, t.LongURL)
+	if err == nil {
+		img, err = s.Screenshots.Capture(ctx, t.LongURL)
+	}
+	if err == nil {
+		// Keyed by link ID so the image survives code rotation.
+		key = fmt.Sprintf("screenshots/%d.%s", t.URLID, img.Extension())
+		err = s.Storage.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+		contentType = img.ContentType
+	}
+	if err != nil {
+		log.Printf("WARN: Screenshot of %s failed: %v", t.ShortCode, err)
+		key, contentType, captureErr = "", "", err.Error()
+	}
+	if err := s.Repo.RecordScreenshot(ctx, t.URLID, key, contentType, captureErr); err != nil {
+		log.Printf("ERROR: %v", err)
+	}
+}
+
+func (s *Service) GetLinkPreview(ctx context.Context, shortCode string) (*repository.LinkPreview, error) {
+	preview, err := s.Repo.GetLinkPreview(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return preview, err
+}
+
+// OpenScreenshot returns a link's screenshot and its content type. The
+// caller must close the reader.
+func (s *Service) OpenScreenshot(ctx context.Context, shortCode string) (io.ReadCloser, string, error) {
+	preview, err := s.GetLinkPreview(ctx, shortCode)
+	if err != nil {
+		return nil, "", err
+	}
+	if preview.ScreenshotKey == "" {
+		return nil, "", ErrNoScreenshot
+	}
+	body, err := s.Storage.Get(ctx, preview.ScreenshotKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, preview.ScreenshotType, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// PublicStatsDays is how many days of clicks a public stats page shows.
+const PublicStatsDays = 30
+
+// GetPublicStats returns the public dashboard of a link, with one bucket per
+// day including days without clicks. Private links are ErrNotFound, so a
+// public page does not reveal which codes exist.
+func (s *Service) GetPublicStats(ctx context.Context, shortCode string) (*repository.PublicStats, error) {
+	stats, err := s.Repo.GetPublicStats(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(PublicStatsDays - 1))
+	buckets, err := s.Repo.GetLinkDailyClicks(ctx, stats.URLID, since)
+	if err != nil {
+		return nil, err
+	}
+	clicks := make(map[time.Time]int, len(buckets))
+	for _, b := range buckets {
+		clicks[b.Bucket.UTC()] = b.Clicks
+	}
+	stats.Daily = make([]repository.TimeBucket, 0, PublicStatsDays)
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		stats.Daily = append(stats.Daily, repository.TimeBucket{Bucket: day, Clicks: clicks[day]})
+	}
+	return stats, nil
+}
+
+func (s *Service) SetPublicStats(ctx context.Context, shortCode string, public bool, actor string) error {
+	err := s.Repo.SetPublicStats(ctx, shortCode, public)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if public {
+		log.Printf("INFO: %s published the stats of %s.", actor, shortCode)
+	} else {
+		log.Printf("INFO: %s made the stats of %s private.", actor, shortCode)
+	}
+	return nil
+}
+
+func (s *Service) IsPublicStats(ctx context.Context, shortCode string) (bool, error) {
+	public, err := s.Repo.IsPublicStats(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	return public, err
+}
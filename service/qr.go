@@ -0,0 +1,264 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// Bounds of QR code images, in pixels.
+const (
+	MinQRSize     = 128
+	MaxQRSize     = 2048
+	DefaultQRSize = 512
+)
+
+const (
+	// qrLogoShare is the fraction of the code's width a logo may cover. The
+	// highest error correction level survives 30% of the code being
+	// unreadable; a fifth of the width, with padding, is about 6% of it.
+	qrLogoShare = 0.2
+	// minQRContrast is the WCAG contrast ratio scanners need between the
+	// two colors, and the dark color must be the foreground.
+	minQRContrast = 3.0
+)
+
+// DefaultQRStyle applies to links outside a workspace and to workspaces
+// without QR defaults.
+var DefaultQRStyle = repository.QRStyle{Foreground: "#000000", Background: "#ffffff", Size: DefaultQRSize}
+
+var (
+	ErrInvalidQRStyle = errors.New("invalid QR style")
+	ErrNoQRStyle      = errors.New("workspace has no QR defaults")
+)
+
+// ParseQRColor accepts a color as rrggbb hex, with or without a leading #,
+// and returns it as #rrggbb.
+func ParseQRColor(s string) (string, error) {
+	hex := strings.ToLower(strings.TrimPrefix(s, "#"))
+	if len(hex) != 6 {
+		return "", fmt.Errorf("%w: color %q is not rrggbb hex", ErrInvalidQRStyle, s)
+	}
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+		return "", fmt.Errorf("%w: color %q is not rrggbb hex", ErrInvalidQRStyle, s)
+	}
+	return "#" + hex, nil
+}
+
+// ValidateQRStyle normalizes the colors of style and checks that a scanner
+// can read the result.
+func ValidateQRStyle(style *repository.QRStyle) error {
+	var err error
+	if style.Foreground, err = ParseQRColor(style.Foreground); err != nil {
+		return err
+	}
+	if style.Background, err = ParseQRColor(style.Background); err != nil {
+		return err
+	}
+	if style.Size < MinQRSize || style.Size > MaxQRSize {
+		return fmt.Errorf("%w: size must be between %d and %d pixels", ErrInvalidQRStyle, MinQRSize, MaxQRSize)
+	}
+	fg, bg := hexColor(style.Foreground), hexColor(style.Background)
+	if luminance(fg) >= luminance(bg) {
+		return fmt.Errorf("%w: foreground must be darker than background", ErrInvalidQRStyle)
+	}
+	if contrast(fg, bg) < minQRContrast {
+		return fmt.Errorf("%w: foreground and background need a contrast ratio of at least %.0f:1", ErrInvalidQRStyle, minQRContrast)
+	}
+	return nil
+}
+
+// LinkWorkspaceID returns the workspace of a live link, 0 for none,
+// without counting a click the way GetDestination does.
+func (s *Service) LinkWorkspaceID(shortCode string) (int64, error) {
+	dest, err := s.lookupDestination(shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !dest.ExpiresAt.IsZero() && !time.Now().Before(dest.ExpiresAt) {
+		return 0, ErrNotFound
+	}
+	return dest.WorkspaceID, nil
+}
+
+// QRStyleFor returns the QR defaults of a workspace, or DefaultQRStyle.
+func (s *Service) QRStyleFor(ctx context.Context, workspaceID int64) (repository.QRStyle, error) {
+	if workspaceID == 0 {
+		return DefaultQRStyle, nil
+	}
+	style, err := s.Repo.GetWorkspaceQRStyle(ctx, workspaceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultQRStyle, nil
+	}
+	if err != nil {
+		return repository.QRStyle{}, err
+	}
+	return *style, nil
+}
+
+// GetWorkspaceQRStyle returns the QR defaults of a workspace, or
+// DefaultQRStyle when it set none.
+func (s *Service) GetWorkspaceQRStyle(ctx context.Context, slug string) (repository.QRStyle, error) {
+	_, style, err := s.Repo.GetWorkspaceQRStyleBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.QRStyle{}, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return repository.QRStyle{}, err
+	}
+	if style == nil {
+		return DefaultQRStyle, nil
+	}
+	return *style, nil
+}
+
+func (s *Service) SetWorkspaceQRStyle(ctx context.Context, slug string, style repository.QRStyle) (repository.QRStyle, error) {
+	if err := ValidateQRStyle(&style); err != nil {
+		return repository.QRStyle{}, err
+	}
+	err := s.Repo.SetWorkspaceQRStyle(ctx, slug, style)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.QRStyle{}, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return repository.QRStyle{}, err
+	}
+	log.Printf("INFO: Workspace %s set its QR style to %s on %s, %dpx, logo %t.", slug, style.Foreground, style.Background, style.Size, style.Logo)
+	return style, nil
+}
+
+func (s *Service) DeleteWorkspaceQRStyle(ctx context.Context, slug string) error {
+	err := s.Repo.DeleteWorkspaceQRStyle(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoQRStyle
+	}
+	return err
+}
+
+// RenderQRCode draws content as a PNG QR code in style, which the caller has
+// validated. With style.Logo, the logo of the workspace is placed in the
+// middle on a patch of background. Codes without a logo use medium error
+// correction, which keeps them coarse enough to print small. A workspace
+// without a logo, or with an SVG or WebP one the standard library cannot
+// decode, gets a plain code.
+func (s *Service) RenderQRCode(ctx context.Context, content string, workspaceID int64, style repository.QRStyle) ([]byte, error) {
+	var logo image.Image
+	if style.Logo && workspaceID != 0 {
+		stored, err := s.Repo.GetWorkspaceLogoByID(ctx, workspaceID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return nil, err
+		default:
+			if logo, _, err = image.Decode(bytes.NewReader(stored.Data)); err != nil {
+				log.Printf("WARN: Logo of workspace %d (%s) cannot be drawn on QR codes: %v", workspaceID, stored.ContentType, err)
+				logo = nil
+			}
+		}
+	}
+
+	level := qrcode.Medium
+	if logo != nil {
+		level = qrcode.Highest
+	}
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	q.ForegroundColor = hexColor(style.Foreground)
+	q.BackgroundColor = hexColor(style.Background)
+	code := q.Image(style.Size)
+
+	var buf bytes.Buffer
+	if logo == nil {
+		err = png.Encode(&buf, code)
+	} else {
+		err = png.Encode(&buf, overlayLogo(code, logo, q.BackgroundColor))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// overlayLogo centers logo on code, scaled to fit qrLogoShare of its width
+// with its aspect ratio kept, over a slightly larger patch of background.
+func overlayLogo(code image.Image, logo image.Image, background color.Color) image.Image {
+	out := image.NewRGBA(code.Bounds())
+	draw.Draw(out, out.Bounds(), code, code.Bounds().Min, draw.Src)
+
+	size := out.Bounds().Dx()
+	box := int(float64(size) * qrLogoShare)
+	lb := logo.Bounds()
+	w, h := box, box
+	if lb.Dx() > lb.Dy() {
+		h = box * lb.Dy() / lb.Dx()
+	} else {
+		w = box * lb.Dx() / lb.Dy()
+	}
+	if w == 0 || h == 0 {
+		return out
+	}
+
+	pad := box / 10
+	patch := image.Rect((size-w)/2-pad, (size-h)/2-pad, (size+w)/2+pad, (size+h)/2+pad)
+	draw.Draw(out, patch, image.NewUniform(background), image.Point{}, draw.Src)
+
+	// Nearest-neighbour scaling; the standard library has no resampler and
+	// logos at this size don't need a better one.
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			scaled.Set(x, y, logo.At(lb.Min.X+x*lb.Dx()/w, lb.Min.Y+y*lb.Dy()/h))
+		}
+	}
+	at := image.Pt((size-w)/2, (size-h)/2)
+	draw.Draw(out, scaled.Bounds().Add(at), scaled, image.Point{}, draw.Over)
+	return out
+}
+
+// hexColor parses a #rrggbb color ValidateQRStyle has checked.
+func hexColor(s string) color.RGBA {
+	v, _ := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+// luminance is the WCAG relative luminance of c.
+func luminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.03928 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+func contrast(a, b color.RGBA) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+const (
+	DefaultQueryPlanRetention = 7 * 24 * time.Hour
+	MaxListedQueryPlans       = 500
+)
+
+// ListQueryPlans returns the newest sampled plans, of queries containing
+// contains when it is set.
+func (s *Service) ListQueryPlans(ctx context.Context, contains string, limit int) ([]repository.QueryPlan, error) {
+	if limit > MaxListedQueryPlans {
+		limit = MaxListedQueryPlans
+	}
+	return s.Repo.ListQueryPlans(ctx, contains, limit)
+}
+
+func (s *Service) PurgeQueryPlans(ctx context.Context) error {
+	retention := s.QueryPlanRetention
+	if retention == 0 {
+		retention = DefaultQueryPlanRetention
+	}
+	n, err := s.Repo.PurgeQueryPlans(ctx, retention)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("INFO: Purged %d query plans older than %s.", n, retention)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrInvalidRecount = errors.New("recount takes at most 1000 codes and a limit between 1 and 1000")
+
+const (
+	DefaultRecountLimit = 100
+	maxRecountLimit     = 1000
+	maxRecountCodes     = 1000
+)
+
+// RecountResult lists links whose click_count disagrees with their click
+// events, as found before any repair, and how many were repaired.
+type RecountResult struct {
+	Links    []repository.ClickRecount `json:"links"`
+	Repaired int64                     `json:"repaired"`
+}
+
+// RecountClicks compares the click_count of codes, or of every link when
+// codes is empty, with their recorded click events. With repair, counts
+// below the recount are raised to it, undoing increments lost to crashes
+// before the in-memory counter flushed. Counts above it are only reported.
+func (s *Service) RecountClicks(ctx context.Context, codes []string, limit int, repair bool, actor string) (*RecountResult, error) {
+	if limit == 0 {
+		limit = DefaultRecountLimit
+	}
+	if limit < 1 || limit > maxRecountLimit || len(codes) > maxRecountCodes {
+		return nil, ErrInvalidRecount
+	}
+	if codes == nil {
+		codes = []string{}
+	}
+
+	links, err := s.Repo.ListClickRecounts(ctx, codes, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &RecountResult{Links: links}
+	if !repair {
+		return result, nil
+	}
+	if result.Repaired, err = s.Repo.RepairClickCounts(ctx, codes); err != nil {
+		return nil, err
+	}
+	if result.Repaired > 0 {
+		log.Printf("INFO: %s raised click counts of %d links to their recorded clicks.", actor, result.Repaired)
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidRegionCode = errors.New("region code must be a single base62 character")
+	ErrInvalidDriftLimit = errors.New("invalid click drift limit")
+)
+
+const (
+	DefaultClickDriftLimit = 100
+	maxClickDriftLimit     = 1000
+)
+
+// ValidateRegionCode checks a REGION_CODE setting. An empty code disables
+// region prefixes.
+func ValidateRegionCode(code string) error {
+	if code != "" && (len(code) != 1 || !strings.Contains(Base62Alphabet, code)) {
+		return ErrInvalidRegionCode
+	}
+	return nil
+}
+
+// ClickDrift lists links whose local click count disagrees with the merged
+// per-region counters.
+func (s *Service) ClickDrift(ctx context.Context, limit int) ([]repository.ClickDrift, error) {
+	if limit == 0 {
+		limit = DefaultClickDriftLimit
+	}
+	if limit < 1 || limit > maxClickDriftLimit {
+		return nil, ErrInvalidDriftLimit
+	}
+	return s.Repo.ListClickDrift(ctx, limit)
+}
+
+// ReconcileClickCounts restores click counts that replication conflicts
+// overwrote, using the per-region counters.
+func (s *Service) ReconcileClickCounts(ctx context.Context, actor string) (int64, error) {
+	n, err := s.Repo.ReconcileClickCounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		log.Printf("INFO: %s reconciled click counts for %d links.", actor, n)
+	}
+	return n, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidReportSchedule  = errors.New("invalid report schedule")
+	ErrReportScheduleExists   = errors.New("report schedule already exists")
+	ErrReportScheduleNotFound = errors.New("report schedule not found")
+)
+
+const maxReportsPerTick = 10
+
+var reportNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+var linkStatsCSVHeader = []string{
+	"short_url", "long_url", "click_count", "view_count", "created_at", "last_accessed_at", "owner", "workspace", "campaign",
+}
+
+// nextReportRun aligns the first run to the next UTC midnight (daily) or Monday (weekly).
+func nextReportRun(frequency string, now time.Time) time.Time {
+	next := now.UTC().Truncate(24 * time.Hour).AddDate(0, 0, 1)
+	if frequency == "weekly" {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+func (s *Service) CreateReportSchedule(ctx context.Context, name string, frequency string) (*repository.ReportSchedule, error) {
+	if !reportNamePattern.MatchString(name) || (frequency != "daily" && frequency != "weekly") {
+		return nil, ErrInvalidReportSchedule
+	}
+
+	schedule, err := s.Repo.CreateReportSchedule(ctx, name, frequency, nextReportRun(frequency, time.Now()))
+	if err != nil {
+		if strings.Contains(err.Error(), "unique_report_schedule_name") {
+			return nil, ErrReportScheduleExists
+		}
+		return nil, err
+	}
+	log.Printf("INFO: Created %s report schedule %s, first run at %s.", frequency, name, schedule.NextRunAt.Format(time.RFC3339))
+	return schedule, nil
+}
+
+func (s *Service) ListReportSchedules(ctx context.Context) ([]repository.ReportSchedule, error) {
+	return s.Repo.ListReportSchedules(ctx)
+}
+
+func (s *Service) DeleteReportSchedule(ctx context.Context, id int64) error {
+	deleted, err := s.Repo.DeleteReportSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrReportScheduleNotFound
+	}
+	return nil
+}
+
+func (s *Service) WriteLinkStatsCSV(ctx context.Context, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(linkStatsCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.Repo.ForEachExportedLink(ctx, func(l repository.ExportedLink) error {
+		lastAccessed := ""
+		if l.LastAccessedAt != nil {
+			lastAccessed = l.LastAccessedAt.UTC().Format(time.RFC3339)
+		}
+		return cw.Write([]string{
+			l.ShortCode,
+			l.LongURL,
+			strconv.Itoa(l.ClickCount),
+			strconv.Itoa(l.ViewCount),
+			l.CreatedAt.UTC().Format(time.RFC3339),
+			lastAccessed,
+			l.Owner,
+			l.Workspace,
+			l.Campaign,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// RunDueReports is the job-runner entry point: it claims due schedules one at a
+// time, renders the link stats CSV and hands it to the configured storage writer.
+func (s *Service) RunDueReports(ctx context.Context) error {
+	if s.Storage == nil {
+		return nil
+	}
+
+	for i := 0; i < maxReportsPerTick; i++ {
+		schedule, err := s.Repo.ClaimDueReportSchedule(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		runErr := s.runReport(ctx, schedule)
+		status := ""
+		if runErr != nil {
+			status = runErr.Error()
+			log.Printf("ERROR: Report %s failed: %v", schedule.Name, runErr)
+		}
+		if err := s.Repo.RecordReportRun(ctx, schedule.ID, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) runReport(ctx context.Context, schedule *repository.ReportSchedule) error {
+	var buf bytes.Buffer
+	if err := s.WriteLinkStatsCSV(ctx, &buf); err != nil {
+		return fmt.Errorf("failed to render CSV: %w", err)
+	}
+
+	key := fmt.Sprintf("reports/%s/%s-%s.csv", schedule.Name, schedule.Name, time.Now().UTC().Format("20060102T150405Z"))
+	if err := s.Storage.Put(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "text/csv"); err != nil {
+		return err
+	}
+	log.Printf("INFO: Report %s written to %s (%d bytes).", schedule.Name, key, buf.Len())
+	return nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrPendingReview       = errors.New("link is awaiting review")
+	ErrInvalidReputation   = errors.New("invalid reputation override")
+	ErrInvalidDomainSignal = errors.New("invalid domain signal")
+)
+
+// Each signal takes this many points off a domain's score of 100. A
+// blocklist hit alone puts a domain under review; reports and failed health
+// checks need to accumulate.
+const (
+	reportPenalty        = 10
+	blocklistHitPenalty  = 60
+	healthFailurePenalty = 5
+
+	DefaultReviewThreshold = 50
+
+	// DefaultDomainSignalHalfLife is how often a domain's signals are
+	// halved, so a domain recovers once the reports stop. A single signal
+	// is gone after one half-life.
+	DefaultDomainSignalHalfLife = 30 * 24 * time.Hour
+
+	// reputationCacheTTL is how long a score is used before it is read
+	// again. Overrides made on this instance apply at once.
+	reputationCacheTTL   = 5 * time.Minute
+	maxReputationEntries = 10_000
+	maxListedReputations = 500
+	perfectReputation    = 100
+)
+
+// domainSignals maps the names accepted by RecordDomainSignal to counters.
+var domainSignals = map[string]string{
+	"report":         repository.SignalReport,
+	"blocklist_hit":  repository.SignalBlocklistHit,
+	"health_failure": repository.SignalHealthFailure,
+}
+
+func reputationScore(d repository.DomainReputation) int {
+	if d.ScoreOverride != nil {
+		return *d.ScoreOverride
+	}
+	score := perfectReputation - d.Reports*reportPenalty - d.BlocklistHits*blocklistHitPenalty - d.HealthFailures*healthFailurePenalty
+	return max(score, 0)
+}
+
+type cachedScore struct {
+	score   int
+	expires time.Time
+}
+
+type reputationCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedScore
+}
+
+func (c *reputationCache) get(domain string, now time.Time) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[domain]
+	if !ok || !now.Before(e.expires) {
+		return 0, false
+	}
+	return e.score, true
+}
+
+func (c *reputationCache) put(domain string, score int, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || len(c.entries) >= maxReputationEntries {
+		c.entries = make(map[string]cachedScore)
+	}
+	c.entries[domain] = cachedScore{score: score, expires: expires}
+}
+
+func (c *reputationCache) invalidate(domain string) {
+	c.mu.Lock()
+	delete(c.entries, domain)
+	c.mu.Unlock()
+}
+
+// domainScore returns a domain's score; domains without signals score 100.
+func (s *Service) domainScore(ctx context.Context, domain string) (int, error) {
+	now := time.Now()
+	if score, ok := s.reputations.get(domain, now); ok {
+		return score, nil
+	}
+	score := perfectReputation
+	d, err := s.Repo.GetDomainReputation(ctx, domain)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	if err == nil {
+		score = reputationScore(d)
+	}
+	s.reputations.put(domain, score, now.Add(reputationCacheTTL))
+	return score, nil
+}
+
+// needsReview reports whether a new link to longURL should be held for
+// moderation. When the score cannot be read the link is not held.
+func (s *Service) needsReview(ctx context.Context, longURL string) bool {
+	domain := registrableDomain(longURL)
+	if s.ReviewThreshold <= 0 || domain == "" {
+		return false
+	}
+	score, err := s.domainScore(ctx, domain)
+	if err != nil {
+		log.Printf("ERROR: Failed to read reputation of %s: %v", domain, err)
+		return false
+	}
+	return score < s.ReviewThreshold
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func (s *Service) GetDomainReputation(ctx context.Context, domain string) (*repository.DomainReputation, error) {
+	domain = normalizeDomain(domain)
+	d, err := s.Repo.GetDomainReputation(ctx, domain)
+	if errors.Is(err, sql.ErrNoRows) {
+		d = repository.DomainReputation{Domain: domain}
+	} else if err != nil {
+		return nil, err
+	}
+	d.Score = reputationScore(d)
+	return &d, nil
+}
+
+func (s *Service) ListDomainReputations(ctx context.Context) ([]repository.DomainReputation, error) {
+	domains, err := s.Repo.ListDomainReputations(ctx, maxListedReputations)
+	if err != nil {
+		return nil, err
+	}
+	for i := range domains {
+		domains[i].Score = reputationScore(domains[i])
+	}
+	return domains, nil
+}
+
+// OverrideDomainScore pins a domain's score between 0 and 100, or returns it
+// to the computed score when score is nil.
+func (s *Service) OverrideDomainScore(ctx context.Context, domain string, score *int, note string, actor string) error {
+	domain = normalizeDomain(domain)
+	if domain == "" || (score != nil && (*score < 0 || *score > perfectReputation)) {
+		return ErrInvalidReputation
+	}
+	if err := s.Repo.SetDomainScoreOverride(ctx, domain, score, note); err != nil {
+		return err
+	}
+	s.reputations.invalidate(domain)
+	if score == nil {
+		log.Printf("INFO: %s cleared the reputation override of %s.", actor, domain)
+	} else {
+		log.Printf("INFO: %s set the reputation of %s to %d.", actor, domain, *score)
+	}
+	return nil
+}
+
+// RecordDomainSignal lowers a domain's reputation. Abuse reports, blocklist
+// feeds and link health checkers report through it.
+func (s *Service) RecordDomainSignal(ctx context.Context, domain string, signal string) error {
+	column, ok := domainSignals[signal]
+	domain = normalizeDomain(domain)
+	if !ok || domain == "" {
+		return ErrInvalidDomainSignal
+	}
+	if err := s.Repo.AddDomainSignal(ctx, domain, column); err != nil {
+		return err
+	}
+	s.reputations.invalidate(domain)
+	return nil
+}
+
+func (s *Service) ListPendingLinks(ctx context.Context) ([]repository.PendingLink, error) {
+	return s.Repo.ListPendingLinks(ctx)
+}
+
+// ApproveLink releases a link held for review. Rejected links are deleted
+// like any other.
+func (s *Service) ApproveLink(ctx context.Context, shortCode string, actor string) error {
+	err := s.Repo.SetPendingReview(ctx, shortCode, false)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: %s approved short code %s.", actor, shortCode)
+	return nil
+}
+
+// DecayDomainSignals halves the signals of domains that have gone
+// DomainSignalHalfLife without being halved, and forgets domains left with
+// neither signals nor an override.
+func (s *Service) DecayDomainSignals(ctx context.Context) error {
+	halfLife := s.DomainSignalHalfLife
+	if halfLife == 0 {
+		halfLife = DefaultDomainSignalHalfLife
+	}
+	decayed, forgotten, err := s.Repo.DecayDomainSignals(ctx, halfLife)
+	if err != nil {
+		return err
+	}
+	if decayed > 0 || forgotten > 0 {
+		log.Printf("INFO: Halved the signals of %d domains and forgot %d.", decayed, forgotten)
+	}
+	return nil
+}
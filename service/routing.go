@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	mathrand "math/rand/v2"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/i18n"
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrInvalidLanguages = errors.New("invalid language destinations")
+
+const MaxLanguagesPerLink = 50
+
+var languageTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// RedirectRequest carries the request attributes destination rules match on.
+// ClientIP and UserAgent together identify a visitor well enough to keep them
+// in the same split variant across clicks.
+type RedirectRequest struct {
+	ShortCode      string
+	AcceptLanguage string
+	ClientIP       string
+	UserAgent      string
+	Now            time.Time
+}
+
+// ChooseDestination picks the URL a redirect should go to and, for links with
+// a split, the variant the visitor was assigned. Schedule rules take
+// precedence over the split, which takes precedence over language
+// destinations; the control variant still honours languages. A dead primary
+// destination is replaced by its archived copy when there is one.
+func ChooseDestination(dest repository.Destination, req RedirectRequest) (string, string) {
+	if len(dest.Schedule) > 0 {
+		if target, ok := matchSchedule(dest.Schedule, req.Now); ok {
+			return target, ""
+		}
+	}
+
+	variant := ""
+	if dest.SplitDestination != "" {
+		if splitBucket(req) < dest.SplitPercent {
+			return dest.SplitDestination, events.VariantCanary
+		}
+		variant = events.VariantControl
+	}
+
+	if len(dest.Languages) > 0 {
+		if target, ok := matchLanguage(dest.Languages, req.AcceptLanguage); ok {
+			return target, variant
+		}
+	}
+	if dest.ArchiveURL != "" {
+		return dest.ArchiveURL, variant
+	}
+	return dest.LongURL, variant
+}
+
+const (
+	fnvOffset32 = 2166136261
+	fnvPrime32  = 16777619
+)
+
+// fnv32a continues an FNV-1a hash over s without the allocations of
+// hash/fnv, since this runs on every redirect of a split link.
+func fnv32a(h uint32, s string) uint32 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= fnvPrime32
+	}
+	return h
+}
+
+// splitBucket maps a visitor to 0-99, stable per link. It hashes
+// code, NUL, IP, "|", user agent.
+func splitBucket(req RedirectRequest) int {
+	if req.ClientIP == "" && req.UserAgent == "" {
+		return mathrand.IntN(100)
+	}
+	h := fnv32a(fnvOffset32, req.ShortCode)
+	h = fnv32a(h, "\x00")
+	h = fnv32a(h, req.ClientIP)
+	h = fnv32a(h, "|")
+	h = fnv32a(h, req.UserAgent)
+	return int(h % 100)
+}
+
+// matchLanguage tries each preferred tag exactly, then by its primary subtag,
+// so "de-CH" falls back to a "de" destination.
+func matchLanguage(destinations map[string]string, acceptLanguage string) (string, bool) {
+	for _, tag := range i18n.ParseAcceptLanguage(acceptLanguage) {
+		if target, ok := destinations[tag]; ok {
+			return target, true
+		}
+		if primary, _, found := strings.Cut(tag, "-"); found {
+			if target, ok := destinations[primary]; ok {
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isAbsoluteHTTPURL(raw string) bool {
+	u, err := url.ParseRequestURI(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (s *Service) SetLinkLanguages(ctx context.Context, shortCode string, destinations map[string]string) (map[string]string, error) {
+	if len(destinations) > MaxLanguagesPerLink {
+		return nil, ErrInvalidLanguages
+	}
+	normalized := make(map[string]string, len(destinations))
+	for lang, target := range destinations {
+		lang = strings.ToLower(lang)
+		if !languageTagPattern.MatchString(lang) || !isAbsoluteHTTPURL(target) {
+			return nil, ErrInvalidLanguages
+		}
+		normalized[lang] = target
+	}
+
+	err := s.Repo.SetLinkLanguages(ctx, shortCode, normalized)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: SetLinkLanguages failed for code %s: %v", shortCode, err)
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: Set %d language destinations for %s.", len(normalized), shortCode)
+	return normalized, nil
+}
+
+func (s *Service) GetLinkLanguages(ctx context.Context, shortCode string) (map[string]string, error) {
+	destinations, err := s.Repo.GetLinkLanguages(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return destinations, err
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"math/rand/v2"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+var ErrInvalidSampleRate = errors.New("sample rate must be greater than 0 and at most 1")
+
+// SetSampleRate limits detailed click recording for a hot link to the given
+// fraction of clicks. The link's click count still includes every click, and
+// breakdowns scale the sampled rows back up.
+func (s *Service) SetSampleRate(ctx context.Context, shortCode string, rate float64) error {
+	if !(rate > 0 && rate <= 1) {
+		return ErrInvalidSampleRate
+	}
+	err := s.Repo.SetSampleRate(ctx, shortCode, rate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: %s now records %.4g of clicks in detail.", shortCode, rate)
+	return nil
+}
+
+func (s *Service) GetSampleRate(ctx context.Context, shortCode string) (float64, error) {
+	rate, err := s.Repo.GetSampleRate(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return rate, err
+}
+
+func sampled(e events.Event) bool {
+	if e.SampleRate <= 0 || e.SampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < e.SampleRate
+}
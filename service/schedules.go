@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule rules")
+
+const MaxScheduleRulesPerLink = 20
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ScheduleRuleSpec is the API form of a schedule rule. An empty Days list
+// means every day; Start equal to End means the whole day.
+type ScheduleRuleSpec struct {
+	Days        []string `json:"days"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Timezone    string   `json:"timezone"`
+	Destination string   `json:"destination"`
+}
+
+// locationCache caches time.LoadLocation, which reads zoneinfo on every call.
+// The zero value is ready to use.
+type locationCache struct {
+	m sync.Map
+}
+
+func (c *locationCache) load(name string) (*time.Location, error) {
+	if loc, ok := c.m.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	c.m.Store(name, loc)
+	return loc, nil
+}
+
+// resolveSchedule loads the time zones of dest's schedule rules, so redirects
+// match them without looking the zones up. dest must not be shared yet.
+func (s *Service) resolveSchedule(dest *repository.Destination) {
+	for i := range dest.Schedule {
+		dest.Schedule[i].Location, _ = s.locations.load(dest.Schedule[i].Timezone)
+	}
+}
+
+func parseClock(v string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(v, "%d:%d", &h, &m); err != nil || len(v) != 5 {
+		return 0, ErrInvalidSchedule
+	}
+	minutes := h*60 + m
+	if h < 0 || m < 0 || m > 59 || minutes > 24*60 {
+		return 0, ErrInvalidSchedule
+	}
+	return minutes, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func (spec ScheduleRuleSpec) toRule() (repository.ScheduleRule, error) {
+	rule := repository.ScheduleRule{Timezone: spec.Timezone, Destination: spec.Destination}
+	if !isAbsoluteHTTPURL(spec.Destination) {
+		return rule, ErrInvalidSchedule
+	}
+	if rule.Timezone == "" {
+		rule.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(rule.Timezone); err != nil {
+		return rule, ErrInvalidSchedule
+	}
+
+	var err error
+	if rule.StartMinute, err = parseClock(spec.Start); err != nil {
+		return rule, err
+	}
+	if rule.EndMinute, err = parseClock(spec.End); err != nil {
+		return rule, err
+	}
+
+	for _, day := range spec.Days {
+		found := false
+		for i, name := range weekdayNames {
+			if strings.EqualFold(day, name) {
+				rule.Days |= 1 << i
+				found = true
+			}
+		}
+		if !found {
+			return rule, ErrInvalidSchedule
+		}
+	}
+	if rule.Days == 0 {
+		rule.Days = 1<<len(weekdayNames) - 1
+	}
+	return rule, nil
+}
+
+func specFromRule(rule repository.ScheduleRule) ScheduleRuleSpec {
+	spec := ScheduleRuleSpec{
+		Days:        []string{},
+		Start:       formatClock(rule.StartMinute),
+		End:         formatClock(rule.EndMinute),
+		Timezone:    rule.Timezone,
+		Destination: rule.Destination,
+	}
+	for i, name := range weekdayNames {
+		if rule.Days&(1<<i) != 0 {
+			spec.Days = append(spec.Days, name)
+		}
+	}
+	return spec
+}
+
+// scheduleMatches reports whether now falls inside the rule's window. Windows
+// that wrap past midnight belong to the day they start on. Rules the service
+// did not resolve load their zone on every call.
+func scheduleMatches(rule repository.ScheduleRule, now time.Time) bool {
+	loc := rule.Location
+	if loc == nil {
+		var err error
+		if loc, err = time.LoadLocation(rule.Timezone); err != nil {
+			return false
+		}
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	onDay := func(d time.Weekday) bool { return rule.Days&(1<<d) != 0 }
+
+	switch {
+	case rule.StartMinute == rule.EndMinute:
+		return onDay(day)
+	case rule.StartMinute < rule.EndMinute:
+		return onDay(day) && minute >= rule.StartMinute && minute < rule.EndMinute
+	default:
+		if minute >= rule.StartMinute {
+			return onDay(day)
+		}
+		return minute < rule.EndMinute && onDay((day+6)%7)
+	}
+}
+
+func matchSchedule(rules []repository.ScheduleRule, now time.Time) (string, bool) {
+	for _, rule := range rules {
+		if scheduleMatches(rule, now) {
+			return rule.Destination, true
+		}
+	}
+	return "", false
+}
+
+// SetScheduleRules replaces a link's rules. The first matching rule wins.
+func (s *Service) SetScheduleRules(ctx context.Context, shortCode string, specs []ScheduleRuleSpec) ([]ScheduleRuleSpec, error) {
+	if len(specs) > MaxScheduleRulesPerLink {
+		return nil, ErrInvalidSchedule
+	}
+	rules := make([]repository.ScheduleRule, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := spec.toRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	err := s.Repo.SetScheduleRules(ctx, shortCode, rules)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: SetScheduleRules failed for code %s: %v", shortCode, err)
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: Set %d schedule rules for %s.", len(rules), shortCode)
+
+	normalized := make([]ScheduleRuleSpec, len(rules))
+	for i, rule := range rules {
+		normalized[i] = specFromRule(rule)
+	}
+	return normalized, nil
+}
+
+func (s *Service) GetScheduleRules(ctx context.Context, shortCode string) ([]ScheduleRuleSpec, error) {
+	rules, err := s.Repo.GetScheduleRules(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]ScheduleRuleSpec, len(rules))
+	for i, rule := range rules {
+		specs[i] = specFromRule(rule)
+	}
+	return specs, nil
+}
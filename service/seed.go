@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Seed describes data to provision at startup. JSON files work as well since
+// YAML is a superset of JSON.
+type Seed struct {
+	Workspaces []SeedWorkspace `yaml:"workspaces"`
+	Links      []SeedLink      `yaml:"links"`
+	APIKeys    []SeedAPIKey    `yaml:"api_keys"`
+}
+
+type SeedWorkspace struct {
+	Slug       string `yaml:"slug"`
+	Name       string `yaml:"name"`
+	CodePrefix string `yaml:"code_prefix"`
+}
+
+type SeedLink struct {
+	LongURL   string `yaml:"long_url"`
+	Alias     string `yaml:"alias"`
+	Workspace string `yaml:"workspace"`
+}
+
+// publishedSeedKey was the key of an earlier seed.example.yaml. It is known
+// to anyone who read the repository, so seeding refuses it.
+const publishedSeedKey = "usk_demo0000000000000000000000"
+
+// SeedAPIKey carries its plaintext key so demo environments know it upfront.
+type SeedAPIKey struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+func ReadSeedFile(path string) (*Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+	var seed Seed
+	if err := yaml.UnmarshalWithOptions(data, &seed, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("failed to parse seed file %s: %w", path, err)
+	}
+	return &seed, nil
+}
+
+// ApplySeed creates whatever in seed does not exist yet. Entries that already
+// exist are left untouched, so applying the same seed again is a no-op.
+func (s *Service) ApplySeed(ctx context.Context, seed *Seed) error {
+	for _, w := range seed.Workspaces {
+		_, err := s.CreateWorkspace(ctx, w.Slug, w.Name, w.CodePrefix)
+		if err != nil && !errors.Is(err, ErrWorkspaceExists) {
+			return fmt.Errorf("seed workspace %s: %w", w.Slug, err)
+		}
+	}
+
+	for _, k := range seed.APIKeys {
+		if !strings.HasPrefix(k.Key, apiKeySecretPrefix) || len(k.Key) < len(apiKeySecretPrefix)+16 {
+			return fmt.Errorf("seed api key %s: key must start with %q and have at least 16 more characters", k.Name, apiKeySecretPrefix)
+		}
+		if k.Key == publishedSeedKey {
+			return fmt.Errorf("seed api key %s: key was published in an earlier seed.example.yaml; generate a new one", k.Name)
+		}
+		if _, _, err := s.ensureAPIKeyWithSecret(ctx, k.Name, k.Key, "seed"); err != nil {
+			return fmt.Errorf("seed api key %s: %w", k.Name, err)
+		}
+	}
+
+	for _, l := range seed.Links {
+		code, err := s.CreateShortURLWithOptions(ctx, l.LongURL, ShortenOptions{Workspace: l.Workspace, Alias: l.Alias})
+		if errors.Is(err, ErrURLAlreadyShortened) {
+			log.Printf("WARN: Seed link %s already exists under a different code. Skipping.", l.LongURL)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("seed link %s: %w", l.LongURL, err)
+		}
+		log.Printf("INFO: Seed link %s -> %s.", code, l.LongURL)
+	}
+
+	log.Printf("INFO: Applied seed with %d workspaces, %d API keys and %d links.", len(seed.Workspaces), len(seed.APIKeys), len(seed.Links))
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+)
+
+var ErrUnknownShortURL = errors.New("URL is a short link of this service that does not exist")
+
+// maxSelfLinkHops bounds how far a chain of links to our own short URLs,
+// created before they were detected, is followed.
+const maxSelfLinkHops = 5
+
+// WarningAlreadyShort is the warning code of a long URL that was one of
+// this service's own short URLs.
+const WarningAlreadyShort = "already_short_url"
+
+// ShortenWarning explains why a shorten request did not do what was asked,
+// although it succeeded.
+type ShortenWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Submitted is the code the long URL pointed at, which may differ from
+	// the one returned when it was rotated or led to another of our links.
+	Submitted string `json:"submitted_code"`
+}
+
+// ShortenResult is the code of a shortened URL. Created is false when an
+// existing link was returned because the URL was already one of ours.
+type ShortenResult struct {
+	ShortCode string
+	Created   bool
+	Warning   *ShortenWarning
+}
+
+// Shorten creates a link to longURL. A long URL that is itself a short URL
+// of this deployment is not wrapped in a second link: the code it resolves
+// to is returned with a warning, so redirects never chain through us.
+func (s *Service) Shorten(ctx context.Context, longURL string, opts ShortenOptions) (*ShortenResult, error) {
+	if submitted, ok := s.ownShortCode(longURL); ok {
+		code, err := s.resolveOwnShortCode(ctx, submitted)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Alias != "" && opts.Alias != code {
+			return nil, ErrURLAlreadyShortened
+		}
+		log.Printf("INFO: %s is already a short URL. Returning %s instead of shortening it again.", longURL, code)
+		return &ShortenResult{ShortCode: code, Warning: &ShortenWarning{
+			Code:      WarningAlreadyShort,
+			Message:   "The URL is already a short link; its existing code was returned and no new link was created.",
+			Submitted: submitted,
+		}}, nil
+	}
+
+	code, err := s.createShortURL(ctx, longURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ShortenResult{ShortCode: code, Created: true}, nil
+}
+
+// ownShortCode returns the code of a URL on one of ShortURLHosts. Paths of
+// other routes, such as /healthcheck, are not codes.
+func (s *Service) ownShortCode(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !s.isShortURLHost(u.Host) {
+		return "", false
+	}
+	code := strings.TrimPrefix(u.Path, "/")
+	// The public stats page of a link, /<code>+, resolves to the link too.
+	code = strings.TrimSuffix(code, "+")
+	if code == "" || strings.Contains(code, "/") || reservedAliases[strings.ToLower(code)] {
+		return "", false
+	}
+	return code, true
+}
+
+func (s *Service) isShortURLHost(host string) bool {
+	for _, h := range s.ShortURLHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveOwnShortCode follows a code to the link that currently serves it:
+// through rotations, and on through links whose destination is itself one
+// of our short URLs.
+func (s *Service) resolveOwnShortCode(ctx context.Context, code string) (string, error) {
+	resolved := ""
+	for hops := 0; hops < maxSelfLinkHops; hops++ {
+		dest, err := s.Repo.LookupDestination(ctx, code)
+		if errors.Is(err, sql.ErrNoRows) {
+			current, err := s.Repo.FindRotatedShortCode(ctx, code)
+			if errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			code = current
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		resolved = code
+		next, ok := s.ownShortCode(dest.LongURL)
+		if !ok {
+			break
+		}
+		code = next
+	}
+	if resolved == "" {
+		return "", ErrUnknownShortURL
+	}
+	return resolved, nil
+}
@@ -4,15 +4,29 @@ import (
 	"context" 
 	"crypto/rand"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
 	"strings" 
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
+	"github.com/AnshulDekate/urlShortener/analytics"
+	"github.com/AnshulDekate/urlShortener/chaos"
+	"github.com/AnshulDekate/urlShortener/linkcheck"
+	"github.com/AnshulDekate/urlShortener/metrics"
 	"github.com/AnshulDekate/urlShortener/repository" 
+	"github.com/AnshulDekate/urlShortener/screenshot"
+	"github.com/AnshulDekate/urlShortener/sharedcache"
+	"github.com/AnshulDekate/urlShortener/storage"
+	"github.com/AnshulDekate/urlShortener/stream"
+	"github.com/AnshulDekate/urlShortener/wayback"
+	"github.com/AnshulDekate/urlShortener/webhook"
 )
 
 var (
@@ -27,30 +41,113 @@ type URLListResponse struct {
     TotalPages  int              `json:"total_pages"`
 }
 
+type RotationResult struct {
+	ShortCode   string    `json:"short_url"`
+	RetiredCode string    `json:"retired_short_url"`
+	GraceUntil  time.Time `json:"grace_until"`
+}
+
 const (
 	MaxShortCodeLength = 10
 	Base62Alphabet     = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	DefaultRotationGracePeriod = 7 * 24 * time.Hour
+	DefaultExpiryNotice        = 7 * 24 * time.Hour
 )
 
 type Service struct {
-	Repo           *repository.Repository
-	MaxRetries     int 
-	DesiredLength  int 
+	Repo                 *repository.Repository
+	MaxRetries           int
+	DesiredLength        int
+	RotationGracePeriod  time.Duration
+	Webhooks             *webhook.Dispatcher
+	Analytics            *analytics.Recorder
+	Storage              storage.Store
+	BackupInterval       time.Duration
+	DeletedLinkRetention time.Duration
+	ClickEventRetention  time.Duration
+	Snowflake            *SnowflakeGenerator
+	Clicks               *stream.Hub
+	Live                 *analytics.LiveCounter
+	Counter              *analytics.ClickCounter
+	DestinationCacheTTL  time.Duration
+	// DestinationStaleTTL is how long past DestinationCacheTTL a cached
+	// destination is still served while it is refreshed in the background.
+	DestinationStaleTTL  time.Duration
+	// SharedCache, when set, holds destinations for all instances. It is
+	// consulted on a local miss before the database.
+	SharedCache          *sharedcache.Ring
+	NegativeCacheTTL     time.Duration
+	Spam                 SpamPolicy
+	ReviewThreshold      int
+	DomainSignalHalfLife time.Duration
+	LinkChecker          *linkcheck.Checker
+	HealthRecheck        time.Duration
+	Archive              *wayback.Client
+	Screenshots          *screenshot.Client
+	InspectDestinations  bool
+	// ShortURLHosts are the hosts this deployment's short links are served
+	// on. Long URLs on them are resolved instead of shortened again.
+	ShortURLHosts        []string
+	// ExpiryNotice is how long before a link expires its owner is warned.
+	ExpiryNotice         time.Duration
+
+	WebhookDeliveryRetention time.Duration
+	// QueryPlanRetention is how long sampled query plans are kept.
+	QueryPlanRetention time.Duration
+	// SchemaVersion is the newest migration of this build. Exported archives
+	// record it and only archives of the same version are imported.
+	SchemaVersion int64
+	// RegionCode is a base62 character that starts every random code issued
+	// in this region, so regions sharing a replicated database never pick
+	// the same code.
+	RegionCode string
+	// ConversionSecret signs the click IDs of links that track conversions;
+	// conversion tracking is off without it. Click IDs older than
+	// ConversionWindow are no longer accepted.
+	ConversionSecret []byte
+	ConversionWindow time.Duration
+	ClickIDParam     string
+
+	bootstrapToken atomic.Pointer[string]
+	destinations   destinationCache
+	notFound       negativeCache
+	spam           spamState
+	reputations    reputationCache
+	locations      locationCache
+	lookups        singleflight.Group
 }
 
 func generateRandomCode(length int) (string, error) {
-	bytes := make([]byte, length)
-	if _, err := io.ReadFull(rand.Reader, bytes); err != nil {
-		return "", fmt.Errorf("failed to read random bytes: %w", err)
-	}
+	return randomCode(Base62Alphabet, length)
+}
 
-	result := make([]byte, length)
-	alphabetLength := len(Base62Alphabet)
-	
-	for i, b := range bytes {
-		result[i] = Base62Alphabet[int(b)%alphabetLength]
+// randomCode draws length characters of alphabet uniformly.
+func randomCode(alphabet string, length int) (string, error) {
+	// maxUnbiasedByte is the largest multiple of the alphabet length that
+	// fits in a byte. Random bytes at or above it are rejected: mapping all
+	// 256 values with a plain modulo made the first 256%62 = 8 characters
+	// of base62 25% more likely.
+	maxUnbiasedByte := 256 - 256%len(alphabet)
+	result := make([]byte, 0, length)
+	// About 3% of bytes are rejected for base62, so one spare byte per four
+	// characters almost always avoids a second read.
+	bytes := make([]byte, length+length/4+1)
+
+	for len(result) < length {
+		if _, err := io.ReadFull(rand.Reader, bytes); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		for _, b := range bytes {
+			if int(b) >= maxUnbiasedByte {
+				continue
+			}
+			result = append(result, alphabet[int(b)%len(alphabet)])
+			if len(result) == length {
+				break
+			}
+		}
 	}
-	
+
 	return string(result), nil
 }
 
@@ -58,27 +155,81 @@ func (s *Service) HealthCheck(ctx context.Context) error {
 	return s.Repo.HealthCheck(ctx)
 }
 
+// ShortenOptions places a new link in a workspace and optionally requests a
+// specific code instead of a generated one. ClientIP is set for anonymous
+// public requests, which are screened by the spam policy; Honeypot carries
+// the hidden form field only bots fill in.
+type ShortenOptions struct {
+	Workspace string
+	Alias     string
+	Mirror    bool
+	ClientIP  string
+	Honeypot  string
+	// Metadata is stored on a newly created link; a link returned by the
+	// idempotency check keeps its own.
+	Metadata map[string]string
+}
+
 func (s *Service) CreateShortURL(longURL string) (string, error) {
-	desiredLen := s.DesiredLength
-	if desiredLen == 0 {
-		desiredLen = MaxShortCodeLength 
-	}
-	maxRetries := s.MaxRetries
-	if maxRetries == 0 {
-		maxRetries = 5 
+	return s.CreateShortURLWithOptions(context.Background(), longURL, ShortenOptions{})
+}
+
+// CreateShortURLWithOptions is Shorten for callers that only need the code.
+func (s *Service) CreateShortURLWithOptions(ctx context.Context, longURL string, opts ShortenOptions) (string, error) {
+	res, err := s.Shorten(ctx, longURL, opts)
+	if err != nil {
+		return "", err
 	}
-	
+	return res.ShortCode, nil
+}
+
+func (s *Service) createShortURL(ctx context.Context, longURL string, opts ShortenOptions) (string, error) {
 	if _, err := url.ParseRequestURI(longURL); err != nil {
 		return "", errors.New("invalid URL format")
 	}
+	review := false
+	if opts.ClientIP != "" {
+		if err := s.screenCreation(ctx, opts.ClientIP, longURL, opts.Honeypot); err != nil {
+			return "", err
+		}
+		review = s.needsReview(ctx, longURL)
+	}
+
+	var workspace *repository.Workspace
+	if opts.Workspace != "" {
+		w, err := s.Repo.GetWorkspaceBySlug(ctx, opts.Workspace)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrWorkspaceNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		workspace = w
+	}
+	if opts.Alias != "" {
+		if err := validateAlias(opts.Alias, workspace); err != nil {
+			return "", err
+		}
+	}
+	if err := ValidateMetadata(opts.Metadata); err != nil {
+		return "", err
+	}
 
-	// Idempotency Check
-	existingShortCode, err := s.Repo.FindExistingShortCode(longURL)
+	var workspaceID int64
+	if workspace != nil {
+		workspaceID = workspace.ID
+	}
+
+	// Idempotency Check, within the workspace
+	existingShortCode, err := s.Repo.FindExistingShortCode(longURL, workspaceID)
 	if err != nil {
 		log.Printf("FATAL ERROR: Idempotency check failed for %s: %v", longURL, err)
 		return "", err
 	}
 	if existingShortCode != "" {
+		if opts.Alias != "" && opts.Alias != existingShortCode {
+			return "", ErrURLAlreadyShortened
+		}
 		log.Printf("INFO: Idempotency hit for %s. Returning existing code: %s", longURL, existingShortCode)
 		return existingShortCode, nil
 	}
@@ -86,25 +237,105 @@ func (s *Service) CreateShortURL(longURL string) (string, error) {
 
 
 	// Insert the long URL first
-	newID, err := s.Repo.InsertURL(longURL) 
+	newID, err := s.Repo.InsertURL(longURL, workspaceID) 
 	if err != nil {
 		if strings.Contains(err.Error(), "unique_long_url") {
 			log.Printf("WARN: Concurrent insertion detected for %s. Retrying idempotency check.", longURL)
-			return s.Repo.FindExistingShortCode(longURL)
+			return s.Repo.FindExistingShortCode(longURL, workspaceID)
 		}
 		log.Printf("FATAL ERROR: Primary InsertURL failed for %s: %v", longURL, err)
 		return "", err
 	}
     log.Printf("INFO: Successfully inserted new row with ID: %d", newID)
 
+	shortCode := opts.Alias
+	if shortCode == "" {
+		shortCode, err = s.generateUniqueCode(workspaceCodeFormat(workspace))
+	} else {
+		var free bool
+		free, err = s.Repo.IsShortCodeUnique(shortCode)
+		if err == nil && !free {
+			err = ErrAliasTaken
+		}
+	}
+	if err != nil {
+		s.discardPendingURL(ctx, newID)
+		return "", err
+	}
+
+	// Update the row with the unique short code
+	if err := s.Repo.UpdateShortCode(newID, shortCode); err != nil {
+		s.discardPendingURL(ctx, newID)
+		if opts.Alias != "" && strings.Contains(err.Error(), "unique_short_url") {
+			return "", ErrAliasTaken
+		}
+		log.Printf("FATAL ERROR: UpdateShortCode failed for ID %d and code %s: %v", newID, shortCode, err)
+		return "", err
+	}
+	s.codeCreated(shortCode)
+    log.Printf("INFO: Successfully updated ID %d with short code %s.", newID, shortCode)
+
+	if review {
+		if err := s.Repo.SetPendingReview(ctx, shortCode, true); err != nil {
+			log.Printf("ERROR: Failed to hold %s for review: %v", shortCode, err)
+			return "", err
+		}
+		log.Printf("INFO: Holding %s for review because of its domain's reputation.", shortCode)
+	}
+
+	if opts.Mirror {
+		if err := s.Repo.SetURLMirror(ctx, newID); err != nil {
+			log.Printf("ERROR: Failed to mark %s as mirror: %v", shortCode, err)
+			return "", err
+		}
+	}
+	if len(opts.Metadata) > 0 {
+		if err := s.Repo.SetURLMetadata(ctx, newID, opts.Metadata); err != nil {
+			log.Printf("ERROR: Failed to store metadata of %s: %v", shortCode, err)
+			return "", err
+		}
+	}
+
+	return shortCode, nil
+}
+
+func (s *Service) discardPendingURL(ctx context.Context, id int64) {
+	if err := s.Repo.DeletePendingURL(ctx, id); err != nil {
+		log.Printf("ERROR: Failed to clean up pending URL %d: %v", id, err)
+	}
+}
+
+// generateUniqueCode issues a fresh code in format, a workspace's or the
+// zero format. Snowflake codes are only issued in the deployment's own
+// alphabet and length.
+func (s *Service) generateUniqueCode(format repository.CodeFormat) (string, error) {
+	prefix := format.Prefix
+	if s.Snowflake != nil && format.Alphabet == "" && format.Length == 0 {
+		code, err := s.Snowflake.Next()
+		if err != nil {
+			metrics.CodeGenerationFailures.Inc()
+			log.Printf("FATAL ERROR: Snowflake code generation failed: %v", err)
+			return "", fmt.Errorf("code generation failed: %w", err)
+		}
+		metrics.CodesGenerated.Inc()
+		return prefix + code, nil
+	}
+
+	alphabet, desiredLen := s.codeAlphabetAndLength(format)
+	maxRetries := s.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 5
+	}
+
 	var shortCode string
 	// Random Generation with Configurable Collision Retry Loop
 	for i := 0; i < maxRetries; i++ {
-		code, err := generateRandomCode(desiredLen)
+		code, err := randomCode(alphabet, desiredLen-len(s.RegionCode))
 		if err != nil {
 			log.Printf("FATAL ERROR: Code generation failed: %v", err)
 			return "", fmt.Errorf("code generation failed: %w", err)
 		}
+		code = prefix + s.RegionCode + code
 
 		isUnique, err := s.Repo.IsShortCodeUnique(code)
 		if err != nil {
@@ -113,52 +344,246 @@ func (s *Service) CreateShortURL(longURL string) (string, error) {
 		}
 
 		if isUnique {
+			metrics.CodesGenerated.Inc()
+			metrics.CodeGenerationAttempts.Observe(float64(i + 1))
 			shortCode = code
 			log.Printf("INFO: Found unique code %s on attempt %d.", shortCode, i+1)
 			break
 		}
 		
+		metrics.CodeCollisions.Inc()
 		log.Printf("COLLISION: Detected for code: %s. Retrying... (%d/%d)", code, i+1, maxRetries)
 		time.Sleep(10 * time.Millisecond) 
 	}
 
 	if shortCode == "" {
+		metrics.CodeGenerationFailures.Inc()
+		metrics.CodeGenerationAttempts.Observe(float64(maxRetries))
 		log.Printf("FATAL ERROR: Failed to find unique code after %d retries.", maxRetries)
 		return "", errors.New("service capacity exhausted")
 	}
 	
 	// Final check against the 10-character assignment requirement
-	if len(shortCode) > MaxShortCodeLength {
-		log.Printf("FATAL ERROR: Generated code length %d exceeds max %d.", len(shortCode), MaxShortCodeLength)
+	if len(shortCode)-len(prefix) > MaxShortCodeLength {
+		log.Printf("FATAL ERROR: Generated code length %d exceeds max %d.", len(shortCode)-len(prefix), MaxShortCodeLength)
 		return "", errors.New("internal error: generated code exceeds max length")
 	}
 
-	// Update the row with the unique short code
-	if err := s.Repo.UpdateShortCode(newID, shortCode); err != nil {
-		log.Printf("FATAL ERROR: UpdateShortCode failed for ID %d and code %s: %v", newID, shortCode, err)
-		return "", err
+	return shortCode, nil
+}
+
+// GetDestination resolves a short code for a redirect and counts the click.
+// Resolved destinations are cached for DestinationCacheTTL, and concurrent
+// misses for the same code share a single database query.
+func (s *Service) GetDestination(shortCode string) (repository.Destination, error) {
+	dest, err := s.lookupDestination(shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Destination{}, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: LookupDestination failed for code %s: %v", shortCode, err)
+		return repository.Destination{}, err
+	}
+	if dest.PendingReview {
+		return repository.Destination{}, ErrPendingReview
+	}
+	if !dest.ExpiresAt.IsZero() && !time.Now().Before(dest.ExpiresAt) {
+		return repository.Destination{}, ErrNotFound
+	}
+	if s.Archive == nil {
+		dest.ArchiveURL = ""
 	}
-    log.Printf("INFO: Successfully updated ID %d with short code %s.", newID, shortCode)
 
-	return shortCode, nil
+	if s.Counter != nil {
+		s.Counter.Add(shortCode)
+	} else if err := s.Repo.IncrementClickCounts(context.Background(), map[string]int{shortCode: 1}); err != nil {
+		log.Printf("ERROR: Failed to count click for %s: %v", shortCode, err)
+	}
+	return dest, nil
 }
 
-func (s *Service) GetLongURL(shortCode string) (string, error) {
-	longURL, err := s.Repo.LookupAndTrack(shortCode)
-	
+func (s *Service) lookupDestination(shortCode string) (repository.Destination, error) {
+	now := time.Now()
+	if s.NegativeCacheTTL > 0 && s.notFound.has(shortCode, now) {
+		metrics.DestinationNegativeHits.Inc()
+		return repository.Destination{}, sql.ErrNoRows
+	}
+	if s.DestinationCacheTTL > 0 {
+		// An injected cache fault reads as a miss, as it would from a
+		// failed remote cache.
+		if chaos.Inject(context.Background(), chaos.Cache) == nil {
+			if dest, stale, ok := s.destinations.get(shortCode, now); ok {
+				if stale {
+					metrics.DestinationCacheStaleHits.Inc()
+					s.refreshDestination(shortCode)
+				} else {
+					metrics.DestinationCacheHits.Inc()
+				}
+				return dest, nil
+			}
+		}
+		metrics.DestinationCacheMisses.Inc()
+	}
+
+	v, err, shared := s.lookups.Do(shortCode, func() (any, error) {
+		return s.fetchDestination(shortCode)
+	})
+	if shared {
+		metrics.DestinationLookupsShared.Inc()
+	}
+	if err != nil {
+		return repository.Destination{}, err
+	}
+	return v.(repository.Destination), nil
+}
+
+// fetchDestination reads a destination from the shared cache or the
+// database and caches it. The query runs detached from any single caller so
+// one client giving up does not fail everyone waiting on it.
+func (s *Service) fetchDestination(shortCode string) (repository.Destination, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if dest, ok := s.sharedDestination(ctx, shortCode); ok {
+		metrics.SharedCacheHits.Inc()
+		s.resolveSchedule(&dest)
+		s.cacheDestination(shortCode, dest)
+		return dest, nil
+	}
+	dest, err := s.Repo.LookupDestination(ctx, shortCode)
+	if err == nil {
+		s.resolveSchedule(&dest)
+	}
+	if err == nil && s.DestinationCacheTTL > 0 {
+		s.cacheDestination(shortCode, dest)
+		if s.SharedCache != nil {
+			if raw, err := json.Marshal(dest); err == nil {
+				s.SharedCache.Set(ctx, sharedDestinationKey(shortCode), raw, s.DestinationCacheTTL)
+			}
+		}
+	}
+	return dest, err
+}
+
+func (s *Service) cacheDestination(shortCode string, dest repository.Destination) {
+	expires := time.Now().Add(s.DestinationCacheTTL)
+	s.destinations.put(shortCode, dest, expires, expires.Add(s.DestinationStaleTTL))
+}
+
+func sharedDestinationKey(shortCode string) string {
+	return "urlshortener:dest:" + shortCode
+}
+
+func (s *Service) sharedDestination(ctx context.Context, shortCode string) (repository.Destination, bool) {
+	if s.SharedCache == nil || s.DestinationCacheTTL <= 0 {
+		return repository.Destination{}, false
+	}
+	raw, ok := s.SharedCache.Get(ctx, sharedDestinationKey(shortCode))
+	if !ok {
+		return repository.Destination{}, false
+	}
+	var dest repository.Destination
+	if err := json.Unmarshal(raw, &dest); err != nil {
+		return repository.Destination{}, false
+	}
+	return dest, true
+}
+
+// refreshDestination reloads a stale destination in the background, sharing
+// the query with any lookup of the same code already in flight. A code that
+// no longer exists is dropped from the cache; on other errors the stale
+// entry keeps being served until its window ends.
+func (s *Service) refreshDestination(shortCode string) {
+	if !s.destinations.startRefresh() {
+		metrics.DestinationRefreshesSkipped.Inc()
+		return
+	}
+	go func() {
+		defer s.destinations.finishRefresh()
+		_, err, _ := s.lookups.Do(shortCode, func() (any, error) {
+			return s.fetchDestination(shortCode)
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			s.destinations.invalidate(shortCode)
+		} else if err != nil {
+			log.Printf("WARN: Failed to refresh cached destination of %s: %v", shortCode, err)
+		}
+	}()
+}
+
+// invalidateDestination drops a cached destination after its routing
+// changed, here and in the shared cache.
+func (s *Service) invalidateDestination(shortCode string) {
+	s.destinations.invalidate(shortCode)
+	if s.SharedCache != nil {
+		s.SharedCache.Delete(context.Background(), sharedDestinationKey(shortCode))
+	}
+}
+
+// codeCreated clears a cached 404 for a code that was just assigned.
+func (s *Service) codeCreated(shortCode string) {
+	s.notFound.forget(shortCode)
+}
+
+func (s *Service) RotateShortURL(ctx context.Context, shortCode string) (*RotationResult, error) {
+	grace := s.RotationGracePeriod
+	if grace == 0 {
+		grace = DefaultRotationGracePeriod
+	}
+
+	format, err := s.Repo.GetLinkCodeFormat(ctx, shortCode)
 	if errors.Is(err, sql.ErrNoRows) {
-		return "", errors.New("short code not found")
+		return nil, ErrNotFound
 	}
-    if err != nil {
-        log.Printf("FATAL ERROR: LookupAndTrack failed for code %s: %v", shortCode, err)
-    }
-	return longURL, err
+	if err != nil {
+		return nil, err
+	}
+
+	newCode, err := s.generateUniqueCode(format)
+	if err != nil {
+		return nil, err
+	}
+
+	graceUntil, err := s.Repo.RotateShortCode(ctx, shortCode, newCode, grace)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: RotateShortCode failed for code %s: %v", shortCode, err)
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	s.codeCreated(newCode)
+	log.Printf("INFO: Rotated short code %s to %s. Old code redirects until %s.", shortCode, newCode, graceUntil.Format(time.RFC3339))
+
+	return &RotationResult{
+		ShortCode:   newCode,
+		RetiredCode: shortCode,
+		GraceUntil:  graceUntil,
+	}, nil
+}
+
+// GetRotatedShortCode finds the current code of a link whose code was
+// rotated. It runs after a code failed to resolve, so a miss here means the
+// code does not exist at all and is remembered for NegativeCacheTTL.
+func (s *Service) GetRotatedShortCode(ctx context.Context, retiredCode string) (string, error) {
+	now := time.Now()
+	if s.NegativeCacheTTL > 0 && s.notFound.has(retiredCode, now) {
+		return "", ErrNotFound
+	}
+	shortCode, err := s.Repo.FindRotatedShortCode(ctx, retiredCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		if s.NegativeCacheTTL > 0 {
+			s.notFound.add(retiredCode, now.Add(s.NegativeCacheTTL))
+		}
+		return "", ErrNotFound
+	}
+	return shortCode, err
 }
 
 
-func (s *Service) ListURLs(ctx context.Context, page int, limit int) (*URLListResponse, error) {
+func (s *Service) ListURLs(ctx context.Context, filter repository.URLFilter, page int, limit int) (*URLListResponse, error) {
 
-    totalCount, err := s.Repo.GetTotalURLCount(ctx)
+    totalCount, err := s.Repo.GetTotalURLCount(ctx, filter)
     if err != nil {
         return nil, fmt.Errorf("failed to get total URL count: %w", err)
     }
@@ -173,7 +598,7 @@ func (s *Service) ListURLs(ctx context.Context, page int, limit int) (*URLListRe
         offset = (page - 1) * limit
     }
 
-    urls, err := s.Repo.ListURLs(ctx, limit, offset)
+    urls, err := s.Repo.ListURLs(ctx, filter, limit, offset)
     if err != nil {
         return nil, fmt.Errorf("failed to fetch paginated URLs: %w", err)
     }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Snowflake layout: 41 bits of milliseconds since SnowflakeEpoch, 8 bits of
+// node ID and 10 bits of per-millisecond sequence. 59 bits always fit in
+// MaxShortCodeLength base62 characters.
+const (
+	snowflakeNodeBits     = 8
+	snowflakeSequenceBits = 10
+	snowflakeTimeBits     = 41
+
+	MaxSnowflakeNodeID = 1<<snowflakeNodeBits - 1
+
+	maxSnowflakeSequence = 1<<snowflakeSequenceBits - 1
+	maxSnowflakeTime     = 1<<snowflakeTimeBits - 1
+
+	// Tolerated clock step backwards (e.g. NTP slew) before generation fails.
+	maxClockDrift = 50 * time.Millisecond
+)
+
+var SnowflakeEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var ErrClockMovedBackwards = errors.New("system clock moved backwards")
+
+// SnowflakeGenerator issues codes that are unique across instances as long as
+// every instance runs with a distinct node ID, so no database round trip is
+// needed to check for collisions.
+type SnowflakeGenerator struct {
+	nodeID int64
+
+	mu       sync.Mutex
+	lastTime int64
+	sequence int64
+	now      func() time.Time
+}
+
+func NewSnowflakeGenerator(nodeID int) (*SnowflakeGenerator, error) {
+	if nodeID < 0 || nodeID > MaxSnowflakeNodeID {
+		return nil, fmt.Errorf("node ID %d out of range 0-%d", nodeID, MaxSnowflakeNodeID)
+	}
+	return &SnowflakeGenerator{nodeID: int64(nodeID), now: time.Now}, nil
+}
+
+func (g *SnowflakeGenerator) NodeID() int {
+	return int(g.nodeID)
+}
+
+func (g *SnowflakeGenerator) Next() (string, error) {
+	id, err := g.nextID()
+	if err != nil {
+		return "", err
+	}
+	return encodeBase62(uint64(id), MaxShortCodeLength), nil
+}
+
+func (g *SnowflakeGenerator) nextID() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ts := g.elapsed()
+	if ts < g.lastTime {
+		if time.Duration(g.lastTime-ts)*time.Millisecond > maxClockDrift {
+			return 0, fmt.Errorf("%w by %dms", ErrClockMovedBackwards, g.lastTime-ts)
+		}
+		ts = g.waitUntil(g.lastTime)
+	}
+
+	if ts == g.lastTime {
+		g.sequence = (g.sequence + 1) & maxSnowflakeSequence
+		if g.sequence == 0 {
+			ts = g.waitUntil(g.lastTime + 1)
+		}
+	} else {
+		g.sequence = 0
+	}
+
+	if ts > maxSnowflakeTime {
+		return 0, errors.New("snowflake timestamp space exhausted")
+	}
+	g.lastTime = ts
+
+	return ts<<(snowflakeNodeBits+snowflakeSequenceBits) | g.nodeID<<snowflakeSequenceBits | g.sequence, nil
+}
+
+func (g *SnowflakeGenerator) elapsed() int64 {
+	return g.now().Sub(SnowflakeEpoch).Milliseconds()
+}
+
+func (g *SnowflakeGenerator) waitUntil(target int64) int64 {
+	ts := g.elapsed()
+	for ts < target {
+		time.Sleep(time.Duration(target-ts) * time.Millisecond)
+		ts = g.elapsed()
+	}
+	return ts
+}
+
+// encodeBase62 left-pads with the zero digit so every code has the same length.
+func encodeBase62(n uint64, width int) string {
+	buf := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		buf[i] = Base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(buf)
+}
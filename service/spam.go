@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	// ErrCreationThrottled means the caller may retry after SpamError.RetryAfter.
+	ErrCreationThrottled = errors.New("link creation throttled")
+	// ErrCreationRejected means the request itself looks like spam.
+	ErrCreationRejected = errors.New("link creation rejected")
+)
+
+const (
+	SpamReasonHoneypot       = "honeypot"
+	SpamReasonIPVelocity     = "ip_velocity"
+	SpamReasonDomainVelocity = "domain_velocity"
+	SpamReasonNewDomain      = "new_domain"
+)
+
+const (
+	DefaultSpamWindow = 10 * time.Minute
+
+	// domainAgeCacheTTL bounds how often one domain is looked up, and
+	// maxDomainAgeEntries how many lookups are remembered.
+	domainAgeCacheTTL   = 24 * time.Hour
+	maxDomainAgeEntries = 10_000
+)
+
+// SpamError explains why a creation was refused. It wraps
+// ErrCreationThrottled or ErrCreationRejected.
+type SpamError struct {
+	Reason     string
+	RetryAfter time.Duration
+	err        error
+}
+
+func (e *SpamError) Error() string { return e.err.Error() + ": " + e.Reason }
+func (e *SpamError) Unwrap() error { return e.err }
+
+// DomainRegistry reports when a registrable domain was registered.
+type DomainRegistry interface {
+	RegistrationDate(ctx context.Context, domain string) (time.Time, error)
+}
+
+// SpamPolicy configures the checks anonymous link creation goes through. A
+// zero limit or age disables that check.
+type SpamPolicy struct {
+	// IPLimit and DomainLimit cap creation attempts per client IP and per
+	// destination's registrable domain within Window.
+	IPLimit     int
+	DomainLimit int
+	Window      time.Duration
+	// MinDomainAge rejects destinations on domains registered more recently,
+	// as looked up through Registry.
+	MinDomainAge time.Duration
+	Registry     DomainRegistry
+}
+
+type spamWindow struct {
+	count int
+	ends  time.Time
+}
+
+// velocityCounter counts attempts per key in fixed windows.
+type velocityCounter struct {
+	mu        sync.Mutex
+	windows   map[string]*spamWindow
+	nextSweep time.Time
+}
+
+func (v *velocityCounter) add(key string, now time.Time, period time.Duration) spamWindow {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.windows == nil {
+		v.windows = make(map[string]*spamWindow)
+	}
+	if !now.Before(v.nextSweep) {
+		v.nextSweep = now.Add(period)
+		for k, w := range v.windows {
+			if now.After(w.ends) {
+				delete(v.windows, k)
+			}
+		}
+	}
+
+	w, ok := v.windows[key]
+	if !ok || now.After(w.ends) {
+		w = &spamWindow{ends: now.Add(period)}
+		v.windows[key] = w
+	}
+	w.count++
+	return *w
+}
+
+type domainAge struct {
+	registered time.Time
+	checked    time.Time
+}
+
+type spamState struct {
+	ips     velocityCounter
+	domains velocityCounter
+
+	mu   sync.Mutex
+	ages map[string]domainAge
+}
+
+// screenCreation applies the spam policy to an anonymous creation attempt.
+// Each limit is logged as an abuse event only when it first trips in a
+// window, so a flood of rejected requests does not become a flood of writes.
+func (s *Service) screenCreation(ctx context.Context, clientIP string, longURL string, honeypot string) error {
+	policy := s.Spam
+	window := policy.Window
+	if window == 0 {
+		window = DefaultSpamWindow
+	}
+	now := time.Now()
+
+	if policy.IPLimit > 0 {
+		w := s.spam.ips.add(clientIP, now, window)
+		if w.count > policy.IPLimit {
+			if w.count == policy.IPLimit+1 {
+				s.recordAbuse(clientIP, SpamReasonIPVelocity, "", map[string]any{"limit": policy.IPLimit, "window": window.String()})
+			}
+			return &SpamError{Reason: SpamReasonIPVelocity, RetryAfter: w.ends.Sub(now), err: ErrCreationThrottled}
+		}
+	}
+
+	// Humans never see the honeypot field; only form-filling bots set it.
+	if honeypot != "" {
+		s.recordAbuse(clientIP, SpamReasonHoneypot, "", map[string]any{"long_url": longURL})
+		return &SpamError{Reason: SpamReasonHoneypot, err: ErrCreationRejected}
+	}
+
+	domain := registrableDomain(longURL)
+	if domain == "" {
+		return nil
+	}
+
+	if policy.DomainLimit > 0 {
+		w := s.spam.domains.add(domain, now, window)
+		if w.count > policy.DomainLimit {
+			if w.count == policy.DomainLimit+1 {
+				s.recordAbuse(clientIP, SpamReasonDomainVelocity, domain, map[string]any{"limit": policy.DomainLimit, "window": window.String()})
+			}
+			return &SpamError{Reason: SpamReasonDomainVelocity, RetryAfter: w.ends.Sub(now), err: ErrCreationThrottled}
+		}
+	}
+
+	if policy.MinDomainAge > 0 && policy.Registry != nil {
+		registered, ok := s.domainRegistered(ctx, domain, now)
+		if ok && now.Sub(registered) < policy.MinDomainAge {
+			s.recordAbuse(clientIP, SpamReasonNewDomain, domain, map[string]any{"registered_at": registered, "min_age": policy.MinDomainAge.String()})
+			return &SpamError{Reason: SpamReasonNewDomain, err: ErrCreationRejected}
+		}
+	}
+	return nil
+}
+
+// domainRegistered returns a domain's registration date from cache or the
+// registry. Lookup failures let the link through, since an unreachable
+// registry must not block creation, and are not cached.
+func (s *Service) domainRegistered(ctx context.Context, domain string, now time.Time) (time.Time, bool) {
+	s.spam.mu.Lock()
+	age, ok := s.spam.ages[domain]
+	s.spam.mu.Unlock()
+	if ok && now.Sub(age.checked) < domainAgeCacheTTL {
+		return age.registered, true
+	}
+
+	registered, err := s.Spam.Registry.RegistrationDate(ctx, domain)
+	if err != nil {
+		log.Printf("WARN: Registration date lookup for %s failed: %v", domain, err)
+		return time.Time{}, false
+	}
+
+	s.spam.mu.Lock()
+	if s.spam.ages == nil || len(s.spam.ages) >= maxDomainAgeEntries {
+		s.spam.ages = make(map[string]domainAge)
+	}
+	s.spam.ages[domain] = domainAge{registered: registered, checked: now}
+	s.spam.mu.Unlock()
+	return registered, true
+}
+
+// registrableDomain reduces a URL's host to the domain its owner registered,
+// so that spam spread over subdomains counts against one domain.
+func registrableDomain(longURL string) string {
+	u, err := url.Parse(longURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// recordAbuse logs a rejected creation. Storing it is best effort and
+// detached from the request, which is already being refused. It does not
+// count against the domain's reputation: a busy or young domain is not
+// evidence of abuse, and anyone could trip the limits for a domain they
+// want held for review.
+func (s *Service) recordAbuse(clientIP string, reason string, domain string, details map[string]any) {
+	log.Printf("WARN: Rejected link creation from %s: %s %s", clientIP, reason, domain)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.Repo.InsertAbuseEvent(ctx, repository.AbuseEvent{IP: clientIP, Reason: reason, Domain: domain, Details: details})
+	if err != nil {
+		log.Printf("ERROR: Failed to record abuse event: %v", err)
+	}
+}
+
+func (s *Service) ListAbuseEvents(ctx context.Context, days int) ([]repository.AbuseEvent, error) {
+	if days < 1 {
+		days = 7
+	}
+	return s.Repo.ListAbuseEvents(ctx, days)
+}
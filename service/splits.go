@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrInvalidSplit  = errors.New("invalid split")
+	ErrSplitNotFound = errors.New("link has no split")
+)
+
+type SplitStats struct {
+	repository.Split
+	Clicks []repository.VariantClicks `json:"clicks"`
+}
+
+func validSplitPercent(percent int) bool {
+	return percent >= 0 && percent <= 100
+}
+
+// SetSplit starts or replaces a canary rollout for a link.
+func (s *Service) SetSplit(ctx context.Context, shortCode string, destination string, percent int) (*repository.Split, error) {
+	if !isAbsoluteHTTPURL(destination) || !validSplitPercent(percent) {
+		return nil, ErrInvalidSplit
+	}
+
+	split, err := s.Repo.UpsertSplit(ctx, shortCode, destination, percent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: UpsertSplit failed for code %s: %v", shortCode, err)
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: %s now sends %d%% of clicks to %s.", shortCode, percent, destination)
+	return split, nil
+}
+
+func (s *Service) UpdateSplitPercent(ctx context.Context, shortCode string, percent int) (*repository.Split, error) {
+	if !validSplitPercent(percent) {
+		return nil, ErrInvalidSplit
+	}
+
+	split, err := s.Repo.UpdateSplitPercent(ctx, shortCode, percent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSplitNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: UpdateSplitPercent failed for code %s: %v", shortCode, err)
+		return nil, err
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: %s canary share set to %d%%.", shortCode, percent)
+	return split, nil
+}
+
+func (s *Service) GetSplitStats(ctx context.Context, shortCode string) (*SplitStats, error) {
+	split, err := s.Repo.GetSplit(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSplitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	clicks, err := s.Repo.GetVariantClicks(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	return &SplitStats{Split: *split, Clicks: clicks}, nil
+}
+
+func (s *Service) DeleteSplit(ctx context.Context, shortCode string) error {
+	deleted, err := s.Repo.DeleteSplit(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSplitNotFound
+	}
+	s.invalidateDestination(shortCode)
+	log.Printf("INFO: Removed split from %s.", shortCode)
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+// Granularities of a click series.
+const (
+	SeriesHour = "hour"
+	SeriesDay  = "day"
+	SeriesWeek = "week"
+)
+
+// MaxSeriesBuckets bounds the buckets of one series: 41 days by the hour,
+// or about 19 years by the week.
+const MaxSeriesBuckets = 1000
+
+// rollupLag holds the last hour back from the roll-up for a few minutes, for
+// clicks still on their way to click_events.
+const rollupLag = 5 * time.Minute
+
+var ErrInvalidSeries = errors.New("invalid time series")
+
+// seriesDefaultBuckets is how far back a series without from reaches.
+var seriesDefaultBuckets = map[string]int{SeriesHour: 48, SeriesDay: 30, SeriesWeek: 12}
+
+// ClickSeries is a link's clicks per bucket from From to To, oldest first,
+// with empty buckets included so charts need not fill gaps. Each bucket is
+// named by its start, in UTC; weeks start on Monday.
+type ClickSeries struct {
+	Granularity string                  `json:"granularity"`
+	From        time.Time               `json:"from"`
+	To          time.Time               `json:"to"`
+	TotalClicks int                     `json:"total_clicks"`
+	Buckets     []repository.TimeBucket `json:"buckets"`
+}
+
+// seriesStart returns the start of the bucket t falls in.
+func seriesStart(granularity string, t time.Time) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case SeriesHour:
+		return t.Truncate(time.Hour)
+	case SeriesWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// seriesAdd moves t by n buckets.
+func seriesAdd(granularity string, t time.Time, n int) time.Time {
+	switch granularity {
+	case SeriesHour:
+		return t.Add(time.Duration(n) * time.Hour)
+	case SeriesWeek:
+		return t.AddDate(0, 0, 7*n)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// GetClickSeries returns a link's clicks per hour, day or week between from
+// and to, widened to whole buckets. A zero to means now, and a zero from a
+// default span of the granularity before to.
+func (s *Service) GetClickSeries(ctx context.Context, shortCode string, granularity string, from time.Time, to time.Time) (*ClickSeries, error) {
+	span, ok := seriesDefaultBuckets[granularity]
+	if !ok {
+		return nil, fmt.Errorf("%w: granularity must be hour, day or week", ErrInvalidSeries)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	end := seriesStart(granularity, to)
+	if end.Before(to) {
+		end = seriesAdd(granularity, end, 1)
+	}
+	start := seriesAdd(granularity, end, -span)
+	if !from.IsZero() {
+		if !from.Before(to) {
+			return nil, fmt.Errorf("%w: from must be before to", ErrInvalidSeries)
+		}
+		start = seriesStart(granularity, from)
+	}
+
+	series := &ClickSeries{Granularity: granularity, From: start, To: end, Buckets: []repository.TimeBucket{}}
+	for b := start; b.Before(end); b = seriesAdd(granularity, b, 1) {
+		if len(series.Buckets) == MaxSeriesBuckets {
+			return nil, fmt.Errorf("%w: more than %d buckets, use a coarser granularity or a shorter range", ErrInvalidSeries, MaxSeriesBuckets)
+		}
+		series.Buckets = append(series.Buckets, repository.TimeBucket{Bucket: b})
+	}
+
+	counts, err := s.Repo.GetClickSeries(ctx, shortCode, granularity, start, end)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	clicks := make(map[int64]int, len(counts))
+	for _, c := range counts {
+		clicks[c.Bucket.Unix()] = c.Clicks
+	}
+	for i := range series.Buckets {
+		series.Buckets[i].Clicks = clicks[series.Buckets[i].Bucket.Unix()]
+		series.TotalClicks += series.Buckets[i].Clicks
+	}
+	return series, nil
+}
+
+// RollUpClicks rolls the clicks of every hour that has passed up into the
+// hourly rollups time series are read from, counting the last day again for
+// late clicks. It catches up a week at a time until done or ctx ends.
+func (s *Service) RollUpClicks(ctx context.Context) error {
+	until := time.Now().UTC().Add(-rollupLag).Truncate(time.Hour)
+	for {
+		from, to, err := s.Repo.RollUpClicks(ctx, until)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, repository.ErrLockNotAcquired) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("INFO: Rolled up clicks from %s to %s.", from.Format(time.RFC3339), to.Format(time.RFC3339))
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+)
+
+var (
+	ErrMissingUTMFields = errors.New("utm_source, utm_medium and utm_campaign are required")
+)
+
+func BuildUTMURL(baseURL string, p repository.UTMParams) (string, error) {
+	u, err := url.ParseRequestURI(baseURL)
+	if err != nil || u.Host == "" {
+		return "", errors.New("invalid URL format")
+	}
+	if p.Source == "" || p.Medium == "" || p.Campaign == "" {
+		return "", ErrMissingUTMFields
+	}
+
+	q := u.Query()
+	q.Set("utm_source", p.Source)
+	q.Set("utm_medium", p.Medium)
+	q.Set("utm_campaign", p.Campaign)
+	if p.Term != "" {
+		q.Set("utm_term", p.Term)
+	}
+	if p.Content != "" {
+		q.Set("utm_content", p.Content)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func (s *Service) CreateUTMShortURL(ctx context.Context, baseURL string, p repository.UTMParams) (string, string, error) {
+	longURL, err := BuildUTMURL(baseURL, p)
+	if err != nil {
+		return "", "", err
+	}
+
+	shortCode, err := s.CreateShortURL(longURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.Repo.UpsertUTMParams(ctx, shortCode, p); err != nil {
+		log.Printf("FATAL ERROR: UpsertUTMParams failed for code %s: %v", shortCode, err)
+		return "", "", err
+	}
+	return shortCode, longURL, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/webhook"
+)
+
+var (
+	ErrInvalidWebhookURL     = errors.New("invalid webhook URL")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrInvalidDeliveryStatus = errors.New("invalid webhook delivery status")
+	ErrDeliveryNotFailed     = errors.New("webhook delivery has not failed")
+)
+
+const (
+	DefaultWebhookDeliveryRetention = 30 * 24 * time.Hour
+	maxListedDeliveries             = 100
+)
+
+// newWebhookSecret returns a signing secret with about 190 bits of entropy.
+func newWebhookSecret() (string, error) {
+	secret, err := generateRandomCode(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
+
+func validateWebhookURL(target string) error {
+	u, err := url.ParseRequestURI(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+func (s *Service) SetLinkWebhook(ctx context.Context, shortCode string, targetURL string) (*repository.Webhook, error) {
+	if err := validateWebhookURL(targetURL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.Repo.UpsertLinkWebhook(ctx, shortCode, targetURL, secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("FATAL ERROR: UpsertLinkWebhook failed for code %s: %v", shortCode, err)
+		return nil, err
+	}
+	log.Printf("INFO: Registered click webhook for %s.", shortCode)
+	return w, nil
+}
+
+func (s *Service) GetLinkWebhook(ctx context.Context, shortCode string) (*repository.Webhook, error) {
+	w, err := s.Repo.GetLinkWebhook(ctx, shortCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return w, err
+}
+
+// RotateWebhookSecret replaces a webhook's signing secret. Deliveries signed
+// with the old secret stop verifying at once, so receivers should accept both
+// until they have switched.
+func (s *Service) RotateWebhookSecret(ctx context.Context, shortCode string, actor string) (*repository.Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.Repo.RotateWebhookSecret(ctx, shortCode, secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INFO: %s rotated the webhook secret for %s.", actor, shortCode)
+	return w, nil
+}
+
+func (s *Service) DeleteLinkWebhook(ctx context.Context, shortCode string) error {
+	deleted, err := s.Repo.DeleteLinkWebhook(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns a webhook's latest deliveries, optionally
+// filtered to one status.
+func (s *Service) ListWebhookDeliveries(ctx context.Context, webhookID int64, status string) ([]repository.WebhookDelivery, error) {
+	switch status {
+	case "", webhook.DeliveryRetrying, webhook.DeliverySucceeded, webhook.DeliveryFailed:
+	default:
+		return nil, ErrInvalidDeliveryStatus
+	}
+	if _, err := s.Repo.GetWebhookTarget(ctx, webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return s.Repo.ListWebhookDeliveries(ctx, webhookID, status, maxListedDeliveries)
+}
+
+// RedeliverWebhook sends a failed delivery again, once, to the webhook's
+// current target and with its current secret. The attempt is recorded like
+// any other, so a success marks the delivery succeeded.
+func (s *Service) RedeliverWebhook(ctx context.Context, webhookID int64, deliveryID string, actor string) (*repository.WebhookDelivery, error) {
+	d, err := s.Repo.GetWebhookDelivery(ctx, webhookID, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != webhook.DeliveryFailed {
+		return nil, ErrDeliveryNotFailed
+	}
+	target, err := s.Repo.GetWebhookTarget(ctx, webhookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a := s.Webhooks.Redeliver(ctx, target, d.ID, d.Payload)
+	log.Printf("INFO: %s redelivered webhook delivery %s: %s.", actor, d.ID, a.Status)
+	return s.Repo.GetWebhookDelivery(ctx, webhookID, deliveryID)
+}
+
+func (s *Service) PurgeWebhookDeliveries(ctx context.Context) error {
+	retention := s.WebhookDeliveryRetention
+	if retention == 0 {
+		retention = DefaultWebhookDeliveryRetention
+	}
+	n, err := s.Repo.PurgeWebhookDeliveries(ctx, retention)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("INFO: Purged %d webhook deliveries older than %s.", n, retention)
+	}
+	return nil
+}
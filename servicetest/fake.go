@@ -0,0 +1,207 @@
+// Package servicetest provides an in-memory double of the service layer, so
+// handlers can be exercised without a database:
+//
+//	fake := servicetest.NewFake()
+//	fake.AddLink("abc123", "https://example.com")
+//	h := handler.NewGinHandler(fake, "http://localhost/")
+package servicetest
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/handler"
+	"github.com/AnshulDekate/urlShortener/repository"
+	"github.com/AnshulDekate/urlShortener/service"
+)
+
+// Fake keeps links in memory and implements shortening, redirects, listing,
+// stats, deletion and the health check like the service does, returning the
+// same errors. Any other method of handler.Service is passed to Service,
+// which is nil unless set, so an unexpected call panics.
+type Fake struct {
+	handler.Service
+
+	// Err, when set, fails every method Fake implements itself, as a
+	// database outage would.
+	Err error
+
+	mu     sync.Mutex
+	nextID int64
+	links  map[string]*repository.URL
+	events []events.Event
+}
+
+var _ handler.Service = (*Fake)(nil)
+
+func NewFake() *Fake {
+	return &Fake{links: make(map[string]*repository.URL)}
+}
+
+// AddLink stores a link to longURL under code, replacing any there.
+func (f *Fake) AddLink(code string, longURL string) *repository.URL {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.add(code, longURL)
+}
+
+func (f *Fake) add(code string, longURL string) *repository.URL {
+	f.nextID++
+	now := time.Now()
+	u := &repository.URL{ID: f.nextID, LongURL: longURL, ShortCode: code, CreatedAt: now, UpdatedAt: now}
+	f.links[code] = u
+	return u
+}
+
+// Link returns a copy of the link stored under code.
+func (f *Fake) Link(code string) (repository.URL, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.links[code]
+	if !ok {
+		return repository.URL{}, false
+	}
+	return *u, true
+}
+
+// Events returns the events TrackEvent was given, oldest first.
+func (f *Fake) Events() []events.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]events.Event(nil), f.events...)
+}
+
+func (f *Fake) HealthCheck(ctx context.Context) error {
+	return f.Err
+}
+
+// Shorten stores a link under opts.Alias, or under a code made from a
+// counter.
+func (f *Fake) Shorten(ctx context.Context, longURL string, opts service.ShortenOptions) (*service.ShortenResult, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	code := opts.Alias
+	if code == "" {
+		code = strconv.FormatInt(f.nextID+1, 36)
+	}
+	if _, taken := f.links[code]; taken {
+		return nil, service.ErrAliasTaken
+	}
+	f.add(code, longURL)
+	return &service.ShortenResult{ShortCode: code, Created: true}, nil
+}
+
+// GetDestination counts a click on the link, as the service does.
+func (f *Fake) GetDestination(shortCode string) (repository.Destination, error) {
+	if f.Err != nil {
+		return repository.Destination{}, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.links[shortCode]
+	if !ok {
+		return repository.Destination{}, service.ErrNotFound
+	}
+	u.ClickCount++
+	u.LastAccessedAt = time.Now()
+	return repository.Destination{ID: u.ID, LongURL: u.LongURL, SampleRate: 1}, nil
+}
+
+// GetRotatedShortCode finds nothing: the fake does not rotate codes.
+func (f *Fake) GetRotatedShortCode(ctx context.Context, retiredCode string) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return "", service.ErrNotFound
+}
+
+// TagClick leaves target alone: the fake does not track conversions.
+func (f *Fake) TagClick(dest repository.Destination, target string, now time.Time) string {
+	return target
+}
+
+func (f *Fake) TrackEvent(e events.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, e)
+}
+
+// ListURLs pages through the links newest first. Of filter only ShortCode
+// is applied.
+func (f *Fake) ListURLs(ctx context.Context, filter repository.URLFilter, page int, limit int) (*service.URLListResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []repository.URL
+	for _, u := range f.links {
+		if filter.ShortCode == "" || u.ShortCode == filter.ShortCode {
+			matched = append(matched, *u)
+		}
+	}
+	// IDs grow with creation, so they order links as created_at does.
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	totalPages := (len(matched) + limit - 1) / limit
+	if totalPages == 0 {
+		totalPages = 1
+	} else if page > totalPages {
+		page = totalPages
+	}
+	start := min((page-1)*limit, len(matched))
+	end := min(start+limit, len(matched))
+	return &service.URLListResponse{
+		URLs:       matched[start:end],
+		TotalCount: len(matched),
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetLinkStats reports the clicks GetDestination counted; the fake flags
+// none and views and conversions are always zero.
+func (f *Fake) GetLinkStats(ctx context.Context, shortCode string, excludeFlagged bool) (*repository.LinkStats, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.links[shortCode]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	stats := &repository.LinkStats{
+		ShortCode:  u.ShortCode,
+		LongURL:    u.LongURL,
+		ClickCount: u.ClickCount,
+		SampleRate: 1,
+		CreatedAt:  u.CreatedAt,
+	}
+	if !u.LastAccessedAt.IsZero() {
+		accessed := u.LastAccessedAt
+		stats.LastAccessedAt = &accessed
+	}
+	return stats, nil
+}
+
+func (f *Fake) DeleteURL(ctx context.Context, shortCode string, actor string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.links[shortCode]; !ok {
+		return service.ErrNotFound
+	}
+	delete(f.links, shortCode)
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package sharedcache is a cache shared by all instances, spread over
+// several Redis nodes by consistent hashing. Every operation is best
+// effort: errors read as misses, so callers fall back to the database.
+package sharedcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/AnshulDekate/urlShortener/metrics"
+)
+
+const (
+	// virtualNodes is the number of points each node takes on the ring,
+	// enough for keys to spread within a few percent of evenly.
+	virtualNodes = 160
+
+	// A node is taken out of the ring after failureThreshold consecutive
+	// errors and offered one request again after retryAfter.
+	failureThreshold = 3
+	retryAfter       = 10 * time.Second
+
+	// The timeouts are short: a cache that answers slower than the
+	// database is not worth waiting for.
+	dialTimeout = 200 * time.Millisecond
+	ioTimeout   = 100 * time.Millisecond
+)
+
+type node struct {
+	addr   string
+	client *redis.Client
+
+	mu        sync.Mutex
+	failures  int
+	downUntil time.Time
+}
+
+type point struct {
+	hash uint64
+	node *node
+}
+
+// Ring maps each key to one node. When a node fails, its keys move to the
+// next node on the ring and the other nodes keep theirs, so only that
+// node's share of lookups misses and goes to the database.
+type Ring struct {
+	nodes  []*node
+	points []point
+	closed atomic.Bool
+}
+
+// New connects to the Redis nodes at urls. Nodes are not contacted until
+// used; one that is unreachable is taken out of the ring then.
+func New(urls []string) (*Ring, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no cache nodes")
+	}
+	r := &Ring{}
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		opts, err := redis.ParseURL(u)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("invalid cache node URL: %w", err)
+		}
+		if seen[opts.Addr] {
+			r.Close()
+			return nil, fmt.Errorf("cache node %s is listed twice", opts.Addr)
+		}
+		seen[opts.Addr] = true
+		opts.DialTimeout = dialTimeout
+		opts.ReadTimeout = ioTimeout
+		opts.WriteTimeout = ioTimeout
+		opts.PoolTimeout = ioTimeout
+		opts.MaxRetries = -1
+		n := &node{addr: opts.Addr, client: redis.NewClient(opts)}
+		r.nodes = append(r.nodes, n)
+		metrics.CacheNodeUp.WithLabelValues(n.addr).Set(1)
+		for i := 0; i < virtualNodes; i++ {
+			r.points = append(r.points, point{hash: hash(n.addr + "#" + strconv.Itoa(i)), node: n})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i].hash < r.points[j].hash })
+	return r, nil
+}
+
+// pick returns the node that holds key: the first node at or after the
+// key's hash that is not down. It returns nil when every node is down.
+func (r *Ring) pick(key string, now time.Time) *node {
+	h := hash(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	tried := make(map[*node]bool, len(r.nodes))
+	for i := 0; i < len(r.points) && len(tried) < len(r.nodes); i++ {
+		n := r.points[(start+i)%len(r.points)].node
+		if tried[n] {
+			continue
+		}
+		tried[n] = true
+		if n.available(now) {
+			return n
+		}
+	}
+	return nil
+}
+
+// hash is FNV-1a with a final mix: similar strings, such as a node's
+// numbered points or sequential codes, otherwise land close together on the
+// ring.
+func hash(s string) uint64 {
+	f := fnv.New64a()
+	f.Write([]byte(s))
+	h := f.Sum64()
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h
+}
+
+// available reports whether n may be used. A node that is down becomes
+// available to one request once retryAfter has passed; if that request
+// fails too, the node stays out for another retryAfter.
+func (n *node) available(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.downUntil.IsZero() {
+		return true
+	}
+	if now.Before(n.downUntil) {
+		return false
+	}
+	n.downUntil = now.Add(retryAfter)
+	return true
+}
+
+// record updates n's health with the outcome of one operation.
+func (n *node) record(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err == nil || errors.Is(err, redis.Nil) {
+		if !n.downUntil.IsZero() {
+			log.Printf("INFO: Cache node %s is back.", n.addr)
+			metrics.CacheNodeUp.WithLabelValues(n.addr).Set(1)
+		}
+		n.failures = 0
+		n.downUntil = time.Time{}
+		return
+	}
+	n.failures++
+	if n.failures == failureThreshold {
+		log.Printf("WARN: Cache node %s failed %d times, taking it out for %s: %v", n.addr, n.failures, retryAfter, err)
+		metrics.CacheNodeUp.WithLabelValues(n.addr).Set(0)
+	}
+	if n.failures >= failureThreshold {
+		n.downUntil = time.Now().Add(retryAfter)
+	}
+}
+
+// Get returns the value of key, reporting false on a miss or any error.
+func (r *Ring) Get(ctx context.Context, key string) ([]byte, bool) {
+	n := r.pick(key, time.Now())
+	if n == nil {
+		return nil, false
+	}
+	value, err := n.client.Get(ctx, key).Bytes()
+	n.record(err)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value under key for ttl.
+func (r *Ring) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if n := r.pick(key, time.Now()); n != nil {
+		n.record(n.client.Set(ctx, key, value, ttl).Err())
+	}
+}
+
+// Delete removes key from every node that is up, not only its current
+// owner: a key may have been stored on another node while its owner was
+// down, and would be served from there again should the owner fail.
+func (r *Ring) Delete(ctx context.Context, key string) {
+	now := time.Now()
+	for _, n := range r.nodes {
+		if n.available(now) {
+			n.record(n.client.Del(ctx, key).Err())
+		}
+	}
+}
+
+func (r *Ring) Close() error {
+	if r.closed.Swap(true) {
+		return nil
+	}
+	var errs []error
+	for _, n := range r.nodes {
+		errs = append(errs, n.client.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,321 @@
+// Package slo tracks per-route latency and error rates over rolling windows
+// and compares them with service level objectives.
+//
+// Each route has a latency threshold, which 99% of requests should beat, and
+// an availability objective, the share of requests that should not fail with
+// a 5xx. Burn rate is how fast a window spends the error budget those leave:
+// 1 spends it exactly over the SLO period, 14.4 spends 2% of a 30-day budget
+// in an hour. Violations follow the multi-window rules from the Google SRE
+// workbook: a fast burn is above 14.4 over both 1h and 5m, a slow burn above
+// 6 over both 6h and 30m.
+package slo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultLatency      = 500 * time.Millisecond
+	DefaultAvailability = 99.9
+
+	// latencyObjective is the share of requests that must beat the latency
+	// threshold, so the threshold is a p99 target.
+	latencyObjective = 0.99
+
+	// The ring holds one bucket per minute for the longest window.
+	bucketWidth = time.Minute
+	ringSize    = 360
+
+	fastBurn = 14.4
+	slowBurn = 6
+
+	// minRequests keeps a handful of requests in a quiet short window from
+	// being reported as a violation.
+	minRequests = 20
+)
+
+// latencyBounds are the upper bounds, in milliseconds, of the histogram used
+// for percentiles; the last bucket is unbounded.
+var latencyBounds = [...]float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Target is the objective for one route.
+type Target struct {
+	Latency      time.Duration
+	Availability float64
+}
+
+// ParseTargets reads per-route overrides written as
+// "GET /:code=100ms@99.95;POST /shorten=1s@99.5". Either half of a target may
+// be left out to keep the default.
+func ParseTargets(spec string, defaults Target) (map[string]Target, error) {
+	targets := make(map[string]Target)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.Contains(route, " /") {
+			return nil, fmt.Errorf("SLO target %q must look like \"METHOD /route=latency@availability\"", entry)
+		}
+		t := defaults
+		latency, availability, _ := strings.Cut(value, "@")
+		if latency != "" {
+			d, err := time.ParseDuration(latency)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("SLO target %q has an invalid latency", entry)
+			}
+			t.Latency = d
+		}
+		if availability != "" {
+			a, err := strconv.ParseFloat(strings.TrimSuffix(availability, "%"), 64)
+			if err != nil || a <= 0 || a >= 100 {
+				return nil, fmt.Errorf("SLO target %q must have an availability between 0 and 100", entry)
+			}
+			t.Availability = a
+		}
+		targets[strings.TrimSpace(route)] = t
+	}
+	return targets, nil
+}
+
+type bucket struct {
+	minute int64
+	total  int64
+	errors int64
+	slow   int64
+	hist   [len(latencyBounds) + 1]int64
+}
+
+type route struct {
+	target Target
+	ring   [ringSize]bucket
+}
+
+// Tracker records requests and reports them against their targets.
+type Tracker struct {
+	defaults Target
+	targets  map[string]Target
+
+	mu     sync.Mutex
+	routes map[string]*route
+}
+
+func NewTracker(defaults Target, overrides map[string]Target) *Tracker {
+	return &Tracker{defaults: defaults, targets: overrides, routes: make(map[string]*route)}
+}
+
+func (t *Tracker) record(key string, now time.Time, latency time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.routes[key]
+	if !ok {
+		target, ok := t.targets[key]
+		if !ok {
+			target = t.defaults
+		}
+		r = &route{target: target}
+		t.routes[key] = r
+	}
+
+	minute := now.Unix() / int64(bucketWidth/time.Second)
+	b := &r.ring[minute%ringSize]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if failed {
+		b.errors++
+	}
+	if latency > r.target.Latency {
+		b.slow++
+	}
+	ms := float64(latency) / float64(time.Millisecond)
+	b.hist[sort.SearchFloat64s(latencyBounds[:], ms)]++
+}
+
+// Middleware records every matched route except event streams, whose
+// duration is the length of the subscription rather than a response time.
+func (t *Tracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if c.FullPath() == "" || strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/event-stream") {
+			return
+		}
+		t.record(c.Request.Method+" "+c.FullPath(), time.Now(), time.Since(start), c.Writer.Status() >= 500)
+	}
+}
+
+// windowStats sums the buckets of the last minutes minutes, including the
+// current one.
+type windowStats struct {
+	total, errors, slow int64
+	hist                [len(latencyBounds) + 1]int64
+}
+
+func (r *route) window(now int64, minutes int64) windowStats {
+	var w windowStats
+	for m := now - minutes + 1; m <= now; m++ {
+		b := &r.ring[((m%ringSize)+ringSize)%ringSize]
+		if b.minute != m {
+			continue
+		}
+		w.total += b.total
+		w.errors += b.errors
+		w.slow += b.slow
+		for i, n := range b.hist {
+			w.hist[i] += n
+		}
+	}
+	return w
+}
+
+// burn returns the error and latency burn rates of a window.
+func (w windowStats) burn(target Target) (float64, float64) {
+	if w.total == 0 {
+		return 0, 0
+	}
+	errorBudget := 1 - target.Availability/100
+	latencyBudget := 1 - latencyObjective
+	return float64(w.errors) / float64(w.total) / errorBudget, float64(w.slow) / float64(w.total) / latencyBudget
+}
+
+// percentile interpolates within the histogram bucket holding quantile q.
+func (w windowStats) percentile(q float64) float64 {
+	if w.total == 0 {
+		return 0
+	}
+	rank := q * float64(w.total)
+	var seen float64
+	for i, n := range w.hist {
+		if n == 0 {
+			continue
+		}
+		if seen+float64(n) >= rank {
+			if i == len(latencyBounds) {
+				return latencyBounds[i-1]
+			}
+			lower := 0.0
+			if i > 0 {
+				lower = latencyBounds[i-1]
+			}
+			return lower + (latencyBounds[i]-lower)*(rank-seen)/float64(n)
+		}
+		seen += float64(n)
+	}
+	return latencyBounds[len(latencyBounds)-1]
+}
+
+type BurnRate struct {
+	Errors  float64 `json:"errors"`
+	Latency float64 `json:"latency"`
+}
+
+// RouteReport describes the last hour of one route. Percentiles are in
+// milliseconds; latencies beyond the histogram's 10s are reported as 10000.
+type RouteReport struct {
+	Route        string              `json:"route"`
+	LatencyMS    float64             `json:"target_latency_p99_ms"`
+	Availability float64             `json:"target_availability"`
+	Requests     int64               `json:"requests"`
+	ErrorRate    float64             `json:"error_rate"`
+	P50MS        float64             `json:"p50_ms"`
+	P95MS        float64             `json:"p95_ms"`
+	P99MS        float64             `json:"p99_ms"`
+	BurnRates    map[string]BurnRate `json:"burn_rates"`
+	Violations   []string            `json:"violations"`
+}
+
+var burnWindows = []struct {
+	name    string
+	minutes int64
+}{
+	{"5m", 5}, {"30m", 30}, {"1h", 60}, {"6h", 360},
+}
+
+// Report returns every route seen in the last six hours, violations first.
+func (t *Tracker) Report(now time.Time) []RouteReport {
+	minute := now.Unix() / int64(bucketWidth/time.Second)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reports := []RouteReport{}
+	for key, r := range t.routes {
+		windows := make(map[string]windowStats, len(burnWindows))
+		burns := make(map[string]BurnRate, len(burnWindows))
+		for _, bw := range burnWindows {
+			w := r.window(minute, bw.minutes)
+			windows[bw.name] = w
+			errs, latency := w.burn(r.target)
+			burns[bw.name] = BurnRate{Errors: round(errs), Latency: round(latency)}
+		}
+		if windows["6h"].total == 0 {
+			continue
+		}
+
+		hour := windows["1h"]
+		rep := RouteReport{
+			Route:        key,
+			LatencyMS:    float64(r.target.Latency) / float64(time.Millisecond),
+			Availability: r.target.Availability,
+			Requests:     hour.total,
+			P50MS:        round(hour.percentile(0.50)),
+			P95MS:        round(hour.percentile(0.95)),
+			P99MS:        round(hour.percentile(0.99)),
+			BurnRates:    burns,
+			Violations:   violations(windows, burns),
+		}
+		if hour.total > 0 {
+			rep.ErrorRate = round(float64(hour.errors) / float64(hour.total))
+		}
+		reports = append(reports, rep)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if (len(reports[i].Violations) > 0) != (len(reports[j].Violations) > 0) {
+			return len(reports[i].Violations) > 0
+		}
+		return reports[i].Route < reports[j].Route
+	})
+	return reports
+}
+
+func violations(windows map[string]windowStats, burns map[string]BurnRate) []string {
+	found := []string{}
+	check := func(name string, long, short string, threshold float64) {
+		if windows[short].total < minRequests {
+			return
+		}
+		if burns[long].Errors > threshold && burns[short].Errors > threshold {
+			found = append(found, name+"_errors")
+		}
+		if burns[long].Latency > threshold && burns[short].Latency > threshold {
+			found = append(found, name+"_latency")
+		}
+	}
+	check("fast_burn", "1h", "5m", fastBurn)
+	check("slow_burn", "6h", "30m", slowBurn)
+	return found
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// Handler serves the report. It answers 200 even when objectives are
+// violated; the violations are in the body.
+func (t *Tracker) Handler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"routes": t.Report(time.Now())})
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Region    string
+	Prefix    string
+	UseSSL    bool
+}
+
+// S3Store reads and writes objects in any S3-compatible store (AWS S3, MinIO, R2, ...).
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client for %s: %w", cfg.Endpoint, err)
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+func (w *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	objectKey := path.Join(w.prefix, key)
+	_, err := w.client.PutObject(ctx, w.bucket, objectKey, body, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to bucket %s: %w", objectKey, w.bucket, err)
+	}
+	return nil
+}
+
+func (w *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectKey := path.Join(w.prefix, key)
+	obj, err := w.client.GetObject(ctx, w.bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from bucket %s: %w", objectKey, w.bucket, err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("failed to fetch %s from bucket %s: %w", objectKey, w.bucket, err)
+	}
+	return obj, nil
+}
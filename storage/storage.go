@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Writer persists generated artifacts (reports, backups) under a slash-separated key.
+// A size of -1 means the length is unknown and the body is streamed.
+type Writer interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+}
+
+type Reader interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+type Store interface {
+	Writer
+	Reader
+}
@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/AnshulDekate/urlShortener/events"
+)
+
+// DefaultSubscriberBuffer is how many events a subscriber may fall behind
+// before further events to it are dropped.
+const DefaultSubscriberBuffer = 64
+
+// Hub fans click events out to in-process subscribers keyed by short code.
+// Publishing never blocks: a subscriber that cannot keep up misses events.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[string]map[chan events.Event]struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[chan events.Event]struct{})}
+}
+
+// Subscribe returns a channel of events for shortCode and a function that
+// ends the subscription. The channel is closed when either the subscription
+// ends or the hub closes.
+func (h *Hub) Subscribe(shortCode string) (<-chan events.Event, func()) {
+	ch := make(chan events.Event, DefaultSubscriberBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[shortCode] == nil {
+		h.subs[shortCode] = make(map[chan events.Event]struct{})
+	}
+	h.subs[shortCode][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { h.unsubscribe(shortCode, ch) })
+	}
+}
+
+func (h *Hub) unsubscribe(shortCode string, ch chan events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[shortCode][ch]; !ok {
+		return
+	}
+	delete(h.subs[shortCode], ch)
+	if len(h.subs[shortCode]) == 0 {
+		delete(h.subs, shortCode)
+	}
+	close(ch)
+}
+
+func (h *Hub) Publish(e events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[e.ShortCode] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription so open streams finish and the HTTP server
+// can shut down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for code, chans := range h.subs {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(h.subs, code)
+	}
+}
@@ -0,0 +1,69 @@
+// Package wayback finds archived copies of pages in the Internet Archive's
+// Wayback Machine.
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	DefaultAvailabilityURL = "https://archive.org/wayback/available"
+	DefaultTimeout         = 10 * time.Second
+)
+
+var ErrNoSnapshot = errors.New("no archived snapshot")
+
+type Client struct {
+	AvailabilityURL string
+	HTTP            *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{AvailabilityURL: DefaultAvailabilityURL, HTTP: &http.Client{Timeout: DefaultTimeout}}
+}
+
+type availability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// LatestSnapshot returns the URL of the most recent snapshot of target that
+// was captured with a 200 response.
+func (c *Client) LatestSnapshot(ctx context.Context, target string) (string, error) {
+	// Without a timestamp the availability API returns the newest capture.
+	endpoint := c.AvailabilityURL + "?" + url.Values{"url": {target}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build Wayback request for %s: %w", target, err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query Wayback Machine for %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Wayback availability for %s returned status %d", target, resp.StatusCode)
+	}
+
+	var body availability
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode Wayback response for %s: %w", target, err)
+	}
+	closest := body.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.Status != "200" || closest.URL == "" {
+		return "", ErrNoSnapshot
+	}
+	return closest.URL, nil
+}
@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AnshulDekate/urlShortener/events"
+	"github.com/AnshulDekate/urlShortener/queue"
+)
+
+const (
+	DefaultQueueSize     = 10000
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 2 * time.Second
+	DefaultTimeout       = 5 * time.Second
+	DefaultMaxAttempts   = 3
+	maxConcurrentSends   = 8
+)
+
+// Target is a registered endpoint and the secret its deliveries are signed
+// with.
+type Target struct {
+	ID     int64
+	URL    string
+	Secret string
+}
+
+type TargetStore interface {
+	FindWebhookTargets(ctx context.Context, shortCodes []string) (map[string]Target, error)
+}
+
+// Delivery statuses. A delivery is retrying until an attempt succeeds or the
+// dispatcher gives up.
+const (
+	DeliveryRetrying  = "retrying"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Attempt is one try at sending a delivery. StatusCode is 0 when no response
+// arrived.
+type Attempt struct {
+	DeliveryID string
+	WebhookID  int64
+	Payload    []byte
+	Status     string
+	StatusCode int
+	Err        string
+	Duration   time.Duration
+	Manual     bool
+}
+
+// DeliveryLog stores attempts so failed deliveries can be inspected and sent
+// again.
+type DeliveryLog interface {
+	RecordWebhookAttempt(ctx context.Context, a Attempt) error
+}
+
+// Payload is one delivery. ID is repeated in the X-Webhook-Id header and
+// stays the same across retries.
+type Payload struct {
+	ID        string         `json:"id"`
+	ShortCode string         `json:"short_url"`
+	Events    []events.Event `json:"events"`
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
+
+// Dispatcher batches click events per short code and POSTs them to the
+// link's registered webhook off the redirect path.
+type Dispatcher struct {
+	Store         TargetStore
+	Log           DeliveryLog
+	Client        *http.Client
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxAttempts   int
+	// Queue holds click events until they are batched for delivery.
+	Queue queue.Queue
+}
+
+func NewDispatcher(store TargetStore) *Dispatcher {
+	return &Dispatcher{
+		Store:         store,
+		Client:        &http.Client{Timeout: DefaultTimeout},
+		BatchSize:     DefaultBatchSize,
+		FlushInterval: DefaultFlushInterval,
+		MaxAttempts:   DefaultMaxAttempts,
+		Queue:         queue.NewMemory(DefaultQueueSize),
+	}
+}
+
+func (d *Dispatcher) Enqueue(e events.Event) {
+	if err := d.Queue.Publish(e); err != nil {
+		log.Printf("WARN: Webhook queue full. Dropping %s event for %s.", e.Type, e.ShortCode)
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	queue.Consume(ctx, d.Queue, d.BatchSize, d.FlushInterval, d.flush)
+}
+
+func (d *Dispatcher) flush(batch []events.Event) {
+	byCode := make(map[string][]events.Event)
+	codes := make([]string, 0)
+	for _, e := range batch {
+		if _, ok := byCode[e.ShortCode]; !ok {
+			codes = append(codes, e.ShortCode)
+		}
+		byCode[e.ShortCode] = append(byCode[e.ShortCode], e)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	targets, err := d.Store.FindWebhookTargets(ctx, codes)
+	cancel()
+	if err != nil {
+		log.Printf("ERROR: Webhook target lookup failed, dropping %d events: %v", len(batch), err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentSends)
+	for code, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(target Target, payload Payload) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(target, payload)
+		}(target, Payload{ID: newDeliveryID(), ShortCode: code, Events: byCode[code]})
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(target Target, payload Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("ERROR: Failed to encode webhook payload for %s: %v", payload.ShortCode, err)
+		return
+	}
+
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		status := DeliveryRetrying
+		if attempt == d.MaxAttempts {
+			status = DeliveryFailed
+		}
+		a := d.attempt(context.Background(), target, payload.ID, body, status)
+		d.record(a)
+		if a.Status == DeliverySucceeded {
+			return
+		}
+		log.Printf("WARN: Webhook delivery for %s to %s failed (%d/%d): %s", payload.ShortCode, target.URL, attempt, d.MaxAttempts, a.Err)
+		time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+	}
+	log.Printf("ERROR: Giving up on webhook delivery for %s after %d attempts (%d events).", payload.ShortCode, d.MaxAttempts, len(payload.Events))
+}
+
+// Redeliver makes one manual attempt at a stored delivery, reusing its ID so
+// receivers that already processed it can recognise the repeat.
+func (d *Dispatcher) Redeliver(ctx context.Context, target Target, id string, body []byte) Attempt {
+	a := d.attempt(ctx, target, id, body, DeliveryFailed)
+	a.Manual = true
+	d.record(a)
+	return a
+}
+
+// attempt posts body once, reporting failedStatus if it does not succeed.
+func (d *Dispatcher) attempt(ctx context.Context, target Target, id string, body []byte, failedStatus string) Attempt {
+	start := time.Now()
+	code, err := d.post(ctx, target, id, body)
+	a := Attempt{
+		DeliveryID: id,
+		WebhookID:  target.ID,
+		Payload:    body,
+		Status:     DeliverySucceeded,
+		StatusCode: code,
+		Duration:   time.Since(start),
+	}
+	if err != nil {
+		a.Status = failedStatus
+		a.Err = err.Error()
+	}
+	return a
+}
+
+func (d *Dispatcher) record(a Attempt) {
+	if d.Log == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	if err := d.Log.RecordWebhookAttempt(ctx, a); err != nil {
+		log.Printf("ERROR: Failed to record webhook delivery %s: %v", a.DeliveryID, err)
+	}
+}
+
+// post signs each attempt with a fresh timestamp, so a retry that arrives
+// late is not rejected by the receiver's tolerance check.
+func (d *Dispatcher) post(ctx context.Context, target Target, id string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "urlShortener-webhook/1.0")
+	req.Header.Set(HeaderID, id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(target.Secret, id, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery headers. The signature covers the delivery ID, the timestamp and
+// the raw body, joined by dots, so none of them can be changed or replayed
+// under a different ID without the endpoint's secret.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signatureVersion = "v1="
+
+	// DefaultTolerance is how old a delivery may be before Verify rejects it.
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook signature headers missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleDelivery    = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign computes the signature header value for a delivery.
+func Sign(secret string, id string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(id))
+	mac.Write([]byte{'.'})
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify authenticates a delivery received by a Go service: it checks the
+// signature against the endpoint's secret and rejects timestamps further than
+// tolerance from now. It returns the delivery ID. Retries of one delivery
+// share the ID, so receivers that must act once should remember IDs seen
+// within the tolerance window and drop repeats.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) (string, error) {
+	id := header.Get(HeaderID)
+	rawTimestamp := header.Get(HeaderTimestamp)
+	signature := header.Get(HeaderSignature)
+	if id == "" || rawTimestamp == "" || !strings.HasPrefix(signature, signatureVersion) {
+		return "", ErrMissingSignature
+	}
+
+	timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return "", ErrMissingSignature
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return "", ErrStaleDelivery
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, id, timestamp, body))) {
+		return "", ErrInvalidSignature
+	}
+	return id, nil
+}
@@ -0,0 +1,51 @@
+// Package wellknown serves files under /.well-known/ from a directory, so
+// the short domain can publish security.txt, Android assetlinks.json and
+// Apple's apple-app-site-association for app links.
+package wellknown
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contentTypes covers well-known files whose type cannot be told from their
+// name; Apple requires JSON for the extensionless association file.
+var contentTypes = map[string]string{
+	"apple-app-site-association": "application/json",
+	"security.txt":               "text/plain; charset=utf-8",
+	"assetlinks.json":            "application/json",
+}
+
+// Handler serves /.well-known/*file from dir. Only regular files directly
+// in dir or its subdirectories are served; dotfiles and anything outside
+// dir are not.
+func Handler(dir string) gin.HandlerFunc {
+	root := os.DirFS(dir)
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(path.Clean(c.Param("file")), "/")
+		if !fs.ValidPath(name) || name == "." || strings.HasPrefix(path.Base(name), ".") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		info, err := fs.Stat(root, name)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.Mode().IsRegular()) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+			return
+		}
+		if ct, ok := contentTypes[path.Base(name)]; ok {
+			c.Header("Content-Type", ct)
+		}
+		c.Header("Cache-Control", "public, max-age=3600")
+		c.FileFromFS(name, http.FS(root))
+	}
+}